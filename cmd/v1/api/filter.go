@@ -0,0 +1,119 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenmud/edgedb/internal/store"
+)
+
+// parseValue parses a predicate value from a query string.
+// `true` and `false` are booleans, numbers are float64 and everything else is a string.
+// Double quoting the value forces a string, eg: `"42"`.
+func parseValue(raw string) any {
+	if len(raw) >= 2 && strings.HasPrefix(raw, `"`) && strings.HasSuffix(raw, `"`) {
+		return raw[1 : len(raw)-1]
+	}
+
+	switch raw {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	if f, err := strconv.ParseFloat(raw, 64); err == nil {
+		return f
+	}
+
+	return raw
+}
+
+// parsePredicate parses a `path:op:value` predicate, eg: `age:gt:30` or `meta.hair:in:brown,black`.
+// The value is omitted for `exists` and `nexists`, eg: `meta.hair:exists`.
+func parsePredicate(raw string) (store.Predicate, error) {
+	parts := strings.SplitN(raw, ":", 3)
+	if len(parts) < 2 {
+		return store.Predicate{}, fmt.Errorf("invalid predicate %q: expected path:op:value", raw)
+	}
+
+	p := store.Predicate{Path: parts[0], Op: store.Operator(parts[1])}
+
+	switch p.Op {
+	case store.OpExists, store.OpNotExists:
+	case store.OpIn:
+		if len(parts) != 3 {
+			return store.Predicate{}, fmt.Errorf("invalid predicate %q: expected path:in:value,value", raw)
+		}
+
+		values := []any{}
+		for _, v := range strings.Split(parts[2], ",") {
+			values = append(values, parseValue(v))
+		}
+		p.Value = values
+	default:
+		if len(parts) != 3 {
+			return store.Predicate{}, fmt.Errorf("invalid predicate %q: expected path:op:value", raw)
+		}
+		p.Value = parseValue(parts[2])
+	}
+
+	return p, p.Validate()
+}
+
+// parseTime parses a RFC3339 timestamp or unix seconds, an empty value returns the zero time.
+func parseTime(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+
+	if secs, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: expected RFC3339 or unix seconds", raw)
+	}
+
+	return t, nil
+}
+
+// parseFilter parses the `label`, `where`, `createdAfter`, `createdBefore`, `updatedAfter` and `updatedBefore`
+// query parameters into a store filter.
+func parseFilter(r *http.Request) (store.Filter, error) {
+	query := r.URL.Query()
+
+	f := store.Filter{Labels: splitQuery(r, "label")}
+
+	for _, raw := range query["where"] {
+		p, err := parsePredicate(raw)
+		if err != nil {
+			return f, err
+		}
+		f.Where = append(f.Where, p)
+	}
+
+	times := []struct {
+		key string
+		t   *time.Time
+	}{
+		{key: "createdAfter", t: &f.CreatedAt.After},
+		{key: "createdBefore", t: &f.CreatedAt.Before},
+		{key: "updatedAfter", t: &f.UpdatedAt.After},
+		{key: "updatedBefore", t: &f.UpdatedAt.Before},
+	}
+
+	for _, tt := range times {
+		t, err := parseTime(query.Get(tt.key))
+		if err != nil {
+			return f, err
+		}
+		*tt.t = t
+	}
+
+	return f, nil
+}
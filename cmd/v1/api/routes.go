@@ -1,14 +1,26 @@
 package api
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/jenmud/edgedb/internal/export"
+	"github.com/jenmud/edgedb/internal/graphs"
+	"github.com/jenmud/edgedb/internal/importer"
+	"github.com/jenmud/edgedb/internal/query"
 	"github.com/jenmud/edgedb/internal/store"
 	"github.com/jenmud/edgedb/models"
+	"github.com/starfederation/datastar-go/datastar"
 )
 
 // GetNodes searches and return nodes
@@ -22,9 +34,17 @@ import (
 // @Param tokens query int false "snippet tokens" minimum(1) maximum(64) default(10)
 // @Param limit query int false "limit results returned" minimum(1) default(1000)
 // @Param lastID query int false "last known ID from previous result used for pagination" default(0)
+// @Param label query []string false "only return items with any of the labels" collectionFormat(multi)
+// @Param where query []string false "property predicate as path:op:value, op is one of eq, ne, gt, gte, lt, lte, in, exists, nexists" collectionFormat(multi)
+// @Param createdAfter query string false "only return items created at or after the RFC3339 or unix time"
+// @Param createdBefore query string false "only return items created before the RFC3339 or unix time"
+// @Param updatedAfter query string false "only return items updated at or after the RFC3339 or unix time"
+// @Param updatedBefore query string false "only return items updated before the RFC3339 or unix time"
+// @Param asOf query string false "return the nodes as they were at the RFC3339 or unix time, can not be combined with term"
 // @Success 200 {array} models.Node "List of nodes"
 // @Failure 400 "Bad request"
 // @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support history"
 // @Router /api/v1/nodes [get]
 func GETNodes(mux *http.ServeMux, s store.Store) {
 	slog.Info("registered route", slog.String("route", "GET /api/v1/nodes"))
@@ -56,9 +76,40 @@ func GETNodes(mux *http.ServeMux, s store.Store) {
 			err   error
 		)
 
-		if term == "" {
-			nodes, err = s.Nodes(ctx, store.NodesArgs{Limit: limit, LastID: lastID})
-		} else {
+		filter, err := parseFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if term != "" && !filter.IsZero() {
+			http.Error(w, "term can not be combined with label, where or time filters", http.StatusBadRequest)
+			return
+		}
+
+		asOf, err := parseAsOf(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if term != "" && !asOf.IsZero() {
+			http.Error(w, "term can not be combined with asOf", http.StatusBadRequest)
+			return
+		}
+
+		switch {
+		case !asOf.IsZero():
+			h, ok := s.(store.Historian)
+			if !ok {
+				http.Error(w, "store does not support history", http.StatusNotImplemented)
+				return
+			}
+
+			nodes, err = h.NodesAsOf(ctx, store.NodesArgs{Limit: limit, LastID: lastID, Filter: filter}, asOf)
+		case term == "":
+			nodes, err = s.Nodes(ctx, store.NodesArgs{Limit: limit, LastID: lastID, Filter: filter})
+		default:
 			args := store.TermSearchArgs{Term: term, Limit: limit, LastID: lastID, SnippetStart: snippetStart, SnippetEnd: snippetEnd, SnippetTokens: tokens}
 			nodes, err = s.NodesTermSearch(ctx, args)
 		}
@@ -86,11 +137,15 @@ type PUTNodesReq struct {
 // PUTNodes adds/update one or more nodes
 // @Summary Add/update one or more nodes.
 // @Description Add/update on or more nodes.
+// @Description With merge the nodes are matched on the natural key of their label instead of their ID, and each node reports if it was created.
+// @Description Nodes with a version are only written if it is the current version, otherwise nothing is written.
 // @Tags nodes
 // @Produce json
 // @Param nodes body PUTNodesReq true "One or more nodes to add/update"
-// @Success 200 {array} models.Node "List of nodes"
-// @Failure 400 "Bad request"
+// @Param merge query bool false "match the nodes on the natural key of their label" default(false)
+// @Success 200 {array} store.MergedNode "List of nodes, created is only reported when merging"
+// @Failure 400 {object} store.SchemaError "Bad request or a node does not match its schema"
+// @Failure 409 {object} store.VersionError "Another node has the same natural key or a node is not at the version"
 // @Failure 500 "Internal server error"
 // @Router /api/v1/nodes [put]
 func PUTNodes(mux *http.ServeMux, s store.Store) {
@@ -107,9 +162,21 @@ func PUTNodes(mux *http.ServeMux, s store.Store) {
 			return
 		}
 
-		nodes, err := s.UpsertNodes(ctx, req.Nodes...)
+		merge, err := parseMerge(r)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var nodes any
+		if merge {
+			nodes, err = s.MergeNodes(ctx, req.Nodes...)
+		} else {
+			nodes, err = s.UpsertNodes(ctx, req.Nodes...)
+		}
+
+		if err != nil {
+			upsertError(w, err)
 			return
 		}
 
@@ -123,6 +190,213 @@ func PUTNodes(mux *http.ServeMux, s store.Store) {
 	})
 }
 
+// DELETENodesReq is a request for deleting one or more nodes.
+type DELETENodesReq struct {
+	IDs    []uint64
+	Policy store.DeletePolicy
+
+	// Soft moves the nodes to the trash instead of deleting them.
+	Soft bool
+}
+
+// DELETENodes deletes one or more nodes
+// @Summary Delete one or more nodes.
+// @Description Delete one or more nodes applying the policy (restrict, cascade or detach) to the attached edges.
+// @Description restrict fails with a conflict listing the edges blocking the delete. Soft moves the nodes to the trash.
+// @Tags nodes
+// @Accept json
+// @Produce json
+// @Param nodes body DELETENodesReq true "One or more nodes to delete"
+// @Success 200 {object} store.DeleteResult "Deleted nodes and edges"
+// @Failure 400 "Bad request"
+// @Failure 409 {object} store.RestrictError "Edges still attached to the nodes"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support the trash"
+// @Router /api/v1/nodes [delete]
+func DELETENodes(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "DELETE /api/v1/nodes"))
+	mux.HandleFunc("DELETE /api/v1/nodes", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		req := DELETENodesReq{}
+		defer r.Body.Close()
+
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		remove := s.DeleteNodes
+
+		if req.Soft {
+			ts, ok := s.(store.TrashStore)
+			if !ok {
+				http.Error(w, "store does not support the trash", http.StatusNotImplemented)
+				return
+			}
+
+			remove = ts.TrashNodes
+		}
+
+		result, err := remove(ctx, store.DeleteNodesArgs{IDs: req.IDs, Policy: req.Policy})
+		if err != nil {
+			deleteError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// DELETENodeByID deletes a single node
+// @Summary Delete a node.
+// @Description Delete a node applying the policy (restrict, cascade or detach) to the attached edges.
+// @Description restrict fails with a conflict listing the edges blocking the delete.
+// @Tags nodes
+// @Produce json
+// @Param id path int true "Node id"
+// @Param policy query string false "policy applied to attached edges" Enums(restrict, cascade, detach) default(restrict)
+// @Param soft query bool false "move the node to the trash instead of deleting it" default(false)
+// @Success 200 {object} store.DeleteResult "Deleted node and edges"
+// @Failure 400 "Bad request"
+// @Failure 404 "Not found"
+// @Failure 409 {object} store.RestrictError "Edges still attached to the node"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support the trash"
+// @Router /api/v1/nodes/{id} [delete]
+func DELETENodeByID(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "DELETE /api/v1/nodes/{id}"))
+	mux.HandleFunc("DELETE /api/v1/nodes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		policy := store.DeletePolicy(r.URL.Query().Get("policy"))
+
+		soft, err := parseSoft(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		remove := s.DeleteNodes
+
+		if soft {
+			ts, ok := s.(store.TrashStore)
+			if !ok {
+				http.Error(w, "store does not support the trash", http.StatusNotImplemented)
+				return
+			}
+
+			remove = ts.TrashNodes
+		}
+
+		result, err := remove(ctx, store.DeleteNodesArgs{IDs: []uint64{id}, Policy: policy})
+		if err != nil {
+			deleteError(w, err)
+			return
+		}
+
+		if len(result.Nodes) == 0 {
+			http.Error(w, store.ErrNotFound.Error(), http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// parseSoft returns the optional soft query parameter.
+func parseSoft(r *http.Request) (bool, error) {
+	soft := r.URL.Query().Get("soft")
+	if soft == "" {
+		return false, nil
+	}
+
+	return strconv.ParseBool(soft)
+}
+
+// parseMerge returns the optional merge query parameter.
+func parseMerge(r *http.Request) (bool, error) {
+	merge := r.URL.Query().Get("merge")
+	if merge == "" {
+		return false, nil
+	}
+
+	return strconv.ParseBool(merge)
+}
+
+// upsertError writes the upsert error, schema violations are returned as a bad request listing the violations and
+// stale versions are returned as a conflict holding the current item.
+func upsertError(w http.ResponseWriter, err error) {
+	var schemaErr *store.SchemaError
+	var versionErr *store.VersionError
+
+	switch {
+	case errors.As(err, &schemaErr):
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusBadRequest)
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(schemaErr); err != nil {
+			slog.Error("error encoding schema error", slog.String("reason", err.Error()))
+		}
+	case errors.As(err, &versionErr):
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusConflict)
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(versionErr); err != nil {
+			slog.Error("error encoding version error", slog.String("reason", err.Error()))
+		}
+	case errors.Is(err, store.ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, store.ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// deleteError writes the delete error, restrict errors are returned as a conflict listing the blocking edges.
+func deleteError(w http.ResponseWriter, err error) {
+	var restrict *store.RestrictError
+
+	switch {
+	case errors.As(err, &restrict):
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusConflict)
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(restrict); err != nil {
+			slog.Error("error encoding conflict", slog.String("reason", err.Error()))
+		}
+	case errors.Is(err, store.ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, store.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 // GetEdges searches and return edges
 // @Summary Search and return edges
 // @Description Search and return edges.
@@ -134,6 +408,12 @@ func PUTNodes(mux *http.ServeMux, s store.Store) {
 // @Param tokens query int false "snippet tokens" minimum(1) maximum(64) default(10)
 // @Param limit query int false "limit results returned" minimum(1) default(1000)
 // @Param lastID query int false "last known ID from previous result used for pagination" default(0)
+// @Param label query []string false "only return items with any of the labels" collectionFormat(multi)
+// @Param where query []string false "property predicate as path:op:value, op is one of eq, ne, gt, gte, lt, lte, in, exists, nexists" collectionFormat(multi)
+// @Param createdAfter query string false "only return items created at or after the RFC3339 or unix time"
+// @Param createdBefore query string false "only return items created before the RFC3339 or unix time"
+// @Param updatedAfter query string false "only return items updated at or after the RFC3339 or unix time"
+// @Param updatedBefore query string false "only return items updated before the RFC3339 or unix time"
 // @Success 200 {array} models.Edge "List of edges"
 // @Failure 400 "Bad request"
 // @Failure 500 "Internal server error"
@@ -168,8 +448,19 @@ func GETEdges(mux *http.ServeMux, s store.Store) {
 			err   error
 		)
 
+		filter, err := parseFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if term != "" && !filter.IsZero() {
+			http.Error(w, "term can not be combined with label, where or time filters", http.StatusBadRequest)
+			return
+		}
+
 		if term == "" {
-			edges, err = s.Edges(ctx, store.EdgesArgs{Limit: limit, LastID: lastID})
+			edges, err = s.Edges(ctx, store.EdgesArgs{Limit: limit, LastID: lastID, Filter: filter})
 		} else {
 			args := store.TermSearchArgs{Term: term, Limit: limit, LastID: lastID, SnippetStart: snippetStart, SnippetEnd: snippetEnd, SnippetTokens: tokens}
 			edges, err = s.EdgesTermSearch(ctx, args)
@@ -198,11 +489,15 @@ type PUTEdgesReq struct {
 // PUTEdges adds/update one or more edges
 // @Summary Add/update one or more edges.
 // @Description Add/update on or more edges.
+// @Description With merge the edges are matched on their from ID, label and to ID instead of their ID, and each edge reports if it was created.
+// @Description Edges with a version are only written if it is the current version, otherwise nothing is written.
 // @Tags edges
 // @Produce json
 // @Param nodes body PUTEdgesReq true "One or more nodes to add/update"
-// @Success 200 {array} models.Edge "List of edges"
-// @Failure 400 "Bad request"
+// @Param merge query bool false "match the edges on their from ID, label and to ID" default(false)
+// @Success 200 {array} store.MergedEdge "List of edges, created is only reported when merging"
+// @Failure 400 {object} store.SchemaError "Bad request or an edge does not match its schema"
+// @Failure 409 {object} store.VersionError "An edge is not at the version"
 // @Failure 500 "Internal server error"
 // @Router /api/v1/edges [put]
 func PUTEdges(mux *http.ServeMux, s store.Store) {
@@ -219,7 +514,81 @@ func PUTEdges(mux *http.ServeMux, s store.Store) {
 			return
 		}
 
-		edges, err := s.UpsertEdges(ctx, req.Edges...)
+		merge, err := parseMerge(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var edges any
+		if merge {
+			edges, err = s.MergeEdges(ctx, req.Edges...)
+		} else {
+			edges, err = s.UpsertEdges(ctx, req.Edges...)
+		}
+
+		if err != nil {
+			upsertError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(edges); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// DELETEEdgesReq is a request for deleting one or more edges.
+type DELETEEdgesReq struct {
+	IDs []uint64
+
+	// Soft moves the edges to the trash instead of deleting them.
+	Soft bool
+}
+
+// DELETEEdges deletes one or more edges
+// @Summary Delete one or more edges.
+// @Description Delete one or more edges. Soft moves the edges to the trash.
+// @Tags edges
+// @Accept json
+// @Produce json
+// @Param edges body DELETEEdgesReq true "One or more edges to delete"
+// @Success 200 {array} models.Edge "List of deleted edges"
+// @Failure 400 "Bad request"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support the trash"
+// @Router /api/v1/edges [delete]
+func DELETEEdges(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "DELETE /api/v1/edges"))
+	mux.HandleFunc("DELETE /api/v1/edges", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		req := DELETEEdgesReq{}
+		defer r.Body.Close()
+
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		remove := s.DeleteEdges
+
+		if req.Soft {
+			ts, ok := s.(store.TrashStore)
+			if !ok {
+				http.Error(w, "store does not support the trash", http.StatusNotImplemented)
+				return
+			}
+
+			remove = ts.TrashEdges
+		}
+
+		edges, err := remove(ctx, req.IDs...)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -245,9 +614,11 @@ func PUTEdges(mux *http.ServeMux, s store.Store) {
 // @Param snippetEnd query string false "snippet start" default(</span>)
 // @Param tokens query int false "snippet tokens" minimum(1) maximum(64) default(10)
 // @Param limit query int false "limit results returned" minimum(1) default(1000)
+// @Param asOf query string false "search the graph as it was at the RFC3339 or unix time"
 // @Success 200 {object} models.Graph "Payload used for drawing graphs."
 // @Failure 400 "Bad request"
 // @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support history"
 // @Router /api/v1/graph [get]
 func GETGraph(mux *http.ServeMux, s store.Store) {
 	slog.Info("registered route", slog.String("route", "GET /api/v1/graph"))
@@ -269,7 +640,28 @@ func GETGraph(mux *http.ServeMux, s store.Store) {
 			tokens = s
 		}
 
-		graph, err := s.Graph(ctx, store.TermSearchArgs{Limit: limit, Term: term, SnippetTokens: tokens, SnippetStart: snippetStart, SnippetEnd: snippetEnd})
+		asOf, err := parseAsOf(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		args := store.TermSearchArgs{Limit: limit, Term: term, SnippetTokens: tokens, SnippetStart: snippetStart, SnippetEnd: snippetEnd}
+
+		var graph models.Graph
+
+		if asOf.IsZero() {
+			graph, err = s.Graph(ctx, args)
+		} else {
+			h, ok := s.(store.Historian)
+			if !ok {
+				http.Error(w, "store does not support history", http.StatusNotImplemented)
+				return
+			}
+
+			graph, err = h.GraphAsOf(ctx, args, asOf)
+		}
+
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
@@ -287,12 +679,13 @@ func GETGraph(mux *http.ServeMux, s store.Store) {
 
 // PUTGraph uploads a graph using a upsert strategy.
 // @Summary Uploads a graph using a upsert strategy.
-// @Description Uploads a graph using a upsert strategy.
+// @Description Uploads a graph using a upsert strategy in a single transaction, either the whole graph is written or nothing is.
+// @Description Nodes can set a temporary `ref` which edges use in `from_ref` and `to_ref` instead of node IDs.
 // @Tags graph
 // @Produce json
 // @Param nodes body models.Graph true "Graph that you are uploading"
-// @Success 200 {object} models.Graph "Uploaded graph."
-// @Failure 400 "Bad request"
+// @Success 200 {object} store.UpsertGraphResult "Uploaded graph and the node ID for every ref."
+// @Failure 400 {object} store.SchemaError "Bad request or an item does not match its schema"
 // @Failure 500 "Internal server error"
 // @Router /api/v1/graph [put]
 func PUTGraph(mux *http.ServeMux, s store.Store) {
@@ -309,25 +702,14 @@ func PUTGraph(mux *http.ServeMux, s store.Store) {
 			return
 		}
 
-		nodes, err := s.UpsertNodes(ctx, req.Nodes...)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		edges, err := s.UpsertEdges(ctx, req.Edges...)
+		resp, err := s.UpsertGraph(ctx, req)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			upsertError(w, err)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 
-		resp := models.Graph{
-			Nodes: nodes,
-			Edges: edges,
-		}
-
 		encoder := json.NewEncoder(w)
 		if err := encoder.Encode(resp); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -338,24 +720,54 @@ func PUTGraph(mux *http.ServeMux, s store.Store) {
 
 // GETSubGraphByNode returns a sub-graph from a node.
 // @Summary Returns a graph from a node.
-// @Description Returns a graph from a node.
+// @Description Returns the neighbourhood N hops deep from a node.
 // @Tags graph
 // @Produce json
 // @Param id path int true "Node id"
-// @Param limit query int false "how many levels deep to return" minimum(1) default(1)
+// @Param depth query int false "how many levels (hops) deep to return" minimum(1) maximum(100) default(1)
+// @Param limit query int false "deprecated: use depth" minimum(1) maximum(100) default(1)
+// @Param direction query string false "direction edges are followed" Enums(out, in, both) default(both)
+// @Param labels query []string false "only follow edges with these labels" collectionFormat(csv)
+// @Param maxNodes query int false "node budget, closest nodes are returned first" minimum(1) default(1000)
+// @Param asOf query string false "return the sub-graph as it was at the RFC3339 or unix time"
 // @Success 200 {object} models.Graph "Payload used for drawing graphs."
+// @Failure 400 "Bad request"
 // @Failure 404 "Not found"
 // @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support history"
 // @Router /api/v1/graph/nodes/{id} [get]
 func GETSubGraphByNode(mux *http.ServeMux, s store.Store) {
 	slog.Info("registered route", slog.String("route", "GET /api/v1/graph/nodes/{id}"))
 	mux.HandleFunc("GET /api/v1/graph/nodes/{id}", func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		args := store.SubGraphArgs{Limit: 1}
+		args := store.SubGraphArgs{
+			Depth:      1,
+			Direction:  store.Direction(r.URL.Query().Get("direction")),
+			EdgeLabels: splitQuery(r, "labels"),
+		}
 
-		if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil {
-			args.Limit = l
+		var err error
+
+		// limit was documented as the depth before depth was introduced.
+		if args.Depth, err = queryInt(r, "limit", args.Depth); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if args.Depth, err = queryInt(r, "depth", args.Depth); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if args.Depth > store.MaxSubGraphDepth {
+			http.Error(w, fmt.Sprintf("depth can not be more than %d", store.MaxSubGraphDepth), http.StatusBadRequest)
+			return
+		}
+
+		if args.Limit, err = queryInt(r, "maxNodes", args.Limit); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
 
 		idstr := r.PathValue("id")
@@ -366,14 +778,32 @@ func GETSubGraphByNode(mux *http.ServeMux, s store.Store) {
 		}
 
 		args.FromNodeID = id
-		args.ToNodeID = id
 
-		graph, err := s.SubGraph(ctx, args)
+		asOf, err := parseAsOf(r)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
+		var graph models.Graph
+
+		if asOf.IsZero() {
+			graph, err = s.SubGraph(ctx, args)
+		} else {
+			h, ok := s.(store.Historian)
+			if !ok {
+				http.Error(w, "store does not support history", http.StatusNotImplemented)
+				return
+			}
+
+			graph, err = h.SubGraphAsOf(ctx, args, asOf)
+		}
+
+		if err != nil {
+			traversalError(w, err)
+			return
+		}
+
 		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 
 		encoder := json.NewEncoder(w)
@@ -384,23 +814,2483 @@ func GETSubGraphByNode(mux *http.ServeMux, s store.Store) {
 	})
 }
 
-// HealthStatus returns returns the health status.
-// @Summary Returns returns the health status.
-// @Description Returns returns the health status.
-// @Tags Health
+// GETPath returns the shortest paths between two nodes.
+// @Summary Returns the shortest paths between two nodes.
+// @Description Returns the shortest paths between two nodes as a graph and an ordered hop list.
+// @Description shortest uses the fewest hops, weighted uses the lowest total edge weight.
+// @Tags graph
 // @Produce json
-// @Success 200 {object} models.Health "Current health status"
+// @Param from query int true "node id the path starts from"
+// @Param to query int true "node id the path ends at"
+// @Param mode query string false "path finding mode" Enums(shortest, weighted) default(shortest)
+// @Param maxDepth query int false "max number of hops in a path" minimum(1) maximum(50) default(10)
+// @Param direction query string false "direction edges are followed" Enums(out, in, both) default(out)
+// @Param labels query []string false "only follow edges with these labels" collectionFormat(csv)
+// @Param k query int false "number of shortest paths to return" minimum(1) maximum(100) default(1)
+// @Success 200 {array} models.Path "Paths, shortest first"
+// @Failure 400 "Bad request"
+// @Failure 404 "No path found"
 // @Failure 500 "Internal server error"
-// @Router /healthz [get]
-func HealthStatus(mux *http.ServeMux, s store.Store) {
-	slog.Info("registered route", slog.String("route", "GET /healthz"))
-	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+// @Router /api/v1/graph/path [get]
+func GETPath(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "GET /api/v1/graph/path"))
+	mux.HandleFunc("GET /api/v1/graph/path", func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		args := store.PathArgs{
+			Mode:       store.PathMode(r.URL.Query().Get("mode")),
+			Direction:  store.Direction(r.URL.Query().Get("direction")),
+			EdgeLabels: splitQuery(r, "labels"),
+		}
 
-		encoder := json.NewEncoder(w)
-		if err := encoder.Encode(s.Health(ctx)); err != nil {
+		from, err := strconv.ParseUint(r.URL.Query().Get("from"), 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid from node id: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		to, err := strconv.ParseUint(r.URL.Query().Get("to"), 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid to node id: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		args.From = from
+		args.To = to
+
+		if args.MaxDepth, err = queryInt(r, "maxDepth", args.MaxDepth); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if args.MaxDepth > store.MaxDepth {
+			http.Error(w, fmt.Sprintf("maxDepth can not be more than %d", store.MaxDepth), http.StatusBadRequest)
+			return
+		}
+
+		if args.K, err = queryInt(r, "k", args.K); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if args.K > store.MaxK {
+			http.Error(w, fmt.Sprintf("k can not be more than %d", store.MaxK), http.StatusBadRequest)
+			return
+		}
+
+		paths, err := s.Paths(ctx, args)
+		if err != nil {
+			traversalError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(paths); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// traversalError writes the sub graph and path errors, invalid arguments are returned as a bad request.
+func traversalError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, store.ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// queryInt returns the integer value for the query key, or the fallback if the key is not given.
+func queryInt(r *http.Request, key string, fallback int) (int, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	return i, nil
+}
+
+// splitQuery returns the values for the query key supporting both repeated keys and comma separated values.
+func splitQuery(r *http.Request, key string) []string {
+	values := []string{}
+
+	for _, v := range r.URL.Query()[key] {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+	}
+
+	return values
+}
+
+// ReindexResp is the response returned after reindexing the store.
+type ReindexResp struct {
+	Indexed int `json:"indexed"`
+}
+
+// POSTReindex rebuilds the term search index.
+// @Summary Rebuilds the term search index.
+// @Description Rebuilds the term search index from the stored nodes and edges, used to repair drifted indexes.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} ReindexResp "Number of items indexed"
+// @Failure 500 "Internal server error"
+// @Router /api/v1/admin/reindex [post]
+func POSTReindex(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "POST /api/v1/admin/reindex"))
+	mux.HandleFunc("POST /api/v1/admin/reindex", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		indexed, err := s.Reindex(ctx)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(ReindexResp{Indexed: indexed}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// HealthStatus returns returns the health status.
+// @Summary Returns returns the health status.
+// @Description Returns returns the health status.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} models.Health "Current health status"
+// @Failure 500 "Internal server error"
+// @Router /healthz [get]
+func HealthStatus(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "GET /healthz"))
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(s.Health(ctx)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// POSTQueryReq is a request for running a read-only graph query.
+type POSTQueryReq struct {
+	// Query is the query, eg: `MATCH (a:person)-[r:knows*1..3]->(b) WHERE a.name = $name RETURN a, r, b LIMIT 10`.
+	Query string
+
+	// Params are the values bound to the `$name` parameters.
+	Params map[string]any
+
+	// Format is the response format, either `table` (default) or `graph`.
+	Format string
+}
+
+// POSTQuery runs a read-only graph query.
+// @Summary Run a read-only graph query.
+// @Description Run a read-only Cypher-like query supporting MATCH, WHERE, RETURN, ORDER BY, SKIP and LIMIT.
+// @Description The `table` format returns the columns and rows, the `graph` format returns the returned nodes and edges.
+// @Tags query
+// @Accept json
+// @Produce json
+// @Param query body POSTQueryReq true "Query, parameters and response format"
+// @Success 200 {object} models.Table "Rows when the format is table"
+// @Success 200 {object} models.Graph "Graph when the format is graph"
+// @Header 200 {boolean} X-EdgeDB-Truncated "true if a variable length relationship stopped walking early"
+// @Failure 400 "Bad request"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support queries"
+// @Router /api/v1/query [post]
+func POSTQuery(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "POST /api/v1/query"))
+	mux.HandleFunc("POST /api/v1/query", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		querier, ok := s.(store.Querier)
+		if !ok {
+			http.Error(w, "store does not support queries", http.StatusNotImplemented)
+			return
+		}
+
+		req := POSTQueryReq{}
+		defer r.Body.Close()
+
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if req.Format == "" {
+			req.Format = "table"
+		}
+
+		if req.Format != "table" && req.Format != "graph" {
+			http.Error(w, fmt.Sprintf("unsupported format: %s", req.Format), http.StatusBadRequest)
+			return
+		}
+
+		result, err := querier.Query(ctx, store.QueryArgs{Query: req.Query, Params: req.Params})
+		if err != nil {
+			var qerr *query.Error
+			if errors.As(err, &qerr) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.Header().Set("X-EdgeDB-Truncated", strconv.FormatBool(result.Truncated))
+
+		var resp any = result.Table
+		if req.Format == "graph" {
+			resp = result.Graph
+		}
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(resp); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// maxImportLine is the longest NDJSON line accepted when importing.
+const maxImportLine = 16 * 1024 * 1024
+
+// ImportEvent is a single NDJSON line streamed back while importing.
+type ImportEvent struct {
+	// Type is `error` for a skipped line, `progress` after every chunk, `done` once finished or `failed` if the import stopped.
+	Type string `json:"type"`
+
+	// Line is the line number of a skipped line.
+	Line int `json:"line,omitempty"`
+
+	// Error is the reason a line was skipped or the import failed.
+	Error string `json:"error,omitempty"`
+
+	// Progress is the progress so far.
+	Progress *store.ImportProgress `json:"progress,omitempty"`
+}
+
+// importLine is the type of an imported NDJSON line.
+type importLine struct {
+	Type string `json:"type"`
+}
+
+// decodeImportLine decodes a NDJSON line into a node or edge, the `type` field is either `node` or `edge`.
+func decodeImportLine(raw []byte) store.ImportItem {
+	line := importLine{}
+	if err := json.Unmarshal(raw, &line); err != nil {
+		return store.ImportItem{Err: err}
+	}
+
+	switch line.Type {
+	case "node":
+		n := models.Node{}
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return store.ImportItem{Err: err}
+		}
+		return store.ImportItem{Node: &n}
+	case "edge":
+		e := models.Edge{}
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return store.ImportItem{Err: err}
+		}
+		return store.ImportItem{Edge: &e}
+	default:
+		return store.ImportItem{Err: fmt.Errorf("unsupported type %q, expected node or edge", line.Type)}
+	}
+}
+
+// POSTImport streams a NDJSON import.
+// @Summary Streams a NDJSON import.
+// @Description Imports a streamed NDJSON body with one node or edge per line, eg: `{"type": "node", "ref": "n1", "label": "person"}`
+// @Description or `{"type": "edge", "from_ref": "n1", "label": "knows", "to_id": 2}`. Edges can use the refs of nodes on earlier lines.
+// @Description Lines are written in chunked transactions, lines which fail are skipped and reported.
+// @Description The response is NDJSON with an `error` event for every skipped line, a `progress` event after every chunk and a final `done` event.
+// @Tags import
+// @Accept application/x-ndjson
+// @Produce application/x-ndjson
+// @Param chunkSize query int false "number of lines written per transaction" minimum(1) default(1000)
+// @Success 200 {array} ImportEvent "Stream of import events"
+// @Failure 501 "Store does not support importing"
+// @Router /api/v1/import [post]
+func POSTImport(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "POST /api/v1/import"))
+	mux.HandleFunc("POST /api/v1/import", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		importer, ok := s.(store.Importer)
+		if !ok {
+			http.Error(w, "store does not support importing", http.StatusNotImplemented)
+			return
+		}
+
+		chunkSize := store.DefaultImportChunkSize
+		if c, err := strconv.Atoi(r.URL.Query().Get("chunkSize")); err == nil && c > 0 {
+			chunkSize = c
+		}
+
+		defer r.Body.Close()
+
+		// progress is written while the body is still being read.
+		rc := http.NewResponseController(w)
+		if err := rc.EnableFullDuplex(); err != nil {
+			slog.Warn("full duplex not supported, progress may only be sent once the body is read", slog.String("reason", err.Error()))
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		emit := func(event ImportEvent) {
+			if err := encoder.Encode(event); err != nil {
+				slog.Error("failed to write import event", slog.String("reason", err.Error()))
+				return
+			}
+			rc.Flush()
+		}
+
+		var readErr error
+
+		items := func(yield func(store.ImportItem) bool) {
+			scanner := bufio.NewScanner(r.Body)
+			scanner.Buffer(make([]byte, 0, 64*1024), maxImportLine)
+
+			line := 0
+			for scanner.Scan() {
+				line++
+
+				raw := bytes.TrimSpace(scanner.Bytes())
+				if len(raw) == 0 {
+					continue
+				}
+
+				item := decodeImportLine(raw)
+				item.Line = line
+
+				if !yield(item) {
+					return
+				}
+			}
+
+			readErr = scanner.Err()
+		}
+
+		args := store.ImportArgs{
+			ChunkSize: chunkSize,
+			OnError: func(line int, err error) {
+				emit(ImportEvent{Type: "error", Line: line, Error: err.Error()})
+			},
+			OnProgress: func(p store.ImportProgress) {
+				emit(ImportEvent{Type: "progress", Progress: &p})
+			},
+		}
+
+		progress, err := importer.Import(ctx, items, args)
+		if err == nil {
+			err = readErr
+		}
+
+		if err != nil {
+			emit(ImportEvent{Type: "failed", Error: err.Error(), Progress: &progress})
+			return
+		}
+
+		emit(ImportEvent{Type: "done", Progress: &progress})
+	})
+}
+
+// GETExport exports the graph.
+// @Summary Exports the graph.
+// @Description Streams the whole store, the graph matching a search term or the sub-graph around a node as GraphML, GEXF, DOT or CSV.
+// @Description Properties are written as typed attributes, nested properties are flattened into dotted keys, eg: `meta.hair`.
+// @Description CSV writes a single table, either the nodes or the edges.
+// @Tags export
+// @Produce application/xml
+// @Produce text/vnd.graphviz
+// @Produce text/csv
+// @Param format query string true "export format" Enums(graphml, gexf, dot, csv)
+// @Param table query string false "table written by csv exports" Enums(nodes, edges) default(nodes)
+// @Param term query string false "only export the graph matching the search term"
+// @Param limit query int false "max number of term matches" minimum(1) default(1000)
+// @Param node query int false "only export the sub-graph around this node"
+// @Param depth query int false "how many levels (hops) deep the sub-graph is" minimum(1) default(1)
+// @Param direction query string false "direction sub-graph edges are followed" Enums(out, in, both) default(both)
+// @Success 200 "Exported graph"
+// @Failure 400 "Bad request"
+// @Failure 404 "Not found"
+// @Router /api/v1/export [get]
+func GETExport(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "GET /api/v1/export"))
+	mux.HandleFunc("GET /api/v1/export", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		format := export.Format(r.URL.Query().Get("format"))
+		switch format {
+		case export.FormatGraphML, export.FormatGEXF, export.FormatDOT, export.FormatCSV:
+		default:
+			http.Error(w, fmt.Sprintf("unsupported export format %q, expected graphml, gexf, dot or csv", format), http.StatusBadRequest)
+			return
+		}
+
+		opts := export.Options{Table: export.Table(r.URL.Query().Get("table"))}
+		switch opts.Table {
+		case "":
+			opts.Table = export.TableNodes
+		case export.TableNodes, export.TableEdges:
+		default:
+			http.Error(w, fmt.Sprintf("unsupported table %q, expected nodes or edges", opts.Table), http.StatusBadRequest)
+			return
+		}
+
+		term := strings.Trim(r.URL.Query().Get("term"), "\"")
+		node := r.URL.Query().Get("node")
+
+		var src export.Source
+
+		switch {
+		case term != "" && node != "":
+			http.Error(w, "term can not be combined with node", http.StatusBadRequest)
+			return
+
+		case term != "":
+			args := store.TermSearchArgs{Term: term}
+			if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil {
+				args.Limit = l
+			}
+
+			graph, err := s.Graph(ctx, args)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			src = export.GraphSource(graph)
+
+		case node != "":
+			id, err := strconv.ParseUint(node, 10, 64)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			args := store.SubGraphArgs{FromNodeID: id, Depth: 1, Direction: store.Direction(r.URL.Query().Get("direction"))}
+			if d, err := strconv.Atoi(r.URL.Query().Get("depth")); err == nil {
+				args.Depth = d
+			}
+
+			graph, err := s.SubGraph(ctx, args)
+			if err != nil {
+				traversalError(w, err)
+				return
+			}
+			src = export.GraphSource(graph)
+
+		default:
+			src = export.StoreSource(s)
+		}
+
+		filename := "edgedb." + string(format)
+		if format == export.FormatCSV {
+			filename = fmt.Sprintf("edgedb-%s.csv", opts.Table)
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+		// the response has already started so errors can only be logged.
+		if err := export.Write(ctx, w, format, src, opts); err != nil {
+			slog.Error("failed to write export", slog.String("format", string(format)), slog.String("reason", err.Error()))
+		}
+	})
+}
+
+// maxImportMemory is the size of multipart import files held in memory, larger files are stored in temporary files.
+const maxImportMemory = 32 << 20
+
+// POSTImportFormat imports a GraphML, GEXF or CSV graph.
+// @Summary Imports a GraphML, GEXF or CSV graph.
+// @Description Imports a GraphML or GEXF document sent as the body, or CSV files sent as `multipart/form-data` with a `nodes` file
+// @Description and an optional `edges` file. Everything is written in a single transaction, either the whole graph is written or nothing is.
+// @Description Attribute types are kept, dotted attribute names become nested properties and the source node IDs are mapped to the new node IDs in `refs`.
+// @Description A dry run parses and validates the graph, including the schemas and natural keys, without writing it. Nothing is written when problems are found.
+// @Tags import
+// @Accept application/xml
+// @Accept multipart/form-data
+// @Produce json
+// @Param format path string true "import format" Enums(graphml, gexf, csv)
+// @Param dryRun query bool false "validate without writing" default(false)
+// @Param nodes formData file false "nodes csv file"
+// @Param edges formData file false "edges csv file"
+// @Success 200 {object} importer.Report "Import report"
+// @Failure 400 {object} importer.Report "Invalid graph"
+// @Failure 500 "Internal server error"
+// @Router /api/v1/import/{format} [post]
+func POSTImportFormat(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "POST /api/v1/import/{format}"))
+	mux.HandleFunc("POST /api/v1/import/{format}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+
+		defer r.Body.Close()
+
+		var (
+			res importer.Result
+			err error
+		)
+
+		switch format := importer.Format(r.PathValue("format")); format {
+		case importer.FormatGraphML:
+			res, err = importer.ParseGraphML(r.Body)
+
+		case importer.FormatGEXF:
+			res, err = importer.ParseGEXF(r.Body)
+
+		case importer.FormatCSV:
+			if err := r.ParseMultipartForm(maxImportMemory); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			defer r.MultipartForm.RemoveAll()
+
+			nodes, _, ferr := r.FormFile("nodes")
+			if ferr != nil {
+				http.Error(w, "nodes file: "+ferr.Error(), http.StatusBadRequest)
+				return
+			}
+			defer nodes.Close()
+
+			var edges io.Reader
+			if f, _, ferr := r.FormFile("edges"); ferr == nil {
+				defer f.Close()
+				edges = f
+			}
+
+			res, err = importer.ParseCSV(nodes, edges)
+
+		default:
+			http.Error(w, fmt.Sprintf("unsupported import format %q, expected graphml, gexf or csv", format), http.StatusBadRequest)
+			return
+		}
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		status := http.StatusOK
+
+		report, err := importer.Write(ctx, s, res, dryRun)
+		if err != nil {
+			if !errors.Is(err, store.ErrInvalid) {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			status = http.StatusBadRequest
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(status)
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(report); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// schemaStoreError writes the schema store error.
+func schemaStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, store.ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// GETSchemas returns the label schemas.
+// @Summary Returns the label schemas.
+// @Description Returns the node and edge label schemas ordered by kind and label.
+// @Tags schema
+// @Produce json
+// @Param kind query string false "only return schemas of the kind" Enums(node, edge)
+// @Success 200 {array} models.Schema "List of schemas"
+// @Failure 500 "Internal server error"
+// @Router /api/v1/schema [get]
+func GETSchemas(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "GET /api/v1/schema"))
+	mux.HandleFunc("GET /api/v1/schema", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		schemas, err := s.Schemas(ctx)
+		if err != nil {
+			schemaStoreError(w, err)
+			return
+		}
+
+		if kind := models.SchemaKind(r.URL.Query().Get("kind")); kind != "" {
+			schemas = slices.DeleteFunc(schemas, func(schema models.Schema) bool { return schema.Kind != kind })
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(schemas); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// GETSchema returns a label schema.
+// @Summary Returns a label schema.
+// @Description Returns the schema of the node or edge label.
+// @Tags schema
+// @Produce json
+// @Param kind path string true "schema kind" Enums(node, edge)
+// @Param label path string true "node or edge label"
+// @Success 200 {object} models.Schema "Schema"
+// @Failure 404 "Not found"
+// @Failure 500 "Internal server error"
+// @Router /api/v1/schema/{kind}/{label} [get]
+func GETSchema(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "GET /api/v1/schema/{kind}/{label}"))
+	mux.HandleFunc("GET /api/v1/schema/{kind}/{label}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		schema, err := s.Schema(ctx, models.SchemaKind(r.PathValue("kind")), r.PathValue("label"))
+		if err != nil {
+			schemaStoreError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(schema); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// PUTSchema adds/replaces a label schema.
+// @Summary Add/replace a label schema.
+// @Description Add/replace the schema enforced when writing nodes or edges with the label.
+// @Description Properties are described with a JSON Schema subset: type, enum, pattern, minimum, maximum, minLength, maxLength, items, properties, required and additionalProperties.
+// @Description Edge schemas can limit the labels of the nodes they link with from_labels and to_labels.
+// @Description strict schemas reject writes which do not match, warn schemas log a warning and write them anyway. Existing items are not checked.
+// @Tags schema
+// @Accept json
+// @Produce json
+// @Param kind path string true "schema kind" Enums(node, edge)
+// @Param label path string true "node or edge label"
+// @Param schema body models.Schema true "Schema, the kind and label are taken from the path"
+// @Success 200 {object} models.Schema "Schema"
+// @Failure 400 "Bad request"
+// @Failure 500 "Internal server error"
+// @Router /api/v1/schema/{kind}/{label} [put]
+func PUTSchema(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "PUT /api/v1/schema/{kind}/{label}"))
+	mux.HandleFunc("PUT /api/v1/schema/{kind}/{label}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		req := models.Schema{}
+		defer r.Body.Close()
+
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		req.Kind = models.SchemaKind(r.PathValue("kind"))
+		req.Label = r.PathValue("label")
+
+		schema, err := s.UpsertSchema(ctx, req)
+		if err != nil {
+			schemaStoreError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(schema); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// DELETESchema deletes a label schema.
+// @Summary Delete a label schema.
+// @Description Delete the schema of the node or edge label, writes with the label are no longer checked.
+// @Tags schema
+// @Produce json
+// @Param kind path string true "schema kind" Enums(node, edge)
+// @Param label path string true "node or edge label"
+// @Success 200 {object} models.Schema "Deleted schema"
+// @Failure 404 "Not found"
+// @Failure 500 "Internal server error"
+// @Router /api/v1/schema/{kind}/{label} [delete]
+func DELETESchema(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "DELETE /api/v1/schema/{kind}/{label}"))
+	mux.HandleFunc("DELETE /api/v1/schema/{kind}/{label}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		schema, err := s.DeleteSchema(ctx, models.SchemaKind(r.PathValue("kind")), r.PathValue("label"))
+		if err != nil {
+			schemaStoreError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(schema); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// keyStoreError writes the natural key store error.
+func keyStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, store.ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, store.ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// GETKeys returns the natural keys.
+// @Summary Returns the natural keys.
+// @Description Returns the natural keys used to merge nodes ordered by label.
+// @Tags keys
+// @Produce json
+// @Success 200 {array} models.NaturalKey "List of natural keys"
+// @Failure 500 "Internal server error"
+// @Router /api/v1/keys [get]
+func GETKeys(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "GET /api/v1/keys"))
+	mux.HandleFunc("GET /api/v1/keys", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		keys, err := s.Keys(ctx)
+		if err != nil {
+			keyStoreError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(keys); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// GETKey returns a natural key.
+// @Summary Returns a natural key.
+// @Description Returns the natural key of the node label.
+// @Tags keys
+// @Produce json
+// @Param label path string true "node label"
+// @Success 200 {object} models.NaturalKey "Natural key"
+// @Failure 404 "Not found"
+// @Failure 500 "Internal server error"
+// @Router /api/v1/keys/{label} [get]
+func GETKey(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "GET /api/v1/keys/{label}"))
+	mux.HandleFunc("GET /api/v1/keys/{label}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		key, err := s.Key(ctx, r.PathValue("label"))
+		if err != nil {
+			keyStoreError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(key); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// PUTKey adds/replaces a natural key.
+// @Summary Add/replace a natural key.
+// @Description Add/replace the natural key of the node label, eg: {"property": "email"}.
+// @Description Natural keys are unique, writing a node with the same key value as another node is a conflict. Nodes without the key property are not keyed.
+// @Tags keys
+// @Accept json
+// @Produce json
+// @Param label path string true "node label"
+// @Param key body models.NaturalKey true "Natural key, the label is taken from the path"
+// @Success 200 {object} models.NaturalKey "Natural key"
+// @Failure 400 "Bad request"
+// @Failure 409 "Existing nodes have the same key value"
+// @Failure 500 "Internal server error"
+// @Router /api/v1/keys/{label} [put]
+func PUTKey(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "PUT /api/v1/keys/{label}"))
+	mux.HandleFunc("PUT /api/v1/keys/{label}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		req := models.NaturalKey{}
+		defer r.Body.Close()
+
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		req.Label = r.PathValue("label")
+
+		key, err := s.UpsertKey(ctx, req)
+		if err != nil {
+			keyStoreError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(key); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// DELETEKey deletes a natural key.
+// @Summary Delete a natural key.
+// @Description Delete the natural key of the node label, nodes with the label can no longer be merged.
+// @Tags keys
+// @Produce json
+// @Param label path string true "node label"
+// @Success 200 {object} models.NaturalKey "Deleted natural key"
+// @Failure 404 "Not found"
+// @Failure 500 "Internal server error"
+// @Router /api/v1/keys/{label} [delete]
+func DELETEKey(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "DELETE /api/v1/keys/{label}"))
+	mux.HandleFunc("DELETE /api/v1/keys/{label}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		key, err := s.DeleteKey(ctx, r.PathValue("label"))
+		if err != nil {
+			keyStoreError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(key); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// indexerError writes the property index error.
+func indexerError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, store.ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, store.ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// GETIndexes returns the property indexes.
+// @Summary Returns the property indexes.
+// @Description Returns the property indexes with their build status and size in bytes ordered by ID.
+// @Tags indexes
+// @Produce json
+// @Success 200 {array} models.PropertyIndex "List of property indexes"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support property indexes"
+// @Router /api/v1/indexes [get]
+func GETIndexes(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "GET /api/v1/indexes"))
+	mux.HandleFunc("GET /api/v1/indexes", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		indexer, ok := s.(store.Indexer)
+		if !ok {
+			http.Error(w, "store does not support property indexes", http.StatusNotImplemented)
+			return
+		}
+
+		indexes, err := indexer.Indexes(ctx)
+		if err != nil {
+			indexerError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(indexes); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// GETIndex returns a property index.
+// @Summary Returns a property index.
+// @Description Returns the property index with its build status and size in bytes.
+// @Tags indexes
+// @Produce json
+// @Param id path int true "property index ID"
+// @Success 200 {object} models.PropertyIndex "Property index"
+// @Failure 400 "Bad request"
+// @Failure 404 "Not found"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support property indexes"
+// @Router /api/v1/indexes/{id} [get]
+func GETIndex(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "GET /api/v1/indexes/{id}"))
+	mux.HandleFunc("GET /api/v1/indexes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		indexer, ok := s.(store.Indexer)
+		if !ok {
+			http.Error(w, "store does not support property indexes", http.StatusNotImplemented)
+			return
+		}
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		index, err := indexer.Index(ctx, id)
+		if err != nil {
+			indexerError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(index); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// POSTIndex creates a property index.
+// @Summary Create a property index.
+// @Description Create a secondary index on a property, eg: {"label": "person", "path": "name"}. The index is limited to the items with the label, every item is indexed if the label is empty.
+// @Description The index is built in the background, poll the index until its status is ready or failed. Filters and queries comparing the property use the index once it is ready.
+// @Tags indexes
+// @Accept json
+// @Produce json
+// @Param index body models.PropertyIndex true "Property index, only the label and path are used"
+// @Success 202 {object} models.PropertyIndex "Property index being built"
+// @Failure 400 "Bad request"
+// @Failure 409 "The label and path are already indexed"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support property indexes"
+// @Router /api/v1/indexes [post]
+func POSTIndex(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "POST /api/v1/indexes"))
+	mux.HandleFunc("POST /api/v1/indexes", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		indexer, ok := s.(store.Indexer)
+		if !ok {
+			http.Error(w, "store does not support property indexes", http.StatusNotImplemented)
+			return
+		}
+
+		req := models.PropertyIndex{}
+		defer r.Body.Close()
+
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		index, err := indexer.CreateIndex(ctx, models.PropertyIndex{Label: req.Label, Path: req.Path})
+		if err != nil {
+			indexerError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusAccepted)
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(index); err != nil {
+			slog.Error("error encoding property index", slog.String("reason", err.Error()))
+		}
+	})
+}
+
+// DELETEIndex drops a property index.
+// @Summary Drop a property index.
+// @Description Drop the property index, filters and queries comparing the property scan the items again.
+// @Tags indexes
+// @Produce json
+// @Param id path int true "property index ID"
+// @Success 200 {object} models.PropertyIndex "Dropped property index"
+// @Failure 400 "Bad request"
+// @Failure 404 "Not found"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support property indexes"
+// @Router /api/v1/indexes/{id} [delete]
+func DELETEIndex(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "DELETE /api/v1/indexes/{id}"))
+	mux.HandleFunc("DELETE /api/v1/indexes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		indexer, ok := s.(store.Indexer)
+		if !ok {
+			http.Error(w, "store does not support property indexes", http.StatusNotImplemented)
+			return
+		}
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		index, err := indexer.DropIndex(ctx, id)
+		if err != nil {
+			indexerError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(index); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// changesPollInterval is how often the change feed checks for new changes once it has sent all the changes.
+var changesPollInterval = 500 * time.Millisecond
+
+// changesBatch is the maximum number of changes read from the store at a time.
+const changesBatch = 1000
+
+// parseSince returns the sequence number to resume the change feed after. The Last-Event-ID header sent by
+// reconnecting clients takes precedence over the since query parameter.
+func parseSince(r *http.Request) (uint64, error) {
+	since := r.Header.Get("Last-Event-ID")
+	if since == "" {
+		since = r.URL.Query().Get("since")
+	}
+
+	if since == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseUint(since, 10, 64)
+}
+
+// GETChanges streams the changes to nodes and edges.
+// @Summary Stream the changes to nodes and edges.
+// @Description Streams the changelog as server-sent `change` events with the before and after images of the node or edge.
+// @Description The event ID is the sequence number of the change, the feed resumes after the Last-Event-ID header or the since sequence number.
+// @Tags changes
+// @Produce text/event-stream
+// @Param since query int false "only stream the changes after the sequence number" default(0)
+// @Param label query []string false "only stream changes to items with any of the labels" collectionFormat(multi)
+// @Param Last-Event-ID header int false "sequence number of the last received change, sent by reconnecting clients"
+// @Success 200 {object} models.Change "Stream of changes"
+// @Failure 400 "Bad request"
+// @Failure 501 "Store does not support the change feed"
+// @Router /api/v1/changes [get]
+func GETChanges(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "GET /api/v1/changes"))
+	mux.HandleFunc("GET /api/v1/changes", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		feed, ok := s.(store.ChangeFeed)
+		if !ok {
+			http.Error(w, "store does not support the change feed", http.StatusNotImplemented)
+			return
+		}
+
+		since, err := parseSince(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		labels := splitQuery(r, "label")
+		sse := datastar.NewSSE(w, r)
+
+		ticker := time.NewTicker(changesPollInterval)
+		defer ticker.Stop()
+
+		for {
+			changes, err := feed.Changes(ctx, store.ChangesArgs{Since: since, Labels: labels, Limit: changesBatch})
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.Error("error reading the changes", slog.Uint64("since", since), slog.String("reason", err.Error()))
+				}
+				return
+			}
+
+			for _, change := range changes {
+				data, err := json.Marshal(change)
+				if err != nil {
+					slog.Error("error encoding the change", slog.Uint64("seq", change.Seq), slog.String("reason", err.Error()))
+					return
+				}
+
+				if err := sse.Send("change", []string{string(data)}, datastar.WithSSEEventId(strconv.FormatUint(change.Seq, 10))); err != nil {
+					return
+				}
+
+				since = change.Seq
+			}
+
+			// keep reading without waiting until the feed has caught up.
+			if len(changes) == changesBatch {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// webhookStoreError writes the webhook error.
+func webhookStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, store.ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// GETWebhooks returns the webhooks.
+// @Summary Returns the webhooks.
+// @Description Returns the webhooks ordered by ID, the secrets are not returned.
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} models.Webhook "List of webhooks"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support webhooks"
+// @Router /api/v1/webhooks [get]
+func GETWebhooks(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "GET /api/v1/webhooks"))
+	mux.HandleFunc("GET /api/v1/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		ws, ok := s.(store.WebhookStore)
+		if !ok {
+			http.Error(w, "store does not support webhooks", http.StatusNotImplemented)
+			return
+		}
+
+		webhooks, err := ws.Webhooks(ctx)
+		if err != nil {
+			webhookStoreError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(webhooks); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// GETWebhook returns a webhook.
+// @Summary Returns a webhook.
+// @Description Returns the webhook, the secret is not returned.
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "webhook ID"
+// @Success 200 {object} models.Webhook "Webhook"
+// @Failure 400 "Bad request"
+// @Failure 404 "Not found"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support webhooks"
+// @Router /api/v1/webhooks/{id} [get]
+func GETWebhook(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "GET /api/v1/webhooks/{id}"))
+	mux.HandleFunc("GET /api/v1/webhooks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		ws, ok := s.(store.WebhookStore)
+		if !ok {
+			http.Error(w, "store does not support webhooks", http.StatusNotImplemented)
+			return
+		}
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		webhook, err := ws.Webhook(ctx, id)
+		if err != nil {
+			webhookStoreError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(webhook); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// POSTWebhook creates a webhook.
+// @Summary Create a webhook.
+// @Description Create a webhook posting the changes to nodes and edges to the URL, eg: {"url": "https://example.com/hook", "labels": ["person"], "ops": ["create", "delete"]}.
+// @Description The changes are limited to the labels and ops if given. Each delivery is signed with the secret in the X-EdgeDB-Signature header as `sha256=<hex hmac of the body>`, a secret is generated if none is given.
+// @Description The secret is only returned when the webhook is created.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body models.Webhook true "Webhook, only the url, secret, labels and ops are used"
+// @Success 201 {object} models.Webhook "Created webhook with its secret"
+// @Failure 400 "Bad request"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support webhooks"
+// @Router /api/v1/webhooks [post]
+func POSTWebhook(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "POST /api/v1/webhooks"))
+	mux.HandleFunc("POST /api/v1/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		ws, ok := s.(store.WebhookStore)
+		if !ok {
+			http.Error(w, "store does not support webhooks", http.StatusNotImplemented)
+			return
+		}
+
+		req := models.Webhook{}
+		defer r.Body.Close()
+
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		webhook, err := ws.CreateWebhook(ctx, models.Webhook{URL: req.URL, Secret: req.Secret, Labels: req.Labels, Ops: req.Ops})
+		if err != nil {
+			webhookStoreError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusCreated)
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(webhook); err != nil {
+			slog.Error("error encoding webhook", slog.String("reason", err.Error()))
+		}
+	})
+}
+
+// DELETEWebhook deletes a webhook.
+// @Summary Delete a webhook.
+// @Description Delete the webhook and its delivery history, pending deliveries are not sent.
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "webhook ID"
+// @Success 200 {object} models.Webhook "Deleted webhook"
+// @Failure 400 "Bad request"
+// @Failure 404 "Not found"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support webhooks"
+// @Router /api/v1/webhooks/{id} [delete]
+func DELETEWebhook(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "DELETE /api/v1/webhooks/{id}"))
+	mux.HandleFunc("DELETE /api/v1/webhooks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		ws, ok := s.(store.WebhookStore)
+		if !ok {
+			http.Error(w, "store does not support webhooks", http.StatusNotImplemented)
+			return
+		}
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		webhook, err := ws.DeleteWebhook(ctx, id)
+		if err != nil {
+			webhookStoreError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(webhook); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// GETWebhookDeliveries returns the delivery history of a webhook.
+// @Summary Returns the deliveries of a webhook.
+// @Description Returns the deliveries of the webhook ordered by ID with the number of attempts and the response code and error of the last attempt.
+// @Description Pending deliveries are retried with an exponential backoff at next_attempt_at.
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "webhook ID"
+// @Param status query string false "only return deliveries with the status" Enums(pending, delivered, failed)
+// @Param limit query int false "limit the number of deliveries returned" default(1000)
+// @Param lastID query int false "last delivery ID, used for pagination" default(0)
+// @Success 200 {array} models.Delivery "List of deliveries"
+// @Failure 400 "Bad request"
+// @Failure 404 "Not found"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support webhooks"
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func GETWebhookDeliveries(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "GET /api/v1/webhooks/{id}/deliveries"))
+	mux.HandleFunc("GET /api/v1/webhooks/{id}/deliveries", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		ws, ok := s.(store.WebhookStore)
+		if !ok {
+			http.Error(w, "store does not support webhooks", http.StatusNotImplemented)
+			return
+		}
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		args := store.DeliveriesArgs{WebhookID: id, Status: models.DeliveryStatus(r.URL.Query().Get("status"))}
+
+		if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil {
+			args.Limit = l
+		}
+
+		if l, err := strconv.ParseUint(r.URL.Query().Get("lastID"), 10, 64); err == nil {
+			args.LastID = l
+		}
+
+		deliveries, err := ws.Deliveries(ctx, args)
+		if err != nil {
+			webhookStoreError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(deliveries); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// parseAsOf parses the asOf query parameter, the zero time is returned if it is not set.
+func parseAsOf(r *http.Request) (time.Time, error) {
+	return parseTime(r.URL.Query().Get("asOf"))
+}
+
+// historyError writes the history error.
+func historyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// GETNode returns a node.
+// @Summary Returns a node.
+// @Description Returns the node, or the node as it was at the asOf time. The ETag is the version of the node.
+// @Tags nodes
+// @Produce json
+// @Param id path int true "Node id"
+// @Param asOf query string false "return the node as it was at the RFC3339 or unix time"
+// @Success 200 {object} models.Node "Node"
+// @Header 200 {string} ETag "Version of the node"
+// @Failure 400 "Bad request"
+// @Failure 404 "Not found"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support history"
+// @Router /api/v1/nodes/{id} [get]
+func GETNode(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "GET /api/v1/nodes/{id}"))
+	mux.HandleFunc("GET /api/v1/nodes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		asOf, err := parseAsOf(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var node models.Node
+
+		if asOf.IsZero() {
+			node, err = s.Node(ctx, id)
+		} else {
+			h, ok := s.(store.Historian)
+			if !ok {
+				http.Error(w, "store does not support history", http.StatusNotImplemented)
+				return
+			}
+
+			node, err = h.NodeAsOf(ctx, id, asOf)
+		}
+
+		if err != nil {
+			historyError(w, err)
+			return
+		}
+
+		if node.Version > 0 {
+			w.Header().Set("ETag", etag(node.Version))
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(node); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// GETNodeHistory returns the revisions of a node.
+// @Summary Returns the revisions of a node.
+// @Description Returns the revisions of the node oldest first, each with the property-level diff from the previous revision.
+// @Description A deleted node has a last revision without the node.
+// @Tags nodes
+// @Produce json
+// @Param id path int true "Node id"
+// @Success 200 {array} models.Revision "List of revisions"
+// @Failure 400 "Bad request"
+// @Failure 404 "Not found"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support history"
+// @Router /api/v1/nodes/{id}/history [get]
+func GETNodeHistory(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "GET /api/v1/nodes/{id}/history"))
+	mux.HandleFunc("GET /api/v1/nodes/{id}/history", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		h, ok := s.(store.Historian)
+		if !ok {
+			http.Error(w, "store does not support history", http.StatusNotImplemented)
+			return
+		}
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		revisions, err := h.NodeHistory(ctx, id)
+		if err != nil {
+			historyError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(revisions); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// GETEdgeHistory returns the revisions of an edge.
+// @Summary Returns the revisions of an edge.
+// @Description Returns the revisions of the edge oldest first, each with the property-level diff from the previous revision.
+// @Description A deleted edge has a last revision without the edge.
+// @Tags edges
+// @Produce json
+// @Param id path int true "Edge id"
+// @Success 200 {array} models.Revision "List of revisions"
+// @Failure 400 "Bad request"
+// @Failure 404 "Not found"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support history"
+// @Router /api/v1/edges/{id}/history [get]
+func GETEdgeHistory(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "GET /api/v1/edges/{id}/history"))
+	mux.HandleFunc("GET /api/v1/edges/{id}/history", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		h, ok := s.(store.Historian)
+		if !ok {
+			http.Error(w, "store does not support history", http.StatusNotImplemented)
+			return
+		}
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		revisions, err := h.EdgeHistory(ctx, id)
+		if err != nil {
+			historyError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(revisions); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// trashStoreError writes the trash store error.
+func trashStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, store.ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// GETTrash returns the trashed nodes and edges.
+// @Summary Returns the trashed nodes and edges.
+// @Description Returns the trashed nodes and edges ordered by ID, edges trashed with a node are restored with it.
+// @Tags trash
+// @Produce json
+// @Param limit query int false "limit results returned" minimum(1) default(1000)
+// @Param lastID query int false "last known ID from previous result used for pagination" default(0)
+// @Success 200 {object} store.Trash "Trashed nodes and edges"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support the trash"
+// @Router /api/v1/trash [get]
+func GETTrash(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "GET /api/v1/trash"))
+	mux.HandleFunc("GET /api/v1/trash", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		ts, ok := s.(store.TrashStore)
+		if !ok {
+			http.Error(w, "store does not support the trash", http.StatusNotImplemented)
+			return
+		}
+
+		args := store.TrashArgs{}
+
+		if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil {
+			args.Limit = l
+		}
+
+		if l, err := strconv.ParseUint(r.URL.Query().Get("lastID"), 10, 64); err == nil {
+			args.LastID = l
+		}
+
+		trash, err := ts.Trash(ctx, args)
+		if err != nil {
+			trashStoreError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(trash); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// DELETETrash purges the trash.
+// @Summary Purges the trash.
+// @Description Permanently removes the items trashed more than olderThan ago, everything in the trash is removed if olderThan is not set.
+// @Tags trash
+// @Produce json
+// @Param olderThan query string false "only purge items trashed longer ago than the duration, eg 720h"
+// @Success 200 {object} store.PurgeResult "Number of purged nodes and edges"
+// @Failure 400 "Bad request"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support the trash"
+// @Router /api/v1/trash [delete]
+func DELETETrash(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "DELETE /api/v1/trash"))
+	mux.HandleFunc("DELETE /api/v1/trash", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		ts, ok := s.(store.TrashStore)
+		if !ok {
+			http.Error(w, "store does not support the trash", http.StatusNotImplemented)
+			return
+		}
+
+		var olderThan time.Duration
+
+		if raw := r.URL.Query().Get("olderThan"); raw != "" {
+			d, err := time.ParseDuration(raw)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			olderThan = d
+		}
+
+		// a second is added so that items trashed this second are purged as well.
+		result, err := ts.Purge(ctx, time.Now().Add(time.Second-olderThan))
+		if err != nil {
+			trashStoreError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// POSTRestoreNode restores a node from the trash.
+// @Summary Restores a node from the trash.
+// @Description Restores the node together with the edges trashed with it.
+// @Tags trash
+// @Produce json
+// @Param id path int true "Node id"
+// @Success 200 {object} store.RestoreResult "Restored node and edges"
+// @Failure 400 "Bad request"
+// @Failure 404 "Not found"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support the trash"
+// @Router /api/v1/nodes/{id}/restore [post]
+func POSTRestoreNode(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "POST /api/v1/nodes/{id}/restore"))
+	mux.HandleFunc("POST /api/v1/nodes/{id}/restore", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		ts, ok := s.(store.TrashStore)
+		if !ok {
+			http.Error(w, "store does not support the trash", http.StatusNotImplemented)
+			return
+		}
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err := ts.RestoreNode(ctx, id)
+		if err != nil {
+			trashStoreError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// POSTRestoreEdge restores an edge from the trash.
+// @Summary Restores an edge from the trash.
+// @Description Restores the edge, both of its nodes must not be in the trash.
+// @Tags trash
+// @Produce json
+// @Param id path int true "Edge id"
+// @Success 200 {object} store.RestoreResult "Restored edge"
+// @Failure 400 "Bad request"
+// @Failure 404 "Not found"
+// @Failure 409 "One of the nodes is in the trash"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support the trash"
+// @Router /api/v1/edges/{id}/restore [post]
+func POSTRestoreEdge(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "POST /api/v1/edges/{id}/restore"))
+	mux.HandleFunc("POST /api/v1/edges/{id}/restore", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		ts, ok := s.(store.TrashStore)
+		if !ok {
+			http.Error(w, "store does not support the trash", http.StatusNotImplemented)
+			return
+		}
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err := ts.RestoreEdge(ctx, id)
+		if err != nil {
+			trashStoreError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// etag returns the ETag of the version.
+func etag(version uint64) string {
+	return fmt.Sprintf("%q", strconv.FormatUint(version, 10))
+}
+
+// parseIfMatch returns the version in the If-Match header, 0 is returned if it is not set or matches any version.
+func parseIfMatch(r *http.Request) (uint64, error) {
+	match := strings.TrimSpace(r.Header.Get("If-Match"))
+	if match == "" || match == "*" {
+		return 0, nil
+	}
+
+	version, err := strconv.ParseUint(strings.Trim(strings.TrimPrefix(match, "W/"), `"`), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("If-Match is not a version: %s", match)
+	}
+
+	return version, nil
+}
+
+// GETEdge returns an edge.
+// @Summary Returns an edge.
+// @Description Returns the edge, the ETag is the version of the edge.
+// @Tags edges
+// @Produce json
+// @Param id path int true "Edge id"
+// @Success 200 {object} models.Edge "Edge"
+// @Header 200 {string} ETag "Version of the edge"
+// @Failure 400 "Bad request"
+// @Failure 404 "Not found"
+// @Failure 500 "Internal server error"
+// @Router /api/v1/edges/{id} [get]
+func GETEdge(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "GET /api/v1/edges/{id}"))
+	mux.HandleFunc("GET /api/v1/edges/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		edge, err := s.Edge(ctx, id)
+		if err != nil {
+			historyError(w, err)
+			return
+		}
+
+		if edge.Version > 0 {
+			w.Header().Set("ETag", etag(edge.Version))
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(edge); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// PUTNode adds/updates a node.
+// @Summary Add/update a node.
+// @Description Add/update the node with the ID. The node is only written if If-Match, or the version in the body, is
+// @Description the current version of the node, otherwise the current node is returned in the conflict.
+// @Tags nodes
+// @Accept json
+// @Produce json
+// @Param id path int true "Node id"
+// @Param If-Match header string false "current version of the node"
+// @Param node body models.Node true "Node to add/update"
+// @Success 200 {object} models.Node "Node"
+// @Header 200 {string} ETag "Version of the node"
+// @Failure 400 {object} store.SchemaError "Bad request or the node does not match its schema"
+// @Failure 409 {object} store.VersionError "The node is not at the version"
+// @Failure 500 "Internal server error"
+// @Router /api/v1/nodes/{id} [put]
+func PUTNode(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "PUT /api/v1/nodes/{id}"))
+	mux.HandleFunc("PUT /api/v1/nodes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		version, err := parseIfMatch(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		node := models.Node{}
+		defer r.Body.Close()
+
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&node); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		node.ID = id
+
+		if version > 0 {
+			node.Version = version
+		}
+
+		nodes, err := s.UpsertNodes(ctx, node)
+		if err != nil {
+			upsertError(w, err)
+			return
+		}
+
+		w.Header().Set("ETag", etag(nodes[0].Version))
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(nodes[0]); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// PUTEdge adds/updates an edge.
+// @Summary Add/update an edge.
+// @Description Add/update the edge with the ID. The edge is only written if If-Match, or the version in the body, is
+// @Description the current version of the edge, otherwise the current edge is returned in the conflict.
+// @Tags edges
+// @Accept json
+// @Produce json
+// @Param id path int true "Edge id"
+// @Param If-Match header string false "current version of the edge"
+// @Param edge body models.Edge true "Edge to add/update"
+// @Success 200 {object} models.Edge "Edge"
+// @Header 200 {string} ETag "Version of the edge"
+// @Failure 400 {object} store.SchemaError "Bad request or the edge does not match its schema"
+// @Failure 409 {object} store.VersionError "The edge is not at the version"
+// @Failure 500 "Internal server error"
+// @Router /api/v1/edges/{id} [put]
+func PUTEdge(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "PUT /api/v1/edges/{id}"))
+	mux.HandleFunc("PUT /api/v1/edges/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		version, err := parseIfMatch(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		edge := models.Edge{}
+		defer r.Body.Close()
+
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&edge); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		edge.ID = id
+
+		if version > 0 {
+			edge.Version = version
+		}
+
+		edges, err := s.UpsertEdges(ctx, edge)
+		if err != nil {
+			upsertError(w, err)
+			return
+		}
+
+		w.Header().Set("ETag", etag(edges[0].Version))
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(edges[0]); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// parsePatch returns the patch in the body, requests with the `application/json-patch+json` Content-Type are JSON
+// Patches and the others are merge patches.
+func parsePatch(r *http.Request) (store.Patch, error) {
+	patch := store.Patch{Type: store.MergePatch}
+
+	contentType, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";")
+	if strings.EqualFold(strings.TrimSpace(contentType), string(store.JSONPatch)) {
+		patch.Type = store.JSONPatch
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		return patch, err
+	}
+
+	patch.Version = version
+
+	defer r.Body.Close()
+
+	if patch.Document, err = io.ReadAll(r.Body); err != nil {
+		return patch, err
+	}
+
+	return patch, nil
+}
+
+// patchError writes the patch error, missing items are not found and the other errors are written like upserts.
+func patchError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	upsertError(w, err)
+}
+
+// PATCHNode updates some of the properties of a node.
+// @Summary Update some of the properties of a node.
+// @Description Applies a JSON Patch (`application/json-patch+json`) or otherwise a JSON Merge Patch
+// @Description (`application/merge-patch+json`) to the properties of the node, JSON Patch paths are relative to the
+// @Description properties, eg: `/meta/hair`. The node is only patched if If-Match is the current version of the node.
+// @Tags nodes
+// @Accept json
+// @Produce json
+// @Param id path int true "Node id"
+// @Param If-Match header string false "current version of the node"
+// @Param patch body object true "Merge patch or JSON Patch"
+// @Success 200 {object} models.Node "Node"
+// @Header 200 {string} ETag "Version of the node"
+// @Failure 400 {object} store.SchemaError "Bad request or the patched node does not match its schema"
+// @Failure 404 "Not found"
+// @Failure 409 {object} store.VersionError "The node is not at the version or the patch can not be applied"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support patches"
+// @Router /api/v1/nodes/{id} [patch]
+func PATCHNode(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "PATCH /api/v1/nodes/{id}"))
+	mux.HandleFunc("PATCH /api/v1/nodes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		ps, ok := s.(store.Patcher)
+		if !ok {
+			http.Error(w, "store does not support patches", http.StatusNotImplemented)
+			return
+		}
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		patch, err := parsePatch(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		node, err := ps.PatchNode(ctx, id, patch)
+		if err != nil {
+			patchError(w, err)
+			return
+		}
+
+		w.Header().Set("ETag", etag(node.Version))
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(node); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// PATCHEdge updates some of the properties of an edge.
+// @Summary Update some of the properties of an edge.
+// @Description Applies a JSON Patch (`application/json-patch+json`) or otherwise a JSON Merge Patch
+// @Description (`application/merge-patch+json`) to the properties of the edge, JSON Patch paths are relative to the
+// @Description properties, eg: `/since`. The edge is only patched if If-Match is the current version of the edge.
+// @Tags edges
+// @Accept json
+// @Produce json
+// @Param id path int true "Edge id"
+// @Param If-Match header string false "current version of the edge"
+// @Param patch body object true "Merge patch or JSON Patch"
+// @Success 200 {object} models.Edge "Edge"
+// @Header 200 {string} ETag "Version of the edge"
+// @Failure 400 {object} store.SchemaError "Bad request or the patched edge does not match its schema"
+// @Failure 404 "Not found"
+// @Failure 409 {object} store.VersionError "The edge is not at the version or the patch can not be applied"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support patches"
+// @Router /api/v1/edges/{id} [patch]
+func PATCHEdge(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "PATCH /api/v1/edges/{id}"))
+	mux.HandleFunc("PATCH /api/v1/edges/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		ps, ok := s.(store.Patcher)
+		if !ok {
+			http.Error(w, "store does not support patches", http.StatusNotImplemented)
+			return
+		}
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		patch, err := parsePatch(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		edge, err := ps.PatchEdge(ctx, id, patch)
+		if err != nil {
+			patchError(w, err)
+			return
+		}
+
+		w.Header().Set("ETag", etag(edge.Version))
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(edge); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// graphError writes the named graph error.
+func graphError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, store.ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, store.ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// GETGraphs returns the named graphs.
+// @Summary Returns the named graphs.
+// @Description Returns the named graphs ordered by name, the default graph is not listed.
+// @Tags graphs
+// @Produce json
+// @Success 200 {array} models.NamedGraph "List of named graphs"
+// @Failure 501 "Server does not support named graphs"
+// @Router /api/v1/graphs [get]
+func GETGraphs(mux *http.ServeMux, h *graphs.Handlers) {
+	slog.Info("registered route", slog.String("route", "GET /api/v1/graphs"))
+	mux.HandleFunc("GET /api/v1/graphs", func(w http.ResponseWriter, r *http.Request) {
+		if h == nil {
+			http.Error(w, "server does not support named graphs", http.StatusNotImplemented)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(h.Manager().Graphs()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// POSTGraph creates a named graph.
+// @Summary Create a named graph.
+// @Description Create an empty named graph, eg: {"name": "movies"}. The name is made of lower case letters, digits, `_` or `-`.
+// @Description The nodes and edges of the graph are served by the /api/v1/graphs/{name}/... routes.
+// @Tags graphs
+// @Accept json
+// @Produce json
+// @Param graph body models.NamedGraph true "Named graph"
+// @Success 201 {object} models.NamedGraph "Created named graph"
+// @Failure 400 "Bad request"
+// @Failure 409 "Graph already exists"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Server does not support named graphs"
+// @Router /api/v1/graphs [post]
+func POSTGraph(mux *http.ServeMux, h *graphs.Handlers) {
+	slog.Info("registered route", slog.String("route", "POST /api/v1/graphs"))
+	mux.HandleFunc("POST /api/v1/graphs", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if h == nil {
+			http.Error(w, "server does not support named graphs", http.StatusNotImplemented)
+			return
+		}
+
+		req := models.NamedGraph{}
+		defer r.Body.Close()
+
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		graph, err := h.Manager().Create(ctx, req.Name)
+		if err != nil {
+			graphError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusCreated)
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(graph); err != nil {
+			slog.Error("error encoding graph", slog.String("reason", err.Error()))
+		}
+	})
+}
+
+// DELETEGraph drops a named graph.
+// @Summary Drop a named graph.
+// @Description Drop the named graph with all its nodes and edges.
+// @Tags graphs
+// @Produce json
+// @Param name path string true "Graph name"
+// @Success 200 {object} models.NamedGraph "Dropped named graph"
+// @Failure 404 "Not found"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Server does not support named graphs"
+// @Router /api/v1/graphs/{name} [delete]
+func DELETEGraph(mux *http.ServeMux, h *graphs.Handlers) {
+	slog.Info("registered route", slog.String("route", "DELETE /api/v1/graphs/{name}"))
+	mux.HandleFunc("DELETE /api/v1/graphs/{name}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if h == nil {
+			http.Error(w, "server does not support named graphs", http.StatusNotImplemented)
+			return
+		}
+
+		graph, err := h.Drop(ctx, r.PathValue("name"))
+		if err != nil {
+			graphError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(graph); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// NamedGraph serves the API of a named graph.
+// @Summary Serves the API of a named graph.
+// @Description Every /api/v1/... route of the default graph is served for the named graph at /api/v1/graphs/{name}/...,
+// @Description eg: PUT /api/v1/graphs/movies/nodes or GET /api/v1/graphs/movies/graph/nodes/1.
+// @Tags graphs
+// @Param name path string true "Graph name"
+// @Param path path string true "Path of the /api/v1 route, eg: nodes"
+// @Failure 404 "Not found"
+// @Failure 501 "Server does not support named graphs"
+// @Router /api/v1/graphs/{name}/{path} [get]
+// @Router /api/v1/graphs/{name}/{path} [put]
+// @Router /api/v1/graphs/{name}/{path} [post]
+// @Router /api/v1/graphs/{name}/{path} [patch]
+// @Router /api/v1/graphs/{name}/{path} [delete]
+func NamedGraph(mux *http.ServeMux, h *graphs.Handlers) {
+	slog.Info("registered route", slog.String("route", "/api/v1/graphs/{name}/{path...}"))
+	mux.HandleFunc("/api/v1/graphs/{name}/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		if h == nil {
+			http.Error(w, "server does not support named graphs", http.StatusNotImplemented)
+			return
+		}
+
+		handler, release, err := h.Handler(r.PathValue("name"))
+		if err != nil {
+			graphError(w, err)
+			return
+		}
+
+		defer release()
+
+		// serve the request as if it was made to the default graph.
+		req := r.Clone(r.Context())
+		req.URL.Path = "/api/v1/" + r.PathValue("path")
+		req.URL.RawPath = ""
+
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// apiKeyStoreError writes the API key error.
+func apiKeyStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, store.ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// GETAPIKeys returns the API keys.
+// @Summary Returns the API keys.
+// @Description Returns the API keys ordered by ID with their prefix and when they were last used, the keys are not returned.
+// @Tags auth
+// @Produce json
+// @Success 200 {array} models.APIKey "List of API keys"
+// @Failure 401 "Unauthorized"
+// @Failure 403 "Forbidden"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support API keys"
+// @Router /api/v1/auth/keys [get]
+func GETAPIKeys(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "GET /api/v1/auth/keys"))
+	mux.HandleFunc("GET /api/v1/auth/keys", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		ks, ok := s.(store.APIKeyStore)
+		if !ok {
+			http.Error(w, "store does not support API keys", http.StatusNotImplemented)
+			return
+		}
+
+		keys, err := ks.APIKeys(ctx)
+		if err != nil {
+			apiKeyStoreError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(keys); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// POSTAPIKey creates an API key.
+// @Summary Create an API key.
+// @Description Create an API key with the reader, writer or admin role, eg: {"name": "importer", "role": "writer"}.
+// @Description The key is generated by the server and only returned when the API key is created, only a hash of the key is stored.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param key body models.APIKey true "API key, only the name and role are used"
+// @Success 201 {object} models.APIKey "Created API key with its key"
+// @Failure 400 "Bad request"
+// @Failure 401 "Unauthorized"
+// @Failure 403 "Forbidden"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support API keys"
+// @Router /api/v1/auth/keys [post]
+func POSTAPIKey(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "POST /api/v1/auth/keys"))
+	mux.HandleFunc("POST /api/v1/auth/keys", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		ks, ok := s.(store.APIKeyStore)
+		if !ok {
+			http.Error(w, "store does not support API keys", http.StatusNotImplemented)
+			return
+		}
+
+		req := models.APIKey{}
+		defer r.Body.Close()
+
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		key, err := ks.CreateAPIKey(ctx, models.APIKey{Name: req.Name, Role: req.Role, Key: req.Key})
+		if err != nil {
+			apiKeyStoreError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusCreated)
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(key); err != nil {
+			slog.Error("error encoding api key", slog.String("reason", err.Error()))
+		}
+	})
+}
+
+// DELETEAPIKey deletes an API key.
+// @Summary Delete an API key.
+// @Description Delete the API key, requests using the key are no longer authenticated.
+// @Tags auth
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 200 {object} models.APIKey "Deleted API key"
+// @Failure 400 "Bad request"
+// @Failure 401 "Unauthorized"
+// @Failure 403 "Forbidden"
+// @Failure 404 "Not found"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Store does not support API keys"
+// @Router /api/v1/auth/keys/{id} [delete]
+func DELETEAPIKey(mux *http.ServeMux, s store.Store) {
+	slog.Info("registered route", slog.String("route", "DELETE /api/v1/auth/keys/{id}"))
+	mux.HandleFunc("DELETE /api/v1/auth/keys/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		ks, ok := s.(store.APIKeyStore)
+		if !ok {
+			http.Error(w, "store does not support API keys", http.StatusNotImplemented)
+			return
+		}
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		key, err := ks.DeleteAPIKey(ctx, id)
+		if err != nil {
+			apiKeyStoreError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(key); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
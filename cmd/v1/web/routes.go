@@ -5,8 +5,12 @@ import (
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/jenmud/edgedb/cmd/v1/web/view/pages"
+	"github.com/jenmud/edgedb/cmd/v1/web/view/partials"
+	"github.com/jenmud/edgedb/internal/auth"
+	"github.com/jenmud/edgedb/internal/graphs"
 	"github.com/jenmud/edgedb/internal/store"
 	"github.com/jenmud/edgedb/models"
 	"github.com/starfederation/datastar-go/datastar"
@@ -57,7 +61,13 @@ func SubGraph(mux *http.ServeMux, s store.Store) {
 			return
 		}
 
-		graph, err := s.SubGraph(ctx, store.SubGraphArgs{FromNodeID: id})
+		args := store.SubGraphArgs{FromNodeID: id, Depth: 1}
+
+		if d, err := strconv.Atoi(r.URL.Query().Get("depth")); err == nil {
+			args.Depth = d
+		}
+
+		graph, err := s.SubGraph(ctx, args)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -156,3 +166,118 @@ func FilterGraphTableContent(mux *http.ServeMux, s store.Store) {
 		component.Render(ctx, w)
 	})
 }
+
+// GraphCookie is the cookie holding the name of the graph shown by the UI, the default graph is shown without it.
+const GraphCookie = "edgedb_graph"
+
+// SelectGraph switches the UI to the named graph, the default graph is shown again when selecting `default`.
+func SelectGraph(mux *http.ServeMux, h *graphs.Handlers) {
+	slog.Info("registered route", slog.String("route", "GET /ui/v1/graphs/{name}"))
+	mux.HandleFunc("GET /ui/v1/graphs/{name}", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+
+		switch {
+		case name == graphs.Default:
+			http.SetCookie(w, &http.Cookie{Name: GraphCookie, Path: "/", MaxAge: -1})
+		case h == nil:
+			http.Error(w, "server does not support named graphs", http.StatusNotImplemented)
+			return
+		default:
+			if _, err := h.Manager().Store(name); err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+
+			http.SetCookie(w, &http.Cookie{Name: GraphCookie, Value: name, Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode})
+		}
+
+		http.Redirect(w, r, "/ui/v1/graph/filter/table", http.StatusSeeOther)
+	})
+}
+
+// NamedGraphs serves the UI requests of the graph selected by the GraphCookie with the named graph handlers, the other
+// requests are served by next. The UI requests are given the graph menu of the navbar.
+func NamedGraphs(next http.Handler, h *graphs.Handlers) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h == nil || !strings.HasPrefix(r.URL.Path, "/ui/v1/graph/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		menu := partials.GraphMenu{Current: graphs.Default, Names: []string{graphs.Default}}
+		for _, g := range h.Manager().Graphs() {
+			menu.Names = append(menu.Names, g.Name)
+		}
+
+		handler := next
+
+		// a dropped graph falls back to the default graph.
+		if cookie, err := r.Cookie(GraphCookie); err == nil {
+			if named, release, err := h.Handler(cookie.Value); err == nil {
+				defer release()
+				handler = named
+				menu.Current = cookie.Value
+			}
+		}
+
+		handler.ServeHTTP(w, r.WithContext(partials.WithGraphMenu(r.Context(), menu)))
+	})
+}
+
+// Login logs the UI in with an API key or bearer token kept in the auth.Cookie, the UI is shown without logging in if
+// a is nil.
+func Login(mux *http.ServeMux, a *auth.Authenticator) {
+	slog.Info("registered route", slog.String("route", "GET /ui/v1/login"))
+	mux.HandleFunc("GET /ui/v1/login", func(w http.ResponseWriter, r *http.Request) {
+		if a == nil {
+			http.Redirect(w, r, "/ui/v1/graph/filter/table", http.StatusSeeOther)
+			return
+		}
+
+		component := pages.LoginPage("")
+		component.Render(r.Context(), w)
+	})
+
+	slog.Info("registered route", slog.String("route", "POST /ui/v1/login"))
+	mux.HandleFunc("POST /ui/v1/login", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if a == nil {
+			http.Redirect(w, r, "/ui/v1/graph/filter/table", http.StatusSeeOther)
+			return
+		}
+
+		credential := strings.TrimSpace(r.PostFormValue("credential"))
+
+		identity, err := a.AuthenticateCredential(ctx, credential)
+		if err != nil {
+			slog.Debug("ui login failed", slog.String("reason", err.Error()))
+			w.WriteHeader(http.StatusUnauthorized)
+			component := pages.LoginPage("Invalid API key or token")
+			component.Render(ctx, w)
+			return
+		}
+
+		slog.Info("ui login", slog.String("subject", identity.Subject), slog.String("role", string(identity.Role)))
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     auth.Cookie,
+			Value:    credential,
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+
+		http.Redirect(w, r, "/ui/v1/graph/filter/table", http.StatusSeeOther)
+	})
+}
+
+// Logout logs the UI out by removing the auth.Cookie.
+func Logout(mux *http.ServeMux) {
+	slog.Info("registered route", slog.String("route", "GET /ui/v1/logout"))
+	mux.HandleFunc("GET /ui/v1/logout", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: auth.Cookie, Path: "/", MaxAge: -1})
+		http.Redirect(w, r, "/ui/v1/login", http.StatusSeeOther)
+	})
+}
@@ -0,0 +1,26 @@
+package partials
+
+import "context"
+
+// graphMenuKey is the context key of the GraphMenu.
+type graphMenuKey struct{}
+
+// GraphMenu is the menu switching between the default graph and the named graphs in the navbar.
+type GraphMenu struct {
+	// Current is the name of the graph shown.
+	Current string
+
+	// Names are the names of the graphs which can be shown, including the default graph.
+	Names []string
+}
+
+// WithGraphMenu returns the context with the graph menu shown in the navbar.
+func WithGraphMenu(ctx context.Context, menu GraphMenu) context.Context {
+	return context.WithValue(ctx, graphMenuKey{}, menu)
+}
+
+// graphMenu returns the graph menu of the context, false is returned if there is no menu.
+func graphMenu(ctx context.Context) (GraphMenu, bool) {
+	menu, ok := ctx.Value(graphMenuKey{}).(GraphMenu)
+	return menu, ok
+}
@@ -15,274 +15,219 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/edges": {
-            "get": {
-                "description": "Search and return edges.",
+        "/api/v1/admin/reindex": {
+            "post": {
+                "description": "Rebuilds the term search index from the stored nodes and edges, used to repair drifted indexes.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "edges"
-                ],
-                "summary": "Search and return edges",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "default": "",
-                        "description": "search term",
-                        "name": "term",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "default": "\u003cspan class=\"text-red-500\"\u003e",
-                        "description": "snippet start",
-                        "name": "snippetStart",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "default": "\u003c/span\u003e",
-                        "description": "snippet start",
-                        "name": "snippetEnd",
-                        "in": "query"
-                    },
-                    {
-                        "maximum": 64,
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 10,
-                        "description": "snippet tokens",
-                        "name": "tokens",
-                        "in": "query"
-                    },
-                    {
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 1000,
-                        "description": "limit results returned",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "last known ID from previous result used for pagination",
-                        "name": "lastID",
-                        "in": "query"
-                    }
+                    "admin"
                 ],
+                "summary": "Rebuilds the term search index.",
                 "responses": {
                     "200": {
-                        "description": "List of edges",
+                        "description": "Number of items indexed",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Edge"
-                            }
+                            "$ref": "#/definitions/api.ReindexResp"
                         }
                     },
-                    "400": {
-                        "description": "Bad request"
-                    },
                     "500": {
                         "description": "Internal server error"
                     }
                 }
-            },
-            "put": {
-                "description": "Add/update on or more edges.",
+            }
+        },
+        "/api/v1/auth/keys": {
+            "get": {
+                "description": "Returns the API keys ordered by ID with their prefix and when they were last used, the keys are not returned.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "edges"
-                ],
-                "summary": "Add/update one or more edges.",
-                "parameters": [
-                    {
-                        "description": "One or more nodes to add/update",
-                        "name": "nodes",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.PUTEdgesReq"
-                        }
-                    }
+                    "auth"
                 ],
+                "summary": "Returns the API keys.",
                 "responses": {
                     "200": {
-                        "description": "List of edges",
+                        "description": "List of API keys",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Edge"
+                                "$ref": "#/definitions/models.APIKey"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad request"
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
                     },
                     "500": {
                         "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support API keys"
                     }
                 }
-            }
-        },
-        "/api/v1/graph": {
-            "get": {
-                "description": "search return nodes and edges in a format that can be used in a force directed graph",
+            },
+            "post": {
+                "description": "Create an API key with the reader, writer or admin role, eg: {\"name\": \"importer\", \"role\": \"writer\"}.\nThe key is generated by the server and only returned when the API key is created, only a hash of the key is stored.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "graph"
+                    "auth"
                 ],
-                "summary": "search return nodes and edges used for a force directed graph",
+                "summary": "Create an API key.",
                 "parameters": [
                     {
-                        "type": "string",
-                        "default": "",
-                        "description": "search term",
-                        "name": "term",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "default": "\u003cspan class=\"text-red-500\"\u003e",
-                        "description": "snippet start",
-                        "name": "snippetStart",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "default": "\u003c/span\u003e",
-                        "description": "snippet start",
-                        "name": "snippetEnd",
-                        "in": "query"
-                    },
-                    {
-                        "maximum": 64,
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 10,
-                        "description": "snippet tokens",
-                        "name": "tokens",
-                        "in": "query"
-                    },
-                    {
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 1000,
-                        "description": "limit results returned",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "API key, only the name and role are used",
+                        "name": "key",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.APIKey"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Payload used for drawing graphs.",
+                    "201": {
+                        "description": "Created API key with its key",
                         "schema": {
-                            "$ref": "#/definitions/models.Graph"
+                            "$ref": "#/definitions/models.APIKey"
                         }
                     },
                     "400": {
                         "description": "Bad request"
                     },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support API keys"
                     }
                 }
-            },
-            "put": {
-                "description": "Uploads a graph using a upsert strategy.",
+            }
+        },
+        "/api/v1/auth/keys/{id}": {
+            "delete": {
+                "description": "Delete the API key, requests using the key are no longer authenticated.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "graph"
+                    "auth"
                 ],
-                "summary": "Uploads a graph using a upsert strategy.",
+                "summary": "Delete an API key.",
                 "parameters": [
                     {
-                        "description": "Graph that you are uploading",
-                        "name": "nodes",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Graph"
-                        }
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Uploaded graph.",
+                        "description": "Deleted API key",
                         "schema": {
-                            "$ref": "#/definitions/models.Graph"
+                            "$ref": "#/definitions/models.APIKey"
                         }
                     },
                     "400": {
                         "description": "Bad request"
                     },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
                     "500": {
                         "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support API keys"
                     }
                 }
             }
         },
-        "/api/v1/graph/nodes/{id}": {
+        "/api/v1/changes": {
             "get": {
-                "description": "Returns a graph from a node.",
+                "description": "Streams the changelog as server-sent ` + "`" + `change` + "`" + ` events with the before and after images of the node or edge.\nThe event ID is the sequence number of the change, the feed resumes after the Last-Event-ID header or the since sequence number.",
                 "produces": [
-                    "application/json"
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "graph"
+                    "changes"
                 ],
-                "summary": "Returns a graph from a node.",
+                "summary": "Stream the changes to nodes and edges.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Node id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "default": 0,
+                        "description": "only stream the changes after the sequence number",
+                        "name": "since",
+                        "in": "query"
                     },
                     {
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 1,
-                        "description": "how many levels deep to return",
-                        "name": "limit",
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "only stream changes to items with any of the labels",
+                        "name": "label",
                         "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "sequence number of the last received change, sent by reconnecting clients",
+                        "name": "Last-Event-ID",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Payload used for drawing graphs.",
+                        "description": "Stream of changes",
                         "schema": {
-                            "$ref": "#/definitions/models.Graph"
+                            "$ref": "#/definitions/models.Change"
                         }
                     },
-                    "404": {
-                        "description": "Not found"
+                    "400": {
+                        "description": "Bad request"
                     },
-                    "500": {
-                        "description": "Internal server error"
+                    "501": {
+                        "description": "Store does not support the change feed"
                     }
                 }
             }
         },
-        "/api/v1/nodes": {
+        "/api/v1/edges": {
             "get": {
-                "description": "Search and return nodes",
+                "description": "Search and return edges.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "nodes"
+                    "edges"
                 ],
-                "summary": "Search and return nodes",
+                "summary": "Search and return edges",
                 "parameters": [
                     {
                         "type": "string",
@@ -328,15 +273,59 @@ const docTemplate = `{
                         "description": "last known ID from previous result used for pagination",
                         "name": "lastID",
                         "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "only return items with any of the labels",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "property predicate as path:op:value, op is one of eq, ne, gt, gte, lt, lte, in, exists, nexists",
+                        "name": "where",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return items created at or after the RFC3339 or unix time",
+                        "name": "createdAfter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return items created before the RFC3339 or unix time",
+                        "name": "createdBefore",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return items updated at or after the RFC3339 or unix time",
+                        "name": "updatedAfter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return items updated before the RFC3339 or unix time",
+                        "name": "updatedBefore",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of nodes",
+                        "description": "List of edges",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Node"
+                                "$ref": "#/definitions/models.Edge"
                             }
                         }
                     },
@@ -349,14 +338,14 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Add/update on or more nodes.",
+                "description": "Add/update on or more edges.\nWith merge the edges are matched on their from ID, label and to ID instead of their ID, and each edge reports if it was created.\nEdges with a version are only written if it is the current version, otherwise nothing is written.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "nodes"
+                    "edges"
                 ],
-                "summary": "Add/update one or more nodes.",
+                "summary": "Add/update one or more edges.",
                 "parameters": [
                     {
                         "description": "One or more nodes to add/update",
@@ -364,76 +353,3355 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.PUTNodesReq"
+                            "$ref": "#/definitions/api.PUTEdgesReq"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "match the edges on their from ID, label and to ID",
+                        "name": "merge",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of nodes",
+                        "description": "List of edges, created is only reported when merging",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Node"
+                                "$ref": "#/definitions/store.MergedEdge"
                             }
                         }
                     },
                     "400": {
-                        "description": "Bad request"
+                        "description": "Bad request or an edge does not match its schema",
+                        "schema": {
+                            "$ref": "#/definitions/store.SchemaError"
+                        }
+                    },
+                    "409": {
+                        "description": "An edge is not at the version",
+                        "schema": {
+                            "$ref": "#/definitions/store.VersionError"
+                        }
                     },
                     "500": {
                         "description": "Internal server error"
                     }
                 }
-            }
-        },
-        "/healthz": {
-            "get": {
-                "description": "Returns returns the health status.",
+            },
+            "delete": {
+                "description": "Delete one or more edges. Soft moves the edges to the trash.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Health"
+                    "edges"
                 ],
-                "summary": "Returns returns the health status.",
-                "responses": {
-                    "200": {
-                        "description": "Current health status",
-                        "schema": {
-                            "$ref": "#/definitions/models.Health"
+                "summary": "Delete one or more edges.",
+                "parameters": [
+                    {
+                        "description": "One or more edges to delete",
+                        "name": "edges",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.DELETEEdgesReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of deleted edges",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Edge"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support the trash"
+                    }
+                }
+            }
+        },
+        "/api/v1/edges/{id}": {
+            "get": {
+                "description": "Returns the edge, the ETag is the version of the edge.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "edges"
+                ],
+                "summary": "Returns an edge.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Edge id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Edge",
+                        "schema": {
+                            "$ref": "#/definitions/models.Edge"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the edge"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "put": {
+                "description": "Add/update the edge with the ID. The edge is only written if If-Match, or the version in the body, is\nthe current version of the edge, otherwise the current edge is returned in the conflict.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "edges"
+                ],
+                "summary": "Add/update an edge.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Edge id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "current version of the edge",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Edge to add/update",
+                        "name": "edge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Edge"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Edge",
+                        "schema": {
+                            "$ref": "#/definitions/models.Edge"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the edge"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request or the edge does not match its schema",
+                        "schema": {
+                            "$ref": "#/definitions/store.SchemaError"
+                        }
+                    },
+                    "409": {
+                        "description": "The edge is not at the version",
+                        "schema": {
+                            "$ref": "#/definitions/store.VersionError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "patch": {
+                "description": "Applies a JSON Patch (` + "`" + `application/json-patch+json` + "`" + `) or otherwise a JSON Merge Patch\n(` + "`" + `application/merge-patch+json` + "`" + `) to the properties of the edge, JSON Patch paths are relative to the\nproperties, eg: ` + "`" + `/since` + "`" + `. The edge is only patched if If-Match is the current version of the edge.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "edges"
+                ],
+                "summary": "Update some of the properties of an edge.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Edge id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "current version of the edge",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Merge patch or JSON Patch",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Edge",
+                        "schema": {
+                            "$ref": "#/definitions/models.Edge"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the edge"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request or the patched edge does not match its schema",
+                        "schema": {
+                            "$ref": "#/definitions/store.SchemaError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "409": {
+                        "description": "The edge is not at the version or the patch can not be applied",
+                        "schema": {
+                            "$ref": "#/definitions/store.VersionError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support patches"
+                    }
+                }
+            }
+        },
+        "/api/v1/edges/{id}/history": {
+            "get": {
+                "description": "Returns the revisions of the edge oldest first, each with the property-level diff from the previous revision.\nA deleted edge has a last revision without the edge.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "edges"
+                ],
+                "summary": "Returns the revisions of an edge.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Edge id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of revisions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Revision"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support history"
+                    }
+                }
+            }
+        },
+        "/api/v1/edges/{id}/restore": {
+            "post": {
+                "description": "Restores the edge, both of its nodes must not be in the trash.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Restores an edge from the trash.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Edge id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Restored edge",
+                        "schema": {
+                            "$ref": "#/definitions/store.RestoreResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "409": {
+                        "description": "One of the nodes is in the trash"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support the trash"
+                    }
+                }
+            }
+        },
+        "/api/v1/export": {
+            "get": {
+                "description": "Streams the whole store, the graph matching a search term or the sub-graph around a node as GraphML, GEXF, DOT or CSV.\nProperties are written as typed attributes, nested properties are flattened into dotted keys, eg: ` + "`" + `meta.hair` + "`" + `.\nCSV writes a single table, either the nodes or the edges.",
+                "produces": [
+                    "application/xml",
+                    "text/vnd.graphviz",
+                    "text/csv"
+                ],
+                "tags": [
+                    "export"
+                ],
+                "summary": "Exports the graph.",
+                "parameters": [
+                    {
+                        "enum": [
+                            "graphml",
+                            "gexf",
+                            "dot",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "export format",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "nodes",
+                            "edges"
+                        ],
+                        "type": "string",
+                        "default": "nodes",
+                        "description": "table written by csv exports",
+                        "name": "table",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only export the graph matching the search term",
+                        "name": "term",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1000,
+                        "description": "max number of term matches",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only export the sub-graph around this node",
+                        "name": "node",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "how many levels (hops) deep the sub-graph is",
+                        "name": "depth",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "out",
+                            "in",
+                            "both"
+                        ],
+                        "type": "string",
+                        "default": "both",
+                        "description": "direction sub-graph edges are followed",
+                        "name": "direction",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exported graph"
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "404": {
+                        "description": "Not found"
+                    }
+                }
+            }
+        },
+        "/api/v1/graph": {
+            "get": {
+                "description": "search return nodes and edges in a format that can be used in a force directed graph",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graph"
+                ],
+                "summary": "search return nodes and edges used for a force directed graph",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "",
+                        "description": "search term",
+                        "name": "term",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "\u003cspan class=\"text-red-500\"\u003e",
+                        "description": "snippet start",
+                        "name": "snippetStart",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "\u003c/span\u003e",
+                        "description": "snippet start",
+                        "name": "snippetEnd",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 64,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "snippet tokens",
+                        "name": "tokens",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1000,
+                        "description": "limit results returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search the graph as it was at the RFC3339 or unix time",
+                        "name": "asOf",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Payload used for drawing graphs.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Graph"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support history"
+                    }
+                }
+            },
+            "put": {
+                "description": "Uploads a graph using a upsert strategy in a single transaction, either the whole graph is written or nothing is.\nNodes can set a temporary ` + "`" + `ref` + "`" + ` which edges use in ` + "`" + `from_ref` + "`" + ` and ` + "`" + `to_ref` + "`" + ` instead of node IDs.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graph"
+                ],
+                "summary": "Uploads a graph using a upsert strategy.",
+                "parameters": [
+                    {
+                        "description": "Graph that you are uploading",
+                        "name": "nodes",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Graph"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Uploaded graph and the node ID for every ref.",
+                        "schema": {
+                            "$ref": "#/definitions/store.UpsertGraphResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request or an item does not match its schema",
+                        "schema": {
+                            "$ref": "#/definitions/store.SchemaError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/api/v1/graph/nodes/{id}": {
+            "get": {
+                "description": "Returns the neighbourhood N hops deep from a node.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graph"
+                ],
+                "summary": "Returns a graph from a node.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Node id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "how many levels (hops) deep to return",
+                        "name": "depth",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "deprecated: use depth",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "out",
+                            "in",
+                            "both"
+                        ],
+                        "type": "string",
+                        "default": "both",
+                        "description": "direction edges are followed",
+                        "name": "direction",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "only follow edges with these labels",
+                        "name": "labels",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1000,
+                        "description": "node budget, closest nodes are returned first",
+                        "name": "maxNodes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "return the sub-graph as it was at the RFC3339 or unix time",
+                        "name": "asOf",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Payload used for drawing graphs.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Graph"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support history"
+                    }
+                }
+            }
+        },
+        "/api/v1/graph/path": {
+            "get": {
+                "description": "Returns the shortest paths between two nodes as a graph and an ordered hop list.\nshortest uses the fewest hops, weighted uses the lowest total edge weight.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graph"
+                ],
+                "summary": "Returns the shortest paths between two nodes.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "node id the path starts from",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "node id the path ends at",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "shortest",
+                            "weighted"
+                        ],
+                        "type": "string",
+                        "default": "shortest",
+                        "description": "path finding mode",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "max number of hops in a path",
+                        "name": "maxDepth",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "out",
+                            "in",
+                            "both"
+                        ],
+                        "type": "string",
+                        "default": "out",
+                        "description": "direction edges are followed",
+                        "name": "direction",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "only follow edges with these labels",
+                        "name": "labels",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "number of shortest paths to return",
+                        "name": "k",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Paths, shortest first",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Path"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "404": {
+                        "description": "No path found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/api/v1/graphs": {
+            "get": {
+                "description": "Returns the named graphs ordered by name, the default graph is not listed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphs"
+                ],
+                "summary": "Returns the named graphs.",
+                "responses": {
+                    "200": {
+                        "description": "List of named graphs",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.NamedGraph"
+                            }
+                        }
+                    },
+                    "501": {
+                        "description": "Server does not support named graphs"
+                    }
+                }
+            },
+            "post": {
+                "description": "Create an empty named graph, eg: {\"name\": \"movies\"}. The name is made of lower case letters, digits, ` + "`" + `_` + "`" + ` or ` + "`" + `-` + "`" + `.\nThe nodes and edges of the graph are served by the /api/v1/graphs/{name}/... routes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphs"
+                ],
+                "summary": "Create a named graph.",
+                "parameters": [
+                    {
+                        "description": "Named graph",
+                        "name": "graph",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.NamedGraph"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created named graph",
+                        "schema": {
+                            "$ref": "#/definitions/models.NamedGraph"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "409": {
+                        "description": "Graph already exists"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Server does not support named graphs"
+                    }
+                }
+            }
+        },
+        "/api/v1/graphs/{name}": {
+            "delete": {
+                "description": "Drop the named graph with all its nodes and edges.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphs"
+                ],
+                "summary": "Drop a named graph.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Graph name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dropped named graph",
+                        "schema": {
+                            "$ref": "#/definitions/models.NamedGraph"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Server does not support named graphs"
+                    }
+                }
+            }
+        },
+        "/api/v1/graphs/{name}/{path}": {
+            "get": {
+                "description": "Every /api/v1/... route of the default graph is served for the named graph at /api/v1/graphs/{name}/...,\neg: PUT /api/v1/graphs/movies/nodes or GET /api/v1/graphs/movies/graph/nodes/1.",
+                "tags": [
+                    "graphs"
+                ],
+                "summary": "Serves the API of a named graph.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Graph name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Path of the /api/v1 route, eg: nodes",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "501": {
+                        "description": "Server does not support named graphs"
+                    }
+                }
+            },
+            "put": {
+                "description": "Every /api/v1/... route of the default graph is served for the named graph at /api/v1/graphs/{name}/...,\neg: PUT /api/v1/graphs/movies/nodes or GET /api/v1/graphs/movies/graph/nodes/1.",
+                "tags": [
+                    "graphs"
+                ],
+                "summary": "Serves the API of a named graph.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Graph name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Path of the /api/v1 route, eg: nodes",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "501": {
+                        "description": "Server does not support named graphs"
+                    }
+                }
+            },
+            "post": {
+                "description": "Every /api/v1/... route of the default graph is served for the named graph at /api/v1/graphs/{name}/...,\neg: PUT /api/v1/graphs/movies/nodes or GET /api/v1/graphs/movies/graph/nodes/1.",
+                "tags": [
+                    "graphs"
+                ],
+                "summary": "Serves the API of a named graph.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Graph name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Path of the /api/v1 route, eg: nodes",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "501": {
+                        "description": "Server does not support named graphs"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Every /api/v1/... route of the default graph is served for the named graph at /api/v1/graphs/{name}/...,\neg: PUT /api/v1/graphs/movies/nodes or GET /api/v1/graphs/movies/graph/nodes/1.",
+                "tags": [
+                    "graphs"
+                ],
+                "summary": "Serves the API of a named graph.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Graph name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Path of the /api/v1 route, eg: nodes",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "501": {
+                        "description": "Server does not support named graphs"
+                    }
+                }
+            },
+            "patch": {
+                "description": "Every /api/v1/... route of the default graph is served for the named graph at /api/v1/graphs/{name}/...,\neg: PUT /api/v1/graphs/movies/nodes or GET /api/v1/graphs/movies/graph/nodes/1.",
+                "tags": [
+                    "graphs"
+                ],
+                "summary": "Serves the API of a named graph.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Graph name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Path of the /api/v1 route, eg: nodes",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "501": {
+                        "description": "Server does not support named graphs"
+                    }
+                }
+            }
+        },
+        "/api/v1/import": {
+            "post": {
+                "description": "Imports a streamed NDJSON body with one node or edge per line, eg: ` + "`" + `{\"type\": \"node\", \"ref\": \"n1\", \"label\": \"person\"}` + "`" + `\nor ` + "`" + `{\"type\": \"edge\", \"from_ref\": \"n1\", \"label\": \"knows\", \"to_id\": 2}` + "`" + `. Edges can use the refs of nodes on earlier lines.\nLines are written in chunked transactions, lines which fail are skipped and reported.\nThe response is NDJSON with an ` + "`" + `error` + "`" + ` event for every skipped line, a ` + "`" + `progress` + "`" + ` event after every chunk and a final ` + "`" + `done` + "`" + ` event.",
+                "consumes": [
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "import"
+                ],
+                "summary": "Streams a NDJSON import.",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1000,
+                        "description": "number of lines written per transaction",
+                        "name": "chunkSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of import events",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.ImportEvent"
+                            }
+                        }
+                    },
+                    "501": {
+                        "description": "Store does not support importing"
+                    }
+                }
+            }
+        },
+        "/api/v1/import/{format}": {
+            "post": {
+                "description": "Imports a GraphML or GEXF document sent as the body, or CSV files sent as ` + "`" + `multipart/form-data` + "`" + ` with a ` + "`" + `nodes` + "`" + ` file\nand an optional ` + "`" + `edges` + "`" + ` file. Everything is written in a single transaction, either the whole graph is written or nothing is.\nAttribute types are kept, dotted attribute names become nested properties and the source node IDs are mapped to the new node IDs in ` + "`" + `refs` + "`" + `.\nA dry run parses and validates the graph, including the schemas and natural keys, without writing it. Nothing is written when problems are found.",
+                "consumes": [
+                    "application/xml",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import"
+                ],
+                "summary": "Imports a GraphML, GEXF or CSV graph.",
+                "parameters": [
+                    {
+                        "enum": [
+                            "graphml",
+                            "gexf",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "import format",
+                        "name": "format",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "validate without writing",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "nodes csv file",
+                        "name": "nodes",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "edges csv file",
+                        "name": "edges",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Import report",
+                        "schema": {
+                            "$ref": "#/definitions/importer.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid graph",
+                        "schema": {
+                            "$ref": "#/definitions/importer.Report"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/api/v1/indexes": {
+            "get": {
+                "description": "Returns the property indexes with their build status and size in bytes ordered by ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "indexes"
+                ],
+                "summary": "Returns the property indexes.",
+                "responses": {
+                    "200": {
+                        "description": "List of property indexes",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PropertyIndex"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support property indexes"
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a secondary index on a property, eg: {\"label\": \"person\", \"path\": \"name\"}. The index is limited to the items with the label, every item is indexed if the label is empty.\nThe index is built in the background, poll the index until its status is ready or failed. Filters and queries comparing the property use the index once it is ready.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "indexes"
+                ],
+                "summary": "Create a property index.",
+                "parameters": [
+                    {
+                        "description": "Property index, only the label and path are used",
+                        "name": "index",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PropertyIndex"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Property index being built",
+                        "schema": {
+                            "$ref": "#/definitions/models.PropertyIndex"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "409": {
+                        "description": "The label and path are already indexed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support property indexes"
+                    }
+                }
+            }
+        },
+        "/api/v1/indexes/{id}": {
+            "get": {
+                "description": "Returns the property index with its build status and size in bytes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "indexes"
+                ],
+                "summary": "Returns a property index.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "property index ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Property index",
+                        "schema": {
+                            "$ref": "#/definitions/models.PropertyIndex"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support property indexes"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Drop the property index, filters and queries comparing the property scan the items again.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "indexes"
+                ],
+                "summary": "Drop a property index.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "property index ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dropped property index",
+                        "schema": {
+                            "$ref": "#/definitions/models.PropertyIndex"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support property indexes"
+                    }
+                }
+            }
+        },
+        "/api/v1/keys": {
+            "get": {
+                "description": "Returns the natural keys used to merge nodes ordered by label.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "keys"
+                ],
+                "summary": "Returns the natural keys.",
+                "responses": {
+                    "200": {
+                        "description": "List of natural keys",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.NaturalKey"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/api/v1/keys/{label}": {
+            "get": {
+                "description": "Returns the natural key of the node label.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "keys"
+                ],
+                "summary": "Returns a natural key.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "node label",
+                        "name": "label",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Natural key",
+                        "schema": {
+                            "$ref": "#/definitions/models.NaturalKey"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "put": {
+                "description": "Add/replace the natural key of the node label, eg: {\"property\": \"email\"}.\nNatural keys are unique, writing a node with the same key value as another node is a conflict. Nodes without the key property are not keyed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "keys"
+                ],
+                "summary": "Add/replace a natural key.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "node label",
+                        "name": "label",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Natural key, the label is taken from the path",
+                        "name": "key",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.NaturalKey"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Natural key",
+                        "schema": {
+                            "$ref": "#/definitions/models.NaturalKey"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "409": {
+                        "description": "Existing nodes have the same key value"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete the natural key of the node label, nodes with the label can no longer be merged.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "keys"
+                ],
+                "summary": "Delete a natural key.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "node label",
+                        "name": "label",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deleted natural key",
+                        "schema": {
+                            "$ref": "#/definitions/models.NaturalKey"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/api/v1/nodes": {
+            "get": {
+                "description": "Search and return nodes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "nodes"
+                ],
+                "summary": "Search and return nodes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "",
+                        "description": "search term",
+                        "name": "term",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "\u003cspan class=\"text-red-500\"\u003e",
+                        "description": "snippet start",
+                        "name": "snippetStart",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "\u003c/span\u003e",
+                        "description": "snippet start",
+                        "name": "snippetEnd",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 64,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "snippet tokens",
+                        "name": "tokens",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1000,
+                        "description": "limit results returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "last known ID from previous result used for pagination",
+                        "name": "lastID",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "only return items with any of the labels",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "property predicate as path:op:value, op is one of eq, ne, gt, gte, lt, lte, in, exists, nexists",
+                        "name": "where",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return items created at or after the RFC3339 or unix time",
+                        "name": "createdAfter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return items created before the RFC3339 or unix time",
+                        "name": "createdBefore",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return items updated at or after the RFC3339 or unix time",
+                        "name": "updatedAfter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return items updated before the RFC3339 or unix time",
+                        "name": "updatedBefore",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "return the nodes as they were at the RFC3339 or unix time, can not be combined with term",
+                        "name": "asOf",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of nodes",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Node"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support history"
+                    }
+                }
+            },
+            "put": {
+                "description": "Add/update on or more nodes.\nWith merge the nodes are matched on the natural key of their label instead of their ID, and each node reports if it was created.\nNodes with a version are only written if it is the current version, otherwise nothing is written.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "nodes"
+                ],
+                "summary": "Add/update one or more nodes.",
+                "parameters": [
+                    {
+                        "description": "One or more nodes to add/update",
+                        "name": "nodes",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.PUTNodesReq"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "match the nodes on the natural key of their label",
+                        "name": "merge",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of nodes, created is only reported when merging",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.MergedNode"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request or a node does not match its schema",
+                        "schema": {
+                            "$ref": "#/definitions/store.SchemaError"
+                        }
+                    },
+                    "409": {
+                        "description": "Another node has the same natural key or a node is not at the version",
+                        "schema": {
+                            "$ref": "#/definitions/store.VersionError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete one or more nodes applying the policy (restrict, cascade or detach) to the attached edges.\nrestrict fails with a conflict listing the edges blocking the delete. Soft moves the nodes to the trash.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "nodes"
+                ],
+                "summary": "Delete one or more nodes.",
+                "parameters": [
+                    {
+                        "description": "One or more nodes to delete",
+                        "name": "nodes",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.DELETENodesReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deleted nodes and edges",
+                        "schema": {
+                            "$ref": "#/definitions/store.DeleteResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "409": {
+                        "description": "Edges still attached to the nodes",
+                        "schema": {
+                            "$ref": "#/definitions/store.RestrictError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support the trash"
+                    }
+                }
+            }
+        },
+        "/api/v1/nodes/{id}": {
+            "get": {
+                "description": "Returns the node, or the node as it was at the asOf time. The ETag is the version of the node.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "nodes"
+                ],
+                "summary": "Returns a node.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Node id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "return the node as it was at the RFC3339 or unix time",
+                        "name": "asOf",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Node",
+                        "schema": {
+                            "$ref": "#/definitions/models.Node"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the node"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support history"
+                    }
+                }
+            },
+            "put": {
+                "description": "Add/update the node with the ID. The node is only written if If-Match, or the version in the body, is\nthe current version of the node, otherwise the current node is returned in the conflict.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "nodes"
+                ],
+                "summary": "Add/update a node.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Node id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "current version of the node",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Node to add/update",
+                        "name": "node",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Node"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Node",
+                        "schema": {
+                            "$ref": "#/definitions/models.Node"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the node"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request or the node does not match its schema",
+                        "schema": {
+                            "$ref": "#/definitions/store.SchemaError"
+                        }
+                    },
+                    "409": {
+                        "description": "The node is not at the version",
+                        "schema": {
+                            "$ref": "#/definitions/store.VersionError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a node applying the policy (restrict, cascade or detach) to the attached edges.\nrestrict fails with a conflict listing the edges blocking the delete.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "nodes"
+                ],
+                "summary": "Delete a node.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Node id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "restrict",
+                            "cascade",
+                            "detach"
+                        ],
+                        "type": "string",
+                        "default": "restrict",
+                        "description": "policy applied to attached edges",
+                        "name": "policy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "move the node to the trash instead of deleting it",
+                        "name": "soft",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deleted node and edges",
+                        "schema": {
+                            "$ref": "#/definitions/store.DeleteResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "409": {
+                        "description": "Edges still attached to the node",
+                        "schema": {
+                            "$ref": "#/definitions/store.RestrictError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support the trash"
+                    }
+                }
+            },
+            "patch": {
+                "description": "Applies a JSON Patch (` + "`" + `application/json-patch+json` + "`" + `) or otherwise a JSON Merge Patch\n(` + "`" + `application/merge-patch+json` + "`" + `) to the properties of the node, JSON Patch paths are relative to the\nproperties, eg: ` + "`" + `/meta/hair` + "`" + `. The node is only patched if If-Match is the current version of the node.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "nodes"
+                ],
+                "summary": "Update some of the properties of a node.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Node id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "current version of the node",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Merge patch or JSON Patch",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Node",
+                        "schema": {
+                            "$ref": "#/definitions/models.Node"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the node"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request or the patched node does not match its schema",
+                        "schema": {
+                            "$ref": "#/definitions/store.SchemaError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "409": {
+                        "description": "The node is not at the version or the patch can not be applied",
+                        "schema": {
+                            "$ref": "#/definitions/store.VersionError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support patches"
+                    }
+                }
+            }
+        },
+        "/api/v1/nodes/{id}/history": {
+            "get": {
+                "description": "Returns the revisions of the node oldest first, each with the property-level diff from the previous revision.\nA deleted node has a last revision without the node.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "nodes"
+                ],
+                "summary": "Returns the revisions of a node.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Node id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of revisions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Revision"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support history"
+                    }
+                }
+            }
+        },
+        "/api/v1/nodes/{id}/restore": {
+            "post": {
+                "description": "Restores the node together with the edges trashed with it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Restores a node from the trash.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Node id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Restored node and edges",
+                        "schema": {
+                            "$ref": "#/definitions/store.RestoreResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support the trash"
+                    }
+                }
+            }
+        },
+        "/api/v1/query": {
+            "post": {
+                "description": "Run a read-only Cypher-like query supporting MATCH, WHERE, RETURN, ORDER BY, SKIP and LIMIT.\nThe ` + "`" + `table` + "`" + ` format returns the columns and rows, the ` + "`" + `graph` + "`" + ` format returns the returned nodes and edges.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "query"
+                ],
+                "summary": "Run a read-only graph query.",
+                "parameters": [
+                    {
+                        "description": "Query, parameters and response format",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.POSTQueryReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Graph when the format is graph",
+                        "schema": {
+                            "$ref": "#/definitions/models.Graph"
+                        },
+                        "headers": {
+                            "X-EdgeDB-Truncated": {
+                                "type": "boolean",
+                                "description": "true if a variable length relationship stopped walking early"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support queries"
+                    }
+                }
+            }
+        },
+        "/api/v1/schema": {
+            "get": {
+                "description": "Returns the node and edge label schemas ordered by kind and label.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schema"
+                ],
+                "summary": "Returns the label schemas.",
+                "parameters": [
+                    {
+                        "enum": [
+                            "node",
+                            "edge"
+                        ],
+                        "type": "string",
+                        "description": "only return schemas of the kind",
+                        "name": "kind",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of schemas",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Schema"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/api/v1/schema/{kind}/{label}": {
+            "get": {
+                "description": "Returns the schema of the node or edge label.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schema"
+                ],
+                "summary": "Returns a label schema.",
+                "parameters": [
+                    {
+                        "enum": [
+                            "node",
+                            "edge"
+                        ],
+                        "type": "string",
+                        "description": "schema kind",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "node or edge label",
+                        "name": "label",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Schema",
+                        "schema": {
+                            "$ref": "#/definitions/models.Schema"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "put": {
+                "description": "Add/replace the schema enforced when writing nodes or edges with the label.\nProperties are described with a JSON Schema subset: type, enum, pattern, minimum, maximum, minLength, maxLength, items, properties, required and additionalProperties.\nEdge schemas can limit the labels of the nodes they link with from_labels and to_labels.\nstrict schemas reject writes which do not match, warn schemas log a warning and write them anyway. Existing items are not checked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schema"
+                ],
+                "summary": "Add/replace a label schema.",
+                "parameters": [
+                    {
+                        "enum": [
+                            "node",
+                            "edge"
+                        ],
+                        "type": "string",
+                        "description": "schema kind",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "node or edge label",
+                        "name": "label",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Schema, the kind and label are taken from the path",
+                        "name": "schema",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Schema"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Schema",
+                        "schema": {
+                            "$ref": "#/definitions/models.Schema"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete the schema of the node or edge label, writes with the label are no longer checked.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schema"
+                ],
+                "summary": "Delete a label schema.",
+                "parameters": [
+                    {
+                        "enum": [
+                            "node",
+                            "edge"
+                        ],
+                        "type": "string",
+                        "description": "schema kind",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "node or edge label",
+                        "name": "label",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deleted schema",
+                        "schema": {
+                            "$ref": "#/definitions/models.Schema"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/api/v1/trash": {
+            "get": {
+                "description": "Returns the trashed nodes and edges ordered by ID, edges trashed with a node are restored with it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Returns the trashed nodes and edges.",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1000,
+                        "description": "limit results returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "last known ID from previous result used for pagination",
+                        "name": "lastID",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Trashed nodes and edges",
+                        "schema": {
+                            "$ref": "#/definitions/store.Trash"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support the trash"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Permanently removes the items trashed more than olderThan ago, everything in the trash is removed if olderThan is not set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Purges the trash.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "only purge items trashed longer ago than the duration, eg 720h",
+                        "name": "olderThan",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Number of purged nodes and edges",
+                        "schema": {
+                            "$ref": "#/definitions/store.PurgeResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support the trash"
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks": {
+            "get": {
+                "description": "Returns the webhooks ordered by ID, the secrets are not returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Returns the webhooks.",
+                "responses": {
+                    "200": {
+                        "description": "List of webhooks",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Webhook"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support webhooks"
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a webhook posting the changes to nodes and edges to the URL, eg: {\"url\": \"https://example.com/hook\", \"labels\": [\"person\"], \"ops\": [\"create\", \"delete\"]}.\nThe changes are limited to the labels and ops if given. Each delivery is signed with the secret in the X-EdgeDB-Signature header as ` + "`" + `sha256=\u003chex hmac of the body\u003e` + "`" + `, a secret is generated if none is given.\nThe secret is only returned when the webhook is created.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create a webhook.",
+                "parameters": [
+                    {
+                        "description": "Webhook, only the url, secret, labels and ops are used",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created webhook with its secret",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support webhooks"
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/{id}": {
+            "get": {
+                "description": "Returns the webhook, the secret is not returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Returns a webhook.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support webhooks"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete the webhook and its delivery history, pending deliveries are not sent.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete a webhook.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deleted webhook",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support webhooks"
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Returns the deliveries of the webhook ordered by ID with the number of attempts and the response code and error of the last attempt.\nPending deliveries are retried with an exponential backoff at next_attempt_at.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Returns the deliveries of a webhook.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "delivered",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "only return deliveries with the status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1000,
+                        "description": "limit the number of deliveries returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "last delivery ID, used for pagination",
+                        "name": "lastID",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of deliveries",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Delivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "404": {
+                        "description": "Not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Store does not support webhooks"
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Returns returns the health status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Returns returns the health status.",
+                "responses": {
+                    "200": {
+                        "description": "Current health status",
+                        "schema": {
+                            "$ref": "#/definitions/models.Health"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/static": {
+            "get": {
+                "description": "Static serves up static files",
+                "summary": "Static serves up static files",
+                "responses": {}
+            }
+        }
+    },
+    "definitions": {
+        "api.DELETEEdgesReq": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "soft": {
+                    "description": "Soft moves the edges to the trash instead of deleting them.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.DELETENodesReq": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "policy": {
+                    "$ref": "#/definitions/store.DeletePolicy"
+                },
+                "soft": {
+                    "description": "Soft moves the nodes to the trash instead of deleting them.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.ImportEvent": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error is the reason a line was skipped or the import failed.",
+                    "type": "string"
+                },
+                "line": {
+                    "description": "Line is the line number of a skipped line.",
+                    "type": "integer"
+                },
+                "progress": {
+                    "description": "Progress is the progress so far.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.ImportProgress"
+                        }
+                    ]
+                },
+                "type": {
+                    "description": "Type is ` + "`" + `error` + "`" + ` for a skipped line, ` + "`" + `progress` + "`" + ` after every chunk, ` + "`" + `done` + "`" + ` once finished or ` + "`" + `failed` + "`" + ` if the import stopped.",
+                    "type": "string"
+                }
+            }
+        },
+        "api.POSTQueryReq": {
+            "type": "object",
+            "properties": {
+                "format": {
+                    "description": "Format is the response format, either ` + "`" + `table` + "`" + ` (default) or ` + "`" + `graph` + "`" + `.",
+                    "type": "string"
+                },
+                "params": {
+                    "description": "Params are the values bound to the ` + "`" + `$name` + "`" + ` parameters.",
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "query": {
+                    "description": "Query is the query, eg: ` + "`" + `MATCH (a:person)-[r:knows*1..3]-\u003e(b) WHERE a.name = $name RETURN a, r, b LIMIT 10` + "`" + `.",
+                    "type": "string"
+                }
+            }
+        },
+        "api.PUTEdgesReq": {
+            "type": "object",
+            "properties": {
+                "edges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Edge"
+                    }
+                }
+            }
+        },
+        "api.PUTNodesReq": {
+            "type": "object",
+            "properties": {
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Node"
+                    }
+                }
+            }
+        },
+        "api.ReindexResp": {
+            "type": "object",
+            "properties": {
+                "indexed": {
+                    "type": "integer"
+                }
+            }
+        },
+        "importer.Problem": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "item": {
+                    "description": "item the problem was found in, eg: ` + "`" + `node n1` + "`" + ` or ` + "`" + `edges.csv line 3` + "`" + `",
+                    "type": "string"
+                }
+            }
+        },
+        "importer.Report": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "edges": {
+                    "type": "integer"
+                },
+                "nodes": {
+                    "type": "integer"
+                },
+                "problems": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/importer.Problem"
+                    }
+                },
+                "refs": {
+                    "description": "source node ID to EdgeDB node ID",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                }
+            }
+        },
+        "models.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "description": "Key is the secret key generated by the server, only a hash of it is stored so it is only returned when the key is\ncreated.",
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "description": "LastUsedAt is when the key last authenticated a request to within a minute, it is nil if the key was never used.",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name describes who or what uses the key.",
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "Prefix is the start of the key which helps finding the key without revealing it.",
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/models.Role"
+                }
+            }
+        },
+        "models.Change": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "description": "After is the node or edge after the change.",
+                    "type": "object"
+                },
+                "before": {
+                    "description": "Before is the node or edge before the change.",
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "description": "Kind is either node or edge.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SchemaKind"
+                        }
+                    ]
+                },
+                "label": {
+                    "description": "Label is the label of the item after the change, or before the change for deletes.",
+                    "type": "string"
+                },
+                "op": {
+                    "$ref": "#/definitions/models.ChangeOp"
+                },
+                "seq": {
+                    "description": "Seq is the sequence number of the change, it is increasing and is used to resume the change feed.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ChangeOp": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "ChangeCreate",
+                "ChangeUpdate",
+                "ChangeDelete"
+            ]
+        },
+        "models.Delivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error is the reason the last attempt failed.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "next_attempt_at": {
+                    "description": "NextAttemptAt is when a pending delivery is sent next.",
+                    "type": "string"
+                },
+                "response_code": {
+                    "description": "ResponseCode is the HTTP status code returned by the receiver on the last attempt.",
+                    "type": "integer"
+                },
+                "seq": {
+                    "description": "Seq is the sequence number of the delivered change.",
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.DeliveryStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "delivered",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "DeliveryPending",
+                "DeliveryDelivered",
+                "DeliveryFailed"
+            ]
+        },
+        "models.DiffOp": {
+            "type": "string",
+            "enum": [
+                "added",
+                "removed",
+                "changed"
+            ],
+            "x-enum-varnames": [
+                "DiffAdded",
+                "DiffRemoved",
+                "DiffChanged"
+            ]
+        },
+        "models.Edge": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "from_id": {
+                    "type": "integer"
+                },
+                "from_ref": {
+                    "description": "reference of a node in the same graph upsert, used instead of From",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "properties": {
+                    "$ref": "#/definitions/models.Properties"
+                },
+                "snippet": {
+                    "description": "this is a special field show a small snippet of the match terms",
+                    "type": "string"
+                },
+                "to_id": {
+                    "type": "integer"
+                },
+                "to_ref": {
+                    "description": "reference of a node in the same graph upsert, used instead of To",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "incremented by every write, a write with a version fails if it is not current",
+                    "type": "integer"
+                },
+                "weight": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Graph": {
+            "type": "object",
+            "properties": {
+                "edges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Edge"
+                    }
+                },
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Node"
+                    }
+                }
+            }
+        },
+        "models.Health": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Hop": {
+            "type": "object",
+            "properties": {
+                "edge_id": {
+                    "type": "integer"
+                },
+                "from_id": {
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "to_id": {
+                    "type": "integer"
+                },
+                "weight": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.IndexStatus": {
+            "type": "string",
+            "enum": [
+                "building",
+                "ready",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "IndexBuilding",
+                "IndexReady",
+                "IndexFailed"
+            ]
+        },
+        "models.NamedGraph": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.NaturalKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "property": {
+                    "description": "Property is the dotted path of the key property, eg: ` + "`" + `email` + "`" + ` or ` + "`" + `contact.email` + "`" + `.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Node": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "properties": {
+                    "$ref": "#/definitions/models.Properties"
+                },
+                "ref": {
+                    "description": "temporary reference used by edges in the same graph upsert",
+                    "type": "string"
+                },
+                "snippet": {
+                    "description": "this is a special field show a small snippet of the match terms",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "incremented by every write, a write with a version fails if it is not current",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Path": {
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "description": "Cost is the number of hops, or the sum of the edge weights for weighted paths.",
+                    "type": "integer"
+                },
+                "graph": {
+                    "description": "Graph contains all the nodes and edges along the path.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Graph"
+                        }
+                    ]
+                },
+                "hops": {
+                    "description": "Hops are the ordered steps from the starting node to the ending node.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Hop"
+                    }
+                }
+            }
+        },
+        "models.Properties": {
+            "type": "object",
+            "additionalProperties": {}
+        },
+        "models.PropertyDiff": {
+            "type": "object",
+            "properties": {
+                "after": {},
+                "before": {},
+                "op": {
+                    "$ref": "#/definitions/models.DiffOp"
+                },
+                "path": {
+                    "description": "Path is the dotted path of the field, eg: ` + "`" + `label` + "`" + `, ` + "`" + `weight` + "`" + ` or ` + "`" + `properties.meta.hair` + "`" + `.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.PropertyIndex": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error is the reason the index could not be built.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "label": {
+                    "description": "Label limits the index to the items with the label, every item is indexed if empty.",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "Path is the dotted path of the indexed property, eg: ` + "`" + `name` + "`" + ` or ` + "`" + `meta.hair` + "`" + `.",
+                    "type": "string"
+                },
+                "size": {
+                    "description": "Size is the size of the index in bytes.",
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.IndexStatus"
+                }
+            }
+        },
+        "models.PropertySchema": {
+            "type": "object",
+            "properties": {
+                "additionalProperties": {
+                    "type": "boolean"
+                },
+                "enum": {
+                    "description": "Enum lists the allowed values.",
+                    "type": "array",
+                    "items": {}
+                },
+                "items": {
+                    "description": "Items is the schema of every array item.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.PropertySchema"
+                        }
+                    ]
+                },
+                "maxLength": {
+                    "type": "integer"
+                },
+                "maximum": {
+                    "type": "number"
+                },
+                "minLength": {
+                    "description": "MinLength and MaxLength are the bounds of the number of characters in strings.",
+                    "type": "integer"
+                },
+                "minimum": {
+                    "description": "Minimum and Maximum are the inclusive bounds of numbers.",
+                    "type": "number"
+                },
+                "pattern": {
+                    "description": "Pattern is a regular expression strings must match, it is not anchored.",
+                    "type": "string"
+                },
+                "properties": {
+                    "description": "Properties, Required and AdditionalProperties describe objects.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.PropertySchema"
+                    }
+                },
+                "required": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "description": "Type is one of string, number, integer, boolean, object, array or null, any type is allowed if empty.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Revision": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "description": "At is when the revision was made.",
+                    "type": "string"
+                },
+                "diff": {
+                    "description": "Diff are the changes from the previous revision ordered by path.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PropertyDiff"
+                    }
+                },
+                "edge": {
+                    "description": "Edge is the edge after the change, it is nil if the edge was deleted or the revision is for a node.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Edge"
+                        }
+                    ]
+                },
+                "node": {
+                    "description": "Node is the node after the change, it is nil if the node was deleted or the revision is for an edge.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Node"
+                        }
+                    ]
+                },
+                "op": {
+                    "$ref": "#/definitions/models.ChangeOp"
+                },
+                "seq": {
+                    "description": "Seq is the sequence number of the change which created the revision.",
+                    "type": "integer"
+                },
+                "version": {
+                    "description": "Version is the version of the item, starting at 1 for the oldest revision in the history.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Role": {
+            "type": "string",
+            "enum": [
+                "reader",
+                "writer",
+                "admin"
+            ],
+            "x-enum-varnames": [
+                "RoleReader",
+                "RoleWriter",
+                "RoleAdmin"
+            ]
+        },
+        "models.Schema": {
+            "type": "object",
+            "properties": {
+                "additionalProperties": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "from_labels": {
+                    "description": "FromLabels and ToLabels are the allowed labels of the nodes an edge links, any label is allowed if empty.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "kind": {
+                    "$ref": "#/definitions/models.SchemaKind"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "mode": {
+                    "description": "Mode is how violations are handled, defaults to SchemaStrict.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SchemaMode"
                         }
-                    },
-                    "500": {
-                        "description": "Internal server error"
+                    ]
+                },
+                "properties": {
+                    "description": "Properties, Required and AdditionalProperties describe the item properties the same way as an object PropertySchema.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.PropertySchema"
+                    }
+                },
+                "required": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "to_labels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "/static": {
-            "get": {
-                "description": "Static serves up static files",
-                "summary": "Static serves up static files",
-                "responses": {}
+        "models.SchemaKind": {
+            "type": "string",
+            "enum": [
+                "node",
+                "edge"
+            ],
+            "x-enum-varnames": [
+                "SchemaNode",
+                "SchemaEdge"
+            ]
+        },
+        "models.SchemaMode": {
+            "type": "string",
+            "enum": [
+                "strict",
+                "warn"
+            ],
+            "x-enum-varnames": [
+                "SchemaStrict",
+                "SchemaWarn"
+            ]
+        },
+        "models.Table": {
+            "type": "object",
+            "properties": {
+                "columns": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {}
+                    }
+                }
             }
-        }
-    },
-    "definitions": {
-        "api.PUTEdgesReq": {
+        },
+        "models.Webhook": {
             "type": "object",
             "properties": {
-                "edges": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "labels": {
+                    "description": "Labels limits the deliveries to the changes to items with any of the labels, every change is delivered if empty.",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Edge"
+                        "type": "string"
+                    }
+                },
+                "ops": {
+                    "description": "Ops limits the deliveries to the changes with any of the operations, every change is delivered if empty.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ChangeOp"
                     }
+                },
+                "secret": {
+                    "description": "Secret is the key used to sign the deliveries, it is only returned when the webhook is created.",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "URL is the http or https URL the changes are posted to.",
+                    "type": "string"
                 }
             }
         },
-        "api.PUTNodesReq": {
+        "store.DeletePolicy": {
+            "type": "string",
+            "enum": [
+                "restrict",
+                "cascade",
+                "detach"
+            ],
+            "x-enum-varnames": [
+                "DeleteRestrict",
+                "DeleteCascade",
+                "DeleteDetach"
+            ]
+        },
+        "store.DeleteResult": {
             "type": "object",
             "properties": {
+                "detached": {
+                    "description": "Detached are the edges which were removed because they were attached to a deleted node (DeleteDetach only).",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Edge"
+                    }
+                },
+                "edges": {
+                    "description": "Edges are the deleted edges.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Edge"
+                    }
+                },
                 "nodes": {
+                    "description": "Nodes are the deleted nodes.",
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/models.Node"
@@ -441,15 +3709,44 @@ const docTemplate = `{
                 }
             }
         },
-        "models.Edge": {
+        "store.ImportProgress": {
+            "type": "object",
+            "properties": {
+                "edges": {
+                    "description": "Edges is the number of edges imported.",
+                    "type": "integer"
+                },
+                "errors": {
+                    "description": "Errors is the number of lines which were skipped because of errors.",
+                    "type": "integer"
+                },
+                "line": {
+                    "description": "Line is the last line committed.",
+                    "type": "integer"
+                },
+                "nodes": {
+                    "description": "Nodes is the number of nodes imported.",
+                    "type": "integer"
+                }
+            }
+        },
+        "store.MergedEdge": {
             "type": "object",
             "properties": {
+                "created": {
+                    "description": "Created is true if the edge was inserted, false if it matched an existing edge.",
+                    "type": "boolean"
+                },
                 "created_at": {
                     "type": "string"
                 },
                 "from_id": {
                     "type": "integer"
                 },
+                "from_ref": {
+                    "description": "reference of a node in the same graph upsert, used instead of From",
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -466,24 +3763,83 @@ const docTemplate = `{
                 "to_id": {
                     "type": "integer"
                 },
+                "to_ref": {
+                    "description": "reference of a node in the same graph upsert, used instead of To",
+                    "type": "string"
+                },
                 "updated_at": {
                     "type": "string"
                 },
+                "version": {
+                    "description": "incremented by every write, a write with a version fails if it is not current",
+                    "type": "integer"
+                },
                 "weight": {
                     "type": "integer"
                 }
             }
         },
-        "models.Graph": {
+        "store.MergedNode": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "description": "Created is true if the node was inserted, false if it matched an existing node.",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "properties": {
+                    "$ref": "#/definitions/models.Properties"
+                },
+                "ref": {
+                    "description": "temporary reference used by edges in the same graph upsert",
+                    "type": "string"
+                },
+                "snippet": {
+                    "description": "this is a special field show a small snippet of the match terms",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "incremented by every write, a write with a version fails if it is not current",
+                    "type": "integer"
+                }
+            }
+        },
+        "store.PurgeResult": {
+            "type": "object",
+            "properties": {
+                "edges": {
+                    "description": "Edges is the number of purged edges.",
+                    "type": "integer"
+                },
+                "nodes": {
+                    "description": "Nodes is the number of purged nodes.",
+                    "type": "integer"
+                }
+            }
+        },
+        "store.RestoreResult": {
             "type": "object",
             "properties": {
                 "edges": {
+                    "description": "Edges are the restored edges.",
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/models.Edge"
                     }
                 },
                 "nodes": {
+                    "description": "Nodes are the restored nodes.",
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/models.Node"
@@ -491,26 +3847,125 @@ const docTemplate = `{
                 }
             }
         },
-        "models.Health": {
+        "store.RestrictError": {
             "type": "object",
             "properties": {
-                "checks": {
-                    "type": "object",
-                    "additionalProperties": {
+                "edges": {
+                    "description": "Edges are the edges blocking the delete.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Edge"
+                    }
+                }
+            }
+        },
+        "store.SchemaError": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "ID is the ID of the item, it is 0 for new items.",
+                    "type": "integer"
+                },
+                "kind": {
+                    "description": "Kind and Label identify the schema.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SchemaKind"
+                        }
+                    ]
+                },
+                "label": {
+                    "type": "string"
+                },
+                "violations": {
+                    "description": "Violations describe every way the item does not match the schema.",
+                    "type": "array",
+                    "items": {
                         "type": "string"
                     }
+                }
+            }
+        },
+        "store.Trash": {
+            "type": "object",
+            "properties": {
+                "edges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.TrashedEdge"
+                    }
                 },
-                "status": {
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.TrashedNode"
+                    }
+                }
+            }
+        },
+        "store.TrashedEdge": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "DeletedAt is when the edge was moved to the trash.",
+                    "type": "string"
+                },
+                "deleted_with": {
+                    "description": "DeletedWith is the node the edge was trashed with, the edge is restored with the node.\nIt is 0 if the edge was trashed on its own.",
+                    "type": "integer"
+                },
+                "from_id": {
+                    "type": "integer"
+                },
+                "from_ref": {
+                    "description": "reference of a node in the same graph upsert, used instead of From",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "properties": {
+                    "$ref": "#/definitions/models.Properties"
+                },
+                "snippet": {
+                    "description": "this is a special field show a small snippet of the match terms",
+                    "type": "string"
+                },
+                "to_id": {
+                    "type": "integer"
+                },
+                "to_ref": {
+                    "description": "reference of a node in the same graph upsert, used instead of To",
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
+                },
+                "version": {
+                    "description": "incremented by every write, a write with a version fails if it is not current",
+                    "type": "integer"
+                },
+                "weight": {
+                    "type": "integer"
                 }
             }
         },
-        "models.Node": {
+        "store.TrashedNode": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
+                "deleted_at": {
+                    "description": "DeletedAt is when the node was moved to the trash.",
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -520,18 +3975,78 @@ const docTemplate = `{
                 "properties": {
                     "$ref": "#/definitions/models.Properties"
                 },
+                "ref": {
+                    "description": "temporary reference used by edges in the same graph upsert",
+                    "type": "string"
+                },
                 "snippet": {
                     "description": "this is a special field show a small snippet of the match terms",
                     "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
+                },
+                "version": {
+                    "description": "incremented by every write, a write with a version fails if it is not current",
+                    "type": "integer"
                 }
             }
         },
-        "models.Properties": {
+        "store.UpsertGraphResult": {
             "type": "object",
-            "additionalProperties": {}
+            "properties": {
+                "edges": {
+                    "description": "Edges are the upserted edges in the order given with the references resolved.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Edge"
+                    }
+                },
+                "nodes": {
+                    "description": "Nodes are the upserted nodes in the order given.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Node"
+                    }
+                },
+                "refs": {
+                    "description": "Refs maps every node reference to the ID of the node.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                }
+            }
+        },
+        "store.VersionError": {
+            "type": "object",
+            "properties": {
+                "edge": {
+                    "description": "Edge is the current edge, it is nil if the item is a node or does not exist.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Edge"
+                        }
+                    ]
+                },
+                "expected": {
+                    "description": "Expected is the version the write expected.",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "ID is the ID of the node or edge.",
+                    "type": "integer"
+                },
+                "node": {
+                    "description": "Node is the current node, it is nil if the item is an edge or does not exist.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Node"
+                        }
+                    ]
+                }
+            }
         }
     }
 }`
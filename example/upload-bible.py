@@ -12,29 +12,20 @@ GRAPH_API_URL = "http://localhost:8080/api/v1/graph"
 # Define the URL for downloading the Bible text
 BIBLE_API_URL = "https://bible-api.com/data/kjv"
 
-_id = 1
-
-# nextID returns the next ID.
-def nextID() -> int:
-    global _id
-    i = _id
-    _id += 1
-    return i
-
-
 # A dictionary to store the graph structure
 graph = {
     "nodes": [],
     "edges": []
 }
 
-# Helper function to add a node to the graph
-def add_node(label: str, properties: dict = {}) -> dict:
+# Helper function to add a node to the graph, the ref is a temporary key edges use to link to the node.
+# The server assigns the real IDs and returns them in the `refs` of the response.
+def add_node(ref: str, label: str, properties: dict = {}) -> dict:
     if properties is None:
         properties = {}
 
     node = {
-        "id": nextID(),
+        "ref": ref,
         "label": label,
         "properties": properties
     }
@@ -42,16 +33,15 @@ def add_node(label: str, properties: dict = {}) -> dict:
     graph['nodes'].append(node)
     return node
 
-# Helper function to add an edge to the graph
-def add_edge(from_id: int, label: str, to_id: int, weight: int = 1, properties: dict = {}) -> dict:
+# Helper function to add an edge to the graph linking two node refs
+def add_edge(from_ref: str, label: str, to_ref: str, weight: int = 1, properties: dict = {}) -> dict:
     if properties is None:
         properties = {}
 
-    edge ={
-        "id": nextID(),
-        "from_id": from_id,
+    edge = {
+        "from_ref": from_ref,
         "label": label,
-        "to_id": to_id,
+        "to_ref": to_ref,
         "weight": weight,
         "properties": properties
     }
@@ -61,7 +51,7 @@ def add_edge(from_id: int, label: str, to_id: int, weight: int = 1, properties:
 
 
 # add in the root node
-root_node = add_node("bible", {})
+root_node = add_node("bible", "bible", {})
 
 
 # Add this helper function for retry logic
@@ -106,14 +96,15 @@ def fetch(url: str):
         chapter_url = book.get("url", "")
 
         book_node = add_node(
+            ref=f"book:{book.get('name', '')}",
             label="book",
             properties={"url": chapter_url, "name": book.get("name", "")},
         )
 
         add_edge(
-            from_id=root_node["id"],
+            from_ref=root_node["ref"],
             label="HAS_BOOK",
-            to_id=book_node["id"],
+            to_ref=book_node["ref"],
             weight=1,
             properties={"url": url},
         )
@@ -125,14 +116,15 @@ def fetch(url: str):
             verse_url = c.get("url", "")
 
             chap_node = add_node(
+                ref=f"{book_node['ref']}:{c.get('chapter', 0)}",
                 label="chapter",
                 properties={"url": verse_url, "chapter": int(c.get("chapter", 0))},
             )
 
             add_edge(
-                from_id=book_node["id"],
+                from_ref=book_node["ref"],
                 label="HAS_CHAPTER",
-                to_id=chap_node["id"],
+                to_ref=chap_node["ref"],
                 weight=1,
                 properties={"url": chapter_url},
             )
@@ -145,14 +137,15 @@ def fetch(url: str):
                 text = v.get("text", "")
 
                 verse_node = add_node(
+                    ref=f"{chap_node['ref']}:{verse_num}",
                     label="verse",
                     properties={"verse": verse_num, "content": text},
                 )
 
                 add_edge(
-                    from_id=chap_node["id"],
+                    from_ref=chap_node["ref"],
                     label="HAS_VERSE",
-                    to_id=verse_node["id"],
+                    to_ref=verse_node["ref"],
                     weight=1,
                     properties={"url": chapter_url},
                 )
@@ -163,7 +156,8 @@ def upload_graph(url: str):
     headers = {"Content-Type": "application/json"}
     response = requests.put(url, json=graph, headers=headers)
     if response.status_code == 200:
-        print("Graph uploaded successfully.")
+        refs = response.json().get("refs", {})
+        print(f"Graph uploaded successfully, {len(refs)} nodes created.")
     else:
         print(f"Failed to upload graph: {response.status_code}, {response.text}")
 
@@ -0,0 +1,284 @@
+// Package auth authenticates the requests with API keys or JWT bearer tokens and authorizes them by role.
+//
+// Credentials are read from the `Authorization: Bearer <credential>` header, the `X-API-Key` header or, for the UI,
+// the Cookie set by the UI login. Credentials with the three dot separated parts of a JWT are verified as tokens, the
+// others are looked up as API keys. Each route requires a role, see RequiredRole.
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// Cookie is the cookie holding the credential of the UI.
+const Cookie = "edgedb_auth"
+
+// ErrUnauthenticated is returned when a request has no valid credential.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Identity is who made a request.
+type Identity struct {
+	// Subject is the name of the API key or the subject of the token.
+	Subject string `json:"subject"`
+
+	Role models.Role `json:"role"`
+}
+
+// Claims are the claims of the JWT bearer tokens, the role claim is required.
+type Claims struct {
+	Role models.Role `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// Config configures the credentials accepted by an Authenticator.
+type Config struct {
+	// Keys looks up the API keys, stored API keys are not accepted if it is nil.
+	Keys store.APIKeyStore
+
+	// AdminKey is an API key with the admin role which is not stored, eg: to create the first API keys.
+	AdminKey string
+
+	// JWTSecret is the HMAC secret verifying HS256, HS384 and HS512 tokens.
+	JWTSecret []byte
+
+	// JWTPublicKey is the RSA, ECDSA or Ed25519 public key verifying RS*, PS*, ES* or EdDSA tokens.
+	JWTPublicKey crypto.PublicKey
+
+	// Issuer and Audience are checked against the `iss` and `aud` claims of the tokens if set.
+	Issuer   string
+	Audience string
+}
+
+// Authenticator authenticates and authorizes the requests.
+type Authenticator struct {
+	config Config
+	parser *jwt.Parser
+}
+
+// New returns an authenticator accepting the credentials of the config.
+func New(config Config) *Authenticator {
+	methods := []string{}
+
+	if len(config.JWTSecret) > 0 {
+		methods = append(methods, "HS256", "HS384", "HS512")
+	}
+
+	switch config.JWTPublicKey.(type) {
+	case *rsa.PublicKey:
+		methods = append(methods, "RS256", "RS384", "RS512", "PS256", "PS384", "PS512")
+	case *ecdsa.PublicKey:
+		methods = append(methods, "ES256", "ES384", "ES512")
+	case ed25519.PublicKey:
+		methods = append(methods, "EdDSA")
+	}
+
+	options := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired(), jwt.WithLeeway(30 * time.Second)}
+
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+
+	return &Authenticator{config: config, parser: jwt.NewParser(options...)}
+}
+
+// ParsePublicKey returns the RSA, ECDSA or Ed25519 public key in the PEM encoded key.
+func ParsePublicKey(pem []byte) (crypto.PublicKey, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(pem); err == nil {
+		return key, nil
+	}
+
+	if key, err := jwt.ParseECPublicKeyFromPEM(pem); err == nil {
+		return key, nil
+	}
+
+	key, err := jwt.ParseEdPublicKeyFromPEM(pem)
+	if err != nil {
+		return nil, fmt.Errorf("not a PEM encoded RSA, ECDSA or Ed25519 public key")
+	}
+
+	return key, nil
+}
+
+// credential returns the credential of the request, an empty string is returned if there is none.
+func credential(r *http.Request) string {
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(bearer)
+	}
+
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+
+	// the cookie of the UI login is only used by the UI so that other sites can not use it to call the API.
+	if cookie, err := r.Cookie(Cookie); err == nil && strings.HasPrefix(r.URL.Path, "/ui/") {
+		return cookie.Value
+	}
+
+	return ""
+}
+
+// Authenticate returns the identity of the credential in the request, returning ErrUnauthenticated if there is no
+// credential or it is not valid.
+func (a *Authenticator) Authenticate(r *http.Request) (Identity, error) {
+	return a.AuthenticateCredential(r.Context(), credential(r))
+}
+
+// AuthenticateCredential returns the identity of the API key or JWT, returning ErrUnauthenticated if it is not valid.
+func (a *Authenticator) AuthenticateCredential(ctx context.Context, credential string) (Identity, error) {
+	if credential == "" {
+		return Identity{}, fmt.Errorf("%w: an API key or bearer token is required", ErrUnauthenticated)
+	}
+
+	if strings.Count(credential, ".") == 2 {
+		return a.token(credential)
+	}
+
+	if a.config.AdminKey != "" && subtle.ConstantTimeCompare([]byte(credential), []byte(a.config.AdminKey)) == 1 {
+		return Identity{Subject: "admin key", Role: models.RoleAdmin}, nil
+	}
+
+	if a.config.Keys == nil {
+		return Identity{}, fmt.Errorf("%w: invalid API key", ErrUnauthenticated)
+	}
+
+	key, err := a.config.Keys.AuthenticateAPIKey(ctx, credential)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return Identity{}, fmt.Errorf("%w: invalid API key", ErrUnauthenticated)
+		}
+		return Identity{}, err
+	}
+
+	return Identity{Subject: key.Name, Role: key.Role}, nil
+}
+
+// token returns the identity of the JWT.
+func (a *Authenticator) token(raw string) (Identity, error) {
+	claims := Claims{}
+
+	_, err := a.parser.ParseWithClaims(raw, &claims, func(t *jwt.Token) (any, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); ok {
+			return a.config.JWTSecret, nil
+		}
+		return a.config.JWTPublicKey, nil
+	})
+
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: invalid bearer token: %s", ErrUnauthenticated, err)
+	}
+
+	if !claims.Role.Valid() {
+		return Identity{}, fmt.Errorf("%w: invalid bearer token: unknown role %q", ErrUnauthenticated, claims.Role)
+	}
+
+	return Identity{Subject: claims.Subject, Role: claims.Role}, nil
+}
+
+// adminPaths are the API paths which need the admin role for every method.
+var adminPaths = []string{"/api/v1/auth", "/api/v1/admin", "/api/v1/webhooks"}
+
+// configPaths are the API paths which can be read by readers but need the admin role to be changed.
+var configPaths = []string{"/api/v1/schema", "/api/v1/keys", "/api/v1/indexes", "/api/v1/graphs"}
+
+// under returns true if the path is one of the paths or below it.
+func under(path string, paths []string) bool {
+	for _, p := range paths {
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RequiredRole returns the role needed by the request, false is returned for the public routes which need no role.
+// Reads need the reader role, writes need the writer role and changing the configuration of a graph, the named graphs,
+// the webhooks or the API keys needs the admin role. The routes of the named graphs need the same roles as the routes
+// of the default graph.
+func RequiredRole(r *http.Request) (models.Role, bool) {
+	path := r.URL.Path
+
+	switch {
+	case path == "/", path == "/healthz", path == "/ui/v1/login", path == "/ui/v1/logout":
+		return "", false
+	case strings.HasPrefix(path, "/static/"), strings.HasPrefix(path, "/swagger/"):
+		return "", false
+	}
+
+	if rest, ok := strings.CutPrefix(path, "/api/v1/graphs/"); ok {
+		if _, sub, ok := strings.Cut(rest, "/"); ok {
+			path = "/api/v1/" + sub
+		}
+	}
+
+	read := r.Method == http.MethodGet || r.Method == http.MethodHead
+
+	switch {
+	case under(path, adminPaths):
+		return models.RoleAdmin, true
+	case under(path, configPaths) && !read:
+		return models.RoleAdmin, true
+	case read, path == "/api/v1/query":
+		return models.RoleReader, true
+	}
+
+	return models.RoleWriter, true
+}
+
+// Middleware returns a handler serving the requests with the role needed by the route. Unauthenticated API requests
+// are answered with a 401 and UI requests are redirected to the login page, requests without the role are forbidden.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		role, ok := RequiredRole(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		identity, err := a.Authenticate(r)
+
+		switch {
+		case errors.Is(err, ErrUnauthenticated) && strings.HasPrefix(r.URL.Path, "/ui/"):
+			http.Redirect(w, r, "/ui/v1/login", http.StatusSeeOther)
+			return
+		case errors.Is(err, ErrUnauthenticated):
+			w.Header().Set("WWW-Authenticate", `Bearer realm="edgedb"`)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if !identity.Role.Allows(role) {
+			slog.Debug(
+				"request forbidden",
+				slog.String("subject", identity.Subject),
+				slog.String("role", string(identity.Role)),
+				slog.String("required", string(role)),
+			)
+			http.Error(w, fmt.Sprintf("forbidden: the %s role is required", role), http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
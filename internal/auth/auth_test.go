@@ -0,0 +1,261 @@
+package auth_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jenmud/edgedb/internal/auth"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/models"
+)
+
+const (
+	adminKey = "edb_admin_0123456789abcdefghijklmnopqrstuvwxyz"
+	secret   = "0123456789abcdefghijklmnopqrstuvwxyz"
+)
+
+// sign returns the token of the claims signed with the method and key.
+func sign(t *testing.T, method jwt.SigningMethod, key any, claims auth.Claims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("SignedString() failed: %v", err)
+	}
+
+	return token
+}
+
+// claims returns the claims of a token with the role expiring in an hour.
+func claims(role models.Role) auth.Claims {
+	return auth.Claims{
+		Role: role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "jenny",
+			Issuer:    "https://issuer.example.com",
+			Audience:  jwt.ClaimStrings{"edgedb"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+}
+
+func TestRequiredRole(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		role   models.Role
+		ok     bool
+	}{
+		{method: http.MethodGet, path: "/healthz"},
+		{method: http.MethodGet, path: "/"},
+		{method: http.MethodGet, path: "/static/js/graph.js"},
+		{method: http.MethodGet, path: "/swagger/index.html"},
+		{method: http.MethodPost, path: "/ui/v1/login"},
+		{method: http.MethodGet, path: "/ui/v1/graph/filter/table", role: models.RoleReader, ok: true},
+		{method: http.MethodGet, path: "/api/v1/nodes", role: models.RoleReader, ok: true},
+		{method: http.MethodPost, path: "/api/v1/query", role: models.RoleReader, ok: true},
+		{method: http.MethodPut, path: "/api/v1/graph", role: models.RoleWriter, ok: true},
+		{method: http.MethodPatch, path: "/api/v1/nodes/1", role: models.RoleWriter, ok: true},
+		{method: http.MethodDelete, path: "/api/v1/nodes", role: models.RoleWriter, ok: true},
+		{method: http.MethodGet, path: "/api/v1/schema", role: models.RoleReader, ok: true},
+		{method: http.MethodPut, path: "/api/v1/schema/person", role: models.RoleAdmin, ok: true},
+		{method: http.MethodGet, path: "/api/v1/webhooks", role: models.RoleAdmin, ok: true},
+		{method: http.MethodGet, path: "/api/v1/auth/keys", role: models.RoleAdmin, ok: true},
+		{method: http.MethodGet, path: "/api/v1/graphs", role: models.RoleReader, ok: true},
+		{method: http.MethodPost, path: "/api/v1/graphs", role: models.RoleAdmin, ok: true},
+		{method: http.MethodDelete, path: "/api/v1/graphs/movies", role: models.RoleAdmin, ok: true},
+		{method: http.MethodPut, path: "/api/v1/graphs/movies/nodes", role: models.RoleWriter, ok: true},
+		{method: http.MethodPost, path: "/api/v1/graphs/movies/query", role: models.RoleReader, ok: true},
+		{method: http.MethodPost, path: "/api/v1/graphs/movies/webhooks", role: models.RoleAdmin, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			role, ok := auth.RequiredRole(httptest.NewRequest(tt.method, tt.path, nil))
+			if role != tt.role || ok != tt.ok {
+				t.Errorf("RequiredRole() = %q, %v, want %q, %v", role, ok, tt.role, tt.ok)
+			}
+		})
+	}
+}
+
+func TestAuthenticator_AuthenticateCredential(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := sqlite.New(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	defer s.Close()
+
+	key, err := s.CreateAPIKey(ctx, models.APIKey{Name: "importer", Role: models.RoleWriter})
+	if err != nil {
+		t.Fatalf("CreateAPIKey() failed: %v", err)
+	}
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() failed: %v", err)
+	}
+
+	a := auth.New(auth.Config{
+		Keys:         s,
+		AdminKey:     adminKey,
+		JWTSecret:    []byte(secret),
+		JWTPublicKey: public,
+		Issuer:       "https://issuer.example.com",
+		Audience:     "edgedb",
+	})
+
+	expired := claims(models.RoleReader)
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+
+	unexpiring := claims(models.RoleReader)
+	unexpiring.ExpiresAt = nil
+
+	issuer := claims(models.RoleReader)
+	issuer.Issuer = "https://other.example.com"
+
+	tests := []struct {
+		name       string
+		credential string
+		want       auth.Identity
+		err        error
+	}{
+		{name: "no credential", err: auth.ErrUnauthenticated},
+		{name: "admin key", credential: adminKey, want: auth.Identity{Subject: "admin key", Role: models.RoleAdmin}},
+		{name: "api key", credential: key.Key, want: auth.Identity{Subject: "importer", Role: models.RoleWriter}},
+		{name: "unknown api key", credential: "edb_unknown", err: auth.ErrUnauthenticated},
+		{
+			name:       "hmac token",
+			credential: sign(t, jwt.SigningMethodHS256, []byte(secret), claims(models.RoleWriter)),
+			want:       auth.Identity{Subject: "jenny", Role: models.RoleWriter},
+		},
+		{
+			name:       "ed25519 token",
+			credential: sign(t, jwt.SigningMethodEdDSA, private, claims(models.RoleAdmin)),
+			want:       auth.Identity{Subject: "jenny", Role: models.RoleAdmin},
+		},
+		{
+			name:       "wrong secret",
+			credential: sign(t, jwt.SigningMethodHS256, []byte("wrong"+secret), claims(models.RoleWriter)),
+			err:        auth.ErrUnauthenticated,
+		},
+		{
+			name:       "unsigned token",
+			credential: sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, claims(models.RoleAdmin)),
+			err:        auth.ErrUnauthenticated,
+		},
+		{
+			name:       "expired token",
+			credential: sign(t, jwt.SigningMethodHS256, []byte(secret), expired),
+			err:        auth.ErrUnauthenticated,
+		},
+		{
+			name:       "token without expiry",
+			credential: sign(t, jwt.SigningMethodHS256, []byte(secret), unexpiring),
+			err:        auth.ErrUnauthenticated,
+		},
+		{
+			name:       "other issuer",
+			credential: sign(t, jwt.SigningMethodHS256, []byte(secret), issuer),
+			err:        auth.ErrUnauthenticated,
+		},
+		{
+			name:       "unknown role",
+			credential: sign(t, jwt.SigningMethodHS256, []byte(secret), claims("root")),
+			err:        auth.ErrUnauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := a.AuthenticateCredential(ctx, tt.credential)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("AuthenticateCredential() error = %v, want %v", err, tt.err)
+			}
+
+			if got != tt.want {
+				t.Errorf("AuthenticateCredential() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticator_Middleware(t *testing.T) {
+	a := auth.New(auth.Config{AdminKey: adminKey, JWTSecret: []byte(secret)})
+
+	handler := a.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	reader := sign(t, jwt.SigningMethodHS256, []byte(secret), claims(models.RoleReader))
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		header   string
+		value    string
+		cookie   string
+		status   int
+		location string
+	}{
+		{name: "public", method: http.MethodGet, path: "/healthz", status: http.StatusOK},
+		{name: "unauthenticated api", method: http.MethodGet, path: "/api/v1/nodes", status: http.StatusUnauthorized},
+		{
+			name: "unauthenticated ui", method: http.MethodGet, path: "/ui/v1/graph/filter/table",
+			status: http.StatusSeeOther, location: "/ui/v1/login",
+		},
+		{
+			name: "bearer token", method: http.MethodGet, path: "/api/v1/nodes",
+			header: "Authorization", value: "Bearer " + reader, status: http.StatusOK,
+		},
+		{
+			name: "forbidden", method: http.MethodPut, path: "/api/v1/graph",
+			header: "Authorization", value: "Bearer " + reader, status: http.StatusForbidden,
+		},
+		{
+			name: "api key header", method: http.MethodPut, path: "/api/v1/graph",
+			header: "X-API-Key", value: adminKey, status: http.StatusOK,
+		},
+		{name: "ui cookie", method: http.MethodGet, path: "/ui/v1/graph/filter/table", cookie: reader, status: http.StatusOK},
+		{name: "api ignores the cookie", method: http.MethodGet, path: "/api/v1/nodes", cookie: reader, status: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: auth.Cookie, Value: tt.cookie})
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+
+			if location := w.Header().Get("Location"); location != tt.location {
+				t.Errorf("Location = %q, want %q", location, tt.location)
+			}
+
+			if tt.status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("WWW-Authenticate header is not set")
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// NodeColumns are the fixed columns written before the property columns of a nodes CSV.
+var NodeColumns = []string{"id", "label", "created_at", "updated_at"}
+
+// EdgeColumns are the fixed columns written before the property columns of an edges CSV.
+var EdgeColumns = []string{"id", "from_id", "label", "to_id", "weight", "created_at", "updated_at"}
+
+// PropertyPrefix prefixes property columns which would otherwise clash with the fixed columns, eg: `properties.label`.
+const PropertyPrefix = "properties."
+
+// propertyColumns returns the column names for the attributes.
+func propertyColumns(fixed []string, attrs *attributes) []string {
+	cols := make([]string, len(attrs.list))
+	for i, attr := range attrs.list {
+		cols[i] = attr.key
+		if slices.Contains(fixed, attr.key) {
+			cols[i] = PropertyPrefix + attr.key
+		}
+	}
+	return cols
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// writeNodesCSV writes a row per node with a column per flattened property key.
+func writeNodesCSV(ctx context.Context, w io.Writer, src Source) error {
+	attrs, err := scanNodes(ctx, src)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append(slices.Clone(NodeColumns), propertyColumns(NodeColumns, attrs)...)); err != nil {
+		return err
+	}
+
+	for n, err := range src.Nodes(ctx) {
+		if err != nil {
+			return err
+		}
+
+		row := make([]string, len(NodeColumns)+len(attrs.list))
+		row[0] = strconv.FormatUint(n.ID, 10)
+		row[1] = n.Label
+		row[2] = formatTime(n.CreatedAt)
+		row[3] = formatTime(n.UpdatedAt)
+
+		_, flat := sortedValues(n.Properties)
+		for k, v := range flat {
+			i, err := attrs.lookup(k)
+			if err != nil {
+				return err
+			}
+			row[len(NodeColumns)+i] = formatValue(v)
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeEdgesCSV writes a row per edge with a column per flattened property key.
+func writeEdgesCSV(ctx context.Context, w io.Writer, src Source) error {
+	attrs, err := scanEdges(ctx, src)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append(slices.Clone(EdgeColumns), propertyColumns(EdgeColumns, attrs)...)); err != nil {
+		return err
+	}
+
+	for e, err := range src.Edges(ctx) {
+		if err != nil {
+			return err
+		}
+
+		row := make([]string, len(EdgeColumns)+len(attrs.list))
+		row[0] = strconv.FormatUint(e.ID, 10)
+		row[1] = strconv.FormatUint(e.From, 10)
+		row[2] = e.Label
+		row[3] = strconv.FormatUint(e.To, 10)
+		row[4] = strconv.Itoa(e.Weight)
+		row[5] = formatTime(e.CreatedAt)
+		row[6] = formatTime(e.UpdatedAt)
+
+		_, flat := sortedValues(e.Properties)
+		for k, v := range flat {
+			i, err := attrs.lookup(k)
+			if err != nil {
+				return err
+			}
+			row[len(EdgeColumns)+i] = formatValue(v)
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
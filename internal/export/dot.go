@@ -0,0 +1,57 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jenmud/edgedb/models"
+)
+
+// quote returns s as a DOT quoted string.
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// dotAttrs returns the DOT attribute list for the label and properties.
+func dotAttrs(label string, extra []string, props models.Properties) string {
+	attrs := append([]string{"label=" + quote(label)}, extra...)
+
+	keys, flat := sortedValues(props)
+	for _, k := range keys {
+		attrs = append(attrs, quote(k)+"="+quote(formatValue(flat[k])))
+	}
+
+	return "[" + strings.Join(attrs, ", ") + "]"
+}
+
+// writeDOT writes the source as a Graphviz digraph in a single pass.
+func writeDOT(ctx context.Context, w io.Writer, src Source) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "digraph edgedb {")
+
+	for n, err := range src.Nodes(ctx) {
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(bw, "  %d %s;\n", n.ID, dotAttrs(n.Label, nil, n.Properties))
+	}
+
+	for e, err := range src.Edges(ctx) {
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(bw, "  %d -> %d %s;\n", e.From, e.To, dotAttrs(e.Label, []string{"weight=" + strconv.Itoa(e.Weight)}, e.Properties))
+	}
+
+	fmt.Fprintln(bw, "}")
+
+	return bw.Flush()
+}
@@ -0,0 +1,413 @@
+// Package export writes graphs in formats other tools understand, GraphML (yEd), GEXF (Gephi), DOT (Graphviz) and CSV.
+//
+// Writers stream the nodes and edges from a Source. Formats which declare their attributes up front (GraphML, GEXF and CSV)
+// read the source twice, once to work out the attributes and their types and again to write the items,
+// so only the attributes, and the written node IDs, are held in memory. A property key added or a property changing
+// type between the passes, or an edge to a node which was not written, fails the export with ErrChanged.
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+	"github.com/jenmud/edgedb/pkg/common"
+)
+
+// Format is an export format.
+type Format string
+
+const (
+	// FormatGraphML is GraphML used by yEd, Cytoscape and most graph tools.
+	FormatGraphML Format = "graphml"
+
+	// FormatGEXF is GEXF used by Gephi.
+	FormatGEXF Format = "gexf"
+
+	// FormatDOT is DOT used by Graphviz.
+	FormatDOT Format = "dot"
+
+	// FormatCSV is CSV with a row per node or edge and a column per property.
+	FormatCSV Format = "csv"
+)
+
+// Table is the table written by CSV exports.
+type Table string
+
+const (
+	// TableNodes writes the nodes.
+	TableNodes Table = "nodes"
+
+	// TableEdges writes the edges.
+	TableEdges Table = "edges"
+)
+
+// ContentType returns the content type for the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatGraphML, FormatGEXF:
+		return "application/xml; charset=UTF-8"
+	case FormatDOT:
+		return "text/vnd.graphviz; charset=UTF-8"
+	case FormatCSV:
+		return "text/csv; charset=UTF-8"
+	}
+	return "application/octet-stream"
+}
+
+// ErrChanged is returned when the source is written to between the passes over it in a way the export can not hold,
+// the export is incomplete and has to be retried.
+var ErrChanged = errors.New("source changed during the export")
+
+// Source provides the nodes and edges being exported, both can be iterated more than once.
+type Source interface {
+	Nodes(context.Context) iter.Seq2[models.Node, error]
+	Edges(context.Context) iter.Seq2[models.Edge, error]
+}
+
+// pageSize is the number of items read from the store at a time.
+const pageSize = 1000
+
+// storeSource pages through all the nodes and edges in a store.
+type storeSource struct {
+	store store.Store
+}
+
+// StoreSource returns a source for everything in the store.
+func StoreSource(s store.Store) Source {
+	return storeSource{store: s}
+}
+
+func (s storeSource) Nodes(ctx context.Context) iter.Seq2[models.Node, error] {
+	return func(yield func(models.Node, error) bool) {
+		args := store.NodesArgs{Limit: pageSize}
+
+		for {
+			nodes, err := s.store.Nodes(ctx, args)
+			if err != nil {
+				yield(models.Node{}, err)
+				return
+			}
+
+			for _, n := range nodes {
+				if !yield(n, nil) {
+					return
+				}
+			}
+
+			if len(nodes) < pageSize {
+				return
+			}
+			args.LastID = nodes[len(nodes)-1].ID
+		}
+	}
+}
+
+func (s storeSource) Edges(ctx context.Context) iter.Seq2[models.Edge, error] {
+	return func(yield func(models.Edge, error) bool) {
+		args := store.EdgesArgs{Limit: pageSize}
+
+		for {
+			edges, err := s.store.Edges(ctx, args)
+			if err != nil {
+				yield(models.Edge{}, err)
+				return
+			}
+
+			for _, e := range edges {
+				if !yield(e, nil) {
+					return
+				}
+			}
+
+			if len(edges) < pageSize {
+				return
+			}
+			args.LastID = edges[len(edges)-1].ID
+		}
+	}
+}
+
+// graphSource is an in-memory graph such as the result of a term search or sub-graph.
+type graphSource struct {
+	graph models.Graph
+}
+
+// GraphSource returns a source for the graph.
+func GraphSource(g models.Graph) Source {
+	return graphSource{graph: g}
+}
+
+func (s graphSource) Nodes(ctx context.Context) iter.Seq2[models.Node, error] {
+	return func(yield func(models.Node, error) bool) {
+		for _, n := range s.graph.Nodes {
+			if !yield(n, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (s graphSource) Edges(ctx context.Context) iter.Seq2[models.Edge, error] {
+	return func(yield func(models.Edge, error) bool) {
+		for _, e := range s.graph.Edges {
+			if !yield(e, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Options are the export options.
+type Options struct {
+	// Table is the table written by CSV exports, defaults to TableNodes.
+	Table Table
+}
+
+// Write streams the source to w in the format.
+func Write(ctx context.Context, w io.Writer, format Format, src Source, opts Options) error {
+	switch format {
+	case FormatGraphML:
+		return writeGraphML(ctx, w, src)
+	case FormatGEXF:
+		return writeGEXF(ctx, w, src)
+	case FormatDOT:
+		return writeDOT(ctx, w, src)
+	case FormatCSV:
+		switch opts.Table {
+		case TableNodes, "":
+			return writeNodesCSV(ctx, w, src)
+		case TableEdges:
+			return writeEdgesCSV(ctx, w, src)
+		}
+		return fmt.Errorf("unsupported csv table: %s", opts.Table)
+	}
+
+	return fmt.Errorf("unsupported export format: %s", format)
+}
+
+// kind is the type of an attribute.
+type kind int
+
+const (
+	kindBool kind = iota
+	kindLong
+	kindDouble
+	kindString
+)
+
+func (k kind) String() string {
+	switch k {
+	case kindBool:
+		return "bool"
+	case kindLong:
+		return "long"
+	case kindDouble:
+		return "double"
+	}
+	return "string"
+}
+
+// kindOf returns the kind of a flattened property value.
+func kindOf(v any) kind {
+	switch v := v.(type) {
+	case bool:
+		return kindBool
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return kindLong
+	case float32:
+		return kindOf(float64(v))
+	case float64:
+		// JSON numbers are decoded as float64, whole numbers are written as longs.
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return kindLong
+		}
+		return kindDouble
+	default:
+		return kindString
+	}
+}
+
+// merge returns the kind which can hold values of both kinds.
+func merge(a, b kind) kind {
+	switch {
+	case a == b:
+		return a
+	case (a == kindLong && b == kindDouble) || (a == kindDouble && b == kindLong):
+		return kindDouble
+	}
+	return kindString
+}
+
+// attribute is a flattened property key and the kind of its values.
+type attribute struct {
+	key  string
+	kind kind
+}
+
+// attributes collects the flattened property keys and kinds of a set of items.
+type attributes struct {
+	list  []attribute
+	index map[string]int
+}
+
+func newAttributes() *attributes {
+	return &attributes{index: map[string]int{}}
+}
+
+// add records the flattened properties.
+func (a *attributes) add(props models.Properties) {
+	for key, v := range common.Flatten(props) {
+		k := kindOf(v)
+
+		if i, ok := a.index[key]; ok {
+			a.list[i].kind = merge(a.list[i].kind, k)
+			continue
+		}
+
+		a.index[key] = len(a.list)
+		a.list = append(a.list, attribute{key: key, kind: k})
+	}
+}
+
+// lookup returns the index of the flattened property key, returning ErrChanged if the key was not seen by the first
+// pass over the source.
+func (a *attributes) lookup(key string) (int, error) {
+	i, ok := a.index[key]
+	if !ok {
+		return 0, fmt.Errorf("%w: property %q was added after the attributes were written", ErrChanged, key)
+	}
+	return i, nil
+}
+
+// typed returns the index of the flattened property key, returning ErrChanged if the key was not seen by the first
+// pass over the source or the value can not be held by the declared kind.
+func (a *attributes) typed(key string, v any) (int, error) {
+	i, err := a.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+
+	attr := a.list[i]
+	if k := kindOf(v); merge(attr.kind, k) != attr.kind {
+		return 0, fmt.Errorf("%w: property %q changed from %s to %s after the attributes were written", ErrChanged, key, attr.kind, k)
+	}
+
+	return i, nil
+}
+
+// sorted returns the attributes ordered by key, the index is updated to match.
+func (a *attributes) sorted() []attribute {
+	slices.SortFunc(a.list, func(x, y attribute) int {
+		return strings.Compare(x.key, y.key)
+	})
+
+	for i, attr := range a.list {
+		a.index[attr.key] = i
+	}
+
+	return a.list
+}
+
+// scanNodes is the first pass over the source nodes collecting their attributes.
+func scanNodes(ctx context.Context, src Source) (*attributes, error) {
+	attrs := newAttributes()
+
+	for n, err := range src.Nodes(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		attrs.add(n.Properties)
+	}
+
+	attrs.sorted()
+	return attrs, nil
+}
+
+// scanEdges is the first pass over the source edges collecting their attributes.
+func scanEdges(ctx context.Context, src Source) (*attributes, error) {
+	attrs := newAttributes()
+
+	for e, err := range src.Edges(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		attrs.add(e.Properties)
+	}
+
+	attrs.sorted()
+	return attrs, nil
+}
+
+// scan is the first pass over the source collecting the node and edge attributes.
+func scan(ctx context.Context, src Source) (*attributes, *attributes, error) {
+	nodes, err := scanNodes(ctx, src)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	edges, err := scanEdges(ctx, src)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nodes, edges, nil
+}
+
+// nodeSet holds the IDs of the written nodes, so edges to nodes deleted or added after the nodes were written are found.
+type nodeSet map[uint64]struct{}
+
+// check returns ErrChanged if either end of the edge was not written.
+func (s nodeSet) check(e models.Edge) error {
+	for _, id := range []uint64{e.From, e.To} {
+		if _, ok := s[id]; !ok {
+			return fmt.Errorf("%w: edge %d references node %d which was not written", ErrChanged, e.ID, id)
+		}
+	}
+	return nil
+}
+
+// formatValue formats a flattened property value, lists are written as JSON.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		if kindOf(v) == kindLong {
+			return strconv.FormatInt(int64(v), 10)
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case []any:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// sortedValues returns the flattened property values ordered by key.
+func sortedValues(props models.Properties) ([]string, map[string]any) {
+	flat := common.Flatten(props)
+
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	return keys, flat
+}
@@ -0,0 +1,247 @@
+package export_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"io"
+	"iter"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenmud/edgedb/internal/export"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/models"
+)
+
+var graph = models.Graph{
+	Nodes: []models.Node{
+		{ID: 1, Label: "person", Properties: models.Properties{"name": "foo", "age": float64(21), "meta": map[string]any{"hair": "brown"}}},
+		{ID: 2, Label: "person", Properties: models.Properties{"name": "bar & co", "age": 4.5, "label": "clash"}},
+	},
+	Edges: []models.Edge{
+		{ID: 3, From: 1, Label: "knows", To: 2, Weight: 2, Properties: models.Properties{"since": float64(2020), "close": true}},
+	},
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name     string // description of this test case
+		format   export.Format
+		opts     export.Options
+		contains []string
+		xml      bool
+	}{
+		{
+			name:   "graphml",
+			format: export.FormatGraphML,
+			xml:    true,
+			contains: []string{
+				`<key id="n0" for="node" attr.name="age" attr.type="double"/>`,
+				`<key id="n2" for="node" attr.name="meta.hair" attr.type="string"/>`,
+				`<key id="e0" for="edge" attr.name="close" attr.type="boolean"/>`,
+				`<key id="e1" for="edge" attr.name="since" attr.type="long"/>`,
+				`<data key="n3">bar &amp; co</data>`,
+				`<edge id="e3" source="n1" target="n2">`,
+				`<data key="e1">2020</data>`,
+			},
+		},
+		{
+			name:   "gexf",
+			format: export.FormatGEXF,
+			xml:    true,
+			contains: []string{
+				`<attribute id="0" title="age" type="double"/>`,
+				`<attribute id="1" title="since" type="long"/>`,
+				`<node id="2" label="person">`,
+				`<attvalue for="2" value="brown"/>`,
+				`<edge id="3" source="1" target="2" label="knows" weight="2">`,
+			},
+		},
+		{
+			name:   "dot",
+			format: export.FormatDOT,
+			contains: []string{
+				`digraph edgedb {`,
+				`1 [label="person", "age"="21", "meta.hair"="brown", "name"="foo"];`,
+				`1 -> 2 [label="knows", weight=2, "close"="true", "since"="2020"];`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			if err := export.Write(t.Context(), &buf, tt.format, export.GraphSource(graph), tt.opts); err != nil {
+				t.Fatalf("Write() failed: %v", err)
+			}
+
+			got := buf.String()
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("Write() expected to contain %q, got:\n%s", want, got)
+				}
+			}
+
+			if tt.xml {
+				dec := xml.NewDecoder(&buf)
+				for {
+					_, err := dec.Token()
+					if err != nil {
+						if !errors.Is(err, io.EOF) {
+							t.Errorf("Write() wrote invalid XML: %v", err)
+						}
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWrite_CSV(t *testing.T) {
+	tests := []struct {
+		name  string // description of this test case
+		table export.Table
+		want  [][]string
+	}{
+		{
+			name:  "nodes",
+			table: export.TableNodes,
+			want: [][]string{
+				{"id", "label", "created_at", "updated_at", "age", "properties.label", "meta.hair", "name"},
+				{"1", "person", "", "", "21", "", "brown", "foo"},
+				{"2", "person", "", "", "4.5", "clash", "", "bar & co"},
+			},
+		},
+		{
+			name:  "edges",
+			table: export.TableEdges,
+			want: [][]string{
+				{"id", "from_id", "label", "to_id", "weight", "created_at", "updated_at", "close", "since"},
+				{"3", "1", "knows", "2", "2", "", "", "true", "2020"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			if err := export.Write(t.Context(), &buf, export.FormatCSV, export.GraphSource(graph), export.Options{Table: tt.table}); err != nil {
+				t.Fatalf("Write() failed: %v", err)
+			}
+
+			got, err := csv.NewReader(&buf).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Write() = mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStoreSource(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := sqlite.New(ctx, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.UpsertGraph(ctx, graph); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := export.Write(ctx, &buf, export.FormatDOT, export.StoreSource(s), export.Options{}); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	if got := strings.Count(buf.String(), "->"); got != 1 {
+		t.Errorf("Write() expected 1 edge, got %d:\n%s", got, buf.String())
+	}
+
+	if got := strings.Count(buf.String(), "[label=\"person\""); got != 2 {
+		t.Errorf("Write() expected 2 nodes, got %d:\n%s", got, buf.String())
+	}
+}
+
+// changingSource is a source which is changed after its first pass, like a store written to during an export.
+type changingSource struct {
+	change       string // what changes after the first pass, either `key`, `kind` or `endpoint`
+	nodes, edges int
+}
+
+func (s *changingSource) Nodes(ctx context.Context) iter.Seq2[models.Node, error] {
+	s.nodes++
+	props := models.Properties{"name": "foo", "age": float64(21)}
+	if s.nodes > 1 {
+		switch s.change {
+		case "key":
+			props["added"] = "bar"
+		case "kind":
+			props["age"] = "old"
+		}
+	}
+
+	return func(yield func(models.Node, error) bool) {
+		yield(models.Node{ID: 1, Label: "person", Properties: props}, nil)
+	}
+}
+
+func (s *changingSource) Edges(ctx context.Context) iter.Seq2[models.Edge, error] {
+	s.edges++
+	props := models.Properties{"since": float64(2020)}
+	to := uint64(1)
+	if s.edges > 1 {
+		switch s.change {
+		case "key":
+			props["added"] = "bar"
+		case "kind":
+			props["since"] = "2020"
+		case "endpoint":
+			to = 3
+		}
+	}
+
+	return func(yield func(models.Edge, error) bool) {
+		yield(models.Edge{ID: 2, From: 1, Label: "knows", To: to, Properties: props}, nil)
+	}
+}
+
+func TestWrite_SourceChanged(t *testing.T) {
+	tests := []struct {
+		name   string // description of this test case
+		format export.Format
+		opts   export.Options
+		change string
+	}{
+		{name: "graphml", format: export.FormatGraphML, change: "key"},
+		{name: "graphml kind", format: export.FormatGraphML, change: "kind"},
+		{name: "graphml endpoint", format: export.FormatGraphML, change: "endpoint"},
+		{name: "gexf", format: export.FormatGEXF, change: "key"},
+		{name: "gexf kind", format: export.FormatGEXF, change: "kind"},
+		{name: "gexf endpoint", format: export.FormatGEXF, change: "endpoint"},
+		{name: "csv nodes", format: export.FormatCSV, opts: export.Options{Table: export.TableNodes}, change: "key"},
+		{name: "csv edges", format: export.FormatCSV, opts: export.Options{Table: export.TableEdges}, change: "key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			err := export.Write(t.Context(), &buf, tt.format, &changingSource{change: tt.change}, tt.opts)
+			if !errors.Is(err, export.ErrChanged) {
+				t.Errorf("Write() error = %v, want export.ErrChanged", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/jenmud/edgedb/models"
+)
+
+// gexfTypes are the GEXF attribute types for each kind.
+var gexfTypes = map[kind]string{
+	kindBool:   "boolean",
+	kindLong:   "long",
+	kindDouble: "double",
+	kindString: "string",
+}
+
+// writeGEXF writes the source as GEXF 1.3.
+func writeGEXF(ctx context.Context, w io.Writer, src Source) error {
+	nodeAttrs, edgeAttrs, err := scan(ctx, src)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintln(bw, `<gexf xmlns="http://gexf.net/1.3" version="1.3">`)
+	fmt.Fprintln(bw, `  <graph defaultedgetype="directed" mode="static">`)
+
+	declare := func(class string, attrs *attributes) {
+		fmt.Fprintf(bw, "    <attributes class=\"%s\">\n", class)
+		for i, attr := range attrs.list {
+			fmt.Fprintf(bw, "      <attribute id=\"%d\" title=\"%s\" type=\"%s\"/>\n", i, escape(attr.key), gexfTypes[attr.kind])
+		}
+		fmt.Fprintln(bw, "    </attributes>")
+	}
+
+	declare("node", nodeAttrs)
+	declare("edge", edgeAttrs)
+
+	values := func(attrs *attributes, props models.Properties) error {
+		keys, flat := sortedValues(props)
+		if len(keys) == 0 {
+			return nil
+		}
+
+		fmt.Fprintln(bw, "        <attvalues>")
+		for _, k := range keys {
+			i, err := attrs.typed(k, flat[k])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(bw, "          <attvalue for=\"%d\" value=\"%s\"/>\n", i, escape(formatValue(flat[k])))
+		}
+		fmt.Fprintln(bw, "        </attvalues>")
+		return nil
+	}
+
+	written := nodeSet{}
+
+	fmt.Fprintln(bw, "    <nodes>")
+	for n, err := range src.Nodes(ctx) {
+		if err != nil {
+			return err
+		}
+
+		written[n.ID] = struct{}{}
+
+		fmt.Fprintf(bw, "      <node id=\"%d\" label=\"%s\">\n", n.ID, escape(n.Label))
+		if err := values(nodeAttrs, n.Properties); err != nil {
+			return err
+		}
+		fmt.Fprintln(bw, "      </node>")
+	}
+	fmt.Fprintln(bw, "    </nodes>")
+
+	fmt.Fprintln(bw, "    <edges>")
+	for e, err := range src.Edges(ctx) {
+		if err != nil {
+			return err
+		}
+
+		if err := written.check(e); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(bw, "      <edge id=\"%d\" source=\"%d\" target=\"%d\" label=\"%s\" weight=\"%d\">\n", e.ID, e.From, e.To, escape(e.Label), e.Weight)
+		if err := values(edgeAttrs, e.Properties); err != nil {
+			return err
+		}
+		fmt.Fprintln(bw, "      </edge>")
+	}
+	fmt.Fprintln(bw, "    </edges>")
+
+	fmt.Fprintln(bw, "  </graph>")
+	fmt.Fprintln(bw, "</gexf>")
+
+	return bw.Flush()
+}
@@ -0,0 +1,104 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jenmud/edgedb/models"
+)
+
+// graphMLTypes are the GraphML attr.type for each kind.
+var graphMLTypes = map[kind]string{
+	kindBool:   "boolean",
+	kindLong:   "long",
+	kindDouble: "double",
+	kindString: "string",
+}
+
+// escape returns the text escaped for XML text and attribute values.
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// writeGraphML writes the source as GraphML, node IDs are `n<id>` and edge IDs are `e<id>`.
+func writeGraphML(ctx context.Context, w io.Writer, src Source) error {
+	nodeAttrs, edgeAttrs, err := scan(ctx, src)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintln(bw, `<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://graphml.graphdrawing.org/xmlns http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd">`)
+	fmt.Fprintln(bw, `  <key id="label" for="all" attr.name="label" attr.type="string"/>`)
+	fmt.Fprintln(bw, `  <key id="weight" for="edge" attr.name="weight" attr.type="int"/>`)
+
+	for i, attr := range nodeAttrs.list {
+		fmt.Fprintf(bw, "  <key id=\"n%d\" for=\"node\" attr.name=\"%s\" attr.type=\"%s\"/>\n", i, escape(attr.key), graphMLTypes[attr.kind])
+	}
+
+	for i, attr := range edgeAttrs.list {
+		fmt.Fprintf(bw, "  <key id=\"e%d\" for=\"edge\" attr.name=\"%s\" attr.type=\"%s\"/>\n", i, escape(attr.key), graphMLTypes[attr.kind])
+	}
+
+	fmt.Fprintln(bw, `  <graph id="edgedb" edgedefault="directed">`)
+
+	data := func(prefix string, attrs *attributes, props models.Properties) error {
+		keys, flat := sortedValues(props)
+		for _, k := range keys {
+			i, err := attrs.typed(k, flat[k])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(bw, "      <data key=\"%s%d\">%s</data>\n", prefix, i, escape(formatValue(flat[k])))
+		}
+		return nil
+	}
+
+	written := nodeSet{}
+
+	for n, err := range src.Nodes(ctx) {
+		if err != nil {
+			return err
+		}
+
+		written[n.ID] = struct{}{}
+
+		fmt.Fprintf(bw, "    <node id=\"n%d\">\n", n.ID)
+		fmt.Fprintf(bw, "      <data key=\"label\">%s</data>\n", escape(n.Label))
+		if err := data("n", nodeAttrs, n.Properties); err != nil {
+			return err
+		}
+		fmt.Fprintln(bw, "    </node>")
+	}
+
+	for e, err := range src.Edges(ctx) {
+		if err != nil {
+			return err
+		}
+
+		if err := written.check(e); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(bw, "    <edge id=\"e%d\" source=\"n%d\" target=\"n%d\">\n", e.ID, e.From, e.To)
+		fmt.Fprintf(bw, "      <data key=\"label\">%s</data>\n", escape(e.Label))
+		fmt.Fprintf(bw, "      <data key=\"weight\">%d</data>\n", e.Weight)
+		if err := data("e", edgeAttrs, e.Properties); err != nil {
+			return err
+		}
+		fmt.Fprintln(bw, "    </edge>")
+	}
+
+	fmt.Fprintln(bw, "  </graph>")
+	fmt.Fprintln(bw, "</graphml>")
+
+	return bw.Flush()
+}
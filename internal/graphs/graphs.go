@@ -0,0 +1,373 @@
+// Package graphs manages the named graphs served next to the default graph.
+//
+// Each named graph is a store of its own so that its IDs, search index, schemas and change feed are isolated from the
+// default graph and the other named graphs. A Driver opens the stores, eg: a sqlite database per graph in a directory.
+package graphs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/models"
+)
+
+// Default is the name of the default graph, it can not be used by a named graph.
+const Default = "default"
+
+// validName is a graph name, it is also used as a file name so it is limited to lower case letters, digits, `_` and `-`.
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9_\-]{0,62}$`)
+
+// Driver opens and removes the stores of the named graphs.
+type Driver interface {
+	// Names returns the names of the existing named graphs.
+	Names(context.Context) ([]string, error)
+
+	// Open opens the store of the named graph, creating it if it does not exist.
+	Open(context.Context, string) (store.Store, error)
+
+	// Remove removes the closed store of the named graph.
+	Remove(context.Context, string) error
+}
+
+// graph is an open named graph.
+type graph struct {
+	store  store.Store
+	cancel context.CancelFunc
+
+	// users tracks the acquired uses of the store so it is only closed once they are released.
+	users sync.WaitGroup
+}
+
+// Manager creates, opens and drops the named graphs.
+type Manager struct {
+	ctx    context.Context
+	driver Driver
+	onOpen func(context.Context, store.Store)
+
+	mu     sync.RWMutex
+	graphs map[string]*graph
+
+	// dropping are the graphs being dropped, they can not be created again until they are removed.
+	dropping map[string]bool
+}
+
+// New returns a manager opening the existing named graphs of the driver. onOpen, if not nil, is called with each opened
+// store and a context which is done when the graph is dropped or the manager is closed, eg: to run background jobs.
+func New(ctx context.Context, driver Driver, onOpen func(context.Context, store.Store)) (*Manager, error) {
+	m := &Manager{
+		ctx:      ctx,
+		driver:   driver,
+		onOpen:   onOpen,
+		graphs:   map[string]*graph{},
+		dropping: map[string]bool{},
+	}
+
+	names, err := driver.Names(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		if err := m.open(ctx, name); err != nil {
+			m.Close()
+			return nil, fmt.Errorf("opening graph %s: %w", name, err)
+		}
+	}
+
+	return m, nil
+}
+
+// open opens the store of the named graph, the caller must hold the lock or own the manager.
+func (m *Manager) open(ctx context.Context, name string) error {
+	s, err := m.driver.Open(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	graphCtx, cancel := context.WithCancel(m.ctx)
+	m.graphs[name] = &graph{store: s, cancel: cancel}
+
+	if m.onOpen != nil {
+		m.onOpen(graphCtx, s)
+	}
+
+	slog.Info("opened graph", slog.String("graph", name))
+	return nil
+}
+
+// Graphs returns the named graphs ordered by name.
+func (m *Manager) Graphs() []models.NamedGraph {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	graphs := make([]models.NamedGraph, 0, len(m.graphs))
+	for name := range m.graphs {
+		graphs = append(graphs, models.NamedGraph{Name: name})
+	}
+
+	slices.SortFunc(graphs, func(a, b models.NamedGraph) int { return strings.Compare(a.Name, b.Name) })
+	return graphs
+}
+
+// Store returns the store of the named graph, returning store.ErrNotFound if there is no such graph.
+// The store is closed if the graph is dropped, use Acquire to keep using it.
+func (m *Manager) Store(name string) (store.Store, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	g, ok := m.graphs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: graph %s", store.ErrNotFound, name)
+	}
+
+	return g.store, nil
+}
+
+// Acquire returns the store of the named graph and the func releasing it, returning store.ErrNotFound if there is no
+// such graph. Dropping or closing the graph waits for the store to be released before closing it.
+func (m *Manager) Acquire(name string) (store.Store, func(), error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	g, ok := m.graphs[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: graph %s", store.ErrNotFound, name)
+	}
+
+	g.users.Add(1)
+	return g.store, sync.OnceFunc(g.users.Done), nil
+}
+
+// Create creates the named graph, returning store.ErrInvalid if the name is not valid and store.ErrConflict if the
+// graph already exists.
+func (m *Manager) Create(ctx context.Context, name string) (models.NamedGraph, error) {
+	if name == Default || !validName.MatchString(name) {
+		return models.NamedGraph{}, fmt.Errorf(
+			"%w: graph name %q must be lower case letters, digits, _ or - and not %q", store.ErrInvalid, name, Default,
+		)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.graphs[name]; ok {
+		return models.NamedGraph{}, fmt.Errorf("%w: graph %s already exists", store.ErrConflict, name)
+	}
+
+	if m.dropping[name] {
+		return models.NamedGraph{}, fmt.Errorf("%w: graph %s is being dropped", store.ErrConflict, name)
+	}
+
+	if err := m.open(ctx, name); err != nil {
+		return models.NamedGraph{}, err
+	}
+
+	return models.NamedGraph{Name: name}, nil
+}
+
+// Drop closes and removes the named graph with all its nodes and edges, returning store.ErrNotFound if there is no such
+// graph. The graph can not be acquired once it is being dropped, the store is closed once the acquired uses are released.
+func (m *Manager) Drop(ctx context.Context, name string) (models.NamedGraph, error) {
+	m.mu.Lock()
+
+	g, ok := m.graphs[name]
+	if !ok {
+		m.mu.Unlock()
+		return models.NamedGraph{}, fmt.Errorf("%w: graph %s", store.ErrNotFound, name)
+	}
+
+	delete(m.graphs, name)
+	m.dropping[name] = true
+	m.mu.Unlock()
+
+	defer func() {
+		m.mu.Lock()
+		delete(m.dropping, name)
+		m.mu.Unlock()
+	}()
+
+	g.cancel()
+	g.users.Wait()
+
+	if err := g.store.Close(); err != nil {
+		return models.NamedGraph{}, err
+	}
+
+	if err := m.driver.Remove(ctx, name); err != nil {
+		return models.NamedGraph{}, err
+	}
+
+	slog.Info("dropped graph", slog.String("graph", name))
+	return models.NamedGraph{Name: name}, nil
+}
+
+// Close closes the stores of all the named graphs once their acquired uses are released, the graphs are not removed.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	errs := []error{}
+
+	for name, g := range m.graphs {
+		g.cancel()
+		g.users.Wait()
+		errs = append(errs, g.store.Close())
+		delete(m.graphs, name)
+	}
+
+	return errors.Join(errs...)
+}
+
+// dir is a Driver storing each named graph in a sqlite database in a directory.
+type dir struct {
+	path string
+}
+
+// Dir returns a Driver storing each named graph in the `<name>.sqlite` database in the directory, the directory is
+// created if it does not exist.
+func Dir(path string) Driver {
+	return dir{path: path}
+}
+
+// file returns the database file of the named graph.
+func (d dir) file(name string) string {
+	return filepath.Join(d.path, name+".sqlite")
+}
+
+// Names returns the names of the databases in the directory.
+func (d dir) Names(ctx context.Context) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(d.path, "*.sqlite"))
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+
+	for _, f := range files {
+		name := strings.TrimSuffix(filepath.Base(f), ".sqlite")
+		if validName.MatchString(name) && name != Default {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// Open opens the database of the named graph creating it if it does not exist.
+func (d dir) Open(ctx context.Context, name string) (store.Store, error) {
+	if err := os.MkdirAll(d.path, 0o755); err != nil {
+		return nil, err
+	}
+
+	return sqlite.New(ctx, fmt.Sprintf("file:%s?_fk=1", d.file(name)))
+}
+
+// Remove removes the database of the named graph.
+func (d dir) Remove(ctx context.Context, name string) error {
+	for _, f := range []string{d.file(name), d.file(name) + "-wal", d.file(name) + "-shm"} {
+		if err := os.Remove(f); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// inMemory is a Driver keeping the named graphs in memory.
+type inMemory struct {
+	open func(context.Context) (store.Store, error)
+}
+
+// InMemory returns a Driver keeping the named graphs in the stores returned by open, the graphs are lost when the
+// server stops.
+func InMemory(open func(context.Context) (store.Store, error)) Driver {
+	return inMemory{open: open}
+}
+
+// Names returns no names, the graphs in memory do not outlive the manager.
+func (d inMemory) Names(ctx context.Context) ([]string, error) {
+	return []string{}, nil
+}
+
+// Open returns a new store for the named graph.
+func (d inMemory) Open(ctx context.Context, name string) (store.Store, error) {
+	return d.open(ctx)
+}
+
+// Remove does nothing, the closed store is dropped with the graph.
+func (d inMemory) Remove(ctx context.Context, name string) error {
+	return nil
+}
+
+// cached is the handler built for the store of a named graph.
+type cached struct {
+	store   store.Store
+	handler http.Handler
+}
+
+// Handlers builds and caches the http.Handler serving each named graph.
+type Handlers struct {
+	manager *Manager
+	build   func(store.Store) http.Handler
+
+	mu       sync.Mutex
+	handlers map[string]cached
+}
+
+// NewHandlers returns the handlers of the named graphs, build returns the handler serving a store.
+func NewHandlers(m *Manager, build func(store.Store) http.Handler) *Handlers {
+	return &Handlers{
+		manager:  m,
+		build:    build,
+		handlers: map[string]cached{},
+	}
+}
+
+// Manager returns the manager of the named graphs.
+func (h *Handlers) Manager() *Manager {
+	return h.manager
+}
+
+// Handler returns the handler serving the named graph and the func releasing the graph once the request is served,
+// returning store.ErrNotFound if there is no such graph. The handler is rebuilt if the graph was dropped and created
+// again.
+func (h *Handlers) Handler(name string) (http.Handler, func(), error) {
+	s, release, err := h.manager.Acquire(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	c, ok := h.handlers[name]
+	if !ok || c.store != s {
+		c = cached{store: s, handler: h.build(s)}
+		h.handlers[name] = c
+	}
+
+	return c.handler, release, nil
+}
+
+// Drop drops the named graph like Manager.Drop, removing its cached handler. The handler is removed once the dropped
+// graph is released, so it is not cached again by the requests served before the drop.
+func (h *Handlers) Drop(ctx context.Context, name string) (models.NamedGraph, error) {
+	graph, err := h.manager.Drop(ctx, name)
+
+	h.mu.Lock()
+	delete(h.handlers, name)
+	h.mu.Unlock()
+
+	return graph, err
+}
@@ -0,0 +1,200 @@
+package graphs_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenmud/edgedb/internal/graphs"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/memory"
+	"github.com/jenmud/edgedb/models"
+)
+
+func TestManager(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+
+	opened := map[store.Store]context.Context{}
+
+	m, err := graphs.New(ctx, graphs.Dir(dir), func(ctx context.Context, s store.Store) { opened[s] = ctx })
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	for _, name := range []string{"", "default", "Movies", "../movies", "movies.db"} {
+		if _, err := m.Create(ctx, name); !errors.Is(err, store.ErrInvalid) {
+			t.Errorf("Create(%q) error = %v, want store.ErrInvalid", name, err)
+		}
+	}
+
+	for _, name := range []string{"movies", "people"} {
+		if _, err := m.Create(ctx, name); err != nil {
+			t.Fatalf("Create(%q) failed: %v", name, err)
+		}
+	}
+
+	if _, err := m.Create(ctx, "movies"); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("Create() of an existing graph error = %v, want store.ErrConflict", err)
+	}
+
+	if diff := cmp.Diff([]models.NamedGraph{{Name: "movies"}, {Name: "people"}}, m.Graphs()); diff != "" {
+		t.Errorf("Graphs() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	// each graph has its own IDs and search index.
+	for _, name := range []string{"movies", "people"} {
+		s, err := m.Store(name)
+		if err != nil {
+			t.Fatalf("Store(%q) failed: %v", name, err)
+		}
+
+		nodes, err := s.UpsertNodes(ctx, models.Node{Label: "thing", Properties: models.Properties{"name": name}})
+		if err != nil {
+			t.Fatalf("UpsertNodes() failed: %v", err)
+		}
+
+		if nodes[0].ID != 1 {
+			t.Errorf("UpsertNodes() in %s ID = %d, want 1", name, nodes[0].ID)
+		}
+	}
+
+	people, _ := m.Store("people")
+
+	matches, err := people.NodesTermSearch(ctx, store.TermSearchArgs{Term: "movies"})
+	if err != nil {
+		t.Fatalf("NodesTermSearch() failed: %v", err)
+	}
+
+	if len(matches) != 0 {
+		t.Errorf("NodesTermSearch() = %v, want no matches from the other graph", matches)
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	// the graphs in the directory are opened again.
+	m, err = graphs.New(ctx, graphs.Dir(dir), nil)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	defer m.Close()
+
+	movies, err := m.Store("movies")
+	if err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+
+	if n, err := movies.Node(ctx, 1); err != nil || n.Properties["name"] != "movies" {
+		t.Errorf("Node() = %+v, %v, want the node written before reopening", n, err)
+	}
+
+	if _, err := m.Drop(ctx, "movies"); err != nil {
+		t.Fatalf("Drop() failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "movies.sqlite")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Drop() left the database, stat error = %v", err)
+	}
+
+	if _, err := m.Store("movies"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Store() of a dropped graph error = %v, want store.ErrNotFound", err)
+	}
+
+	if _, err := m.Drop(ctx, "movies"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Drop() of a dropped graph error = %v, want store.ErrNotFound", err)
+	}
+
+	// the contexts given to onOpen are done once the graphs are closed.
+	for _, graphCtx := range opened {
+		if graphCtx.Err() == nil {
+			t.Errorf("onOpen context is not done after Close()")
+		}
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	ctx := t.Context()
+
+	m, err := graphs.New(ctx, graphs.InMemory(func(ctx context.Context) (store.Store, error) { return memory.New(), nil }), nil)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	defer m.Close()
+
+	built := 0
+	h := graphs.NewHandlers(m, func(s store.Store) http.Handler {
+		built++
+		return http.NotFoundHandler()
+	})
+
+	if _, _, err := h.Handler("movies"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Handler() of a missing graph error = %v, want store.ErrNotFound", err)
+	}
+
+	if _, err := m.Create(ctx, "movies"); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	for range 2 {
+		_, release, err := h.Handler("movies")
+		if err != nil {
+			t.Fatalf("Handler() failed: %v", err)
+		}
+
+		release()
+	}
+
+	// dropping and creating the graph again needs a new handler for the new store.
+	if _, err := h.Drop(ctx, "movies"); err != nil {
+		t.Fatalf("Drop() failed: %v", err)
+	}
+
+	if _, err := m.Create(ctx, "movies"); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	_, release, err := h.Handler("movies")
+	if err != nil {
+		t.Fatalf("Handler() failed: %v", err)
+	}
+
+	if built != 2 {
+		t.Errorf("handlers built = %d, want 2", built)
+	}
+
+	// the graph is only closed once the request being served releases it.
+	dropped := make(chan error)
+	go func() {
+		_, err := h.Drop(ctx, "movies")
+		dropped <- err
+	}()
+
+	select {
+	case err := <-dropped:
+		t.Fatalf("Drop() = %v, returned before the graph was released", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, _, err := h.Handler("movies"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Handler() of a graph being dropped error = %v, want store.ErrNotFound", err)
+	}
+
+	if _, err := m.Create(ctx, "movies"); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("Create() of a graph being dropped error = %v, want store.ErrConflict", err)
+	}
+
+	release()
+
+	if err := <-dropped; err != nil {
+		t.Fatalf("Drop() failed: %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jenmud/edgedb/models"
+)
+
+// csvPropertyPrefix is stripped from property columns, it is used by exports when a property clashes with a fixed column.
+const csvPropertyPrefix = "properties."
+
+// csvColumns maps the lower case column names to the fixed columns, all other columns are properties.
+var (
+	csvNodeColumns = map[string]string{
+		"id":         "id",
+		"label":      "label",
+		"created_at": "",
+		"updated_at": "",
+	}
+
+	csvEdgeColumns = map[string]string{
+		"id":         "",
+		"from_id":    "from",
+		"source":     "from",
+		"to_id":      "to",
+		"target":     "to",
+		"label":      "label",
+		"type":       "label",
+		"weight":     "weight",
+		"created_at": "",
+		"updated_at": "",
+	}
+)
+
+// csvRows reads the rows of a CSV file calling fn with the line number and the cells keyed by the fixed column,
+// or by the property name for property columns. Empty cells are skipped.
+func csvRows(res *Result, name string, r io.Reader, columns map[string]string, required []string, fn func(item string, fixed map[string]string, props map[string]any)) error {
+	cr := csv.NewReader(r)
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("%s is empty", name)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	fixed := make([]string, len(header))
+	props := make([]string, len(header))
+	found := map[string]struct{}{}
+
+	for i, col := range header {
+		col = strings.TrimSpace(col)
+
+		if f, ok := columns[strings.ToLower(col)]; ok {
+			fixed[i] = f
+			found[f] = struct{}{}
+			continue
+		}
+
+		props[i] = strings.TrimPrefix(col, csvPropertyPrefix)
+	}
+
+	for _, col := range required {
+		if _, ok := found[col]; !ok {
+			return fmt.Errorf("%s is missing the %s column", name, col)
+		}
+	}
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+
+		line, _ := cr.FieldPos(0)
+		item := fmt.Sprintf("%s line %d", name, line)
+
+		if err != nil {
+			res.problem(item, "expected %d columns, got %d", len(header), len(record))
+			continue
+		}
+
+		fixedValues := map[string]string{}
+		propValues := map[string]any{}
+
+		for i, cell := range record {
+			switch {
+			case cell == "":
+			case fixed[i] != "":
+				fixedValues[fixed[i]] = cell
+			case props[i] != "":
+				propValues[props[i]] = infer(cell)
+			}
+		}
+
+		fn(item, fixedValues, propValues)
+	}
+}
+
+// ParseCSV reads a nodes CSV file with an `id` column and optional `label` column and an optional edges CSV file
+// with `from_id` and `to_id` (or `source` and `target`) columns and optional `label` (or `type`) and `weight` columns.
+// All other columns are properties, cell values are converted to booleans and numbers where possible.
+// The column names match the CSV export, so exported files can be imported again.
+func ParseCSV(nodes, edges io.Reader) (Result, error) {
+	res := Result{Graph: models.Graph{Nodes: []models.Node{}, Edges: []models.Edge{}}}
+
+	err := csvRows(&res, "nodes.csv", nodes, csvNodeColumns, []string{"id"}, func(item string, fixed map[string]string, props map[string]any) {
+		if fixed["id"] == "" {
+			res.problem(item, "missing id")
+		}
+
+		res.Graph.Nodes = append(res.Graph.Nodes, models.Node{
+			Ref:        fixed["id"],
+			Label:      trim(fixed["label"], DefaultNodeLabel),
+			Properties: properties(props),
+		})
+	})
+
+	if err != nil {
+		return res, err
+	}
+
+	if edges != nil {
+		err := csvRows(&res, "edges.csv", edges, csvEdgeColumns, []string{"from", "to"}, func(item string, fixed map[string]string, props map[string]any) {
+			edge := models.Edge{
+				FromRef:    fixed["from"],
+				ToRef:      fixed["to"],
+				Label:      trim(fixed["label"], DefaultEdgeLabel),
+				Properties: properties(props),
+			}
+
+			if fixed["weight"] != "" {
+				w, err := weight(fixed["weight"])
+				if err != nil {
+					res.problem(item, "weight is not a number: %q", fixed["weight"])
+				}
+				edge.Weight = w
+			}
+
+			res.addEdge(item, edge)
+		})
+
+		if err != nil {
+			return res, err
+		}
+	}
+
+	res.validate()
+	return res, nil
+}
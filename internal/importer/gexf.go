@@ -0,0 +1,177 @@
+package importer
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jenmud/edgedb/models"
+)
+
+type gexfAttribute struct {
+	ID      string  `xml:"id,attr"`
+	Title   string  `xml:"title,attr"`
+	Type    string  `xml:"type,attr"`
+	Default *string `xml:"default"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type gexfNode struct {
+	ID     string      `xml:"id,attr"`
+	Label  string      `xml:"label,attr"`
+	Values []gexfValue `xml:"attvalues>attvalue"`
+}
+
+type gexfEdge struct {
+	ID     string      `xml:"id,attr"`
+	Source string      `xml:"source,attr"`
+	Target string      `xml:"target,attr"`
+	Label  string      `xml:"label,attr"`
+	Kind   string      `xml:"kind,attr"`
+	Weight string      `xml:"weight,attr"`
+	Values []gexfValue `xml:"attvalues>attvalue"`
+}
+
+// gexfAttrs are the declared attributes of a class by ID.
+type gexfAttrs map[string]gexfAttribute
+
+// values returns the converted attribute values by title, including attribute defaults.
+func (a gexfAttrs) values(res *Result, item string, attvalues []gexfValue) map[string]any {
+	values := map[string]any{}
+	seen := map[string]struct{}{}
+
+	for _, v := range attvalues {
+		seen[v.For] = struct{}{}
+
+		attr, ok := a[v.For]
+		if !ok {
+			res.problem(item, "undeclared attribute %q", v.For)
+			continue
+		}
+
+		value, err := convert(v.Value, attr.Type)
+		if err != nil {
+			res.problem(item, "%s is not a valid %s: %q", attr.Title, attr.Type, v.Value)
+			continue
+		}
+
+		values[attr.Title] = value
+	}
+
+	for id, attr := range a {
+		if _, ok := seen[id]; ok || attr.Default == nil {
+			continue
+		}
+
+		value, err := convert(*attr.Default, attr.Type)
+		if err != nil {
+			res.problem(item, "%s default is not a valid %s: %q", attr.Title, attr.Type, *attr.Default)
+			continue
+		}
+
+		values[attr.Title] = value
+	}
+
+	return values
+}
+
+// ParseGEXF reads a GEXF document, node and edge labels are used as the labels, falling back to the edge kind,
+// and attributes become properties. Dynamic attribute values and visualisation data are ignored.
+func ParseGEXF(r io.Reader) (Result, error) {
+	res := Result{Graph: models.Graph{Nodes: []models.Node{}, Edges: []models.Edge{}}}
+	attrs := map[string]gexfAttrs{"node": {}, "edge": {}}
+
+	dec := xml.NewDecoder(r)
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return res, fmt.Errorf("invalid gexf: %w", err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "attributes":
+			a := gexfAttributes{}
+			if err := dec.DecodeElement(&a, &start); err != nil {
+				return res, fmt.Errorf("invalid gexf attributes: %w", err)
+			}
+
+			class, ok := attrs[a.Class]
+			if !ok {
+				continue
+			}
+
+			for _, attr := range a.Attributes {
+				class[attr.ID] = attr
+			}
+
+		case "node":
+			n := gexfNode{}
+			if err := dec.DecodeElement(&n, &start); err != nil {
+				return res, fmt.Errorf("invalid gexf node: %w", err)
+			}
+
+			item := "node " + n.ID
+			if n.ID == "" {
+				item = fmt.Sprintf("node %d", len(res.Graph.Nodes)+1)
+				res.problem(item, "missing id")
+			}
+
+			res.Graph.Nodes = append(res.Graph.Nodes, models.Node{
+				Ref:        n.ID,
+				Label:      trim(n.Label, DefaultNodeLabel),
+				Properties: properties(attrs["node"].values(&res, item, n.Values)),
+			})
+
+		case "edge":
+			e := gexfEdge{}
+			if err := dec.DecodeElement(&e, &start); err != nil {
+				return res, fmt.Errorf("invalid gexf edge: %w", err)
+			}
+
+			item := "edge " + e.ID
+			if e.ID == "" {
+				item = fmt.Sprintf("edge %d", len(res.Graph.Edges)+1)
+			}
+
+			edge := models.Edge{
+				FromRef:    e.Source,
+				ToRef:      e.Target,
+				Label:      trim(e.Label, trim(e.Kind, DefaultEdgeLabel)),
+				Properties: properties(attrs["edge"].values(&res, item, e.Values)),
+			}
+
+			if e.Weight != "" {
+				w, err := weight(e.Weight)
+				if err != nil {
+					res.problem(item, "weight is not a number: %q", e.Weight)
+				}
+				edge.Weight = w
+			}
+
+			res.addEdge(item, edge)
+		}
+	}
+
+	res.validate()
+	return res, nil
+}
@@ -0,0 +1,210 @@
+package importer
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jenmud/edgedb/models"
+)
+
+type graphMLKey struct {
+	ID      string  `xml:"id,attr"`
+	For     string  `xml:"for,attr"`
+	Name    string  `xml:"attr.name,attr"`
+	Type    string  `xml:"attr.type,attr"`
+	Default *string `xml:"default"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+// graphMLKeys are the declared keys by ID.
+type graphMLKeys map[string]graphMLKey
+
+// forItem returns the keys which apply to the item, either `node` or `edge`.
+func (k graphMLKeys) forItem(item string) []graphMLKey {
+	keys := []graphMLKey{}
+	for _, key := range k {
+		if key.For == item || key.For == "all" || key.For == "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// values returns the converted values of the item data by attribute name, including key defaults.
+// Keys without an attribute name, eg: yEd graphics, are ignored.
+func (k graphMLKeys) values(res *Result, item, kind string, data []graphMLData) map[string]any {
+	values := map[string]any{}
+	seen := map[string]struct{}{}
+
+	for _, d := range data {
+		seen[d.Key] = struct{}{}
+
+		key, ok := k[d.Key]
+		if !ok {
+			res.problem(item, "undeclared key %q", d.Key)
+			continue
+		}
+
+		if key.Name == "" {
+			continue
+		}
+
+		v, err := convert(d.Value, key.Type)
+		if err != nil {
+			res.problem(item, "%s is not a valid %s: %q", key.Name, key.Type, d.Value)
+			continue
+		}
+
+		values[key.Name] = v
+	}
+
+	for _, key := range k.forItem(kind) {
+		if _, ok := seen[key.ID]; ok || key.Default == nil || key.Name == "" {
+			continue
+		}
+
+		v, err := convert(*key.Default, key.Type)
+		if err != nil {
+			res.problem(item, "%s default is not a valid %s: %q", key.Name, key.Type, *key.Default)
+			continue
+		}
+
+		values[key.Name] = v
+	}
+
+	return values
+}
+
+// ParseGraphML reads a GraphML document, the `label` attribute is used as the node or edge label
+// and the `weight` attribute as the edge weight, all other attributes become properties.
+// Only the first graph is read and edges are always directed.
+func ParseGraphML(r io.Reader) (Result, error) {
+	res := Result{Graph: models.Graph{Nodes: []models.Node{}, Edges: []models.Edge{}}}
+	keys := graphMLKeys{}
+
+	dec := xml.NewDecoder(r)
+
+	depth := 0 // nested graphs are skipped
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return res, fmt.Errorf("invalid graphml: %w", err)
+		}
+
+		switch tok := tok.(type) {
+		case xml.EndElement:
+			if tok.Name.Local == "graph" {
+				depth--
+			}
+
+		case xml.StartElement:
+			switch tok.Name.Local {
+			case "graph":
+				depth++
+
+			case "key":
+				key := graphMLKey{}
+				if err := dec.DecodeElement(&key, &tok); err != nil {
+					return res, fmt.Errorf("invalid graphml key: %w", err)
+				}
+				keys[key.ID] = key
+
+			case "node":
+				n := graphMLNode{}
+				if err := dec.DecodeElement(&n, &tok); err != nil {
+					return res, fmt.Errorf("invalid graphml node: %w", err)
+				}
+
+				if depth > 1 {
+					continue
+				}
+
+				item := "node " + n.ID
+				if n.ID == "" {
+					item = fmt.Sprintf("node %d", len(res.Graph.Nodes)+1)
+					res.problem(item, "missing id")
+				}
+
+				values := keys.values(&res, item, "node", n.Data)
+				node := models.Node{Ref: n.ID, Label: DefaultNodeLabel}
+
+				if label, ok := values["label"]; ok {
+					node.Label = trim(fmt.Sprint(label), DefaultNodeLabel)
+					delete(values, "label")
+				}
+
+				node.Properties = properties(values)
+				res.Graph.Nodes = append(res.Graph.Nodes, node)
+
+			case "edge":
+				e := graphMLEdge{}
+				if err := dec.DecodeElement(&e, &tok); err != nil {
+					return res, fmt.Errorf("invalid graphml edge: %w", err)
+				}
+
+				if depth > 1 {
+					continue
+				}
+
+				item := "edge " + e.ID
+				if e.ID == "" {
+					item = fmt.Sprintf("edge %d", len(res.Graph.Edges)+1)
+				}
+
+				values := keys.values(&res, item, "edge", e.Data)
+				edge := models.Edge{FromRef: e.Source, ToRef: e.Target, Label: DefaultEdgeLabel}
+
+				if label, ok := values["label"]; ok {
+					edge.Label = trim(fmt.Sprint(label), DefaultEdgeLabel)
+					delete(values, "label")
+				}
+
+				if v, ok := values["weight"]; ok {
+					w, err := weight(fmt.Sprint(v))
+					if err != nil {
+						res.problem(item, "weight is not a number: %v", v)
+					}
+					edge.Weight = w
+					delete(values, "weight")
+				}
+
+				edge.Properties = properties(values)
+				res.addEdge(item, edge)
+			}
+		}
+	}
+
+	res.validate()
+	return res, nil
+}
+
+// trim returns the trimmed label, falling back to the default label.
+func trim(label, fallback string) string {
+	if label = strings.TrimSpace(label); label != "" {
+		return label
+	}
+	return fallback
+}
@@ -0,0 +1,227 @@
+// Package importer reads graphs written by other tools, GraphML, GEXF and paired node and edge CSV files.
+//
+// Source IDs are kept as node refs (see models.Node.Ref) and edges reference their nodes with FromRef and ToRef,
+// so the parsed graph can be written in a single transaction with store.GraphWriter and the source IDs mapped to the
+// EdgeDB IDs using the returned refs.
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+	"github.com/jenmud/edgedb/pkg/common"
+)
+
+// Format is an import format.
+type Format string
+
+const (
+	// FormatGraphML is GraphML written by yEd, Cytoscape and most graph tools.
+	FormatGraphML Format = "graphml"
+
+	// FormatGEXF is GEXF written by Gephi.
+	FormatGEXF Format = "gexf"
+
+	// FormatCSV is a nodes CSV file and an optional edges CSV file.
+	FormatCSV Format = "csv"
+)
+
+const (
+	// DefaultNodeLabel is the label used for nodes without a label.
+	DefaultNodeLabel = "node"
+
+	// DefaultEdgeLabel is the label used for edges without a label.
+	DefaultEdgeLabel = "edge"
+)
+
+// Problem is a validation error found in the source, eg: an attribute value which does not match its declared type.
+type Problem struct {
+	Item  string `json:"item"` // item the problem was found in, eg: `node n1` or `edges.csv line 3`
+	Error string `json:"error"`
+}
+
+func (p Problem) String() string {
+	return p.Item + ": " + p.Error
+}
+
+// Result is a parsed graph and the problems found while parsing it.
+type Result struct {
+	Graph    models.Graph
+	Problems []Problem
+
+	edgeItems []string // how the edges are named in problems, eg: `edge e1` or `edges.csv line 2`
+}
+
+// addEdge adds the edge named item to the graph.
+func (r *Result) addEdge(item string, e models.Edge) {
+	r.Graph.Edges = append(r.Graph.Edges, e)
+	r.edgeItems = append(r.edgeItems, item)
+}
+
+// problem records a problem found in the item.
+func (r *Result) problem(item string, format string, args ...any) {
+	r.Problems = append(r.Problems, Problem{Item: item, Error: fmt.Sprintf(format, args...)})
+}
+
+// validate checks for duplicate node IDs and edges referencing unknown nodes.
+func (r *Result) validate() {
+	seen := make(map[string]struct{}, len(r.Graph.Nodes))
+
+	for _, n := range r.Graph.Nodes {
+		if n.Ref == "" {
+			continue
+		}
+
+		if _, ok := seen[n.Ref]; ok {
+			r.problem("node "+n.Ref, "duplicate node id")
+		}
+		seen[n.Ref] = struct{}{}
+	}
+
+	for i, e := range r.Graph.Edges {
+		item := r.edgeItems[i]
+
+		if _, ok := seen[e.FromRef]; !ok {
+			r.problem(item, "unknown source node %q", e.FromRef)
+		}
+
+		if _, ok := seen[e.ToRef]; !ok {
+			r.problem(item, "unknown target node %q", e.ToRef)
+		}
+	}
+}
+
+// Report summarises an import.
+type Report struct {
+	DryRun   bool              `json:"dry_run"`
+	Nodes    int               `json:"nodes"`
+	Edges    int               `json:"edges"`
+	Problems []Problem         `json:"problems"`
+	Refs     map[string]uint64 `json:"refs,omitempty"` // source node ID to EdgeDB node ID
+}
+
+// Write writes the parsed graph in a single transaction returning the report. Nothing is written for dry runs
+// or when problems were found, in which case store.ErrInvalid is returned with the report.
+//
+// Dry runs without parse problems check the graph with the store, so schema violations, natural key conflicts and
+// edges to unknown nodes are reported as a problem of the `graph`.
+func Write(ctx context.Context, w store.GraphWriter, res Result, dryRun bool) (Report, error) {
+	report := Report{
+		DryRun:   dryRun,
+		Nodes:    len(res.Graph.Nodes),
+		Edges:    len(res.Graph.Edges),
+		Problems: res.Problems,
+	}
+
+	if report.Problems == nil {
+		report.Problems = []Problem{}
+	}
+
+	if len(res.Problems) > 0 {
+		if dryRun {
+			return report, nil
+		}
+		return report, fmt.Errorf("%w: %d problems found, first %s", store.ErrInvalid, len(res.Problems), res.Problems[0])
+	}
+
+	if dryRun {
+		err := w.CheckGraph(ctx, res.Graph)
+		if err != nil && !errors.Is(err, store.ErrInvalid) && !errors.Is(err, store.ErrConflict) {
+			return report, err
+		}
+
+		if err != nil {
+			report.Problems = append(report.Problems, Problem{Item: "graph", Error: err.Error()})
+		}
+
+		return report, nil
+	}
+
+	result, err := w.UpsertGraph(ctx, res.Graph)
+	if err != nil {
+		return report, err
+	}
+
+	report.Refs = result.Refs
+	return report, nil
+}
+
+// properties returns the flattened attribute values as properties, dotted keys are expanded into nested maps.
+func properties(flat map[string]any) models.Properties {
+	return models.Properties(common.Unflatten(flat))
+}
+
+// convert converts an attribute value into a property value using the declared attribute type.
+// Unknown types are kept as strings.
+func convert(value, typ string) (any, error) {
+	switch strings.ToLower(typ) {
+	case "boolean":
+		return strconv.ParseBool(strings.TrimSpace(value))
+	case "int", "integer", "long", "short", "byte":
+		return strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	case "float", "double":
+		return strconv.ParseFloat(strings.TrimSpace(value), 64)
+	case "liststring":
+		return list(value), nil
+	}
+	return value, nil
+}
+
+// list splits a GEXF list value, eg: `a|b` or `[a, b]`.
+func list(value string) []any {
+	value = strings.TrimSpace(value)
+
+	sep := "|"
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		value = value[1 : len(value)-1]
+		sep = ","
+	}
+
+	values := []any{}
+	for v := range strings.SplitSeq(value, sep) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+// infer converts an untyped value, eg: from a CSV cell, into a bool, number or string.
+// Numbers which would not be written back the same way, eg: `007`, are kept as strings.
+func infer(value string) any {
+	if b, err := strconv.ParseBool(value); err == nil && (value == "true" || value == "false") {
+		return b
+	}
+
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil && strconv.FormatInt(i, 10) == value {
+		return i
+	}
+
+	if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) && !leadingZero(value) {
+		return f
+	}
+
+	return value
+}
+
+// leadingZero returns true for numbers with a leading zero, eg: `007` or `-01.5`.
+func leadingZero(value string) bool {
+	value = strings.TrimPrefix(value, "-")
+	return len(value) > 1 && value[0] == '0' && value[1] != '.'
+}
+
+// weight converts an edge weight, fractional weights are rounded.
+func weight(value string) (int, error) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Round(f)), nil
+}
@@ -0,0 +1,366 @@
+package importer_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jenmud/edgedb/internal/export"
+	"github.com/jenmud/edgedb/internal/importer"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/models"
+)
+
+const graphML = `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="d0" for="node" attr.name="label" attr.type="string"/>
+  <key id="d1" for="node" attr.name="age" attr.type="int"/>
+  <key id="d2" for="node" attr.name="meta.hair" attr.type="string">
+    <default>brown</default>
+  </key>
+  <key id="d3" for="edge" attr.name="weight" attr.type="double"/>
+  <key id="d4" for="edge" attr.name="close" attr.type="boolean"/>
+  <key id="d5" for="node" yfiles.type="nodegraphics"/>
+  <graph id="G" edgedefault="directed">
+    <node id="a">
+      <data key="d0">person</data>
+      <data key="d1">21</data>
+      <data key="d5"><shape/></data>
+    </node>
+    <node id="b">
+      <data key="d1">old</data>
+      <data key="d2">black</data>
+    </node>
+    <edge id="e1" source="a" target="b">
+      <data key="d3">2.6</data>
+      <data key="d4">true</data>
+    </edge>
+    <edge id="e2" source="a" target="c"/>
+  </graph>
+</graphml>`
+
+const gexf = `<?xml version="1.0" encoding="UTF-8"?>
+<gexf xmlns="http://gexf.net/1.3" version="1.3">
+  <graph defaultedgetype="directed">
+    <attributes class="node">
+      <attribute id="0" title="age" type="integer"/>
+      <attribute id="1" title="tags" type="liststring"/>
+    </attributes>
+    <attributes class="edge">
+      <attribute id="0" title="since" type="long"/>
+    </attributes>
+    <nodes>
+      <node id="a" label="person">
+        <attvalues>
+          <attvalue for="0" value="21"/>
+          <attvalue for="1" value="foo|bar"/>
+        </attvalues>
+      </node>
+      <node id="b"/>
+      <node id="b"/>
+    </nodes>
+    <edges>
+      <edge id="0" source="a" target="b" kind="knows" weight="3">
+        <attvalues>
+          <attvalue for="0" value="2020"/>
+        </attvalues>
+      </edge>
+    </edges>
+  </graph>
+</gexf>`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name         string // description of this test case
+		parse        func() (importer.Result, error)
+		wantNodes    []models.Node
+		wantEdges    []models.Edge
+		wantProblems []importer.Problem
+		wantErr      bool
+	}{
+		{
+			name:  "graphml",
+			parse: func() (importer.Result, error) { return importer.ParseGraphML(strings.NewReader(graphML)) },
+			wantNodes: []models.Node{
+				{Ref: "a", Label: "person", Properties: models.Properties{"age": int64(21), "meta": map[string]any{"hair": "brown"}}},
+				{Ref: "b", Label: importer.DefaultNodeLabel, Properties: models.Properties{"meta": map[string]any{"hair": "black"}}},
+			},
+			wantEdges: []models.Edge{
+				{FromRef: "a", ToRef: "b", Label: importer.DefaultEdgeLabel, Weight: 3, Properties: models.Properties{"close": true}},
+				{FromRef: "a", ToRef: "c", Label: importer.DefaultEdgeLabel, Properties: models.Properties{}},
+			},
+			wantProblems: []importer.Problem{
+				{Item: "node b", Error: `age is not a valid int: "old"`},
+				{Item: "edge e2", Error: `unknown target node "c"`},
+			},
+		},
+		{
+			name:  "gexf",
+			parse: func() (importer.Result, error) { return importer.ParseGEXF(strings.NewReader(gexf)) },
+			wantNodes: []models.Node{
+				{Ref: "a", Label: "person", Properties: models.Properties{"age": int64(21), "tags": []any{"foo", "bar"}}},
+				{Ref: "b", Label: importer.DefaultNodeLabel, Properties: models.Properties{}},
+				{Ref: "b", Label: importer.DefaultNodeLabel, Properties: models.Properties{}},
+			},
+			wantEdges: []models.Edge{
+				{FromRef: "a", ToRef: "b", Label: "knows", Weight: 3, Properties: models.Properties{"since": int64(2020)}},
+			},
+			wantProblems: []importer.Problem{
+				{Item: "node b", Error: "duplicate node id"},
+			},
+		},
+		{
+			name: "csv",
+			parse: func() (importer.Result, error) {
+				nodes := "Id,Label,created_at,age,zip,properties.label,meta.hair\n1,person,2024-01-01T00:00:00Z,21,007,clash,brown\n2,,,4.5,,,\n3,dog\n"
+				edges := "source,target,type,weight,since\n1,2,knows,2,2020\n1,4,,,\n"
+				return importer.ParseCSV(strings.NewReader(nodes), strings.NewReader(edges))
+			},
+			wantNodes: []models.Node{
+				{Ref: "1", Label: "person", Properties: models.Properties{"age": int64(21), "zip": "007", "label": "clash", "meta": map[string]any{"hair": "brown"}}},
+				{Ref: "2", Label: importer.DefaultNodeLabel, Properties: models.Properties{"age": 4.5}},
+			},
+			wantEdges: []models.Edge{
+				{FromRef: "1", ToRef: "2", Label: "knows", Weight: 2, Properties: models.Properties{"since": int64(2020)}},
+				{FromRef: "1", ToRef: "4", Label: importer.DefaultEdgeLabel, Properties: models.Properties{}},
+			},
+			wantProblems: []importer.Problem{
+				{Item: "nodes.csv line 4", Error: "expected 7 columns, got 2"},
+				{Item: "edges.csv line 3", Error: `unknown target node "4"`},
+			},
+		},
+		{
+			name: "csv missing id column",
+			parse: func() (importer.Result, error) {
+				return importer.ParseCSV(strings.NewReader("label\nperson\n"), nil)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "invalid xml",
+			parse:   func() (importer.Result, error) { return importer.ParseGraphML(strings.NewReader("<graphml><node>")) },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotErr := tt.parse()
+			if gotErr != nil {
+				if !tt.wantErr {
+					t.Errorf("Parse() failed: %v", gotErr)
+				}
+				return
+			}
+
+			if tt.wantErr {
+				t.Fatal("Parse() succeeded unexpectedly")
+			}
+
+			if diff := cmp.Diff(tt.wantNodes, got.Graph.Nodes, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Parse() nodes mismatch (-want, +got): \n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.wantEdges, got.Graph.Edges, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Parse() edges mismatch (-want, +got): \n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.wantProblems, got.Problems, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Parse() problems mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := sqlite.New(ctx, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := importer.ParseGEXF(strings.NewReader(gexf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := importer.Write(ctx, s, res, true)
+	if err != nil {
+		t.Fatalf("Write() dry run failed: %v", err)
+	}
+
+	want := importer.Report{DryRun: true, Nodes: 3, Edges: 1, Problems: res.Problems}
+	if diff := cmp.Diff(want, report); diff != "" {
+		t.Errorf("Write() dry run mismatch (-want, +got): \n%s", diff)
+	}
+
+	if _, err := importer.Write(ctx, s, res, false); !errors.Is(err, store.ErrInvalid) {
+		t.Errorf("Write() expected ErrInvalid with problems, got: %v", err)
+	}
+
+	nodes, err := s.Nodes(ctx, store.NodesArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nodes) != 0 {
+		t.Errorf("Write() expected nothing to be written, got %d nodes", len(nodes))
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	graph := models.Graph{
+		Nodes: []models.Node{
+			{Ref: "a", Label: "person", Properties: models.Properties{"name": "foo", "age": 21, "meta": map[string]any{"hair": "brown"}}},
+			{Ref: "b", Label: "person", Properties: models.Properties{"name": "bar", "age": 4.5, "alive": true}},
+		},
+		Edges: []models.Edge{
+			{FromRef: "a", Label: "knows", ToRef: "b", Weight: 2, Properties: models.Properties{"since": 2020}},
+		},
+	}
+
+	tests := []struct {
+		name     string // description of this test case
+		parse    func(t *testing.T, src export.Source) (importer.Result, error)
+		wantRefs map[string]uint64
+	}{
+		{
+			name:     "graphml",
+			wantRefs: map[string]uint64{"n1": 1, "n2": 2},
+			parse: func(t *testing.T, src export.Source) (importer.Result, error) {
+				var buf bytes.Buffer
+				if err := export.Write(t.Context(), &buf, export.FormatGraphML, src, export.Options{}); err != nil {
+					t.Fatal(err)
+				}
+				return importer.ParseGraphML(&buf)
+			},
+		},
+		{
+			name:     "gexf",
+			wantRefs: map[string]uint64{"1": 1, "2": 2},
+			parse: func(t *testing.T, src export.Source) (importer.Result, error) {
+				var buf bytes.Buffer
+				if err := export.Write(t.Context(), &buf, export.FormatGEXF, src, export.Options{}); err != nil {
+					t.Fatal(err)
+				}
+				return importer.ParseGEXF(&buf)
+			},
+		},
+		{
+			name:     "csv",
+			wantRefs: map[string]uint64{"1": 1, "2": 2},
+			parse: func(t *testing.T, src export.Source) (importer.Result, error) {
+				var nodes, edges bytes.Buffer
+				if err := export.Write(t.Context(), &nodes, export.FormatCSV, src, export.Options{Table: export.TableNodes}); err != nil {
+					t.Fatal(err)
+				}
+				if err := export.Write(t.Context(), &edges, export.FormatCSV, src, export.Options{Table: export.TableEdges}); err != nil {
+					t.Fatal(err)
+				}
+				return importer.ParseCSV(&nodes, &edges)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+
+			from, err := sqlite.New(ctx, ":memory:")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := from.UpsertGraph(ctx, graph); err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := tt.parse(t, export.StoreSource(from))
+			if err != nil {
+				t.Fatalf("Parse() failed: %v", err)
+			}
+
+			to, err := sqlite.New(ctx, ":memory:")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := importer.Write(ctx, to, res, false)
+			if err != nil {
+				t.Fatalf("Write() failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.wantRefs, report.Refs); diff != "" {
+				t.Errorf("Write() refs mismatch (-want, +got): \n%s", diff)
+			}
+
+			want, err := from.Graph(ctx, store.TermSearchArgs{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := to.Graph(ctx, store.TermSearchArgs{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			diff := cmp.Diff(
+				want,
+				got,
+				cmpopts.EquateEmpty(),
+				cmpopts.IgnoreFields(models.Node{}, "CreatedAt", "UpdatedAt", "Snippet"),
+				cmpopts.IgnoreFields(models.Edge{}, "CreatedAt", "UpdatedAt", "Snippet"),
+			)
+
+			if diff != "" {
+				t.Errorf("round trip mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWrite_DryRunChecksTheStore(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := sqlite.New(ctx, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := models.Schema{Kind: models.SchemaNode, Label: "person", Required: []string{"name"}}
+	if _, err := s.UpsertSchema(ctx, schema); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := importer.ParseCSV(strings.NewReader("id,label\na,person\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Problems) != 0 {
+		t.Fatalf("ParseCSV() problems = %v, want none", res.Problems)
+	}
+
+	report, err := importer.Write(ctx, s, res, true)
+	if err != nil {
+		t.Fatalf("Write() dry run failed: %v", err)
+	}
+
+	if len(report.Problems) != 1 || report.Problems[0].Item != "graph" {
+		t.Errorf("Write() dry run problems = %v, want the schema violation", report.Problems)
+	}
+
+	nodes, err := s.Nodes(ctx, store.NodesArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nodes) != 0 {
+		t.Errorf("Write() expected nothing to be written, got %d nodes", len(nodes))
+	}
+}
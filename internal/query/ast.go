@@ -0,0 +1,192 @@
+package query
+
+import "fmt"
+
+// Error is returned when a query can not be parsed or compiled.
+type Error struct {
+	// Pos is the character offset in the query where the error was found.
+	Pos int
+
+	// Msg describes the error.
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("query error at %d: %s", e.Pos, e.Msg)
+}
+
+// Query is a parsed read-only query.
+type Query struct {
+	// Patterns are the patterns from all the MATCH clauses.
+	Patterns []Pattern
+
+	// Where is the optional WHERE condition.
+	Where Expr
+
+	// Distinct removes duplicate rows.
+	Distinct bool
+
+	// Return are the returned items, empty when returning `*`.
+	Return []ReturnItem
+
+	// OrderBy are the ordering items.
+	OrderBy []OrderItem
+
+	// Skip is the optional number of rows to skip.
+	Skip Expr
+
+	// Limit is the optional max number of rows to return.
+	Limit Expr
+}
+
+// Pattern is a chain of nodes joined by relationships, there is always one more node than relationships.
+type Pattern struct {
+	Nodes []NodePattern
+	Rels  []RelPattern
+}
+
+// NodePattern matches a node, eg: `(a:person {name: $name})`.
+type NodePattern struct {
+	Var        string
+	Labels     []string
+	Properties map[string]Expr
+	Pos        int
+}
+
+// RelPattern matches a relationship, eg: `-[r:knows*1..3]->`.
+type RelPattern struct {
+	Var        string
+	Labels     []string
+	Properties map[string]Expr
+
+	// Direction is "out" for `->`, "in" for `<-` and "both" for `-`.
+	Direction string
+
+	// VarLength is true for variable length relationships using `*`.
+	VarLength bool
+
+	// Min is the min number of hops for variable length relationships.
+	Min int
+
+	// Max is the max number of hops for variable length relationships, 0 is unbounded.
+	Max int
+
+	Pos int
+}
+
+// ReturnItem is a returned expression.
+type ReturnItem struct {
+	Expr Expr
+
+	// Alias is the column name, defaults to the expression text.
+	Alias string
+}
+
+// OrderItem is an expression to order the rows by.
+type OrderItem struct {
+	Expr Expr
+	Desc bool
+}
+
+// Expr is an expression.
+type Expr interface {
+	// String returns the expression text used as the default column name.
+	String() string
+}
+
+// Literal is a string, number, boolean or null value.
+type Literal struct {
+	Value any
+}
+
+func (l Literal) String() string {
+	switch v := l.Value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("'%s'", v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// List is a list of expressions, eg: `['a', $b]`.
+type List struct {
+	Items []Expr
+}
+
+func (l List) String() string {
+	s := "["
+	for i, item := range l.Items {
+		if i > 0 {
+			s += ", "
+		}
+		s += item.String()
+	}
+	return s + "]"
+}
+
+// Param is a `$name` parameter.
+type Param struct {
+	Name string
+	Pos  int
+}
+
+func (p Param) String() string { return "$" + p.Name }
+
+// Variable is a reference to a node or relationship variable.
+type Variable struct {
+	Name string
+	Pos  int
+}
+
+func (v Variable) String() string { return v.Name }
+
+// Property is a property lookup on a variable, eg: `a.meta.hair`.
+type Property struct {
+	Var  Variable
+	Keys []string
+}
+
+func (p Property) String() string {
+	s := p.Var.Name
+	for _, k := range p.Keys {
+		s += "." + k
+	}
+	return s
+}
+
+// Call is a function call on a variable, eg: `id(a)`.
+type Call struct {
+	Func string
+	Arg  Variable
+	Pos  int
+}
+
+func (c Call) String() string { return fmt.Sprintf("%s(%s)", c.Func, c.Arg.Name) }
+
+// Unary is a unary expression, eg: `NOT a.active` or `a.name IS NULL`.
+type Unary struct {
+	// Op is one of NOT, IS NULL or IS NOT NULL.
+	Op   string
+	Expr Expr
+}
+
+func (u Unary) String() string {
+	if u.Op == "NOT" {
+		return "NOT " + u.Expr.String()
+	}
+	return u.Expr.String() + " " + u.Op
+}
+
+// Binary is a binary expression, eg: `a.age > 30`.
+type Binary struct {
+	// Op is one of AND, OR, =, <>, <, <=, >, >=, IN, STARTS WITH, ENDS WITH or CONTAINS.
+	Op          string
+	Left, Right Expr
+	Pos         int
+}
+
+func (b Binary) String() string {
+	return fmt.Sprintf("%s %s %s", b.Left, b.Op, b.Right)
+}
@@ -0,0 +1,795 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jenmud/edgedb/internal/store"
+)
+
+// DefaultLimit is the number of rows returned when the query has no LIMIT.
+const DefaultLimit = 1000
+
+// DefaultMaxHops is the max number of hops of variable length relationships without a max, eg: `*` or `*2..`.
+const DefaultMaxHops = 3
+
+// MaxHops is the largest max number of hops a variable length relationship can have.
+const MaxHops = store.DefaultMaxDepth
+
+// MaxWalkRows is the max number of rows walked by a variable length relationship. The number of walks grows
+// exponentially with the hops in dense graphs, the walk stops after this many rows so a query can not hold the store,
+// and the result is flagged as truncated.
+const MaxWalkRows = 20000
+
+// ColumnKind is the kind of value selected for a result column.
+type ColumnKind int
+
+const (
+	// ColumnValue columns select a JSON encoded value.
+	ColumnValue ColumnKind = iota
+
+	// ColumnNode columns select a node ID.
+	ColumnNode
+
+	// ColumnEdge columns select an edge ID.
+	ColumnEdge
+
+	// ColumnEdges columns select a JSON array of the edge IDs walked by a variable length relationship.
+	ColumnEdges
+)
+
+// Column is a result column.
+type Column struct {
+	Name string
+	Kind ColumnKind
+}
+
+// Plan is a query compiled to SQL against the `items` table, trashed items are not matched.
+type Plan struct {
+	SQL     string
+	Args    []any
+	Columns []Column
+
+	// Truncated is the SQL, using the same Args, returning true if a variable length relationship walked more than
+	// MaxWalkRows rows. It is empty if none of the walks can be truncated.
+	Truncated string
+}
+
+// binding is a variable bound to a table alias.
+type binding struct {
+	kind  ColumnKind
+	alias string
+}
+
+// compiler holds the state while compiling a query.
+type compiler struct {
+	params map[string]any
+	args   []any
+
+	vars  map[string]*binding
+	names []string
+
+	// rels are all the relationship bindings, including anonymous ones, used to keep relationships unique.
+	rels []*binding
+
+	ctes  []string
+	from  []string
+	conds []string
+
+	// walkLimit is the LIMIT pushed into the walk of a variable length relationship, 0 if it can not be pushed.
+	walkLimit int
+
+	// budgeted are the aliases of the walks limited by MaxWalkRows.
+	budgeted []string
+
+	nodes, edges, paths int
+}
+
+// Compile compiles the query into SQL binding the `$name` parameters from params.
+// Variable length relationships without a max are limited to DefaultMaxHops hops and walk at most MaxWalkRows rows.
+func Compile(q *Query, params map[string]any) (Plan, error) {
+	c := &compiler{params: params, vars: map[string]*binding{}}
+
+	limit, err := c.count(q.Limit, DefaultLimit)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	skip, err := c.count(q.Skip, 0)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	if pushLimit(q) {
+		l, _ := strconv.Atoi(limit)
+		s, _ := strconv.Atoi(skip)
+		c.walkLimit = l + s
+	}
+
+	for _, pattern := range q.Patterns {
+		if err := c.pattern(pattern); err != nil {
+			return Plan{}, err
+		}
+	}
+
+	c.unique()
+
+	if q.Where != nil {
+		where, err := c.expr(q.Where)
+		if err != nil {
+			return Plan{}, err
+		}
+		c.conds = append(c.conds, where)
+	}
+
+	items := q.Return
+	if len(items) == 0 {
+		for _, name := range c.names {
+			items = append(items, ReturnItem{Expr: Variable{Name: name}, Alias: name})
+		}
+
+		if len(items) == 0 {
+			return Plan{}, &Error{Msg: "RETURN * requires at least one named variable"}
+		}
+	}
+
+	plan := Plan{}
+	selects := make([]string, len(items))
+	aliases := map[string]string{}
+
+	for i, item := range items {
+		col, kind, err := c.column(item.Expr)
+		if err != nil {
+			return Plan{}, err
+		}
+
+		name := fmt.Sprintf("c%d", i)
+		selects[i] = fmt.Sprintf("%s AS %s", col, name)
+		aliases[item.Alias] = name
+		plan.Columns = append(plan.Columns, Column{Name: item.Alias, Kind: kind})
+	}
+
+	var orders []string
+	for _, item := range q.OrderBy {
+		order, err := c.order(item.Expr, aliases)
+		if err != nil {
+			return Plan{}, err
+		}
+
+		if item.Desc {
+			order += " DESC"
+		}
+		orders = append(orders, order)
+	}
+
+	var b strings.Builder
+
+	if len(c.ctes) > 0 {
+		fmt.Fprintf(&b, "WITH RECURSIVE\n%s\n", strings.Join(c.ctes, ",\n"))
+	}
+
+	if len(c.budgeted) > 0 {
+		// the budgeted walks stop one row after MaxWalkRows so a walk which was cut short can be told apart.
+		checks := make([]string, len(c.budgeted))
+		for i, alias := range c.budgeted {
+			checks[i] = fmt.Sprintf("(SELECT count(*) FROM %s) > %d", alias, MaxWalkRows)
+		}
+
+		plan.Truncated = fmt.Sprintf("%sSELECT %s;", b.String(), strings.Join(checks, " OR "))
+	}
+
+	b.WriteString("SELECT ")
+	if q.Distinct {
+		b.WriteString("DISTINCT ")
+	}
+	fmt.Fprintf(&b, "%s\nFROM %s\n", strings.Join(selects, ", "), strings.Join(c.from, ", "))
+
+	if len(c.conds) > 0 {
+		fmt.Fprintf(&b, "WHERE %s\n", strings.Join(c.conds, "\nAND "))
+	}
+
+	if len(orders) > 0 {
+		fmt.Fprintf(&b, "ORDER BY %s\n", strings.Join(orders, ", "))
+	}
+
+	fmt.Fprintf(&b, "LIMIT %s OFFSET %s;", limit, skip)
+
+	plan.SQL = b.String()
+	plan.Args = c.args
+
+	return plan, nil
+}
+
+// pushLimit returns true if the LIMIT of the query can be pushed into the walk of its variable length relationship,
+// which is when each row walked is a row of the result: a single pattern with a single variable length relationship
+// of at most 1 min hop to a new node without labels or properties, and no WHERE, DISTINCT or ORDER BY.
+func pushLimit(q *Query) bool {
+	if len(q.Patterns) != 1 || q.Where != nil || q.Distinct || len(q.OrderBy) > 0 {
+		return false
+	}
+
+	p := q.Patterns[0]
+	if len(p.Rels) != 1 || !p.Rels[0].VarLength || p.Rels[0].Min > 1 {
+		return false
+	}
+
+	left, right := p.Nodes[0], p.Nodes[1]
+	if right.Var != "" && right.Var == left.Var {
+		return false
+	}
+
+	return len(right.Labels) == 0 && len(right.Properties) == 0
+}
+
+// bind adds the value as a numbered query argument returning the placeholder.
+// Numbered placeholders let the SQL be assembled in any order and reuse the same argument.
+func (c *compiler) bind(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "NULL", nil
+	case bool:
+		// json_extract returns JSON true and false as 1 and 0.
+		if v {
+			c.args = append(c.args, 1)
+		} else {
+			c.args = append(c.args, 0)
+		}
+	case string, int, int32, int64, uint, uint32, uint64, float32, float64:
+		c.args = append(c.args, v)
+	default:
+		return "", &Error{Msg: fmt.Sprintf("unsupported value type %T", v)}
+	}
+
+	return fmt.Sprintf("?%d", len(c.args)), nil
+}
+
+// param returns the value for the parameter.
+func (c *compiler) param(p Param) (any, error) {
+	v, ok := c.params[p.Name]
+	if !ok {
+		return nil, &Error{Pos: p.Pos, Msg: fmt.Sprintf("missing parameter $%s", p.Name)}
+	}
+	return v, nil
+}
+
+// jsonPath returns the quoted JSON path literal for the keys, eg: `'$."meta"."hair"'`.
+func jsonPath(keys []string) (string, error) {
+	var b strings.Builder
+	b.WriteString(`'$`)
+
+	for _, k := range keys {
+		if strings.Contains(k, `"`) {
+			return "", &Error{Msg: fmt.Sprintf("property key %q can not contain a double quote", k)}
+		}
+		fmt.Fprintf(&b, `."%s"`, strings.ReplaceAll(k, "'", "''"))
+	}
+
+	b.WriteString(`'`)
+	return b.String(), nil
+}
+
+// itemConds returns the label and property conditions for the table alias.
+func (c *compiler) itemConds(alias string, labels []string, props map[string]Expr) ([]string, error) {
+	var conds []string
+
+	if len(labels) > 0 {
+		// labels are compared with `=` instead of `IN` so sqlite can use the property indexes scoped to the labels.
+		// Several labels only use the indexes if every label has an index on the path, sqlite then unions the indexes.
+		marks := make([]string, len(labels))
+		for i, label := range labels {
+			mark, err := c.bind(label)
+			if err != nil {
+				return nil, err
+			}
+			marks[i] = fmt.Sprintf("%s.label = %s", alias, mark)
+		}
+		conds = append(conds, "("+strings.Join(marks, " OR ")+")")
+	}
+
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		path, err := jsonPath([]string{k})
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := c.expr(props[k])
+		if err != nil {
+			return nil, err
+		}
+
+		conds = append(conds, fmt.Sprintf("json_extract(%s.properties, %s) = %s", alias, path, value))
+	}
+
+	return conds, nil
+}
+
+// declare records a named variable.
+func (c *compiler) declare(name string, b *binding) {
+	c.vars[name] = b
+	c.names = append(c.names, name)
+}
+
+func (c *compiler) node(np NodePattern) (*binding, error) {
+	b, ok := c.vars[np.Var]
+	if np.Var != "" && ok {
+		if b.kind != ColumnNode {
+			return nil, &Error{Pos: np.Pos, Msg: fmt.Sprintf("variable %s is already bound to a relationship", np.Var)}
+		}
+	} else {
+		b = &binding{kind: ColumnNode, alias: fmt.Sprintf("n%d", c.nodes)}
+		c.nodes++
+
+		c.from = append(c.from, "items "+b.alias)
+		c.conds = append(c.conds, fmt.Sprintf("%s.from_id = 0 AND %s.to_id = 0 AND %s.deleted_at IS NULL", b.alias, b.alias, b.alias))
+
+		if np.Var != "" {
+			c.declare(np.Var, b)
+		}
+	}
+
+	conds, err := c.itemConds(b.alias, np.Labels, np.Properties)
+	if err != nil {
+		return nil, err
+	}
+	c.conds = append(c.conds, conds...)
+
+	return b, nil
+}
+
+func (c *compiler) pattern(p Pattern) error {
+	left, err := c.node(p.Nodes[0])
+	if err != nil {
+		return err
+	}
+
+	for i, rel := range p.Rels {
+		right, err := c.node(p.Nodes[i+1])
+		if err != nil {
+			return err
+		}
+
+		if rel.Var != "" {
+			if _, ok := c.vars[rel.Var]; ok {
+				return &Error{Pos: rel.Pos, Msg: fmt.Sprintf("variable %s is already bound", rel.Var)}
+			}
+		}
+
+		var b *binding
+		if rel.VarLength {
+			b, err = c.varLength(rel, p.Nodes[i], left, right)
+		} else {
+			b, err = c.rel(rel, left, right)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		c.rels = append(c.rels, b)
+		if rel.Var != "" {
+			c.declare(rel.Var, b)
+		}
+
+		left = right
+	}
+
+	return nil
+}
+
+// rel joins a single edge between the left and right nodes.
+func (c *compiler) rel(rel RelPattern, left, right *binding) (*binding, error) {
+	b := &binding{kind: ColumnEdge, alias: fmt.Sprintf("e%d", c.edges)}
+	c.edges++
+
+	e, l, r := b.alias, left.alias, right.alias
+
+	c.from = append(c.from, "items "+e)
+	c.conds = append(c.conds, fmt.Sprintf("%s.from_id > 0 AND %s.to_id > 0 AND %s.deleted_at IS NULL", e, e, e))
+
+	switch rel.Direction {
+	case "out":
+		c.conds = append(c.conds, fmt.Sprintf("%s.from_id = %s.id AND %s.to_id = %s.id", e, l, e, r))
+	case "in":
+		c.conds = append(c.conds, fmt.Sprintf("%s.from_id = %s.id AND %s.to_id = %s.id", e, r, e, l))
+	default:
+		c.conds = append(
+			c.conds,
+			fmt.Sprintf("((%s.from_id = %s.id AND %s.to_id = %s.id) OR (%s.from_id = %s.id AND %s.to_id = %s.id))", e, l, e, r, e, r, e, l),
+		)
+	}
+
+	conds, err := c.itemConds(e, rel.Labels, rel.Properties)
+	if err != nil {
+		return nil, err
+	}
+	c.conds = append(c.conds, conds...)
+
+	return b, nil
+}
+
+// varLength joins a recursive CTE walking between min and max edges from the left node to the right node.
+// The CTE is seeded with the nodes matching the left node pattern so the walk starts from as few nodes as possible,
+// or with their first edges if the walk needs at least one hop. Edges are not repeated along a walk, and the walk
+// stops after MaxWalkRows rows, flagging the result as truncated, or the LIMIT of the query if it was pushed into the walk.
+// The walked edges are kept as comma separated IDs, eg: `,5,6,`, which are cheaper to grow and search than JSON.
+func (c *compiler) varLength(rel RelPattern, leftPattern NodePattern, left, right *binding) (*binding, error) {
+	b := &binding{kind: ColumnEdges, alias: fmt.Sprintf("p%d", c.paths)}
+	c.paths++
+
+	maxHops := rel.Max
+	if maxHops == 0 {
+		maxHops = max(DefaultMaxHops, rel.Min)
+	}
+
+	if maxHops > MaxHops {
+		return nil, &Error{Pos: rel.Pos, Msg: fmt.Sprintf("max hops %d is more than %d", maxHops, MaxHops)}
+	}
+
+	if rel.Min > maxHops {
+		return nil, &Error{Pos: rel.Pos, Msg: fmt.Sprintf("min hops %d is more than the max hops %d", rel.Min, maxHops)}
+	}
+
+	walkLimit := MaxWalkRows + 1
+	if c.walkLimit > 0 && c.walkLimit <= MaxWalkRows {
+		walkLimit = c.walkLimit
+	} else {
+		c.budgeted = append(c.budgeted, b.alias)
+	}
+
+	seedConds, err := c.itemConds("n", leftPattern.Labels, leftPattern.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	edgeConds, err := c.itemConds("e", rel.Labels, rel.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	step := func(from, join, next string) string {
+		conds := append([]string{
+			fmt.Sprintf("p.depth < %d", maxHops),
+			"e.from_id > 0 AND e.to_id > 0 AND e.deleted_at IS NULL",
+			"instr(p.edges, ',' || e.id || ',') = 0",
+		}, edgeConds...)
+
+		return fmt.Sprintf(
+			"SELECT p.start, e.%s, p.depth + 1, p.edges || e.id || ',' FROM %s p JOIN items e ON e.%s = p.node WHERE %s",
+			next,
+			from,
+			join,
+			strings.Join(conds, " AND "),
+		)
+	}
+
+	steps := func(from string) []string {
+		switch rel.Direction {
+		case "out":
+			return []string{step(from, "from_id", "to_id")}
+		case "in":
+			return []string{step(from, "to_id", "from_id")}
+		}
+		return []string{step(from, "from_id", "to_id"), step(from, "to_id", "from_id")}
+	}
+
+	seedConds = append([]string{"n.from_id = 0 AND n.to_id = 0 AND n.deleted_at IS NULL"}, seedConds...)
+	seed := fmt.Sprintf("SELECT n.id AS start, n.id AS node, 0 AS depth, ',' AS edges FROM items n WHERE %s", strings.Join(seedConds, " AND "))
+
+	// walks of at least one hop start with the first edges so that every row walked can be a row of the result.
+	anchor := []string{seed}
+	if rel.Min > 0 {
+		anchor = steps("(" + seed + ")")
+	}
+
+	c.ctes = append(
+		c.ctes,
+		fmt.Sprintf(
+			"%s(start, node, depth, edges) AS (\n\t%s\n\tUNION ALL\n\t%s\n\tLIMIT %d\n)",
+			b.alias,
+			strings.Join(anchor, "\n\tUNION ALL\n\t"),
+			strings.Join(steps(b.alias), "\n\tUNION ALL\n\t"),
+			walkLimit,
+		),
+	)
+
+	c.from = append(c.from, b.alias)
+	c.conds = append(
+		c.conds,
+		fmt.Sprintf("%s.start = %s.id AND %s.node = %s.id AND %s.depth >= %d", b.alias, left.alias, b.alias, right.alias, b.alias, rel.Min),
+	)
+
+	return b, nil
+}
+
+// edgesJSON returns the JSON array of the edge IDs walked by the variable length relationship.
+func edgesJSON(b *binding) string {
+	return fmt.Sprintf("json('[' || trim(%s.edges, ',') || ']')", b.alias)
+}
+
+// unique adds the conditions which stop the same edge being matched by more than one relationship.
+func (c *compiler) unique() {
+	for i, a := range c.rels {
+		for _, b := range c.rels[i+1:] {
+			switch {
+			case a.kind == ColumnEdge && b.kind == ColumnEdge:
+				c.conds = append(c.conds, fmt.Sprintf("%s.id != %s.id", a.alias, b.alias))
+			case a.kind == ColumnEdge:
+				c.conds = append(c.conds, fmt.Sprintf("instr(%s.edges, ',' || %s.id || ',') = 0", b.alias, a.alias))
+			case b.kind == ColumnEdge:
+				c.conds = append(c.conds, fmt.Sprintf("instr(%s.edges, ',' || %s.id || ',') = 0", a.alias, b.alias))
+			default:
+				c.conds = append(
+					c.conds,
+					fmt.Sprintf("NOT EXISTS (SELECT 1 FROM json_each(%s) x JOIN json_each(%s) y ON x.value = y.value)", edgesJSON(a), edgesJSON(b)),
+				)
+			}
+		}
+	}
+}
+
+// lookup returns the binding for the variable.
+func (c *compiler) lookup(v Variable) (*binding, error) {
+	b, ok := c.vars[v.Name]
+	if !ok {
+		return nil, &Error{Pos: v.Pos, Msg: fmt.Sprintf("unknown variable %s", v.Name)}
+	}
+	return b, nil
+}
+
+// property returns the table alias and JSON path for the property.
+func (c *compiler) property(p Property) (string, string, error) {
+	b, err := c.lookup(p.Var)
+	if err != nil {
+		return "", "", err
+	}
+
+	if b.kind == ColumnEdges {
+		return "", "", &Error{Pos: p.Var.Pos, Msg: fmt.Sprintf("%s is a variable length relationship and has no properties", p.Var.Name)}
+	}
+
+	path, err := jsonPath(p.Keys)
+	if err != nil {
+		return "", "", err
+	}
+
+	return b.alias, path, nil
+}
+
+// list returns the placeholders for a list literal or list parameter.
+func (c *compiler) list(e Expr) ([]string, error) {
+	var values []any
+
+	switch e := e.(type) {
+	case List:
+		marks := make([]string, len(e.Items))
+		for i, item := range e.Items {
+			mark, err := c.expr(item)
+			if err != nil {
+				return nil, err
+			}
+			marks[i] = mark
+		}
+		return marks, nil
+	case Param:
+		v, err := c.param(e)
+		if err != nil {
+			return nil, err
+		}
+
+		list, ok := v.([]any)
+		if !ok {
+			return nil, &Error{Pos: e.Pos, Msg: fmt.Sprintf("parameter $%s must be a list", e.Name)}
+		}
+		values = list
+	default:
+		return nil, &Error{Msg: fmt.Sprintf("IN expects a list, got %s", e)}
+	}
+
+	marks := make([]string, len(values))
+	for i, v := range values {
+		mark, err := c.bind(v)
+		if err != nil {
+			return nil, err
+		}
+		marks[i] = mark
+	}
+
+	return marks, nil
+}
+
+// expr compiles the expression into a SQL expression.
+func (c *compiler) expr(e Expr) (string, error) {
+	switch e := e.(type) {
+	case Literal:
+		return c.bind(e.Value)
+
+	case Param:
+		v, err := c.param(e)
+		if err != nil {
+			return "", err
+		}
+		return c.bind(v)
+
+	case List:
+		return "", &Error{Msg: fmt.Sprintf("lists are only supported with IN, got %s", e)}
+
+	case Variable:
+		b, err := c.lookup(e)
+		if err != nil {
+			return "", err
+		}
+
+		if b.kind == ColumnEdges {
+			return "", &Error{Pos: e.Pos, Msg: fmt.Sprintf("%s is a variable length relationship and can not be compared", e.Name)}
+		}
+		return b.alias + ".id", nil
+
+	case Property:
+		alias, path, err := c.property(e)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("json_extract(%s.properties, %s)", alias, path), nil
+
+	case Call:
+		b, err := c.lookup(e.Arg)
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case e.Func == "id" && b.kind != ColumnEdges:
+			return b.alias + ".id", nil
+		case e.Func == "type" && b.kind == ColumnEdge:
+			return b.alias + ".label", nil
+		case e.Func == "length" && b.kind == ColumnEdges:
+			return b.alias + ".depth", nil
+		}
+		return "", &Error{Pos: e.Pos, Msg: fmt.Sprintf("unsupported function %s", e)}
+
+	case Unary:
+		inner, err := c.expr(e.Expr)
+		if err != nil {
+			return "", err
+		}
+
+		if e.Op == "NOT" {
+			return fmt.Sprintf("NOT (%s)", inner), nil
+		}
+		return fmt.Sprintf("(%s) %s", inner, e.Op), nil
+
+	case Binary:
+		left, err := c.expr(e.Left)
+		if err != nil {
+			return "", err
+		}
+
+		if e.Op == "IN" {
+			marks, err := c.list(e.Right)
+			if err != nil {
+				return "", err
+			}
+
+			if len(marks) == 0 {
+				// nothing is in an empty list.
+				return "0", nil
+			}
+			return fmt.Sprintf("%s IN (%s)", left, strings.Join(marks, ", ")), nil
+		}
+
+		right, err := c.expr(e.Right)
+		if err != nil {
+			return "", err
+		}
+
+		switch e.Op {
+		case "AND", "OR":
+			return fmt.Sprintf("(%s %s %s)", left, e.Op, right), nil
+		case "STARTS WITH":
+			return fmt.Sprintf("instr(%s, %s) = 1", left, right), nil
+		case "ENDS WITH":
+			return fmt.Sprintf("(length(%s) = 0 OR substr(%s, -length(%s)) = %s)", right, left, right, right), nil
+		case "CONTAINS":
+			return fmt.Sprintf("instr(%s, %s) > 0", left, right), nil
+		case "=", "<>", "<", "<=", ">", ">=":
+			return fmt.Sprintf("%s %s %s", left, e.Op, right), nil
+		}
+		return "", &Error{Pos: e.Pos, Msg: fmt.Sprintf("unsupported operator %s", e.Op)}
+	}
+
+	return "", &Error{Msg: fmt.Sprintf("unsupported expression %s", e)}
+}
+
+// column compiles a returned expression into the selected column.
+// Variables select their IDs, properties select their JSON and everything else is JSON quoted.
+func (c *compiler) column(e Expr) (string, ColumnKind, error) {
+	switch e := e.(type) {
+	case Variable:
+		b, err := c.lookup(e)
+		if err != nil {
+			return "", 0, err
+		}
+
+		if b.kind == ColumnEdges {
+			return edgesJSON(b), b.kind, nil
+		}
+		return b.alias + ".id", b.kind, nil
+
+	case Property:
+		alias, path, err := c.property(e)
+		if err != nil {
+			return "", 0, err
+		}
+		return fmt.Sprintf("%s.properties -> %s", alias, path), ColumnValue, nil
+	}
+
+	expr, err := c.expr(e)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return fmt.Sprintf("json_quote(%s)", expr), ColumnValue, nil
+}
+
+// order compiles an ORDER BY expression, returned aliases which are not variables order by the column.
+func (c *compiler) order(e Expr, aliases map[string]string) (string, error) {
+	if v, ok := e.(Variable); ok {
+		if _, isVar := c.vars[v.Name]; !isVar {
+			if col, ok := aliases[v.Name]; ok {
+				return col, nil
+			}
+		}
+	}
+
+	return c.expr(e)
+}
+
+// count compiles a SKIP or LIMIT expression which must be a non-negative integer.
+func (c *compiler) count(e Expr, fallback int) (string, error) {
+	if e == nil {
+		return fmt.Sprint(fallback), nil
+	}
+
+	var v any
+
+	switch e := e.(type) {
+	case Literal:
+		v = e.Value
+	case Param:
+		pv, err := c.param(e)
+		if err != nil {
+			return "", err
+		}
+		v = pv
+	}
+
+	var n int64
+
+	switch v := v.(type) {
+	case int64:
+		n = v
+	case int:
+		n = int64(v)
+	case float64:
+		if v != float64(int64(v)) {
+			return "", &Error{Msg: fmt.Sprintf("SKIP and LIMIT expect an integer, got %v", v)}
+		}
+		n = int64(v)
+	default:
+		return "", &Error{Msg: fmt.Sprintf("SKIP and LIMIT expect an integer, got %s", e)}
+	}
+
+	if n < 0 {
+		return "", &Error{Msg: fmt.Sprintf("SKIP and LIMIT can not be negative, got %d", n)}
+	}
+
+	return fmt.Sprint(n), nil
+}
@@ -0,0 +1,218 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tokenKind is the kind of a lexed token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenKeyword
+	tokenString
+	tokenNumber
+	tokenParam
+	tokenPunct
+)
+
+// keywords are the reserved words, they are matched case-insensitively.
+var keywords = map[string]bool{
+	"MATCH":    true,
+	"WHERE":    true,
+	"RETURN":   true,
+	"DISTINCT": true,
+	"AS":       true,
+	"ORDER":    true,
+	"BY":       true,
+	"ASC":      true,
+	"DESC":     true,
+	"SKIP":     true,
+	"LIMIT":    true,
+	"AND":      true,
+	"OR":       true,
+	"NOT":      true,
+	"IN":       true,
+	"IS":       true,
+	"NULL":     true,
+	"TRUE":     true,
+	"FALSE":    true,
+	"STARTS":   true,
+	"ENDS":     true,
+	"WITH":     true,
+	"CONTAINS": true,
+}
+
+// writeKeywords are the clauses which modify the graph, they are rejected as the language is read-only.
+var writeKeywords = map[string]bool{
+	"CREATE": true,
+	"MERGE":  true,
+	"DELETE": true,
+	"DETACH": true,
+	"SET":    true,
+	"REMOVE": true,
+}
+
+// token is a single lexed token.
+type token struct {
+	kind tokenKind
+	// text is the token text, keywords are upper-cased and strings are unquoted.
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenString:
+		return fmt.Sprintf("string %q", t.text)
+	case tokenParam:
+		return "$" + t.text
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// punctuation are the multi character punctuation tokens, they are matched before single characters.
+var punctuation = []string{"..", "<>", "!=", "<=", ">="}
+
+// lex splits the query into tokens.
+func lex(src string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(src)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			// line comment.
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+
+			word := string(runes[start:i])
+			upper := strings.ToUpper(word)
+
+			switch {
+			case keywords[upper]:
+				tokens = append(tokens, token{kind: tokenKeyword, text: upper, pos: start})
+			case writeKeywords[upper]:
+				return nil, &Error{Pos: start, Msg: fmt.Sprintf("%s is not supported, queries are read-only", upper)}
+			default:
+				tokens = append(tokens, token{kind: tokenIdent, text: word, pos: start})
+			}
+
+		case r == '`':
+			start := i
+			i++
+			for i < len(runes) && runes[i] != '`' {
+				i++
+			}
+			if i >= len(runes) {
+				return nil, &Error{Pos: start, Msg: "unterminated quoted identifier"}
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start+1 : i]), pos: start})
+			i++
+
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			// only consume the decimal point if a digit follows so ranges like `1..3` lex as 1, .., 3.
+			if i+1 < len(runes) && runes[i] == '.' && unicode.IsDigit(runes[i+1]) {
+				i++
+				for i < len(runes) && unicode.IsDigit(runes[i]) {
+					i++
+				}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+
+		case r == '\'' || r == '"':
+			start := i
+			quote := r
+			i++
+
+			var b strings.Builder
+			for {
+				if i >= len(runes) {
+					return nil, &Error{Pos: start, Msg: "unterminated string"}
+				}
+
+				c := runes[i]
+				if c == quote {
+					i++
+					break
+				}
+
+				if c == '\\' && i+1 < len(runes) {
+					i++
+					switch runes[i] {
+					case 'n':
+						b.WriteRune('\n')
+					case 't':
+						b.WriteRune('\t')
+					default:
+						b.WriteRune(runes[i])
+					}
+					i++
+					continue
+				}
+
+				b.WriteRune(c)
+				i++
+			}
+
+			tokens = append(tokens, token{kind: tokenString, text: b.String(), pos: start})
+
+		case r == '$':
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			if i == start+1 {
+				return nil, &Error{Pos: start, Msg: "expected a parameter name after $"}
+			}
+			tokens = append(tokens, token{kind: tokenParam, text: string(runes[start+1 : i]), pos: start})
+
+		default:
+			matched := false
+			for _, p := range punctuation {
+				if strings.HasPrefix(string(runes[i:min(i+len(p), len(runes))]), p) {
+					tokens = append(tokens, token{kind: tokenPunct, text: p, pos: i})
+					i += len(p)
+					matched = true
+					break
+				}
+			}
+
+			if matched {
+				continue
+			}
+
+			if !strings.ContainsRune("()[]{}:,.|*-<>=", r) {
+				return nil, &Error{Pos: i, Msg: fmt.Sprintf("unexpected character %q", r)}
+			}
+
+			tokens = append(tokens, token{kind: tokenPunct, text: string(r), pos: i})
+			i++
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
@@ -0,0 +1,638 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parser is a recursive descent parser over the lexed tokens.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse parses a read-only query, eg:
+//
+//	MATCH (a:person)-[r:knows*1..3]->(b) WHERE a.name = $name RETURN a, r, b LIMIT 10
+func Parse(src string) (*Query, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	return p.query()
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// is returns true if the next token is the keyword or punctuation.
+func (p *parser) is(text string) bool {
+	t := p.peek()
+	return (t.kind == tokenKeyword || t.kind == tokenPunct) && t.text == text
+}
+
+// accept consumes the next token if it is the keyword or punctuation.
+func (p *parser) accept(text string) bool {
+	if p.is(text) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+// expect consumes the keyword or punctuation returning an error if it is not next.
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return p.errorf("expected %q, got %s", text, p.peek())
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &Error{Pos: p.peek().pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// name consumes an identifier, keywords are also accepted so labels and keys like `order` can be used.
+func (p *parser) name(what string) (string, error) {
+	t := p.peek()
+	if t.kind != tokenIdent && t.kind != tokenKeyword {
+		return "", p.errorf("expected %s, got %s", what, t)
+	}
+	p.next()
+	return t.text, nil
+}
+
+func (p *parser) query() (*Query, error) {
+	q := &Query{}
+
+	if !p.is("MATCH") {
+		return nil, p.errorf("expected MATCH, got %s", p.peek())
+	}
+
+	for p.accept("MATCH") {
+		for {
+			pattern, err := p.pattern()
+			if err != nil {
+				return nil, err
+			}
+			q.Patterns = append(q.Patterns, pattern)
+
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+
+	if p.accept("WHERE") {
+		where, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		q.Where = where
+	}
+
+	if err := p.expect("RETURN"); err != nil {
+		return nil, err
+	}
+
+	q.Distinct = p.accept("DISTINCT")
+
+	if !p.accept("*") {
+		for {
+			item, err := p.returnItem()
+			if err != nil {
+				return nil, err
+			}
+			q.Return = append(q.Return, item)
+
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+
+	if p.accept("ORDER") {
+		if err := p.expect("BY"); err != nil {
+			return nil, err
+		}
+
+		for {
+			expr, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+
+			item := OrderItem{Expr: expr}
+			if p.accept("DESC") {
+				item.Desc = true
+			} else {
+				p.accept("ASC")
+			}
+			q.OrderBy = append(q.OrderBy, item)
+
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+
+	if p.accept("SKIP") {
+		skip, err := p.atom()
+		if err != nil {
+			return nil, err
+		}
+		q.Skip = skip
+	}
+
+	if p.accept("LIMIT") {
+		limit, err := p.atom()
+		if err != nil {
+			return nil, err
+		}
+		q.Limit = limit
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorf("unexpected %s", t)
+	}
+
+	return q, nil
+}
+
+func (p *parser) returnItem() (ReturnItem, error) {
+	expr, err := p.expr()
+	if err != nil {
+		return ReturnItem{}, err
+	}
+
+	item := ReturnItem{Expr: expr, Alias: expr.String()}
+	if p.accept("AS") {
+		alias, err := p.name("alias")
+		if err != nil {
+			return ReturnItem{}, err
+		}
+		item.Alias = alias
+	}
+
+	return item, nil
+}
+
+func (p *parser) pattern() (Pattern, error) {
+	pattern := Pattern{}
+
+	node, err := p.node()
+	if err != nil {
+		return pattern, err
+	}
+	pattern.Nodes = append(pattern.Nodes, node)
+
+	for p.is("-") || p.is("<") {
+		rel, err := p.rel()
+		if err != nil {
+			return pattern, err
+		}
+		pattern.Rels = append(pattern.Rels, rel)
+
+		node, err := p.node()
+		if err != nil {
+			return pattern, err
+		}
+		pattern.Nodes = append(pattern.Nodes, node)
+	}
+
+	return pattern, nil
+}
+
+func (p *parser) node() (NodePattern, error) {
+	node := NodePattern{Pos: p.peek().pos}
+
+	if err := p.expect("("); err != nil {
+		return node, err
+	}
+
+	if t := p.peek(); t.kind == tokenIdent {
+		node.Var = p.next().text
+	}
+
+	labels, err := p.labels()
+	if err != nil {
+		return node, err
+	}
+	node.Labels = labels
+
+	props, err := p.properties()
+	if err != nil {
+		return node, err
+	}
+	node.Properties = props
+
+	return node, p.expect(")")
+}
+
+// labels parses the optional `:a|b` labels, nodes and edges have a single label so `:a:b` is rejected.
+func (p *parser) labels() ([]string, error) {
+	if !p.accept(":") {
+		return nil, nil
+	}
+
+	labels := []string{}
+	for {
+		label, err := p.name("label")
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, label)
+
+		if p.is(":") {
+			return nil, p.errorf("items have a single label, use `:a|b` to match any of the labels")
+		}
+
+		if !p.accept("|") {
+			return labels, nil
+		}
+		// `:a|:b` is also accepted.
+		p.accept(":")
+	}
+}
+
+// properties parses the optional `{key: value, ...}` property map.
+func (p *parser) properties() (map[string]Expr, error) {
+	if !p.accept("{") {
+		return nil, nil
+	}
+
+	props := map[string]Expr{}
+	if p.accept("}") {
+		return props, nil
+	}
+
+	for {
+		key, err := p.name("property key")
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+
+		value, err := p.atom()
+		if err != nil {
+			return nil, err
+		}
+		props[key] = value
+
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	return props, p.expect("}")
+}
+
+func (p *parser) rel() (RelPattern, error) {
+	rel := RelPattern{Pos: p.peek().pos, Direction: "both"}
+
+	incoming := p.accept("<")
+	if err := p.expect("-"); err != nil {
+		return rel, err
+	}
+
+	if p.accept("[") {
+		if t := p.peek(); t.kind == tokenIdent {
+			rel.Var = p.next().text
+		}
+
+		labels, err := p.labels()
+		if err != nil {
+			return rel, err
+		}
+		rel.Labels = labels
+
+		if p.accept("*") {
+			if err := p.hops(&rel); err != nil {
+				return rel, err
+			}
+		}
+
+		props, err := p.properties()
+		if err != nil {
+			return rel, err
+		}
+		rel.Properties = props
+
+		if err := p.expect("]"); err != nil {
+			return rel, err
+		}
+	}
+
+	if err := p.expect("-"); err != nil {
+		return rel, err
+	}
+
+	outgoing := p.accept(">")
+
+	switch {
+	case incoming && outgoing:
+		return rel, &Error{Pos: rel.Pos, Msg: "a relationship can not point both ways, use `-[]-` to match either direction"}
+	case incoming:
+		rel.Direction = "in"
+	case outgoing:
+		rel.Direction = "out"
+	}
+
+	return rel, nil
+}
+
+// hops parses the `*`, `*n`, `*n..`, `*..m` and `*n..m` variable length hops.
+func (p *parser) hops(rel *RelPattern) error {
+	rel.VarLength = true
+	rel.Min = 1
+
+	number := func() (int, bool, error) {
+		t := p.peek()
+		if t.kind != tokenNumber {
+			return 0, false, nil
+		}
+		p.next()
+
+		n, err := strconv.Atoi(t.text)
+		if err != nil || n < 0 {
+			return 0, false, &Error{Pos: t.pos, Msg: fmt.Sprintf("invalid hop count %q", t.text)}
+		}
+		return n, true, nil
+	}
+
+	lower, hasLower, err := number()
+	if err != nil {
+		return err
+	}
+
+	if !p.accept("..") {
+		if hasLower {
+			rel.Min, rel.Max = lower, lower
+		}
+		return nil
+	}
+
+	if hasLower {
+		rel.Min = lower
+	}
+
+	upper, hasUpper, err := number()
+	if err != nil {
+		return err
+	}
+
+	if hasUpper {
+		if upper < rel.Min {
+			return &Error{Pos: rel.Pos, Msg: fmt.Sprintf("max hops %d is less than min hops %d", upper, rel.Min)}
+		}
+		rel.Max = upper
+	}
+
+	return nil
+}
+
+func (p *parser) expr() (Expr, error) {
+	return p.or()
+}
+
+func (p *parser) or() (Expr, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.is("OR") {
+		pos := p.next().pos
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = Binary{Op: "OR", Left: left, Right: right, Pos: pos}
+	}
+
+	return left, nil
+}
+
+func (p *parser) and() (Expr, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.is("AND") {
+		pos := p.next().pos
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		left = Binary{Op: "AND", Left: left, Right: right, Pos: pos}
+	}
+
+	return left, nil
+}
+
+func (p *parser) not() (Expr, error) {
+	if p.accept("NOT") {
+		expr, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return Unary{Op: "NOT", Expr: expr}, nil
+	}
+
+	return p.comparison()
+}
+
+func (p *parser) comparison() (Expr, error) {
+	left, err := p.atom()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+
+	switch {
+	case t.kind == tokenPunct && strings.Contains(" = <> != < <= > >= ", " "+t.text+" "):
+		p.next()
+
+		op := t.text
+		if op == "!=" {
+			op = "<>"
+		}
+
+		right, err := p.atom()
+		if err != nil {
+			return nil, err
+		}
+		return Binary{Op: op, Left: left, Right: right, Pos: t.pos}, nil
+
+	case p.is("IS"):
+		p.next()
+
+		op := "IS NULL"
+		if p.accept("NOT") {
+			op = "IS NOT NULL"
+		}
+
+		if err := p.expect("NULL"); err != nil {
+			return nil, err
+		}
+		return Unary{Op: op, Expr: left}, nil
+
+	case p.is("IN") || p.is("CONTAINS"):
+		p.next()
+
+		right, err := p.atom()
+		if err != nil {
+			return nil, err
+		}
+		return Binary{Op: t.text, Left: left, Right: right, Pos: t.pos}, nil
+
+	case p.is("STARTS") || p.is("ENDS"):
+		p.next()
+
+		if err := p.expect("WITH"); err != nil {
+			return nil, err
+		}
+
+		right, err := p.atom()
+		if err != nil {
+			return nil, err
+		}
+		return Binary{Op: t.text + " WITH", Left: left, Right: right, Pos: t.pos}, nil
+	}
+
+	return left, nil
+}
+
+func (p *parser) atom() (Expr, error) {
+	t := p.peek()
+
+	switch t.kind {
+	case tokenString:
+		p.next()
+		return Literal{Value: t.text}, nil
+
+	case tokenNumber:
+		p.next()
+		return number(t)
+
+	case tokenParam:
+		p.next()
+		return Param{Name: t.text, Pos: t.pos}, nil
+
+	case tokenKeyword:
+		switch t.text {
+		case "TRUE":
+			p.next()
+			return Literal{Value: true}, nil
+		case "FALSE":
+			p.next()
+			return Literal{Value: false}, nil
+		case "NULL":
+			p.next()
+			return Literal{Value: nil}, nil
+		}
+
+	case tokenIdent:
+		p.next()
+
+		if p.accept("(") {
+			arg, err := p.name("variable")
+			if err != nil {
+				return nil, err
+			}
+
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+
+			return Call{Func: strings.ToLower(t.text), Arg: Variable{Name: arg, Pos: t.pos}, Pos: t.pos}, nil
+		}
+
+		v := Variable{Name: t.text, Pos: t.pos}
+		if !p.is(".") {
+			return v, nil
+		}
+
+		prop := Property{Var: v}
+		for p.accept(".") {
+			key, err := p.name("property key")
+			if err != nil {
+				return nil, err
+			}
+			prop.Keys = append(prop.Keys, key)
+		}
+		return prop, nil
+
+	case tokenPunct:
+		switch t.text {
+		case "-":
+			p.next()
+			if n := p.peek(); n.kind == tokenNumber {
+				p.next()
+				n.text = "-" + n.text
+				return number(n)
+			}
+			return nil, p.errorf("expected a number after -, got %s", p.peek())
+
+		case "(":
+			p.next()
+			expr, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			return expr, p.expect(")")
+
+		case "[":
+			p.next()
+			list := List{Items: []Expr{}}
+			if p.accept("]") {
+				return list, nil
+			}
+
+			for {
+				item, err := p.atom()
+				if err != nil {
+					return nil, err
+				}
+				list.Items = append(list.Items, item)
+
+				if !p.accept(",") {
+					break
+				}
+			}
+			return list, p.expect("]")
+		}
+	}
+
+	return nil, p.errorf("unexpected %s", t)
+}
+
+// number converts a number token into an int64 or float64 literal.
+func number(t token) (Expr, error) {
+	if i, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+		return Literal{Value: i}, nil
+	}
+
+	f, err := strconv.ParseFloat(t.text, 64)
+	if err != nil {
+		return nil, &Error{Pos: t.pos, Msg: fmt.Sprintf("invalid number %q", t.text)}
+	}
+	return Literal{Value: f}, nil
+}
@@ -0,0 +1,222 @@
+package query_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenmud/edgedb/internal/query"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string // description of this test case
+		src     string
+		want    *query.Query
+		wantErr bool
+	}{
+		{
+			name: "variable length relationship",
+			src:  "MATCH (a:Person)-[r:KNOWS*1..3]->(b) WHERE a.name = $name RETURN a, r, b LIMIT 10",
+			want: &query.Query{
+				Patterns: []query.Pattern{
+					{
+						Nodes: []query.NodePattern{
+							{Var: "a", Labels: []string{"Person"}, Pos: 6},
+							{Var: "b", Pos: 33},
+						},
+						Rels: []query.RelPattern{
+							{Var: "r", Labels: []string{"KNOWS"}, Direction: "out", VarLength: true, Min: 1, Max: 3, Pos: 16},
+						},
+					},
+				},
+				Where: query.Binary{
+					Op:    "=",
+					Left:  query.Property{Var: query.Variable{Name: "a", Pos: 43}, Keys: []string{"name"}},
+					Right: query.Param{Name: "name", Pos: 52},
+					Pos:   50,
+				},
+				Return: []query.ReturnItem{
+					{Expr: query.Variable{Name: "a", Pos: 65}, Alias: "a"},
+					{Expr: query.Variable{Name: "r", Pos: 68}, Alias: "r"},
+					{Expr: query.Variable{Name: "b", Pos: 71}, Alias: "b"},
+				},
+				Limit: query.Literal{Value: int64(10)},
+			},
+		},
+		{
+			name: "incoming, label alternatives and properties",
+			src:  "match (a)<-[:x|y {w: 1.5}]-(:b) return distinct a.meta.hair as hair order by hair desc skip 2",
+			want: &query.Query{
+				Patterns: []query.Pattern{
+					{
+						Nodes: []query.NodePattern{
+							{Var: "a", Pos: 6},
+							{Labels: []string{"b"}, Pos: 27},
+						},
+						Rels: []query.RelPattern{
+							{Labels: []string{"x", "y"}, Properties: map[string]query.Expr{"w": query.Literal{Value: 1.5}}, Direction: "in", Pos: 9},
+						},
+					},
+				},
+				Distinct: true,
+				Return: []query.ReturnItem{
+					{Expr: query.Property{Var: query.Variable{Name: "a", Pos: 48}, Keys: []string{"meta", "hair"}}, Alias: "hair"},
+				},
+				OrderBy: []query.OrderItem{{Expr: query.Variable{Name: "hair", Pos: 77}, Desc: true}},
+				Skip:    query.Literal{Value: int64(2)},
+			},
+		},
+		{
+			name: "unbounded hops and precedence",
+			src:  "MATCH (a)-[*]-(b) WHERE NOT a.x IS NULL OR a.y IN [1, -2] AND b.z CONTAINS 'q' RETURN *",
+			want: &query.Query{
+				Patterns: []query.Pattern{
+					{
+						Nodes: []query.NodePattern{{Var: "a", Pos: 6}, {Var: "b", Pos: 14}},
+						Rels:  []query.RelPattern{{Direction: "both", VarLength: true, Min: 1, Pos: 9}},
+					},
+				},
+				Where: query.Binary{
+					Op:   "OR",
+					Left: query.Unary{Op: "NOT", Expr: query.Unary{Op: "IS NULL", Expr: query.Property{Var: query.Variable{Name: "a", Pos: 28}, Keys: []string{"x"}}}},
+					Right: query.Binary{
+						Op: "AND",
+						Left: query.Binary{
+							Op:    "IN",
+							Left:  query.Property{Var: query.Variable{Name: "a", Pos: 43}, Keys: []string{"y"}},
+							Right: query.List{Items: []query.Expr{query.Literal{Value: int64(1)}, query.Literal{Value: int64(-2)}}},
+							Pos:   47,
+						},
+						Right: query.Binary{
+							Op:    "CONTAINS",
+							Left:  query.Property{Var: query.Variable{Name: "b", Pos: 62}, Keys: []string{"z"}},
+							Right: query.Literal{Value: "q"},
+							Pos:   66,
+						},
+						Pos: 58,
+					},
+					Pos: 40,
+				},
+			},
+		},
+		{
+			name:    "write clauses are rejected",
+			src:     "MATCH (a) SET a.x = 1 RETURN a",
+			wantErr: true,
+		},
+		{
+			name:    "multiple labels",
+			src:     "MATCH (a:x:y) RETURN a",
+			wantErr: true,
+		},
+		{
+			name:    "both directions",
+			src:     "MATCH (a)<-->(b) RETURN a",
+			wantErr: true,
+		},
+		{
+			name:    "missing return",
+			src:     "MATCH (a)",
+			wantErr: true,
+		},
+		{
+			name:    "max less than min",
+			src:     "MATCH (a)-[*3..1]->(b) RETURN a",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotErr := query.Parse(tt.src)
+			if gotErr != nil {
+				if !tt.wantErr {
+					t.Errorf("Parse() failed: %v", gotErr)
+				}
+				return
+			}
+
+			if tt.wantErr {
+				t.Fatal("Parse() succeeded unexpectedly")
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Parse() = mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name    string // description of this test case
+		src     string
+		params  map[string]any
+		want    []query.Column
+		wantErr bool
+	}{
+		{
+			name: "columns",
+			src:  "MATCH (a)-[r]->(b)-[p*]->(c) RETURN a, r, p, c.name, id(c)",
+			want: []query.Column{
+				{Name: "a", Kind: query.ColumnNode},
+				{Name: "r", Kind: query.ColumnEdge},
+				{Name: "p", Kind: query.ColumnEdges},
+				{Name: "c.name", Kind: query.ColumnValue},
+				{Name: "id(c)", Kind: query.ColumnValue},
+			},
+		},
+		{
+			name:    "relationship variable reused",
+			src:     "MATCH (a)-[r]->(b)-[r]->(c) RETURN a",
+			wantErr: true,
+		},
+		{
+			name:    "too many hops",
+			src:     "MATCH (a)-[*..11]->(b) RETURN b",
+			wantErr: true,
+		},
+		{
+			name:    "properties of variable length relationships",
+			src:     "MATCH (a)-[r*]->(b) RETURN r.since",
+			wantErr: true,
+		},
+		{
+			name:    "IN parameter must be a list",
+			src:     "MATCH (a) WHERE a.name IN $names RETURN a",
+			params:  map[string]any{"names": "foo"},
+			wantErr: true,
+		},
+		{
+			name:    "negative limit",
+			src:     "MATCH (a) RETURN a LIMIT $n",
+			params:  map[string]any{"n": -1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.Parse(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, gotErr := query.Compile(q, tt.params)
+			if gotErr != nil {
+				if !tt.wantErr {
+					t.Errorf("Compile() failed: %v", gotErr)
+				}
+				return
+			}
+
+			if tt.wantErr {
+				t.Fatal("Compile() succeeded unexpectedly")
+			}
+
+			if diff := cmp.Diff(tt.want, got.Columns); diff != "" {
+				t.Errorf("Compile() = mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jenmud/edgedb/models"
+)
+
+const (
+	// APIKeyPrefix starts every API key so that they are easy to recognise, eg: by secret scanners.
+	APIKeyPrefix = "edb_"
+
+	// APIKeyUsedInterval is how often the last use of an API key is recorded, the uses in between are not written.
+	APIKeyUsedInterval = time.Minute
+)
+
+// ValidateAPIKey returns ErrInvalid if the API key has no name, an unknown role or a key, and generates its key.
+// Keys are always generated so that every key is random, a key chosen by a client could be guessed.
+func ValidateAPIKey(k *models.APIKey) error {
+	if strings.TrimSpace(k.Name) == "" {
+		return fmt.Errorf("%w: api key name is required", ErrInvalid)
+	}
+
+	if !k.Role.Valid() {
+		return fmt.Errorf("%w: unknown api key role %q, expected one of reader, writer or admin", ErrInvalid, k.Role)
+	}
+
+	if k.Key != "" {
+		return fmt.Errorf("%w: api keys are generated, a key can not be given", ErrInvalid)
+	}
+
+	secret := make([]byte, 32)
+	rand.Read(secret)
+
+	k.Key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	k.Prefix = k.Key[:len(APIKeyPrefix)+6]
+	return nil
+}
+
+// HashAPIKey returns the hash of the key which is stored instead of the key.
+// The keys are 32 random bytes generated by ValidateAPIKey so a fast hash is enough, unlike passwords they can not be
+// guessed.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
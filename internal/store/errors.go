@@ -0,0 +1,68 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jenmud/edgedb/models"
+)
+
+var (
+	// ErrNotFound is returned when the requested item does not exist in the store.
+	ErrNotFound = errors.New("not found")
+
+	// ErrConflict is returned when a write can not be applied because of the current state of the store.
+	ErrConflict = errors.New("conflict")
+
+	// ErrInvalid is returned when the arguments or items given to the store are not valid.
+	ErrInvalid = errors.New("invalid")
+)
+
+// RestrictError is returned when deleting nodes with the DeleteRestrict policy and
+// there are still edges attached to one or more of the nodes.
+type RestrictError struct {
+	// Edges are the edges blocking the delete.
+	Edges []models.Edge `json:"edges"`
+}
+
+// Error implements the error interface.
+func (e *RestrictError) Error() string {
+	return fmt.Sprintf("%s: %d edge(s) still attached to the node(s)", ErrConflict, len(e.Edges))
+}
+
+// Unwrap allows errors.Is(err, ErrConflict) to match.
+func (e *RestrictError) Unwrap() error {
+	return ErrConflict
+}
+
+// VersionError is returned when a write expects a version of a node or edge which is not the current version.
+type VersionError struct {
+	// ID is the ID of the node or edge.
+	ID uint64 `json:"id"`
+
+	// Expected is the version the write expected.
+	Expected uint64 `json:"expected"`
+
+	// Node is the current node, it is nil if the item is an edge or does not exist.
+	Node *models.Node `json:"node,omitempty"`
+
+	// Edge is the current edge, it is nil if the item is a node or does not exist.
+	Edge *models.Edge `json:"edge,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *VersionError) Error() string {
+	switch {
+	case e.Node != nil:
+		return fmt.Sprintf("%s: node %d is at version %d, expected version %d", ErrConflict, e.ID, e.Node.Version, e.Expected)
+	case e.Edge != nil:
+		return fmt.Sprintf("%s: edge %d is at version %d, expected version %d", ErrConflict, e.ID, e.Edge.Version, e.Expected)
+	}
+
+	return fmt.Sprintf("%s: item %d does not exist, expected version %d", ErrConflict, e.ID, e.Expected)
+}
+
+// Unwrap allows errors.Is(err, ErrConflict) to match.
+func (e *VersionError) Unwrap() error {
+	return ErrConflict
+}
@@ -0,0 +1,282 @@
+package store
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Operator is a comparison operator used by a predicate.
+type Operator string
+
+const (
+	// OpEq matches properties equal to the value.
+	OpEq Operator = "eq"
+
+	// OpNe matches properties not equal to the value, missing properties are not matched.
+	OpNe Operator = "ne"
+
+	// OpGt matches properties greater than the value.
+	OpGt Operator = "gt"
+
+	// OpGte matches properties greater than or equal to the value.
+	OpGte Operator = "gte"
+
+	// OpLt matches properties less than the value.
+	OpLt Operator = "lt"
+
+	// OpLte matches properties less than or equal to the value.
+	OpLte Operator = "lte"
+
+	// OpIn matches properties equal to any of the values, the value must be a []any.
+	OpIn Operator = "in"
+
+	// OpExists matches items which have the property, the value is ignored.
+	OpExists Operator = "exists"
+
+	// OpNotExists matches items which do not have the property, the value is ignored.
+	OpNotExists Operator = "nexists"
+)
+
+// propertyPath is a dotted property path where each key contains letters, digits, `_` or `-`.
+var propertyPath = regexp.MustCompile(`^[A-Za-z0-9_\-]+(\.[A-Za-z0-9_\-]+)*$`)
+
+// Predicate is a condition on a property.
+type Predicate struct {
+	// Path is the dotted path to the property, eg: `age` or `meta.hair`. The `properties.` prefix is optional.
+	Path string
+
+	// Op is the comparison operator.
+	Op Operator
+
+	// Value is the value compared against, it is a []any for OpIn.
+	Value any
+}
+
+// Keys returns the property keys making up the path with the optional `properties.` prefix removed.
+func (p Predicate) Keys() []string {
+	return strings.Split(strings.TrimPrefix(p.Path, "properties."), ".")
+}
+
+// Validate returns an error if the predicate path or operator is not supported.
+func (p Predicate) Validate() error {
+	if !propertyPath.MatchString(strings.TrimPrefix(p.Path, "properties.")) {
+		return fmt.Errorf("invalid property path %q: keys may only contain letters, digits, `_` and `-`", p.Path)
+	}
+
+	switch p.Op {
+	case OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpExists, OpNotExists:
+	case OpIn:
+		if _, ok := p.Value.([]any); !ok {
+			return fmt.Errorf("operator %s expects a list of values, got %T", p.Op, p.Value)
+		}
+	default:
+		return fmt.Errorf("unsupported operator: %s", p.Op)
+	}
+
+	return nil
+}
+
+// TimeRange is a time range, a zero After or Before is unbounded.
+type TimeRange struct {
+	// After includes times after or equal to After.
+	After time.Time
+
+	// Before includes times before Before.
+	Before time.Time
+}
+
+// Filter is a structured filter applied when listing nodes or edges, all the conditions must match.
+type Filter struct {
+	// Labels matches items with any of the labels.
+	Labels []string
+
+	// Where are the property predicates.
+	Where []Predicate
+
+	// CreatedAt limits the items to those created in the range.
+	CreatedAt TimeRange
+
+	// UpdatedAt limits the items to those updated in the range.
+	UpdatedAt TimeRange
+}
+
+// IsZero returns true if the filter has no conditions.
+func (f Filter) IsZero() bool {
+	return len(f.Labels) == 0 &&
+		len(f.Where) == 0 &&
+		f.CreatedAt == (TimeRange{}) &&
+		f.UpdatedAt == (TimeRange{})
+}
+
+// Validate returns an error if any of the predicates are not supported.
+func (f Filter) Validate() error {
+	for _, p := range f.Where {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookup returns the property at the path and true if it exists.
+func (p Predicate) lookup(props map[string]any) (any, bool) {
+	keys := p.Keys()
+
+	var current any = props
+	for _, k := range keys {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		current, ok = m[k]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// comparable returns the value as a float64 for numbers and booleans or a string for everything else,
+// lists and maps are compared as their JSON text.
+func comparable(v any) any {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return float64(1)
+		}
+		return float64(0)
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case int:
+		return float64(v)
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case string:
+		return v
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	}
+}
+
+// compare compares two comparable values, numbers sort before strings like they do in SQL.
+func compare(a, b any) int {
+	switch a := a.(type) {
+	case float64:
+		if b, ok := b.(float64); ok {
+			return cmp.Compare(a, b)
+		}
+		return -1
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b)
+		}
+		return 1
+	}
+	return 0
+}
+
+// Match returns true if the properties match the predicate. It follows the same rules backends use when comparing
+// JSON properties, missing and null properties only match OpNotExists (null properties do exist), booleans compare as
+// 1 and 0 and numbers sort before strings.
+func (p Predicate) Match(props map[string]any) bool {
+	v, ok := p.lookup(props)
+
+	switch p.Op {
+	case OpExists:
+		return ok
+	case OpNotExists:
+		return !ok
+	}
+
+	if !ok || v == nil || p.Value == nil {
+		return false
+	}
+
+	got := comparable(v)
+
+	switch p.Op {
+	case OpEq:
+		return compare(got, comparable(p.Value)) == 0
+	case OpNe:
+		return compare(got, comparable(p.Value)) != 0
+	case OpGt:
+		return compare(got, comparable(p.Value)) > 0
+	case OpGte:
+		return compare(got, comparable(p.Value)) >= 0
+	case OpLt:
+		return compare(got, comparable(p.Value)) < 0
+	case OpLte:
+		return compare(got, comparable(p.Value)) <= 0
+	case OpIn:
+		values, _ := p.Value.([]any)
+		for _, value := range values {
+			if value == nil {
+				continue
+			}
+
+			if compare(got, comparable(value)) == 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// inRange returns true if the time is within the range, times are compared to the second.
+func (r TimeRange) inRange(t time.Time) bool {
+	if !r.After.IsZero() && t.Unix() < r.After.Unix() {
+		return false
+	}
+
+	if !r.Before.IsZero() && t.Unix() >= r.Before.Unix() {
+		return false
+	}
+
+	return true
+}
+
+// Match returns true if the item label, properties and timestamps match all the filter conditions.
+// Backends which can not push the filter down to a query can use it to filter items in memory.
+func (f Filter) Match(label string, props map[string]any, createdAt, updatedAt time.Time) bool {
+	if len(f.Labels) > 0 && !slices.Contains(f.Labels, label) {
+		return false
+	}
+
+	for _, p := range f.Where {
+		if !p.Match(props) {
+			return false
+		}
+	}
+
+	return f.CreatedAt.inRange(createdAt) && f.UpdatedAt.inRange(updatedAt)
+}
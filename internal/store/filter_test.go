@@ -0,0 +1,45 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/jenmud/edgedb/internal/store"
+)
+
+func TestPredicate_Match(t *testing.T) {
+	props := map[string]any{
+		"name":  "foo",
+		"age":   float64(21),
+		"alive": true,
+		"none":  nil,
+		"meta":  map[string]any{"hair": "brown"},
+	}
+
+	tests := []struct {
+		name string // description of this test case
+		p    store.Predicate
+		want bool
+	}{
+		{name: "eq string", p: store.Predicate{Path: "name", Op: store.OpEq, Value: "foo"}, want: true},
+		{name: "eq int and float", p: store.Predicate{Path: "age", Op: store.OpEq, Value: 21}, want: true},
+		{name: "ne", p: store.Predicate{Path: "name", Op: store.OpNe, Value: "bar"}, want: true},
+		{name: "ne missing", p: store.Predicate{Path: "colour", Op: store.OpNe, Value: "bar"}, want: false},
+		{name: "gt", p: store.Predicate{Path: "age", Op: store.OpGt, Value: 20}, want: true},
+		{name: "lte", p: store.Predicate{Path: "age", Op: store.OpLte, Value: 20}, want: false},
+		{name: "numbers before strings", p: store.Predicate{Path: "age", Op: store.OpLt, Value: "a"}, want: true},
+		{name: "bool", p: store.Predicate{Path: "alive", Op: store.OpEq, Value: 1}, want: true},
+		{name: "nested", p: store.Predicate{Path: "properties.meta.hair", Op: store.OpEq, Value: "brown"}, want: true},
+		{name: "in", p: store.Predicate{Path: "name", Op: store.OpIn, Value: []any{"bar", "foo"}}, want: true},
+		{name: "exists null", p: store.Predicate{Path: "none", Op: store.OpExists}, want: true},
+		{name: "eq null", p: store.Predicate{Path: "none", Op: store.OpEq, Value: nil}, want: false},
+		{name: "not exists", p: store.Predicate{Path: "meta.eyes", Op: store.OpNotExists}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.Match(props); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
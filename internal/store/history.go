@@ -0,0 +1,96 @@
+package store
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/jenmud/edgedb/models"
+)
+
+// withProperties adds the properties to the fields, empty properties are left out so they match missing properties.
+func withProperties(fields map[string]any, props models.Properties) map[string]any {
+	if len(props) > 0 {
+		fields["properties"] = map[string]any(props)
+	}
+
+	return fields
+}
+
+// nodeFields returns the fields of the node compared by DiffNodes.
+func nodeFields(n *models.Node) map[string]any {
+	if n == nil {
+		return map[string]any{}
+	}
+
+	return withProperties(map[string]any{"label": n.Label}, n.Properties)
+}
+
+// edgeFields returns the fields of the edge compared by DiffEdges.
+func edgeFields(e *models.Edge) map[string]any {
+	if e == nil {
+		return map[string]any{}
+	}
+
+	fields := map[string]any{"from_id": e.From, "label": e.Label, "to_id": e.To, "weight": e.Weight}
+	return withProperties(fields, e.Properties)
+}
+
+// DiffNodes returns the differences between two revisions of a node ordered by path,
+// before is nil for a created node and after is nil for a deleted node.
+func DiffNodes(before, after *models.Node) []models.PropertyDiff {
+	return diff("", nodeFields(before), nodeFields(after), []models.PropertyDiff{})
+}
+
+// DiffEdges returns the differences between two revisions of an edge ordered by path,
+// before is nil for a created edge and after is nil for a deleted edge.
+func DiffEdges(before, after *models.Edge) []models.PropertyDiff {
+	return diff("", edgeFields(before), edgeFields(after), []models.PropertyDiff{})
+}
+
+// diff appends the differences between the maps to diffs. Nested maps are compared field by field, a map which was
+// added or removed is reported as each of its fields being added or removed.
+func diff(path string, before, after map[string]any, diffs []models.PropertyDiff) []models.PropertyDiff {
+	keys := make([]string, 0, len(before)+len(after))
+
+	for k := range before {
+		keys = append(keys, k)
+	}
+
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		p := k
+		if path != "" {
+			p = path + "." + k
+		}
+
+		b, inBefore := before[k]
+		a, inAfter := after[k]
+
+		bm, bIsMap := b.(map[string]any)
+		am, aIsMap := a.(map[string]any)
+
+		switch {
+		case bIsMap && aIsMap:
+			diffs = diff(p, bm, am, diffs)
+		case !inBefore && aIsMap && len(am) > 0:
+			diffs = diff(p, map[string]any{}, am, diffs)
+		case !inAfter && bIsMap && len(bm) > 0:
+			diffs = diff(p, bm, map[string]any{}, diffs)
+		case !inBefore:
+			diffs = append(diffs, models.PropertyDiff{Path: p, Op: models.DiffAdded, After: a})
+		case !inAfter:
+			diffs = append(diffs, models.PropertyDiff{Path: p, Op: models.DiffRemoved, Before: b})
+		case !reflect.DeepEqual(b, a):
+			diffs = append(diffs, models.PropertyDiff{Path: p, Op: models.DiffChanged, Before: b, After: a})
+		}
+	}
+
+	return diffs
+}
@@ -0,0 +1,81 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+func TestDiffNodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		before *models.Node
+		after  *models.Node
+		want   []models.PropertyDiff
+	}{
+		{
+			name:  "created",
+			after: &models.Node{Label: "person", Properties: models.Properties{"name": "foo", "meta": map[string]any{"hair": "red"}}},
+			want: []models.PropertyDiff{
+				{Path: "label", Op: models.DiffAdded, After: "person"},
+				{Path: "properties.meta.hair", Op: models.DiffAdded, After: "red"},
+				{Path: "properties.name", Op: models.DiffAdded, After: "foo"},
+			},
+		},
+		{
+			name:   "deleted",
+			before: &models.Node{Label: "person"},
+			want:   []models.PropertyDiff{{Path: "label", Op: models.DiffRemoved, Before: "person"}},
+		},
+		{
+			name:   "updated",
+			before: &models.Node{Label: "person", Properties: models.Properties{"name": "foo", "age": float64(1), "meta": map[string]any{"hair": "red"}}},
+			after:  &models.Node{Label: "human", Properties: models.Properties{"name": "foo", "tags": []any{"a"}, "meta": map[string]any{"hair": "blue"}}},
+			want: []models.PropertyDiff{
+				{Path: "label", Op: models.DiffChanged, Before: "person", After: "human"},
+				{Path: "properties.age", Op: models.DiffRemoved, Before: float64(1)},
+				{Path: "properties.meta.hair", Op: models.DiffChanged, Before: "red", After: "blue"},
+				{Path: "properties.tags", Op: models.DiffAdded, After: []any{"a"}},
+			},
+		},
+		{
+			name:   "empty properties match missing properties",
+			before: &models.Node{Label: "person", Properties: models.Properties{}},
+			after:  &models.Node{Label: "person"},
+			want:   []models.PropertyDiff{},
+		},
+		{
+			name:   "property changed to an object",
+			before: &models.Node{Label: "person", Properties: models.Properties{"meta": "none"}},
+			after:  &models.Node{Label: "person", Properties: models.Properties{"meta": map[string]any{"hair": "red"}}},
+			want: []models.PropertyDiff{
+				{Path: "properties.meta", Op: models.DiffChanged, Before: "none", After: map[string]any{"hair": "red"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := store.DiffNodes(tt.before, tt.after)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("DiffNodes() = mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDiffEdges(t *testing.T) {
+	before := &models.Edge{From: 1, Label: "knows", To: 2, Weight: 1}
+	after := &models.Edge{From: 1, Label: "knows", To: 3, Weight: 1, Properties: models.Properties{"since": float64(2020)}}
+
+	want := []models.PropertyDiff{
+		{Path: "properties.since", Op: models.DiffAdded, After: float64(2020)},
+		{Path: "to_id", Op: models.DiffChanged, Before: uint64(2), After: uint64(3)},
+	}
+
+	if diff := cmp.Diff(want, store.DiffEdges(before, after)); diff != "" {
+		t.Errorf("DiffEdges() = mismatch (-want, +got): \n%s", diff)
+	}
+}
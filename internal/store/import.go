@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"iter"
+
+	"github.com/jenmud/edgedb/models"
+)
+
+// DefaultImportChunkSize is the number of items written per transaction when importing.
+const DefaultImportChunkSize = 1000
+
+// ImportItem is a node or edge read from a single line of an import.
+type ImportItem struct {
+	// Line is the line number the item was read from, used when reporting errors.
+	Line int
+
+	// Node is set when the line is a node.
+	Node *models.Node
+
+	// Edge is set when the line is an edge, edges can use the refs of nodes imported on earlier lines.
+	Edge *models.Edge
+
+	// Err is set when the line could not be read, the error is reported and the line is skipped.
+	Err error
+}
+
+// ImportProgress is the progress of an import.
+type ImportProgress struct {
+	// Line is the last line committed.
+	Line int `json:"line"`
+
+	// Nodes is the number of nodes imported.
+	Nodes int `json:"nodes"`
+
+	// Edges is the number of edges imported.
+	Edges int `json:"edges"`
+
+	// Errors is the number of lines which were skipped because of errors.
+	Errors int `json:"errors"`
+}
+
+// ImportArgs are the arguments used when importing.
+type ImportArgs struct {
+	// ChunkSize is the number of items written per transaction, defaults to DefaultImportChunkSize.
+	ChunkSize int
+
+	// OnError is called for every line which could not be imported, the import carries on with the next line.
+	OnError func(line int, err error)
+
+	// OnProgress is called after every chunk is committed.
+	OnProgress func(ImportProgress)
+}
+
+// Importer is implemented by stores which can stream large imports in bounded memory.
+type Importer interface {
+	// Import writes the items in chunked transactions returning the final progress.
+	// Only the refs of imported nodes are kept in memory between chunks.
+	Import(context.Context, iter.Seq[ImportItem], ImportArgs) (ImportProgress, error)
+}
@@ -0,0 +1,21 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenmud/edgedb/models"
+)
+
+// ValidateIndex returns ErrInvalid if the property index can not be used.
+// The optional `properties.` prefix is removed from the path.
+func ValidateIndex(idx *models.PropertyIndex) error {
+	idx.Path = strings.TrimPrefix(idx.Path, "properties.")
+
+	p := Predicate{Path: idx.Path, Op: OpEq}
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalid, err)
+	}
+
+	return nil
+}
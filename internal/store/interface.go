@@ -2,14 +2,57 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/jenmud/edgedb/models"
 )
 
+// DeletePolicy defines what happens to the edges attached to a node being deleted.
+type DeletePolicy string
+
+const (
+	// DeleteRestrict refuses to delete a node which still has edges attached, returning a *RestrictError.
+	DeleteRestrict DeletePolicy = "restrict"
+
+	// DeleteCascade deletes the node and all the edges attached to it, the edges are reported as deleted edges.
+	DeleteCascade DeletePolicy = "cascade"
+
+	// DeleteDetach detaches the edges attached to the node before deleting it, the edges are reported as detached edges.
+	DeleteDetach DeletePolicy = "detach"
+)
+
+// DeleteNodesArgs are the arguments used for deleting nodes.
+type DeleteNodesArgs struct {
+	// IDs are the node IDs to delete.
+	IDs []uint64
+
+	// Policy is the policy applied to edges attached to the nodes, defaults to DeleteRestrict.
+	Policy DeletePolicy
+}
+
+// DeleteResult reports exactly what was removed from the store.
+type DeleteResult struct {
+	// Nodes are the deleted nodes.
+	Nodes []models.Node `json:"nodes"`
+
+	// Edges are the deleted edges.
+	Edges []models.Edge `json:"edges"`
+
+	// Detached are the edges which were removed because they were attached to a deleted node (DeleteDetach only).
+	Detached []models.Edge `json:"detached"`
+}
+
 // NodeWriter defines the behavior required to modify the node store.
 type NodeWriter interface {
 	// UpsertNodes inserts or updates one or more nodes.
 	UpsertNodes(context.Context, ...models.Node) ([]models.Node, error)
+
+	// MergeNodes inserts or updates one or more nodes matching existing nodes on the natural key of their label
+	// instead of their ID, returning ErrInvalid if a label has no natural key or a node has no key value.
+	MergeNodes(context.Context, ...models.Node) ([]MergedNode, error)
+
+	// DeleteNodes deletes one or more nodes applying the delete policy to the attached edges.
+	DeleteNodes(context.Context, DeleteNodesArgs) (DeleteResult, error)
 }
 
 // TermSearchArgs are arguments used for search term queries.
@@ -40,6 +83,12 @@ type NodesArgs struct {
 
 	// LastID is the last know primary key/ID which will be used for fast pagination.
 	LastID uint64
+
+	// IDs limits the nodes to those with the IDs.
+	IDs []uint64
+
+	// Filter limits the nodes to those matching the labels, properties and time ranges.
+	Filter Filter
 }
 
 // NodeSearcher defines the behavior required to search for nodes in the store..
@@ -63,6 +112,13 @@ type NodeStore interface {
 type EdgeWriter interface {
 	// UpsertEdges inserts or updates one or more edges.
 	UpsertEdges(context.Context, ...models.Edge) ([]models.Edge, error)
+
+	// MergeEdges inserts or updates one or more edges matching existing edges on their from ID, label and to ID
+	// instead of their ID, the oldest edge is updated if several edges match.
+	MergeEdges(context.Context, ...models.Edge) ([]MergedEdge, error)
+
+	// DeleteEdges deletes one or more edges returning the edges deleted.
+	DeleteEdges(context.Context, ...uint64) ([]models.Edge, error)
 }
 
 // EdgesArgs are the search arguments for edges in the store.
@@ -72,6 +128,15 @@ type EdgesArgs struct {
 
 	// LastID is the last know primary key/ID which will be used for fast pagination.
 	LastID uint64
+
+	// FromIDs limits the edges to those starting at any of the node IDs.
+	FromIDs []uint64
+
+	// ToIDs limits the edges to those ending at any of the node IDs.
+	ToIDs []uint64
+
+	// Filter limits the edges to those matching the labels, properties and time ranges.
+	Filter Filter
 }
 
 // EdgeSearcher defines the behavior required to search for edges in the store..
@@ -91,6 +156,23 @@ type EdgeStore interface {
 	Close() error
 }
 
+// Direction is the direction edges are followed when walking the graph.
+type Direction string
+
+const (
+	// DirectionOut follows edges from the `from` node to the `to` node.
+	DirectionOut Direction = "out"
+
+	// DirectionIn follows edges from the `to` node back to the `from` node.
+	DirectionIn Direction = "in"
+
+	// DirectionBoth follows edges in both directions.
+	DirectionBoth Direction = "both"
+)
+
+// MaxSubGraphDepth is the max number of hops a sub graph is expanded, deeper requests are clamped to it.
+const MaxSubGraphDepth int = 100
+
 // SubGraphArgs are the arguments for building a sub graph.
 type SubGraphArgs struct {
 	// FromNodeID is the node ID to start building the sub graph from.
@@ -99,22 +181,294 @@ type SubGraphArgs struct {
 	// ToNodeID is the node ID to start building the sub graph from.
 	ToNodeID uint64
 
-	// EdgeID is the edge ID to start building the sub graph from.
+	// EdgeID is the edge ID to start building the sub graph from, both the edge's nodes are used as starting points.
 	EdgeID uint64
 
-	// Limit is the max number of items to return.
+	// Depth is how many hops to expand from the starting points, defaults to 1 and is clamped to MaxSubGraphDepth.
+	Depth int
+
+	// Direction is the direction the edges are followed, defaults to DirectionBoth.
+	Direction Direction
+
+	// EdgeLabels is an allow-list of edge labels to follow, all edges are followed if empty.
+	EdgeLabels []string
+
+	// Limit is the node budget, the max number of nodes to return, closest nodes first. Defaults to 1000 if 0.
 	Limit int
 
 	// LastID is the last know primary key/ID which will be used for fast pagination.
 	LastID uint64
 }
 
+// PathMode is the algorithm used when finding paths.
+type PathMode string
+
+const (
+	// PathShortest finds the paths with the fewest hops (breadth first).
+	PathShortest PathMode = "shortest"
+
+	// PathWeighted finds the paths with the lowest total edge weight (Dijkstra), weights must not be negative.
+	PathWeighted PathMode = "weighted"
+)
+
+// PathArgs are the arguments used for finding paths between two nodes.
+type PathArgs struct {
+	// From is the node ID the paths start from.
+	From uint64
+
+	// To is the node ID the paths end at.
+	To uint64
+
+	// Mode is the path finding algorithm, defaults to PathShortest.
+	Mode PathMode
+
+	// MaxDepth is the max number of hops in a path, defaults to DefaultMaxDepth and is clamped to MaxDepth.
+	MaxDepth int
+
+	// Direction is the direction the edges are followed, defaults to DirectionOut.
+	Direction Direction
+
+	// EdgeLabels is an allow-list of edge labels to follow, all edges are followed if empty.
+	EdgeLabels []string
+
+	// K is the number of shortest paths to return, shortest first. Defaults to 1 and is clamped to MaxK.
+	K int
+}
+
+// PathFinder defines the behavior required to find paths between nodes.
+type PathFinder interface {
+	// Paths returns up to K paths between two nodes, returning ErrNotFound if there is no path.
+	Paths(context.Context, PathArgs) ([]models.Path, error)
+}
+
+// UpsertGraphResult is the result of upserting a graph.
+type UpsertGraphResult struct {
+	// Nodes are the upserted nodes in the order given.
+	Nodes []models.Node `json:"nodes"`
+
+	// Edges are the upserted edges in the order given with the references resolved.
+	Edges []models.Edge `json:"edges"`
+
+	// Refs maps every node reference to the ID of the node.
+	Refs map[string]uint64 `json:"refs"`
+}
+
+// GraphWriter defines the behavior required to write a whole graph at once.
+type GraphWriter interface {
+	// UpsertGraph inserts or updates the nodes and edges in a single transaction.
+	// Edges can reference nodes in the same graph using their Ref with FromRef and ToRef.
+	UpsertGraph(context.Context, models.Graph) (UpsertGraphResult, error)
+
+	// CheckGraph checks the graph would be written by UpsertGraph, returning the same errors, without writing it.
+	CheckGraph(context.Context, models.Graph) error
+}
+
+// QueryArgs are the arguments for a declarative graph query.
+type QueryArgs struct {
+	// Query is the read-only query, eg: `MATCH (a:person)-[r:knows*1..3]->(b) WHERE a.name = $name RETURN a, r, b`.
+	Query string
+
+	// Params are the values bound to the `$name` parameters in the query.
+	Params map[string]any
+}
+
+// Querier is implemented by stores which support declarative graph queries.
+type Querier interface {
+	// Query runs the read-only query returning the rows and the graph of the returned nodes and edges.
+	Query(context.Context, QueryArgs) (models.QueryResult, error)
+}
+
+// Indexer is implemented by stores which support managed secondary property indexes.
+// Indexes are built in the background, filters and queries comparing an indexed property use the index once it is ready.
+type Indexer interface {
+	// Indexes returns all the property indexes ordered by ID.
+	Indexes(context.Context) ([]models.PropertyIndex, error)
+
+	// Index returns the property index with the ID, returning ErrNotFound if there is no such index.
+	Index(context.Context, uint64) (models.PropertyIndex, error)
+
+	// CreateIndex starts building the property index returning the index while it is being built,
+	// returning ErrInvalid if the index is not valid and ErrConflict if the label and path are already indexed.
+	CreateIndex(context.Context, models.PropertyIndex) (models.PropertyIndex, error)
+
+	// DropIndex drops the property index with the ID returning the dropped index,
+	// returning ErrNotFound if there is no such index.
+	DropIndex(context.Context, uint64) (models.PropertyIndex, error)
+}
+
+// ChangesArgs are the arguments for reading the changelog.
+type ChangesArgs struct {
+	// Since only returns the changes after the sequence number.
+	Since uint64
+
+	// Labels only returns the changes to items with one of the labels, before or after the change.
+	Labels []string
+
+	// Limit is the maximum number of changes to return.
+	Limit int
+}
+
+// ChangeFeed is implemented by stores which record a changelog of the writes to nodes and edges.
+// Changes are recorded in the same transaction as the write, so a committed write is never missing from the feed.
+type ChangeFeed interface {
+	// Changes returns the changes ordered by sequence number.
+	Changes(context.Context, ChangesArgs) ([]models.Change, error)
+}
+
+// Historian is implemented by stores which keep the history of the nodes and edges.
+// The AsOf methods read the nodes and edges as they were at the time, the history starts once the store records it.
+type Historian interface {
+	// NodeAsOf returns the node as it was at the time, returning ErrNotFound if the node did not exist at the time.
+	NodeAsOf(context.Context, uint64, time.Time) (models.Node, error)
+
+	// NodesAsOf applies the search for all the nodes as they were at the time.
+	NodesAsOf(context.Context, NodesArgs, time.Time) ([]models.Node, error)
+
+	// GraphAsOf applies the search term to the graph as it was at the time.
+	GraphAsOf(context.Context, TermSearchArgs, time.Time) (models.Graph, error)
+
+	// SubGraphAsOf returns the sub-graph as it was at the time.
+	SubGraphAsOf(context.Context, SubGraphArgs, time.Time) (models.Graph, error)
+
+	// NodeHistory returns the revisions of the node oldest first, returning ErrNotFound if the node has no history.
+	NodeHistory(context.Context, uint64) ([]models.Revision, error)
+
+	// EdgeHistory returns the revisions of the edge oldest first, returning ErrNotFound if the edge has no history.
+	EdgeHistory(context.Context, uint64) ([]models.Revision, error)
+}
+
+// TrashStore is implemented by stores which can soft delete nodes and edges. Trashed items are left out of every
+// search until they are restored or purged, upserting a trashed item restores it.
+type TrashStore interface {
+	// TrashNodes moves the nodes to the trash applying the delete policy to the attached edges. The edges trashed by
+	// DeleteCascade are restored with their node, the edges trashed by DeleteDetach are restored on their own.
+	TrashNodes(context.Context, DeleteNodesArgs) (DeleteResult, error)
+
+	// TrashEdges moves the edges to the trash returning the trashed edges.
+	TrashEdges(context.Context, ...uint64) ([]models.Edge, error)
+
+	// Trash returns the trashed nodes and edges ordered by ID.
+	Trash(context.Context, TrashArgs) (Trash, error)
+
+	// RestoreNode restores the node and the edges trashed with it, returning ErrNotFound if the node is not in the trash.
+	// Edges to nodes which are still in the trash are restored with the other node instead.
+	RestoreNode(context.Context, uint64) (RestoreResult, error)
+
+	// RestoreEdge restores the edge, returning ErrNotFound if the edge is not in the trash
+	// and ErrConflict if one of its nodes is in the trash.
+	RestoreEdge(context.Context, uint64) (RestoreResult, error)
+
+	// Purge removes the items trashed before the time from the store.
+	Purge(context.Context, time.Time) (PurgeResult, error)
+}
+
+// APIKeyStore is implemented by stores which keep the API keys authenticating the clients of the API.
+// Only the hashes of the keys are stored, the keys are only returned when they are created.
+type APIKeyStore interface {
+	// APIKeys returns all the API keys ordered by ID, the keys are not returned.
+	APIKeys(context.Context) ([]models.APIKey, error)
+
+	// CreateAPIKey creates the API key returning it with its key, returning ErrInvalid if the API key is not valid.
+	CreateAPIKey(context.Context, models.APIKey) (models.APIKey, error)
+
+	// DeleteAPIKey deletes the API key returning the deleted API key, returning ErrNotFound if there is no such key.
+	DeleteAPIKey(context.Context, uint64) (models.APIKey, error)
+
+	// AuthenticateAPIKey returns the API key of the key recording when it was used at most once every
+	// APIKeyUsedInterval, returning ErrNotFound if there is no such key.
+	AuthenticateAPIKey(context.Context, string) (models.APIKey, error)
+}
+
+// Patcher is implemented by stores which can update some of the properties of a node or edge without replacing them.
+// Patches are applied in a single transaction and checked against the schemas like any other write.
+type Patcher interface {
+	// PatchNode applies the patch to the properties of the node returning the patched node, returning ErrNotFound if
+	// there is no such node, ErrInvalid if the patch is not valid and ErrConflict if it can not be applied.
+	PatchNode(context.Context, uint64, Patch) (models.Node, error)
+
+	// PatchEdge applies the patch to the properties of the edge returning the patched edge, returning ErrNotFound if
+	// there is no such edge, ErrInvalid if the patch is not valid and ErrConflict if it can not be applied.
+	PatchEdge(context.Context, uint64, Patch) (models.Edge, error)
+}
+
+// WebhookStore is implemented by stores which queue the changes to nodes and edges for delivery to webhooks.
+// Deliveries are queued in the same transaction as the write and sent by a webhook.Dispatcher.
+type WebhookStore interface {
+	// Webhooks returns all the webhooks ordered by ID, the secrets are not returned.
+	Webhooks(context.Context) ([]models.Webhook, error)
+
+	// Webhook returns the webhook with the ID, returning ErrNotFound if there is no such webhook.
+	// The secret is not returned.
+	Webhook(context.Context, uint64) (models.Webhook, error)
+
+	// CreateWebhook creates the webhook returning it with its secret, returning ErrInvalid if the webhook is not valid.
+	// Only the changes made after the webhook is created are delivered.
+	CreateWebhook(context.Context, models.Webhook) (models.Webhook, error)
+
+	// DeleteWebhook deletes the webhook and its deliveries returning the deleted webhook,
+	// returning ErrNotFound if there is no such webhook.
+	DeleteWebhook(context.Context, uint64) (models.Webhook, error)
+
+	// Deliveries returns the deliveries of a webhook ordered by ID, returning ErrNotFound if there is no such webhook.
+	Deliveries(context.Context, DeliveriesArgs) ([]models.Delivery, error)
+
+	// DueDeliveries returns the pending deliveries due to be sent at the time ordered by ID, holding back the deliveries
+	// behind a pending delivery of the same webhook which is not due yet.
+	DueDeliveries(context.Context, time.Time, int) ([]DueDelivery, error)
+
+	// RecordAttempt records the outcome of sending the delivery with the ID.
+	RecordAttempt(context.Context, uint64, DeliveryAttempt) error
+}
+
+// SchemaStore defines the behavior required to manage the label schemas enforced by UpsertNodes and UpsertEdges.
+// Schemas only apply to writes made after they are upserted, existing items are not checked.
+type SchemaStore interface {
+	// Schemas returns all the schemas ordered by kind and label.
+	Schemas(context.Context) ([]models.Schema, error)
+
+	// Schema returns the schema for the kind and label, returning ErrNotFound if there is no such schema.
+	Schema(context.Context, models.SchemaKind, string) (models.Schema, error)
+
+	// UpsertSchema inserts or replaces the schema for its kind and label, returning ErrInvalid if the schema is not valid.
+	UpsertSchema(context.Context, models.Schema) (models.Schema, error)
+
+	// DeleteSchema deletes the schema for the kind and label returning the deleted schema,
+	// returning ErrNotFound if there is no such schema.
+	DeleteSchema(context.Context, models.SchemaKind, string) (models.Schema, error)
+}
+
+// KeyStore defines the behavior required to manage the natural keys used by MergeNodes.
+// Natural keys are unique, writing a node with the same key value as another node returns ErrConflict.
+type KeyStore interface {
+	// Keys returns all the natural keys ordered by label.
+	Keys(context.Context) ([]models.NaturalKey, error)
+
+	// Key returns the natural key for the label, returning ErrNotFound if there is no such key.
+	Key(context.Context, string) (models.NaturalKey, error)
+
+	// UpsertKey inserts or replaces the natural key for its label, returning ErrInvalid if the key is not valid
+	// and ErrConflict if existing nodes have the same key value.
+	UpsertKey(context.Context, models.NaturalKey) (models.NaturalKey, error)
+
+	// DeleteKey deletes the natural key for the label returning the deleted key,
+	// returning ErrNotFound if there is no such key.
+	DeleteKey(context.Context, string) (models.NaturalKey, error)
+}
+
 // Store defines the behavior required to persist and search a store.
 type Store interface {
 	NodeStore
 	EdgeStore
 	Graph(context.Context, TermSearchArgs) (models.Graph, error)
 	SubGraph(context.Context, SubGraphArgs) (models.Graph, error)
+	PathFinder
+	GraphWriter
+	SchemaStore
+	KeyStore
+
+	// Reindex rebuilds the term search index from the stored items returning the number of items indexed.
+	Reindex(context.Context) (int, error)
+
 	Health(context.Context) models.Health
 	Close() error
 }
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenmud/edgedb/models"
+)
+
+// MergedNode is a node written by MergeNodes.
+type MergedNode struct {
+	models.Node
+
+	// Created is true if the node was inserted, false if it matched an existing node.
+	Created bool `json:"created"`
+}
+
+// MergedEdge is an edge written by MergeEdges.
+type MergedEdge struct {
+	models.Edge
+
+	// Created is true if the edge was inserted, false if it matched an existing edge.
+	Created bool `json:"created"`
+}
+
+// ValidateKey returns ErrInvalid if the natural key can not be used.
+// The optional `properties.` prefix is removed from the property path.
+func ValidateKey(k *models.NaturalKey) error {
+	if k.Label == "" {
+		return fmt.Errorf("%w: natural key label is required", ErrInvalid)
+	}
+
+	k.Property = strings.TrimPrefix(k.Property, "properties.")
+
+	p := Predicate{Path: k.Property, Op: OpEq}
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalid, err)
+	}
+
+	return nil
+}
+
+// KeyPredicate returns the predicate matching the nodes with the key value.
+func KeyPredicate(k models.NaturalKey, value any) Predicate {
+	return Predicate{Path: k.Property, Op: OpEq, Value: value}
+}
+
+// KeyValue returns the normalized key value of the node, returning ErrInvalid if the node does not have a key value.
+func KeyValue(k models.NaturalKey, n models.Node) (any, error) {
+	props, _ := jsonValue(n.Properties).(map[string]any)
+
+	value, ok := KeyPredicate(k, nil).lookup(props)
+	if !ok || value == nil {
+		return nil, fmt.Errorf("%w: node with label %q is missing its natural key %s", ErrInvalid, n.Label, k.Property)
+	}
+
+	return value, nil
+}
+
+// KeyString returns the key value as a string, values which compare as equal have the same string.
+func KeyString(value any) string {
+	c := comparable(value)
+	return fmt.Sprintf("%T:%v", c, c)
+}
+
+// NoKeyError returns the ErrInvalid error for merging a node with a label which has no natural key.
+func NoKeyError(label string) error {
+	return fmt.Errorf("%w: label %q has no natural key to merge on", ErrInvalid, label)
+}
+
+// DuplicateKeyError returns the ErrConflict error for writing a node with the same key value as another node.
+func DuplicateKeyError(label string) error {
+	return fmt.Errorf("%w: another node with label %q has the same natural key", ErrConflict, label)
+}
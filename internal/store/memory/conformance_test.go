@@ -0,0 +1,15 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/memory"
+	"github.com/jenmud/edgedb/internal/store/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		return memory.New()
+	})
+}
@@ -0,0 +1,363 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// snippetDefaults applies the same snippet defaults as the sqlite store.
+func snippetDefaults(args store.TermSearchArgs) store.TermSearchArgs {
+	if args.Limit == 0 {
+		args.Limit = DefaultLimit
+	}
+
+	if args.SnippetTokens < 0 {
+		args.SnippetTokens = 10
+	}
+
+	if args.SnippetTokens > 64 {
+		args.SnippetTokens = 64
+	}
+
+	if args.SnippetStart == "" {
+		args.SnippetStart = `<span class="text-red-500">`
+	}
+
+	if args.SnippetEnd == "" {
+		args.SnippetEnd = `</span>`
+	}
+
+	return args
+}
+
+// search returns the ranked IDs of the items matching the term which are accepted by keep, it must be called while holding the read lock.
+func (s *Store) search(args store.TermSearchArgs, keep func(id uint64) bool) (*term, []uint64, error) {
+	t, err := parseTerm(args.Term)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	matched := []uint64{}
+	for id := range t.match(s.index) {
+		if keep(id) {
+			matched = append(matched, id)
+		}
+	}
+
+	matched = t.rank(s.index, matched)
+
+	if args.Limit > 0 && len(matched) > args.Limit {
+		matched = matched[:args.Limit]
+	}
+
+	return t, matched, nil
+}
+
+// NodesTermSearch applies the search term and returns nodes with match, best matches first. Limit defaults to 1000 if limit is 0
+func (s *Store) NodesTermSearch(ctx context.Context, args store.TermSearchArgs) ([]models.Node, error) {
+	args = snippetDefaults(args)
+
+	if args.Term == "" {
+		return s.Nodes(ctx, store.NodesArgs{Limit: args.Limit, LastID: args.LastID})
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, matched, err := s.search(args, func(id uint64) bool {
+		_, ok := s.nodes[id]
+		return ok && id > args.LastID
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]models.Node, len(matched))
+	for i, id := range matched {
+		nodes[i] = cloneNode(s.nodes[id])
+		nodes[i].Snippet = t.snippet(s.index, id, args.SnippetStart, args.SnippetEnd, args.SnippetTokens)
+	}
+
+	return nodes, nil
+}
+
+// EdgesTermSearch applies the search term and returns edges with match, best matches first. Limit defaults to 1000 if limit is 0
+func (s *Store) EdgesTermSearch(ctx context.Context, args store.TermSearchArgs) ([]models.Edge, error) {
+	args = snippetDefaults(args)
+
+	if args.Term == "" {
+		return s.Edges(ctx, store.EdgesArgs{Limit: args.Limit, LastID: args.LastID})
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, matched, err := s.search(args, func(id uint64) bool {
+		_, ok := s.edges[id]
+		return ok && id > args.LastID
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	edges := make([]models.Edge, len(matched))
+	for i, id := range matched {
+		edges[i] = cloneEdge(s.edges[id])
+		edges[i].Snippet = t.snippet(s.index, id, args.SnippetStart, args.SnippetEnd, args.SnippetTokens)
+	}
+
+	return edges, nil
+}
+
+// Graph applies the search term and returns the graph containing matched nodes and edges, the nodes of the matched
+// edges are always included. Limit defaults to 1000 if limit is 0
+func (s *Store) Graph(ctx context.Context, args store.TermSearchArgs) (models.Graph, error) {
+	args = snippetDefaults(args)
+
+	graph := models.Graph{
+		Nodes: make([]models.Node, 0),
+		Edges: make([]models.Edge, 0),
+	}
+
+	if args.Term == "" {
+		args.Term = "type:node OR type:edge"
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, matched, err := s.search(args, func(id uint64) bool { return true })
+	if err != nil {
+		return graph, err
+	}
+
+	seen := map[uint64]struct{}{}
+
+	for _, id := range matched {
+		snippet := t.snippet(s.index, id, args.SnippetStart, args.SnippetEnd, args.SnippetTokens)
+
+		if n, ok := s.nodes[id]; ok {
+			n = cloneNode(n)
+			n.Snippet = snippet
+			graph.AddNodes(n)
+			seen[id] = struct{}{}
+			continue
+		}
+
+		e := cloneEdge(s.edges[id])
+		e.Snippet = snippet
+		graph.AddEdges(e)
+	}
+
+	// add the nodes of the matched edges which did not match the term.
+	missing := []uint64{}
+	for _, e := range graph.Edges {
+		for _, id := range []uint64{e.From, e.To} {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				missing = append(missing, id)
+			}
+		}
+	}
+
+	slices.Sort(missing)
+
+	for _, id := range missing {
+		if n, ok := s.nodes[id]; ok {
+			graph.AddNodes(cloneNode(n))
+		}
+	}
+
+	return graph, nil
+}
+
+// follow returns the sorted IDs of the edges followed from the node in the direction, limited to the edge labels.
+// It must be called while holding the read lock.
+func (s *Store) follow(id uint64, direction store.Direction, labels []string) []uint64 {
+	var edgeIDs []uint64
+
+	switch direction {
+	case store.DirectionOut:
+		edgeIDs = s.adjacent(s.out, id)
+	case store.DirectionIn:
+		edgeIDs = s.adjacent(s.in, id)
+	default:
+		edgeIDs = append(s.adjacent(s.out, id), s.adjacent(s.in, id)...)
+		slices.Sort(edgeIDs)
+		edgeIDs = slices.Compact(edgeIDs)
+	}
+
+	if len(labels) == 0 {
+		return edgeIDs
+	}
+
+	return slices.DeleteFunc(edgeIDs, func(edgeID uint64) bool {
+		return !slices.Contains(labels, s.edges[edgeID].Label)
+	})
+}
+
+// SubGraph returns a new sub-graph expanding N hops from the starting point.
+// The starting points are the FromNodeID, ToNodeID and the nodes of EdgeID.
+func (s *Store) SubGraph(ctx context.Context, args store.SubGraphArgs) (models.Graph, error) {
+	graph := models.Graph{
+		Nodes: make([]models.Node, 0),
+		Edges: make([]models.Edge, 0),
+	}
+
+	if args.Depth <= 0 {
+		args.Depth = 1
+	}
+
+	args.Depth = min(args.Depth, store.MaxSubGraphDepth)
+
+	if args.Direction == "" {
+		args.Direction = store.DirectionBoth
+	}
+
+	if args.Limit == 0 {
+		args.Limit = DefaultLimit
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seeds := []uint64{}
+
+	if args.FromNodeID > 0 {
+		seeds = append(seeds, args.FromNodeID)
+	}
+
+	if args.ToNodeID > 0 {
+		seeds = append(seeds, args.ToNodeID)
+	}
+
+	// if we have a edge ID, then walk from both ends of it.
+	if args.EdgeID > 0 {
+		edge, ok := s.edges[args.EdgeID]
+		if !ok {
+			return models.Graph{}, fmt.Errorf("%w: edge %d", store.ErrNotFound, args.EdgeID)
+		}
+
+		seeds = append(seeds, edge.From, edge.To)
+	}
+
+	if len(seeds) == 0 {
+		return graph, nil
+	}
+
+	switch args.Direction {
+	case store.DirectionOut, store.DirectionIn, store.DirectionBoth:
+	default:
+		return graph, fmt.Errorf("%w: unsupported direction: %s", store.ErrInvalid, args.Direction)
+	}
+
+	// breadth first, one level per depth so every node is found at its shortest depth and the nodes are
+	// ordered by depth and then ID.
+	visited := map[uint64]struct{}{}
+	level := []uint64{}
+
+	for _, id := range seeds {
+		if _, ok := visited[id]; !ok {
+			visited[id] = struct{}{}
+			level = append(level, id)
+		}
+	}
+
+	hood := []uint64{}
+
+	for depth := 0; len(level) > 0; depth++ {
+		slices.Sort(level)
+
+		for _, id := range level {
+			if _, ok := s.nodes[id]; ok && (args.Limit < 0 || len(hood) < args.Limit) {
+				hood = append(hood, id)
+			}
+		}
+
+		if depth == args.Depth {
+			break
+		}
+
+		next := []uint64{}
+		for _, id := range level {
+			for _, edgeID := range s.follow(id, args.Direction, args.EdgeLabels) {
+				e := s.edges[edgeID]
+
+				neighbour := e.To
+				if e.To == id {
+					neighbour = e.From
+				}
+
+				if _, ok := visited[neighbour]; !ok {
+					visited[neighbour] = struct{}{}
+					next = append(next, neighbour)
+				}
+			}
+		}
+
+		level = next
+	}
+
+	inHood := make(map[uint64]struct{}, len(hood))
+	for _, id := range hood {
+		inHood[id] = struct{}{}
+		graph.AddNodes(cloneNode(s.nodes[id]))
+	}
+
+	// only the edges between the nodes in the neighbourhood are returned.
+	edgeIDs := s.adjacent(s.out, hood...)
+	for _, id := range edgeIDs {
+		e := s.edges[id]
+
+		if _, ok := inHood[e.To]; !ok {
+			continue
+		}
+
+		if len(args.EdgeLabels) > 0 && !slices.Contains(args.EdgeLabels, e.Label) {
+			continue
+		}
+
+		graph.AddEdges(cloneEdge(e))
+	}
+
+	return graph, nil
+}
+
+// Paths returns up to K paths between two nodes, returning store.ErrNotFound if there is no path.
+func (s *Store) Paths(ctx context.Context, args store.PathArgs) ([]models.Path, error) {
+	if args.Direction == "" {
+		args.Direction = store.DirectionOut
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	adjacent := func(ctx context.Context, id uint64) ([]models.Edge, error) {
+		edgeIDs := s.follow(id, args.Direction, args.EdgeLabels)
+
+		edges := make([]models.Edge, len(edgeIDs))
+		for i, edgeID := range edgeIDs {
+			edges[i] = cloneEdge(s.edges[edgeID])
+		}
+
+		return edges, nil
+	}
+
+	nodes := func(ctx context.Context, ids ...uint64) ([]models.Node, error) {
+		nodes := make([]models.Node, 0, len(ids))
+		for _, id := range ids {
+			if n, ok := s.nodes[id]; ok {
+				nodes = append(nodes, cloneNode(n))
+			}
+		}
+		return nodes, nil
+	}
+
+	return store.FindPaths(ctx, args, adjacent, nodes)
+}
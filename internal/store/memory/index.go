@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+	"github.com/jenmud/edgedb/pkg/common"
+)
+
+// column is a searchable column of an item, the columns match the sqlite full text search columns.
+type column int
+
+const (
+	colID column = iota
+	colType
+	colLabel
+	colPropKeys
+	colPropValues
+	numColumns
+)
+
+// columnNames are the names of the columns in store.TermColumns.
+var columnNames = map[string]column{
+	"id":          colID,
+	"type":        colType,
+	"label":       colLabel,
+	"prop_keys":   colPropKeys,
+	"prop_values": colPropValues,
+}
+
+// columns is a set of columns a phrase is matched against.
+type columns [numColumns]bool
+
+// field is the text of a column and its tokens.
+type field struct {
+	text   string
+	tokens []store.Token
+}
+
+// document is the indexed text of an item.
+type document struct {
+	fields [numColumns]field
+}
+
+// index is an inverted index of the item text used for term searches.
+type index struct {
+	docs     map[uint64]*document
+	postings [numColumns]map[string]map[uint64]int // term -> item ID -> number of occurrences
+}
+
+func newIndex() *index {
+	ix := &index{docs: map[uint64]*document{}}
+	for c := range ix.postings {
+		ix.postings[c] = map[string]map[uint64]int{}
+	}
+	return ix
+}
+
+// add indexes the item, replacing any previously indexed text for the item.
+func (ix *index) add(id uint64, kind, label string, props models.Properties) {
+	ix.remove(id)
+
+	keys, values := common.FlattenMAP(props)
+
+	texts := [numColumns]string{
+		colID:         strconv.FormatUint(id, 10),
+		colType:       kind,
+		colLabel:      label,
+		colPropKeys:   strings.Join(keys, ","),
+		colPropValues: strings.Join(values, ","),
+	}
+
+	doc := &document{}
+
+	for c, text := range texts {
+		doc.fields[c] = field{text: text, tokens: store.Tokenize(text)}
+
+		for _, t := range doc.fields[c].tokens {
+			postings, ok := ix.postings[c][t.Text]
+			if !ok {
+				postings = map[uint64]int{}
+				ix.postings[c][t.Text] = postings
+			}
+			postings[id]++
+		}
+	}
+
+	ix.docs[id] = doc
+}
+
+// remove removes the item from the index.
+func (ix *index) remove(id uint64) {
+	doc, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+
+	for c, f := range doc.fields {
+		for _, t := range f.tokens {
+			postings := ix.postings[c][t.Text]
+			delete(postings, id)
+			if len(postings) == 0 {
+				delete(ix.postings[c], t.Text)
+			}
+		}
+	}
+
+	delete(ix.docs, id)
+}
+
+// terms returns the indexed terms in the column matching the term, or starting with the term for prefixes.
+func (ix *index) terms(c column, term string, prefix bool) []string {
+	if !prefix {
+		if _, ok := ix.postings[c][term]; ok {
+			return []string{term}
+		}
+		return nil
+	}
+
+	terms := []string{}
+	for t := range ix.postings[c] {
+		if strings.HasPrefix(t, term) {
+			terms = append(terms, t)
+		}
+	}
+
+	return terms
+}
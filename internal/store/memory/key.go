@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// Keys returns all the natural keys ordered by label.
+func (s *Store) Keys(ctx context.Context) ([]models.NaturalKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]models.NaturalKey, 0, len(s.keys))
+	for _, k := range s.keys {
+		keys = append(keys, k)
+	}
+
+	slices.SortFunc(keys, func(a, b models.NaturalKey) int {
+		return cmp.Compare(a.Label, b.Label)
+	})
+
+	return keys, nil
+}
+
+// Key returns the natural key for the label, returning store.ErrNotFound if there is no such key.
+func (s *Store) Key(ctx context.Context, label string) (models.NaturalKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	k, ok := s.keys[label]
+	if !ok {
+		return models.NaturalKey{}, fmt.Errorf("%w: natural key for label %q", store.ErrNotFound, label)
+	}
+
+	return k, nil
+}
+
+// UpsertKey inserts or replaces the natural key for its label, returning store.ErrInvalid if the key is not valid and
+// store.ErrConflict if existing nodes have the same key value.
+func (s *Store) UpsertKey(ctx context.Context, k models.NaturalKey) (models.NaturalKey, error) {
+	if err := store.ValidateKey(&k); err != nil {
+		return models.NaturalKey{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values := map[string]struct{}{}
+
+	for _, n := range s.nodes {
+		if n.Label != k.Label {
+			continue
+		}
+
+		value, err := store.KeyValue(k, n)
+		if err != nil {
+			continue
+		}
+
+		str := store.KeyString(value)
+		if _, ok := values[str]; ok {
+			return models.NaturalKey{}, store.DuplicateKeyError(k.Label)
+		}
+		values[str] = struct{}{}
+	}
+
+	k.CreatedAt = now()
+	s.keys[k.Label] = k
+
+	return k, nil
+}
+
+// DeleteKey deletes the natural key for the label returning the deleted key,
+// returning store.ErrNotFound if there is no such key.
+func (s *Store) DeleteKey(ctx context.Context, label string) (models.NaturalKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k, ok := s.keys[label]
+	if !ok {
+		return models.NaturalKey{}, fmt.Errorf("%w: natural key for label %q", store.ErrNotFound, label)
+	}
+
+	delete(s.keys, label)
+	return k, nil
+}
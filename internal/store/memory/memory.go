@@ -0,0 +1,986 @@
+// Package memory is a pure Go, in-memory store.Store built on adjacency maps with an inverted index for term searches.
+//
+// It is used for unit tests and ephemeral graphs, nothing is persisted once the store is closed.
+// Term searches use the same syntax as the sqlite store without stemming, eg: `dogs` does not match `dog`.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// DefaultLimit is the default limit of return items to return.
+const DefaultLimit int = 1000
+
+// Store is an in-memory store, it is safe for concurrent use.
+type Store struct {
+	mu sync.RWMutex
+
+	nextID uint64
+
+	nodes map[uint64]models.Node
+	edges map[uint64]models.Edge
+
+	// nodeIDs and edgeIDs are the sorted IDs used for ordered listings and pagination.
+	nodeIDs []uint64
+	edgeIDs []uint64
+
+	// out and in are the IDs of the edges starting and ending at a node.
+	out map[uint64]map[uint64]struct{}
+	in  map[uint64]map[uint64]struct{}
+
+	index *index
+
+	// schemas are the label schemas keyed by kind and label.
+	schemas map[models.SchemaKind]map[string]models.Schema
+
+	// keys are the natural keys keyed by label.
+	keys map[string]models.NaturalKey
+}
+
+// New returns a new empty store.
+func New() *Store {
+	slog.Debug("attached to store", slog.Group("store", slog.String("driver", "memory")))
+
+	return &Store{
+		nextID: 1,
+		nodes:  map[uint64]models.Node{},
+		edges:  map[uint64]models.Edge{},
+		out:    map[uint64]map[uint64]struct{}{},
+		in:     map[uint64]map[uint64]struct{}{},
+		index:  newIndex(),
+		schemas: map[models.SchemaKind]map[string]models.Schema{
+			models.SchemaNode: {},
+			models.SchemaEdge: {},
+		},
+		keys: map[string]models.NaturalKey{},
+	}
+}
+
+// Close closes the store.
+func (s *Store) Close() error {
+	return nil
+}
+
+// Health returns the number of stored nodes and edges.
+func (s *Store) Health(ctx context.Context) models.Health {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return models.Health{
+		Status: "ok",
+		Checks: map[string]string{
+			"nodes": strconv.Itoa(len(s.nodes)),
+			"edges": strconv.Itoa(len(s.edges)),
+		},
+	}
+}
+
+// Reindex rebuilds the term search index from the stored items returning the number of items indexed.
+func (s *Store) Reindex(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.index = newIndex()
+
+	for _, n := range s.nodes {
+		s.index.add(n.ID, "node", n.Label, n.Properties)
+	}
+
+	for _, e := range s.edges {
+		s.index.add(e.ID, "edge", e.Label, e.Properties)
+	}
+
+	indexed := len(s.nodes) + len(s.edges)
+
+	slog.Info("term search reindexed", slog.Int("items", indexed))
+	return indexed, nil
+}
+
+// now returns the current time to the second, the same precision the sqlite store uses.
+func now() time.Time {
+	return time.Unix(time.Now().Unix(), 0)
+}
+
+// normalize returns a copy of the properties as they would be read back from JSON, eg: numbers become float64.
+func normalize(p models.Properties) (models.Properties, error) {
+	b, err := p.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	props := models.Properties{}
+	if err := props.FromBytes(b); err != nil {
+		return nil, err
+	}
+
+	return props, nil
+}
+
+// clone returns a deep copy of a normalized property value.
+func clone(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, val := range v {
+			m[k] = clone(val)
+		}
+		return m
+	case []any:
+		l := make([]any, len(v))
+		for i, val := range v {
+			l[i] = clone(val)
+		}
+		return l
+	default:
+		return v
+	}
+}
+
+// cloneProperties returns a deep copy of the properties so callers can not modify the stored items.
+func cloneProperties(p models.Properties) models.Properties {
+	if p == nil {
+		return nil
+	}
+	return models.Properties(clone(map[string]any(p)).(map[string]any))
+}
+
+func cloneNode(n models.Node) models.Node {
+	n.Properties = cloneProperties(n.Properties)
+	return n
+}
+
+func cloneEdge(e models.Edge) models.Edge {
+	e.Properties = cloneProperties(e.Properties)
+	return e
+}
+
+// insertID adds the ID to the sorted IDs.
+func insertID(sorted []uint64, id uint64) []uint64 {
+	i, found := slices.BinarySearch(sorted, id)
+	if found {
+		return sorted
+	}
+	return slices.Insert(sorted, i, id)
+}
+
+// removeID removes the ID from the sorted IDs.
+func removeID(sorted []uint64, id uint64) []uint64 {
+	i, found := slices.BinarySearch(sorted, id)
+	if !found {
+		return sorted
+	}
+	return slices.Delete(sorted, i, i+1)
+}
+
+// after returns the sorted IDs greater than the last ID.
+func after(sorted []uint64, lastID uint64) []uint64 {
+	i, found := slices.BinarySearch(sorted, lastID)
+	if found {
+		i++
+	}
+	return sorted[i:]
+}
+
+// txn stages writes which are only applied once every item is valid, so a failed write leaves the store untouched.
+// It must be used while holding the write lock.
+type txn struct {
+	s       *Store
+	now     time.Time
+	nextID  uint64
+	nodes   []models.Node
+	edges   []models.Edge
+	staged  map[uint64]staged
+	schemas store.SchemaSet
+}
+
+// staged is the kind, creation time and version of a staged item.
+type staged struct {
+	kind      string
+	createdAt time.Time
+	version   uint64
+}
+
+func (s *Store) txn() *txn {
+	return &txn{s: s, now: now(), nextID: s.nextID, staged: map[uint64]staged{}, schemas: s.schemaSet()}
+}
+
+// nodeLabel returns the label of a staged or existing node.
+func (t *txn) nodeLabel(id uint64) (string, bool, error) {
+	for _, n := range slices.Backward(t.nodes) {
+		if n.ID == id {
+			return n.Label, true, nil
+		}
+	}
+
+	if t.kind(id) == "edge" {
+		return "", false, nil
+	}
+
+	n, ok := t.s.nodes[id]
+	return n.Label, ok, nil
+}
+
+// kind returns `node` or `edge` for existing or staged items, or an empty string for unknown IDs.
+func (t *txn) kind(id uint64) string {
+	if st, ok := t.staged[id]; ok {
+		return st.kind
+	}
+
+	if _, ok := t.s.nodes[id]; ok {
+		return "node"
+	}
+
+	if _, ok := t.s.edges[id]; ok {
+		return "edge"
+	}
+
+	return ""
+}
+
+// assign returns the ID for a new item, or the given ID making sure new IDs are greater than it.
+func (t *txn) assign(id uint64) uint64 {
+	if id == 0 {
+		id = t.nextID
+	}
+
+	if id >= t.nextID {
+		t.nextID = id + 1
+	}
+
+	return id
+}
+
+// createdAt returns when an existing or staged item was created, new items are created now.
+func (t *txn) createdAt(id uint64) time.Time {
+	if st, ok := t.staged[id]; ok {
+		return st.createdAt
+	}
+
+	if n, ok := t.s.nodes[id]; ok {
+		return n.CreatedAt
+	}
+
+	if e, ok := t.s.edges[id]; ok {
+		return e.CreatedAt
+	}
+
+	return t.now
+}
+
+// version returns the version of an existing or staged item, new items have no version.
+func (t *txn) version(id uint64) uint64 {
+	if st, ok := t.staged[id]; ok {
+		return st.version
+	}
+
+	if n, ok := t.s.nodes[id]; ok {
+		return n.Version
+	}
+
+	if e, ok := t.s.edges[id]; ok {
+		return e.Version
+	}
+
+	return 0
+}
+
+// checkVersion returns a *store.VersionError holding the current item if the expected version is set and is not the
+// current version of the staged or existing item.
+func (t *txn) checkVersion(id, expected uint64) error {
+	if expected == 0 || (id > 0 && t.version(id) == expected) {
+		return nil
+	}
+
+	err := &store.VersionError{ID: id, Expected: expected}
+
+	switch t.kind(id) {
+	case "node":
+		n := t.s.nodes[id]
+		for _, staged := range slices.Backward(t.nodes) {
+			if staged.ID == id {
+				n = staged
+				break
+			}
+		}
+
+		n = cloneNode(n)
+		err.Node = &n
+	case "edge":
+		e := t.s.edges[id]
+		for _, staged := range slices.Backward(t.edges) {
+			if staged.ID == id {
+				e = staged
+				break
+			}
+		}
+
+		e = cloneEdge(e)
+		err.Edge = &e
+	}
+
+	return err
+}
+
+// node stages the node returning the node as it will be stored.
+func (t *txn) node(n models.Node) (models.Node, error) {
+	if n.ID > 0 && t.kind(n.ID) == "edge" {
+		return models.Node{}, fmt.Errorf("%w: item %d is an edge", store.ErrConflict, n.ID)
+	}
+
+	if err := t.checkVersion(n.ID, n.Version); err != nil {
+		return models.Node{}, err
+	}
+
+	if err := t.schemas.CheckNode(n); err != nil {
+		return models.Node{}, err
+	}
+
+	if err := t.checkKey(n); err != nil {
+		return models.Node{}, err
+	}
+
+	props, err := normalize(n.Properties)
+	if err != nil {
+		return models.Node{}, err
+	}
+
+	node := models.Node{
+		ID:         t.assign(n.ID),
+		Label:      n.Label,
+		Properties: props,
+		UpdatedAt:  t.now,
+	}
+
+	node.CreatedAt = t.createdAt(node.ID)
+	node.Version = t.version(node.ID) + 1
+
+	t.staged[node.ID] = staged{kind: "node", createdAt: node.CreatedAt, version: node.Version}
+	t.nodes = append(t.nodes, node)
+
+	return cloneNode(node), nil
+}
+
+// checkKey returns store.ErrConflict if another staged or existing node has the same natural key value.
+func (t *txn) checkKey(n models.Node) error {
+	k, ok := t.s.keys[n.Label]
+	if !ok {
+		return nil
+	}
+
+	value, err := store.KeyValue(k, n)
+	if err != nil {
+		// nodes without a key value are not keyed.
+		return nil
+	}
+
+	if id, found := t.keyOwner(k, value); found && id != n.ID {
+		return store.DuplicateKeyError(n.Label)
+	}
+
+	return nil
+}
+
+// keyOwner returns the ID of the staged or existing node with the natural key value.
+func (t *txn) keyOwner(k models.NaturalKey, value any) (uint64, bool) {
+	match := store.KeyPredicate(k, value)
+
+	// only the last staged version of a node counts.
+	seen := map[uint64]struct{}{}
+	for _, n := range slices.Backward(t.nodes) {
+		if _, ok := seen[n.ID]; ok {
+			continue
+		}
+		seen[n.ID] = struct{}{}
+
+		if n.Label == k.Label && match.Match(n.Properties) {
+			return n.ID, true
+		}
+	}
+
+	for id, n := range t.s.nodes {
+		if _, ok := t.staged[id]; ok {
+			continue
+		}
+
+		if n.Label == k.Label && match.Match(n.Properties) {
+			return id, true
+		}
+	}
+
+	return 0, false
+}
+
+// mergeNode stages the node matching an existing node on the natural key of its label.
+func (t *txn) mergeNode(n models.Node) (store.MergedNode, error) {
+	k, ok := t.s.keys[n.Label]
+	if !ok {
+		return store.MergedNode{}, store.NoKeyError(n.Label)
+	}
+
+	value, err := store.KeyValue(k, n)
+	if err != nil {
+		return store.MergedNode{}, err
+	}
+
+	id, found := t.keyOwner(k, value)
+	n.ID = id
+
+	node, err := t.node(n)
+	if err != nil {
+		return store.MergedNode{}, err
+	}
+
+	return store.MergedNode{Node: node, Created: !found}, nil
+}
+
+// edgeOwner returns the lowest ID of the staged or existing edges with the same from ID, label and to ID.
+func (t *txn) edgeOwner(e models.Edge) (uint64, bool) {
+	var owner uint64
+
+	same := func(id uint64, other models.Edge) {
+		if other.From == e.From && other.Label == e.Label && other.To == e.To && (owner == 0 || id < owner) {
+			owner = id
+		}
+	}
+
+	// only the last staged version of an edge counts.
+	seen := map[uint64]struct{}{}
+	for _, staged := range slices.Backward(t.edges) {
+		if _, ok := seen[staged.ID]; ok {
+			continue
+		}
+		seen[staged.ID] = struct{}{}
+		same(staged.ID, staged)
+	}
+
+	for id := range t.s.out[e.From] {
+		if _, ok := t.staged[id]; ok {
+			continue
+		}
+		same(id, t.s.edges[id])
+	}
+
+	return owner, owner > 0
+}
+
+// mergeEdge stages the edge matching an existing edge on its from ID, label and to ID.
+func (t *txn) mergeEdge(e models.Edge) (store.MergedEdge, error) {
+	id, found := t.edgeOwner(e)
+	e.ID = id
+
+	edge, err := t.edge(e)
+	if err != nil {
+		return store.MergedEdge{}, err
+	}
+
+	return store.MergedEdge{Edge: edge, Created: !found}, nil
+}
+
+// edge stages the edge returning the edge as it will be stored.
+func (t *txn) edge(e models.Edge) (models.Edge, error) {
+	if e.From == 0 || e.To == 0 {
+		return models.Edge{}, fmt.Errorf("%w: edge requires both the from and to node IDs", store.ErrInvalid)
+	}
+
+	if e.ID > 0 && t.kind(e.ID) == "node" {
+		return models.Edge{}, fmt.Errorf("%w: item %d is a node", store.ErrConflict, e.ID)
+	}
+
+	if err := t.checkVersion(e.ID, e.Version); err != nil {
+		return models.Edge{}, err
+	}
+
+	if err := t.schemas.CheckEdge(e, t.nodeLabel); err != nil {
+		return models.Edge{}, err
+	}
+
+	props, err := normalize(e.Properties)
+	if err != nil {
+		return models.Edge{}, err
+	}
+
+	edge := models.Edge{
+		ID:         t.assign(e.ID),
+		From:       e.From,
+		Label:      e.Label,
+		To:         e.To,
+		Weight:     e.Weight,
+		Properties: props,
+		UpdatedAt:  t.now,
+	}
+
+	edge.CreatedAt = t.createdAt(edge.ID)
+	edge.Version = t.version(edge.ID) + 1
+
+	t.staged[edge.ID] = staged{kind: "edge", createdAt: edge.CreatedAt, version: edge.Version}
+	t.edges = append(t.edges, edge)
+
+	return cloneEdge(edge), nil
+}
+
+// commit applies the staged writes.
+func (t *txn) commit() {
+	s := t.s
+
+	for _, n := range t.nodes {
+		s.nodes[n.ID] = n
+		s.nodeIDs = insertID(s.nodeIDs, n.ID)
+		s.index.add(n.ID, "node", n.Label, n.Properties)
+	}
+
+	for _, e := range t.edges {
+		if old, ok := s.edges[e.ID]; ok {
+			s.unlink(old)
+		}
+
+		s.edges[e.ID] = e
+		s.edgeIDs = insertID(s.edgeIDs, e.ID)
+		s.link(e)
+		s.index.add(e.ID, "edge", e.Label, e.Properties)
+	}
+
+	s.nextID = t.nextID
+}
+
+// link adds the edge to the adjacency maps.
+func (s *Store) link(e models.Edge) {
+	if s.out[e.From] == nil {
+		s.out[e.From] = map[uint64]struct{}{}
+	}
+	s.out[e.From][e.ID] = struct{}{}
+
+	if s.in[e.To] == nil {
+		s.in[e.To] = map[uint64]struct{}{}
+	}
+	s.in[e.To][e.ID] = struct{}{}
+}
+
+// unlink removes the edge from the adjacency maps.
+func (s *Store) unlink(e models.Edge) {
+	delete(s.out[e.From], e.ID)
+	if len(s.out[e.From]) == 0 {
+		delete(s.out, e.From)
+	}
+
+	delete(s.in[e.To], e.ID)
+	if len(s.in[e.To]) == 0 {
+		delete(s.in, e.To)
+	}
+}
+
+// UpsertNodes inserts or updates one or more nodes, either all the nodes are written or none are.
+// Nodes with a version are only updated if it is the current version, returning a *store.VersionError otherwise.
+func (s *Store) UpsertNodes(ctx context.Context, n ...models.Node) ([]models.Node, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.txn()
+	nodes := make([]models.Node, len(n))
+
+	for i, n := range n {
+		node, err := t.node(n)
+		if err != nil {
+			return nodes, err
+		}
+		nodes[i] = node
+	}
+
+	t.commit()
+	return nodes, nil
+}
+
+// UpsertEdges inserts or updates one or more edges, either all the edges are written or none are.
+// Edges with a version are only updated if it is the current version, returning a *store.VersionError otherwise.
+// Edges require both the from and to node IDs, returning store.ErrInvalid if either is missing.
+func (s *Store) UpsertEdges(ctx context.Context, e ...models.Edge) ([]models.Edge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.txn()
+	edges := make([]models.Edge, len(e))
+
+	for i, e := range e {
+		edge, err := t.edge(e)
+		if err != nil {
+			return edges, err
+		}
+		edges[i] = edge
+	}
+
+	t.commit()
+	return edges, nil
+}
+
+// MergeNodes inserts or updates one or more nodes matching existing nodes on the natural key of their label instead
+// of their ID, either all the nodes are written or none are.
+func (s *Store) MergeNodes(ctx context.Context, n ...models.Node) ([]store.MergedNode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.txn()
+	nodes := make([]store.MergedNode, len(n))
+
+	for i, n := range n {
+		node, err := t.mergeNode(n)
+		if err != nil {
+			return nodes, err
+		}
+		nodes[i] = node
+	}
+
+	t.commit()
+	return nodes, nil
+}
+
+// MergeEdges inserts or updates one or more edges matching existing edges on their from ID, label and to ID instead
+// of their ID, either all the edges are written or none are.
+func (s *Store) MergeEdges(ctx context.Context, e ...models.Edge) ([]store.MergedEdge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.txn()
+	edges := make([]store.MergedEdge, len(e))
+
+	for i, e := range e {
+		edge, err := t.mergeEdge(e)
+		if err != nil {
+			return edges, err
+		}
+		edges[i] = edge
+	}
+
+	t.commit()
+	return edges, nil
+}
+
+// UpsertGraph inserts or updates the nodes and edges, either everything is written or nothing is.
+// Edges can reference nodes in the same graph using the node Ref with FromRef and ToRef, returning store.ErrInvalid
+// for duplicate or unknown references.
+func (s *Store) UpsertGraph(ctx context.Context, g models.Graph) (store.UpsertGraphResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.txn()
+
+	result, err := t.graph(g)
+	if err != nil {
+		return result, err
+	}
+
+	t.commit()
+	return result, nil
+}
+
+// CheckGraph stages the nodes and edges the same way as UpsertGraph returning the same errors, but never commits them.
+func (s *Store) CheckGraph(ctx context.Context, g models.Graph) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.txn().graph(g)
+	return err
+}
+
+// graph stages the nodes and edges resolving the edge refs.
+func (t *txn) graph(g models.Graph) (store.UpsertGraphResult, error) {
+	result := store.UpsertGraphResult{
+		Nodes: []models.Node{},
+		Edges: []models.Edge{},
+		Refs:  map[string]uint64{},
+	}
+
+	if err := store.CheckRefs(g.Nodes); err != nil {
+		return result, err
+	}
+
+	nodes := make([]models.Node, len(g.Nodes))
+	for i, n := range g.Nodes {
+		node, err := t.node(n)
+		if err != nil {
+			return result, err
+		}
+
+		node.Ref = n.Ref
+		if n.Ref != "" {
+			result.Refs[n.Ref] = node.ID
+		}
+
+		nodes[i] = node
+	}
+
+	resolved, err := store.ResolveRefs(result.Refs, g.Edges)
+	if err != nil {
+		return result, err
+	}
+
+	edges := make([]models.Edge, len(resolved))
+	for i, e := range resolved {
+		edge, err := t.edge(e)
+		if err != nil {
+			return result, err
+		}
+
+		edge.FromRef = g.Edges[i].FromRef
+		edge.ToRef = g.Edges[i].ToRef
+		edges[i] = edge
+	}
+
+	result.Nodes = nodes
+	result.Edges = edges
+
+	return result, nil
+}
+
+// Node returns the node with the provided ID, returning store.ErrNotFound if there is no such node.
+func (s *Store) Node(ctx context.Context, id uint64) (models.Node, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n, ok := s.nodes[id]
+	if !ok {
+		return models.Node{}, fmt.Errorf("%w: node %d", store.ErrNotFound, id)
+	}
+
+	return cloneNode(n), nil
+}
+
+// Nodes returns the nodes ordered by ID matching the arguments.
+func (s *Store) Nodes(ctx context.Context, args store.NodesArgs) ([]models.Node, error) {
+	if args.Limit == 0 {
+		args.Limit = DefaultLimit
+	}
+
+	if err := args.Filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	candidates := after(s.nodeIDs, args.LastID)
+
+	if len(args.IDs) > 0 {
+		candidates = []uint64{}
+		for _, id := range args.IDs {
+			if _, ok := s.nodes[id]; ok && id > args.LastID {
+				candidates = append(candidates, id)
+			}
+		}
+		slices.Sort(candidates)
+		candidates = slices.Compact(candidates)
+	}
+
+	nodes := []models.Node{}
+
+	for _, id := range candidates {
+		if args.Limit > 0 && len(nodes) >= args.Limit {
+			break
+		}
+
+		n := s.nodes[id]
+		if !args.Filter.Match(n.Label, n.Properties, n.CreatedAt, n.UpdatedAt) {
+			continue
+		}
+
+		nodes = append(nodes, cloneNode(n))
+	}
+
+	return nodes, nil
+}
+
+// Edge returns the edge with the provided ID, returning store.ErrNotFound if there is no such edge.
+func (s *Store) Edge(ctx context.Context, id uint64) (models.Edge, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.edges[id]
+	if !ok {
+		return models.Edge{}, fmt.Errorf("%w: edge %d", store.ErrNotFound, id)
+	}
+
+	return cloneEdge(e), nil
+}
+
+// Edges returns the edges ordered by ID matching the arguments.
+func (s *Store) Edges(ctx context.Context, args store.EdgesArgs) ([]models.Edge, error) {
+	if args.Limit == 0 {
+		args.Limit = DefaultLimit
+	}
+
+	if err := args.Filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var candidates []uint64
+
+	// use the adjacency maps when the edges are limited to nodes.
+	switch {
+	case len(args.FromIDs) > 0:
+		candidates = s.adjacent(s.out, args.FromIDs...)
+	case len(args.ToIDs) > 0:
+		candidates = s.adjacent(s.in, args.ToIDs...)
+	default:
+		candidates = s.edgeIDs
+	}
+
+	candidates = after(candidates, args.LastID)
+
+	edges := []models.Edge{}
+
+	for _, id := range candidates {
+		if args.Limit > 0 && len(edges) >= args.Limit {
+			break
+		}
+
+		e := s.edges[id]
+
+		if len(args.FromIDs) > 0 && !slices.Contains(args.FromIDs, e.From) {
+			continue
+		}
+
+		if len(args.ToIDs) > 0 && !slices.Contains(args.ToIDs, e.To) {
+			continue
+		}
+
+		if !args.Filter.Match(e.Label, e.Properties, e.CreatedAt, e.UpdatedAt) {
+			continue
+		}
+
+		edges = append(edges, cloneEdge(e))
+	}
+
+	return edges, nil
+}
+
+// adjacent returns the sorted IDs of the edges in the adjacency map for the nodes.
+func (s *Store) adjacent(adjacency map[uint64]map[uint64]struct{}, nodeIDs ...uint64) []uint64 {
+	ids := []uint64{}
+	for _, id := range nodeIDs {
+		for edgeID := range adjacency[id] {
+			ids = append(ids, edgeID)
+		}
+	}
+
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+// DeleteNodes deletes the nodes with the given IDs applying the delete policy to the attached edges.
+// IDs which do not exist, or are not nodes, are ignored and will not be reported in the result.
+func (s *Store) DeleteNodes(ctx context.Context, args store.DeleteNodesArgs) (store.DeleteResult, error) {
+	result := store.DeleteResult{
+		Nodes:    make([]models.Node, 0),
+		Edges:    make([]models.Edge, 0),
+		Detached: make([]models.Edge, 0),
+	}
+
+	if args.Policy == "" {
+		args.Policy = store.DeleteRestrict
+	}
+
+	switch args.Policy {
+	case store.DeleteRestrict, store.DeleteCascade, store.DeleteDetach:
+	default:
+		return result, fmt.Errorf("%w: unsupported delete policy: %s", store.ErrInvalid, args.Policy)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nodeIDs := []uint64{}
+	for _, id := range args.IDs {
+		if _, ok := s.nodes[id]; ok {
+			nodeIDs = append(nodeIDs, id)
+		}
+	}
+
+	slices.Sort(nodeIDs)
+	nodeIDs = slices.Compact(nodeIDs)
+
+	if len(nodeIDs) == 0 {
+		return result, nil
+	}
+
+	edgeIDs := append(s.adjacent(s.out, nodeIDs...), s.adjacent(s.in, nodeIDs...)...)
+	slices.Sort(edgeIDs)
+	edgeIDs = slices.Compact(edgeIDs)
+
+	attached := make([]models.Edge, len(edgeIDs))
+	for i, id := range edgeIDs {
+		attached[i] = cloneEdge(s.edges[id])
+	}
+
+	if len(attached) > 0 && args.Policy == store.DeleteRestrict {
+		return result, &store.RestrictError{Edges: attached}
+	}
+
+	for _, id := range edgeIDs {
+		s.deleteEdge(id)
+	}
+
+	for _, id := range nodeIDs {
+		result.Nodes = append(result.Nodes, s.nodes[id])
+
+		delete(s.nodes, id)
+		s.nodeIDs = removeID(s.nodeIDs, id)
+		s.index.remove(id)
+	}
+
+	switch args.Policy {
+	case store.DeleteDetach:
+		result.Detached = attached
+	default:
+		result.Edges = attached
+	}
+
+	return result, nil
+}
+
+// DeleteEdges deletes the edges with the given IDs returning the edges deleted.
+// IDs which do not exist, or are not edges, are ignored and will not be reported in the result.
+func (s *Store) DeleteEdges(ctx context.Context, ids ...uint64) ([]models.Edge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	edgeIDs := []uint64{}
+	for _, id := range ids {
+		if _, ok := s.edges[id]; ok {
+			edgeIDs = append(edgeIDs, id)
+		}
+	}
+
+	slices.Sort(edgeIDs)
+	edgeIDs = slices.Compact(edgeIDs)
+
+	edges := make([]models.Edge, len(edgeIDs))
+	for i, id := range edgeIDs {
+		edges[i] = s.edges[id]
+		s.deleteEdge(id)
+	}
+
+	return edges, nil
+}
+
+// deleteEdge removes the edge from the store, it must be called while holding the write lock.
+func (s *Store) deleteEdge(id uint64) {
+	e, ok := s.edges[id]
+	if !ok {
+		return
+	}
+
+	s.unlink(e)
+	delete(s.edges, id)
+	s.edgeIDs = removeID(s.edgeIDs, id)
+	s.index.remove(id)
+}
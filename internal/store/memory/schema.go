@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// allSchemas returns every schema unordered, it must be used while holding the lock.
+func (s *Store) allSchemas() []models.Schema {
+	schemas := []models.Schema{}
+	for _, labels := range s.schemas {
+		for _, schema := range labels {
+			schemas = append(schemas, schema)
+		}
+	}
+	return schemas
+}
+
+// schemaSet returns the set of schemas used to check writes, it must be used while holding the lock.
+func (s *Store) schemaSet() store.SchemaSet {
+	return store.NewSchemaSet(s.allSchemas()...)
+}
+
+// Schemas returns all the schemas ordered by kind and label.
+func (s *Store) Schemas(ctx context.Context) ([]models.Schema, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	schemas := s.allSchemas()
+
+	slices.SortFunc(schemas, func(a, b models.Schema) int {
+		return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Label, b.Label))
+	})
+
+	return schemas, nil
+}
+
+// Schema returns the schema for the kind and label, returning store.ErrNotFound if there is no such schema.
+func (s *Store) Schema(ctx context.Context, kind models.SchemaKind, label string) (models.Schema, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	schema, ok := s.schemas[kind][label]
+	if !ok {
+		return models.Schema{}, fmt.Errorf("%w: %s schema %q", store.ErrNotFound, kind, label)
+	}
+
+	return schema, nil
+}
+
+// UpsertSchema inserts or replaces the schema for its kind and label, returning store.ErrInvalid if the schema is not valid.
+func (s *Store) UpsertSchema(ctx context.Context, schema models.Schema) (models.Schema, error) {
+	if err := store.ValidateSchema(&schema); err != nil {
+		return models.Schema{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schema.CreatedAt = now()
+	schema.UpdatedAt = schema.CreatedAt
+
+	if old, ok := s.schemas[schema.Kind][schema.Label]; ok {
+		schema.CreatedAt = old.CreatedAt
+	}
+
+	s.schemas[schema.Kind][schema.Label] = schema
+	return schema, nil
+}
+
+// DeleteSchema deletes the schema for the kind and label returning the deleted schema,
+// returning store.ErrNotFound if there is no such schema.
+func (s *Store) DeleteSchema(ctx context.Context, kind models.SchemaKind, label string) (models.Schema, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schema, ok := s.schemas[kind][label]
+	if !ok {
+		return models.Schema{}, fmt.Errorf("%w: %s schema %q", store.ErrNotFound, kind, label)
+	}
+
+	delete(s.schemas[kind], label)
+	return schema, nil
+}
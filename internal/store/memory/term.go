@@ -0,0 +1,297 @@
+package memory
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/jenmud/edgedb/internal/store"
+)
+
+// ids is a set of item IDs.
+type ids map[uint64]struct{}
+
+// expr is a parsed search term expression.
+type expr interface {
+	eval(ix *index) ids
+}
+
+// phrase matches items with the tokens next to each other in one of the columns,
+// the last token is matched as a prefix for prefix phrases, eg: `foo*`.
+type phrase struct {
+	tokens []string
+	prefix bool
+	cols   columns
+}
+
+// occurrences returns the positions of the phrase in the field.
+func (p *phrase) occurrences(f field) []int {
+	if len(p.tokens) == 0 || len(f.tokens) < len(p.tokens) {
+		return nil
+	}
+
+	positions := []int{}
+
+	for i := 0; i+len(p.tokens) <= len(f.tokens); i++ {
+		matched := true
+
+		for j, want := range p.tokens {
+			got := f.tokens[i+j].Text
+			last := j == len(p.tokens)-1
+
+			if got != want && !(last && p.prefix && strings.HasPrefix(got, want)) {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			positions = append(positions, i)
+		}
+	}
+
+	return positions
+}
+
+func (p *phrase) eval(ix *index) ids {
+	matched := ids{}
+
+	if len(p.tokens) == 0 {
+		return matched
+	}
+
+	// candidates contain the first token, phrases are then checked against the document.
+	first, firstPrefix := p.tokens[0], p.prefix && len(p.tokens) == 1
+
+	for c := range numColumns {
+		if !p.cols[c] {
+			continue
+		}
+
+		for _, term := range ix.terms(c, first, firstPrefix) {
+			for id := range ix.postings[c][term] {
+				if _, ok := matched[id]; ok {
+					continue
+				}
+
+				if len(p.tokens) == 1 || len(p.occurrences(ix.docs[id].fields[c])) > 0 {
+					matched[id] = struct{}{}
+				}
+			}
+		}
+	}
+
+	return matched
+}
+
+// binary is an AND, OR or NOT of two expressions.
+type binary struct {
+	op          string
+	left, right expr
+}
+
+func (b *binary) eval(ix *index) ids {
+	left, right := b.left.eval(ix), b.right.eval(ix)
+	result := ids{}
+
+	switch b.op {
+	case "AND":
+		for id := range left {
+			if _, ok := right[id]; ok {
+				result[id] = struct{}{}
+			}
+		}
+	case "OR":
+		for id := range left {
+			result[id] = struct{}{}
+		}
+		for id := range right {
+			result[id] = struct{}{}
+		}
+	case "NOT":
+		for id := range left {
+			if _, ok := right[id]; !ok {
+				result[id] = struct{}{}
+			}
+		}
+	}
+
+	return result
+}
+
+// term is a compiled search term.
+type term struct {
+	expr    expr
+	phrases []*phrase
+}
+
+// parseTerm parses the search term with the shared term syntax and compiles it for the index.
+func parseTerm(src string) (*term, error) {
+	parsed, err := store.ParseTerm(src)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &term{}
+	t.expr = t.compile(parsed)
+
+	return t, nil
+}
+
+// compile converts the parsed term into expressions evaluated against the index.
+func (t *term) compile(parsed store.Term) expr {
+	switch parsed := parsed.(type) {
+	case *store.TermOp:
+		return &binary{op: string(parsed.Op), left: t.compile(parsed.Left), right: t.compile(parsed.Right)}
+	case *store.TermPhrase:
+		ph := &phrase{tokens: parsed.Tokens, prefix: parsed.Prefix}
+		for _, name := range parsed.Columns {
+			ph.cols[columnNames[name]] = true
+		}
+
+		t.phrases = append(t.phrases, ph)
+		return ph
+	}
+
+	panic(fmt.Sprintf("unsupported term: %T", parsed))
+}
+
+// match returns the IDs of the items matching the term.
+func (t *term) match(ix *index) ids {
+	return t.expr.eval(ix)
+}
+
+// score ranks how well the item matches the term, higher is better. It is a simplified BM25 without the length normalisation.
+func (t *term) score(ix *index, id uint64, df map[*phrase]int) float64 {
+	doc := ix.docs[id]
+	n := float64(len(ix.docs))
+
+	score := 0.0
+	for _, ph := range t.phrases {
+		tf := 0
+		for c := range numColumns {
+			if ph.cols[c] {
+				tf += len(ph.occurrences(doc.fields[c]))
+			}
+		}
+
+		if tf == 0 {
+			continue
+		}
+
+		idf := math.Log(1 + (n-float64(df[ph])+0.5)/(float64(df[ph])+0.5))
+		score += idf * float64(tf) * 2.2 / (float64(tf) + 1.2)
+	}
+
+	return score
+}
+
+// rank orders the matched IDs by score, best first, using the ID to break ties.
+func (t *term) rank(ix *index, matched []uint64) []uint64 {
+	df := make(map[*phrase]int, len(t.phrases))
+	for _, ph := range t.phrases {
+		df[ph] = len(ph.eval(ix))
+	}
+
+	scores := make(map[uint64]float64, len(matched))
+	for _, id := range matched {
+		scores[id] = t.score(ix, id, df)
+	}
+
+	slices.SortFunc(matched, func(a, b uint64) int {
+		if c := cmp.Compare(scores[b], scores[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+
+	return matched
+}
+
+// snippet returns a fragment of the best matching column with up to n tokens, the matched tokens are wrapped
+// with the start and end tags and ` ... ` marks text which was cut.
+func (t *term) snippet(ix *index, id uint64, start, end string, n int) string {
+	doc := ix.docs[id]
+	if doc == nil || n <= 0 {
+		return ""
+	}
+
+	best, bestCount := column(-1), 0
+	var bestMarks []bool
+
+	for c := range numColumns {
+		f := doc.fields[c]
+		marks := make([]bool, len(f.tokens))
+		count := 0
+
+		for _, ph := range t.phrases {
+			if !ph.cols[c] {
+				continue
+			}
+
+			for _, pos := range ph.occurrences(f) {
+				for i := pos; i < pos+len(ph.tokens); i++ {
+					if !marks[i] {
+						marks[i] = true
+						count++
+					}
+				}
+			}
+		}
+
+		if count > bestCount {
+			best, bestCount, bestMarks = c, count, marks
+		}
+	}
+
+	if best < 0 {
+		return ""
+	}
+
+	f := doc.fields[best]
+
+	// the window starts at the first match, moved back so it is full when the match is near the end.
+	first := slices.Index(bestMarks, true)
+	from := max(0, min(first, len(f.tokens)-n))
+	to := min(len(f.tokens), from+n)
+
+	var b strings.Builder
+
+	textStart := 0
+	if from > 0 {
+		b.WriteString(" ... ")
+		textStart = f.tokens[from].Start
+	}
+
+	pos := textStart
+	for i := from; i < to; i++ {
+		if !bestMarks[i] {
+			continue
+		}
+
+		// consecutive matched tokens are wrapped together.
+		j := i
+		for j+1 < to && bestMarks[j+1] {
+			j++
+		}
+
+		b.WriteString(f.text[pos:f.tokens[i].Start])
+		b.WriteString(start)
+		b.WriteString(f.text[f.tokens[i].Start:f.tokens[j].End])
+		b.WriteString(end)
+
+		pos = f.tokens[j].End
+		i = j
+	}
+
+	if to < len(f.tokens) {
+		b.WriteString(f.text[pos:f.tokens[to-1].End])
+		b.WriteString(" ... ")
+	} else {
+		b.WriteString(f.text[pos:])
+	}
+
+	return b.String()
+}
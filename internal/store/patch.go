@@ -0,0 +1,381 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jenmud/edgedb/models"
+)
+
+// PatchType is the media type of a patch document.
+type PatchType string
+
+const (
+	// MergePatch is a RFC 7396 JSON Merge Patch, the document is merged into the properties and null values remove
+	// properties.
+	MergePatch PatchType = "application/merge-patch+json"
+
+	// JSONPatch is a RFC 6902 JSON Patch, the document is a list of operations applied in order.
+	JSONPatch PatchType = "application/json-patch+json"
+)
+
+// Patch is a partial update of the properties of a node or edge.
+type Patch struct {
+	// Type is the type of the patch document.
+	Type PatchType
+
+	// Document is the patch document, the paths of a JSONPatch are relative to the properties, eg: `/meta/hair`.
+	Document json.RawMessage
+
+	// Version is the version the node or edge is expected to be at, 0 patches any version.
+	Version uint64
+}
+
+// PatchOp is an operation of a JSON Patch.
+type PatchOp struct {
+	// Op is one of `add`, `remove`, `replace`, `move`, `copy` or `test`.
+	Op string `json:"op"`
+
+	// Path is the JSON pointer to the target of the operation.
+	Path string `json:"path"`
+
+	// From is the JSON pointer to the source of a `move` or `copy`.
+	From string `json:"from,omitempty"`
+
+	// Value is the value of an `add`, `replace` or `test`.
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Validate returns ErrInvalid if the patch document can not be applied to properties.
+func (p Patch) Validate() error {
+	switch p.Type {
+	case MergePatch:
+		if !bytes.HasPrefix(bytes.TrimSpace(p.Document), []byte("{")) || !json.Valid(p.Document) {
+			return fmt.Errorf("%w: merge patch must be a JSON object", ErrInvalid)
+		}
+	case JSONPatch:
+		if _, err := p.ops(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: unsupported patch type %q", ErrInvalid, p.Type)
+	}
+
+	return nil
+}
+
+// ops returns the operations of a JSON Patch, returning ErrInvalid if an operation is missing a member.
+func (p Patch) ops() ([]PatchOp, error) {
+	ops := []PatchOp{}
+
+	if err := json.Unmarshal(p.Document, &ops); err != nil {
+		return nil, fmt.Errorf("%w: json patch must be an array of operations: %s", ErrInvalid, err)
+	}
+
+	for i, op := range ops {
+		if _, err := pointer(op.Path); err != nil {
+			return nil, fmt.Errorf("%w: operation %d: %s", ErrInvalid, i, err)
+		}
+
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil {
+				return nil, fmt.Errorf("%w: operation %d: %s requires a value", ErrInvalid, i, op.Op)
+			}
+		case "move", "copy":
+			if _, err := pointer(op.From); err != nil {
+				return nil, fmt.Errorf("%w: operation %d: from %s", ErrInvalid, i, err)
+			}
+		case "remove":
+		default:
+			return nil, fmt.Errorf("%w: operation %d: unknown op %q", ErrInvalid, i, op.Op)
+		}
+	}
+
+	return ops, nil
+}
+
+// Apply returns the properties with the patch applied, the properties are not modified. ErrInvalid is returned if the
+// document is not valid and ErrConflict if an operation can not be applied to the properties, eg: a failed `test`.
+func (p Patch) Apply(props models.Properties) (models.Properties, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	// round trip the properties so that the patched properties share nothing with them.
+	b, err := props.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	var doc any
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	if p.Type == MergePatch {
+		var patch any
+		if err := json.Unmarshal(p.Document, &patch); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalid, err)
+		}
+
+		doc = mergePatch(doc, patch)
+	} else {
+		ops, _ := p.ops()
+
+		if doc == nil {
+			doc = map[string]any{}
+		}
+
+		for i, op := range ops {
+			if doc, err = applyOp(doc, op); err != nil {
+				return nil, fmt.Errorf("%w: operation %d: %s", ErrConflict, i, err)
+			}
+		}
+	}
+
+	patched, ok := doc.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: the patched properties are not an object", ErrConflict)
+	}
+
+	return models.Properties(patched), nil
+}
+
+// mergePatch merges the patch into the target as described by RFC 7396.
+func mergePatch(target, patch any) any {
+	members, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	doc, ok := target.(map[string]any)
+	if !ok {
+		doc = map[string]any{}
+	}
+
+	for k, v := range members {
+		if v == nil {
+			delete(doc, k)
+			continue
+		}
+
+		doc[k] = mergePatch(doc[k], v)
+	}
+
+	return doc
+}
+
+// pointer returns the reference tokens of the JSON pointer with `~1` and `~0` unescaped.
+func pointer(path string) ([]string, error) {
+	if path == "" {
+		return []string{}, nil
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("path %q is not a JSON pointer", path)
+	}
+
+	tokens := strings.Split(path[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// index returns the array index of the token, `-` is the index after the last element if end is true.
+func index(token string, length int, end bool) (int, error) {
+	if token == "-" && end {
+		return length, nil
+	}
+
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("%q is not an array index", token)
+	}
+
+	last := length - 1
+	if end {
+		last = length
+	}
+
+	if i > last {
+		return 0, fmt.Errorf("array index %d is out of range", i)
+	}
+
+	return i, nil
+}
+
+// get returns the value at the path.
+func get(doc any, path string) (any, error) {
+	tokens, _ := pointer(path)
+
+	for _, t := range tokens {
+		switch v := doc.(type) {
+		case map[string]any:
+			value, ok := v[t]
+			if !ok {
+				return nil, fmt.Errorf("path %q does not exist", path)
+			}
+			doc = value
+		case []any:
+			i, err := index(t, len(v), false)
+			if err != nil {
+				return nil, fmt.Errorf("path %q: %s", path, err)
+			}
+			doc = v[i]
+		default:
+			return nil, fmt.Errorf("path %q does not exist", path)
+		}
+	}
+
+	return doc, nil
+}
+
+// update applies fn to the parent of the path and the last token, returning the document with the new parent.
+func update(doc any, path string, fn func(parent any, token string) (any, error)) (any, error) {
+	tokens, _ := pointer(path)
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("the properties can not be replaced")
+	}
+
+	parentPath := path[:strings.LastIndex(path, "/")]
+
+	parent, err := get(doc, parentPath)
+	if err != nil {
+		return nil, err
+	}
+
+	parent, err = fn(parent, tokens[len(tokens)-1])
+	if err != nil {
+		return nil, fmt.Errorf("path %q: %s", path, err)
+	}
+
+	if parentPath == "" {
+		return parent, nil
+	}
+
+	// arrays are copied when they grow or shrink so the new parent replaces the old one.
+	return update(doc, parentPath, func(grandparent any, token string) (any, error) {
+		return set(grandparent, token, parent, false)
+	})
+}
+
+// set sets the member or element of the parent, elements are inserted if insert is true.
+func set(parent any, token string, value any, insert bool) (any, error) {
+	switch v := parent.(type) {
+	case map[string]any:
+		v[token] = value
+		return v, nil
+	case []any:
+		i, err := index(token, len(v), insert)
+		if err != nil {
+			return nil, err
+		}
+
+		if insert {
+			return slices.Insert(v, i, value), nil
+		}
+
+		v[i] = value
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("parent is not an object or array")
+}
+
+// remove removes the member or element from the parent.
+func remove(parent any, token string) (any, error) {
+	switch v := parent.(type) {
+	case map[string]any:
+		if _, ok := v[token]; !ok {
+			return nil, fmt.Errorf("does not exist")
+		}
+
+		delete(v, token)
+		return v, nil
+	case []any:
+		i, err := index(token, len(v), false)
+		if err != nil {
+			return nil, err
+		}
+
+		return slices.Delete(v, i, i+1), nil
+	}
+
+	return nil, fmt.Errorf("parent is not an object or array")
+}
+
+// applyOp applies the JSON Patch operation to the document as described by RFC 6902.
+func applyOp(doc any, op PatchOp) (any, error) {
+	var value any
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, err
+		}
+	case "move", "copy":
+		from, err := get(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		// round trip the value so that a copy shares nothing with the source.
+		b, err := json.Marshal(from)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(b, &value); err != nil {
+			return nil, err
+		}
+	}
+
+	switch op.Op {
+	case "add", "copy":
+		return update(doc, op.Path, func(parent any, token string) (any, error) {
+			return set(parent, token, value, true)
+		})
+	case "replace":
+		if _, err := get(doc, op.Path); err != nil {
+			return nil, err
+		}
+
+		return update(doc, op.Path, func(parent any, token string) (any, error) {
+			return set(parent, token, value, false)
+		})
+	case "remove":
+		return update(doc, op.Path, remove)
+	case "move":
+		if op.Path != op.From && strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("path %q can not be moved into itself", op.From)
+		}
+
+		doc, err := update(doc, op.From, remove)
+		if err != nil {
+			return nil, err
+		}
+
+		return update(doc, op.Path, func(parent any, token string) (any, error) {
+			return set(parent, token, value, true)
+		})
+	case "test":
+		current, err := get(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !reflect.DeepEqual(current, value) {
+			return nil, fmt.Errorf("test failed, path %q is not the value", op.Path)
+		}
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,142 @@
+package store_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+func TestPatch_Apply(t *testing.T) {
+	props := models.Properties{
+		"name": "foo",
+		"age":  float64(21),
+		"tags": []any{"a", "b"},
+		"meta": map[string]any{"hair": "brown", "eyes": "blue"},
+	}
+
+	tests := []struct {
+		name    string // description of this test case
+		patch   store.Patch
+		want    models.Properties
+		wantErr error
+	}{
+		{
+			name:  "merge",
+			patch: store.Patch{Type: store.MergePatch, Document: []byte(`{"age": 22, "meta": {"eyes": null, "height": 180}, "tags": ["c"]}`)},
+			want: models.Properties{
+				"name": "foo",
+				"age":  float64(22),
+				"tags": []any{"c"},
+				"meta": map[string]any{"hair": "brown", "height": float64(180)},
+			},
+		},
+		{
+			name:    "merge is not an object",
+			patch:   store.Patch{Type: store.MergePatch, Document: []byte(`["age"]`)},
+			wantErr: store.ErrInvalid,
+		},
+		{
+			name: "add replace and remove",
+			patch: store.Patch{Type: store.JSONPatch, Document: []byte(`[
+				{"op": "add", "path": "/meta/height", "value": 180},
+				{"op": "replace", "path": "/age", "value": 22},
+				{"op": "remove", "path": "/meta/eyes"}
+			]`)},
+			want: models.Properties{
+				"name": "foo",
+				"age":  float64(22),
+				"tags": []any{"a", "b"},
+				"meta": map[string]any{"hair": "brown", "height": float64(180)},
+			},
+		},
+		{
+			name: "array elements",
+			patch: store.Patch{Type: store.JSONPatch, Document: []byte(`[
+				{"op": "add", "path": "/tags/1", "value": "x"},
+				{"op": "add", "path": "/tags/-", "value": "z"},
+				{"op": "remove", "path": "/tags/0"}
+			]`)},
+			want: models.Properties{
+				"name": "foo",
+				"age":  float64(21),
+				"tags": []any{"x", "b", "z"},
+				"meta": map[string]any{"hair": "brown", "eyes": "blue"},
+			},
+		},
+		{
+			name: "move copy and test",
+			patch: store.Patch{Type: store.JSONPatch, Document: []byte(`[
+				{"op": "test", "path": "/meta/hair", "value": "brown"},
+				{"op": "move", "from": "/meta/hair", "path": "/hair"},
+				{"op": "copy", "from": "/tags", "path": "/labels"}
+			]`)},
+			want: models.Properties{
+				"name":   "foo",
+				"age":    float64(21),
+				"hair":   "brown",
+				"tags":   []any{"a", "b"},
+				"labels": []any{"a", "b"},
+				"meta":   map[string]any{"eyes": "blue"},
+			},
+		},
+		{
+			name:    "failed test",
+			patch:   store.Patch{Type: store.JSONPatch, Document: []byte(`[{"op": "test", "path": "/name", "value": "bar"}]`)},
+			wantErr: store.ErrConflict,
+		},
+		{
+			name:    "replace missing",
+			patch:   store.Patch{Type: store.JSONPatch, Document: []byte(`[{"op": "replace", "path": "/colour", "value": "red"}]`)},
+			wantErr: store.ErrConflict,
+		},
+		{
+			name:    "index out of range",
+			patch:   store.Patch{Type: store.JSONPatch, Document: []byte(`[{"op": "add", "path": "/tags/3", "value": "c"}]`)},
+			wantErr: store.ErrConflict,
+		},
+		{
+			name:    "unknown op",
+			patch:   store.Patch{Type: store.JSONPatch, Document: []byte(`[{"op": "merge", "path": "/name"}]`)},
+			wantErr: store.ErrInvalid,
+		},
+		{
+			name:    "not a pointer",
+			patch:   store.Patch{Type: store.JSONPatch, Document: []byte(`[{"op": "remove", "path": "name"}]`)},
+			wantErr: store.ErrInvalid,
+		},
+		{
+			name:    "unsupported type",
+			patch:   store.Patch{Type: "application/json", Document: []byte(`{}`)},
+			wantErr: store.ErrInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.patch.Apply(props)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Apply() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Apply() failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Apply() = mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+
+	// the properties patched are left untouched.
+	if diff := cmp.Diff([]any{"a", "b"}, props["tags"]); diff != "" {
+		t.Errorf("Apply() modified the properties (-want, +got): \n%s", diff)
+	}
+}
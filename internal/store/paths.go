@@ -0,0 +1,399 @@
+package store
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/jenmud/edgedb/models"
+)
+
+// DefaultMaxDepth is the default max number of hops in a path.
+const DefaultMaxDepth int = 10
+
+// MaxDepth is the max number of hops a path can have, deeper requests are clamped to it.
+const MaxDepth int = 50
+
+// MaxK is the max number of paths which can be found, larger requests are clamped to it.
+const MaxK int = 100
+
+// AdjacentFunc returns the edges attached to a node. Backends should apply the direction and edge label filters
+// from the PathArgs so that as few edges as possible are returned.
+type AdjacentFunc func(ctx context.Context, id uint64) ([]models.Edge, error)
+
+// NodesFunc returns the nodes with the given IDs.
+type NodesFunc func(ctx context.Context, ids ...uint64) ([]models.Node, error)
+
+// FindPaths finds up to K paths between two nodes using the adjacency lookups provided by a backend.
+// Backends can use it to implement PathFinder.
+//
+// The paths are found using Dijkstra (using a cost of 1 per hop for PathShortest), and Yen's algorithm is used
+// to find the next K shortest loopless paths.
+func FindPaths(ctx context.Context, args PathArgs, adjacent AdjacentFunc, nodes NodesFunc) ([]models.Path, error) {
+	if args.From == 0 || args.To == 0 {
+		return nil, fmt.Errorf("%w: both the from and to node IDs are required", ErrInvalid)
+	}
+
+	if args.Mode == "" {
+		args.Mode = PathShortest
+	}
+
+	if args.Mode != PathShortest && args.Mode != PathWeighted {
+		return nil, fmt.Errorf("%w: unsupported path mode: %s", ErrInvalid, args.Mode)
+	}
+
+	if args.Direction == "" {
+		args.Direction = DirectionOut
+	}
+
+	if args.Direction != DirectionOut && args.Direction != DirectionIn && args.Direction != DirectionBoth {
+		return nil, fmt.Errorf("%w: unsupported direction: %s", ErrInvalid, args.Direction)
+	}
+
+	if args.MaxDepth <= 0 {
+		args.MaxDepth = DefaultMaxDepth
+	}
+
+	if args.K <= 0 {
+		args.K = 1
+	}
+
+	args.MaxDepth = min(args.MaxDepth, MaxDepth)
+	args.K = min(args.K, MaxK)
+
+	f := &pathFinder{
+		args:     args,
+		adjacent: adjacent,
+		cache:    make(map[uint64][]models.Edge),
+		edges:    make(map[uint64]models.Edge),
+	}
+
+	first, found, err := f.shortest(ctx, args.From, nil, nil, args.MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, fmt.Errorf("%w: no path from node %d to node %d", ErrNotFound, args.From, args.To)
+	}
+
+	routes := []route{first}
+	candidates := []route{}
+
+	// Yen's algorithm, each spur node along the previous path is used as a detour point.
+	for len(routes) < args.K {
+		prev := routes[len(routes)-1]
+		prevNodes := prev.nodes(args.From)
+
+		for i := range prev.hops {
+			spur := prevNodes[i]
+			root := prev.hops[:i]
+
+			bannedEdges := make(map[uint64]struct{})
+			for _, p := range routes {
+				if len(p.hops) > i && sameHops(p.hops[:i], root) {
+					bannedEdges[p.hops[i].EdgeID] = struct{}{}
+				}
+			}
+
+			bannedNodes := make(map[uint64]struct{})
+			for _, id := range prevNodes[:i] {
+				bannedNodes[id] = struct{}{}
+			}
+
+			spurRoute, ok, err := f.shortest(ctx, spur, bannedNodes, bannedEdges, args.MaxDepth-i)
+			if err != nil {
+				return nil, err
+			}
+
+			if !ok {
+				continue
+			}
+
+			total := route{
+				hops: append(slices.Clone(root), spurRoute.hops...),
+				cost: routeCost(root, args.Mode) + spurRoute.cost,
+			}
+
+			if containsRoute(routes, total) || containsRoute(candidates, total) {
+				continue
+			}
+
+			candidates = append(candidates, total)
+		}
+
+		if len(candidates) == 0 {
+			break
+		}
+
+		slices.SortStableFunc(candidates, func(a, b route) int {
+			if a.cost != b.cost {
+				return a.cost - b.cost
+			}
+			return len(a.hops) - len(b.hops)
+		})
+
+		routes = append(routes, candidates[0])
+		candidates = candidates[1:]
+	}
+
+	return f.build(ctx, routes, nodes)
+}
+
+// route is a path found by the path finder.
+type route struct {
+	hops []models.Hop
+	cost int
+}
+
+// nodes returns the node IDs along the route in walking order.
+func (r route) nodes(start uint64) []uint64 {
+	ids := []uint64{start}
+	for _, h := range r.hops {
+		ids = append(ids, h.To)
+	}
+	return ids
+}
+
+// sameHops returns true if both hop lists walk the same edges in the same order.
+func sameHops(a, b []models.Hop) bool {
+	return slices.EqualFunc(a, b, func(x, y models.Hop) bool {
+		return x.EdgeID == y.EdgeID && x.To == y.To
+	})
+}
+
+// containsRoute returns true if the route is already in the routes.
+func containsRoute(routes []route, r route) bool {
+	return slices.ContainsFunc(routes, func(o route) bool {
+		return len(o.hops) == len(r.hops) && sameHops(o.hops, r.hops)
+	})
+}
+
+// routeCost returns the cost of walking the hops.
+func routeCost(hops []models.Hop, mode PathMode) int {
+	if mode != PathWeighted {
+		return len(hops)
+	}
+
+	cost := 0
+	for _, h := range hops {
+		cost += h.Weight
+	}
+
+	return cost
+}
+
+// pathFinder holds the state shared between the shortest path searches.
+type pathFinder struct {
+	args     PathArgs
+	adjacent AdjacentFunc
+	cache    map[uint64][]models.Edge // adjacency cache, keyed by node ID
+	edges    map[uint64]models.Edge   // every edge seen, keyed by edge ID
+}
+
+// neighbours returns the cached edges attached to the node.
+func (f *pathFinder) neighbours(ctx context.Context, id uint64) ([]models.Edge, error) {
+	if edges, ok := f.cache[id]; ok {
+		return edges, nil
+	}
+
+	edges, err := f.adjacent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range edges {
+		f.edges[e.ID] = e
+	}
+
+	f.cache[id] = edges
+	return edges, nil
+}
+
+// next returns the node reached by walking the edge from the current node honouring the direction.
+func (f *pathFinder) next(current uint64, e models.Edge) (uint64, bool) {
+	dir := f.args.Direction
+
+	switch {
+	case e.From == current && (dir == DirectionOut || dir == DirectionBoth):
+		return e.To, true
+	case e.To == current && (dir == DirectionIn || dir == DirectionBoth):
+		return e.From, true
+	}
+
+	return 0, false
+}
+
+// step is a search state, a node reached at a depth with a cost.
+type step struct {
+	node   uint64
+	depth  int
+	cost   int
+	seq    int
+	hop    models.Hop
+	parent *step
+}
+
+// onPath returns true if the node has already been walked to reach this step.
+func (s *step) onPath(id uint64) bool {
+	for cur := s; cur != nil; cur = cur.parent {
+		if cur.node == id {
+			return true
+		}
+	}
+	return false
+}
+
+// steps is a priority queue of steps ordered by cost, depth and then insertion order.
+type steps []*step
+
+func (q steps) Len() int { return len(q) }
+func (q steps) Less(i, j int) bool {
+	if q[i].cost != q[j].cost {
+		return q[i].cost < q[j].cost
+	}
+	if q[i].depth != q[j].depth {
+		return q[i].depth < q[j].depth
+	}
+	return q[i].seq < q[j].seq
+}
+func (q steps) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *steps) Push(x any)   { *q = append(*q, x.(*step)) }
+func (q *steps) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	*q = old[:n-1]
+	return item
+}
+
+// shortest finds the cheapest loopless route from the start node to the target node using no more than maxDepth hops,
+// avoiding the banned nodes and edges.
+func (f *pathFinder) shortest(ctx context.Context, start uint64, bannedNodes, bannedEdges map[uint64]struct{}, maxDepth int) (route, bool, error) {
+	queue := &steps{{node: start}}
+	seq := 0
+
+	// settled holds the (depth, cost) pairs already expanded for each node, a step is skipped
+	// if a settled step reached the same node with less or equal depth and cost.
+	settled := make(map[uint64][][2]int)
+
+	for queue.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			return route{}, false, err
+		}
+
+		cur := heap.Pop(queue).(*step)
+
+		if cur.node == f.args.To {
+			hops := make([]models.Hop, cur.depth)
+			for s := cur; s.parent != nil; s = s.parent {
+				hops[s.depth-1] = s.hop
+			}
+			return route{hops: hops, cost: cur.cost}, true, nil
+		}
+
+		dominated := slices.ContainsFunc(settled[cur.node], func(dc [2]int) bool {
+			return dc[0] <= cur.depth && dc[1] <= cur.cost
+		})
+
+		if dominated {
+			continue
+		}
+
+		settled[cur.node] = append(settled[cur.node], [2]int{cur.depth, cur.cost})
+
+		if cur.depth >= maxDepth {
+			continue
+		}
+
+		edges, err := f.neighbours(ctx, cur.node)
+		if err != nil {
+			return route{}, false, err
+		}
+
+		for _, e := range edges {
+			if _, banned := bannedEdges[e.ID]; banned {
+				continue
+			}
+
+			next, ok := f.next(cur.node, e)
+			if !ok {
+				continue
+			}
+
+			if _, banned := bannedNodes[next]; banned || cur.onPath(next) {
+				continue
+			}
+
+			cost := 1
+			if f.args.Mode == PathWeighted {
+				if e.Weight < 0 {
+					return route{}, false, fmt.Errorf("%w: edge %d has a negative weight %d which is not supported for weighted paths", ErrInvalid, e.ID, e.Weight)
+				}
+				cost = e.Weight
+			}
+
+			seq++
+			heap.Push(queue, &step{
+				node:   next,
+				depth:  cur.depth + 1,
+				cost:   cur.cost + cost,
+				seq:    seq,
+				hop:    models.Hop{From: cur.node, EdgeID: e.ID, Label: e.Label, To: next, Weight: e.Weight},
+				parent: cur,
+			})
+		}
+	}
+
+	return route{}, false, nil
+}
+
+// build converts the routes into paths filling in the nodes and edges.
+func (f *pathFinder) build(ctx context.Context, routes []route, nodes NodesFunc) ([]models.Path, error) {
+	ids := []uint64{}
+	seen := make(map[uint64]struct{})
+
+	for _, r := range routes {
+		for _, id := range r.nodes(f.args.From) {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	fetched, err := nodes(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := make(map[uint64]models.Node, len(fetched))
+	for _, n := range fetched {
+		lookup[n.ID] = n
+	}
+
+	paths := make([]models.Path, len(routes))
+
+	for i, r := range routes {
+		path := models.Path{
+			Graph: models.Graph{Nodes: make([]models.Node, 0), Edges: make([]models.Edge, 0)},
+			Hops:  r.hops,
+			Cost:  r.cost,
+		}
+
+		for _, id := range r.nodes(f.args.From) {
+			if n, ok := lookup[id]; ok {
+				path.Graph.AddNodes(n)
+			}
+		}
+
+		for _, h := range r.hops {
+			path.Graph.AddEdges(f.edges[h.EdgeID])
+		}
+
+		paths[i] = path
+	}
+
+	return paths, nil
+}
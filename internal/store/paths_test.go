@@ -0,0 +1,182 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// adjacency is a helper returning the adjacency and node lookups for the edges.
+func adjacency(edges ...models.Edge) (store.AdjacentFunc, store.NodesFunc) {
+	adjacent := func(ctx context.Context, id uint64) ([]models.Edge, error) {
+		attached := []models.Edge{}
+		for _, e := range edges {
+			if e.From == id || e.To == id {
+				attached = append(attached, e)
+			}
+		}
+		return attached, nil
+	}
+
+	nodes := func(ctx context.Context, ids ...uint64) ([]models.Node, error) {
+		found := []models.Node{}
+		for _, id := range ids {
+			found = append(found, models.Node{ID: id, Label: "node"})
+		}
+		return found, nil
+	}
+
+	return adjacent, nodes
+}
+
+// walked returns the node IDs walked by each path.
+func walked(paths []models.Path) [][]uint64 {
+	all := [][]uint64{}
+	for _, p := range paths {
+		ids := []uint64{}
+		for i, h := range p.Hops {
+			if i == 0 {
+				ids = append(ids, h.From)
+			}
+			ids = append(ids, h.To)
+		}
+		all = append(all, ids)
+	}
+	return all
+}
+
+func TestFindPaths(t *testing.T) {
+	/*
+		1 -a(1)-> 2 -a(1)-> 3 -a(1)-> 4
+		1 -b(10)----------> 4
+		1 -a(1)-> 5 -b(1)-> 4
+	*/
+	edges := []models.Edge{
+		{ID: 10, From: 1, Label: "a", To: 2, Weight: 1},
+		{ID: 11, From: 2, Label: "a", To: 3, Weight: 1},
+		{ID: 12, From: 3, Label: "a", To: 4, Weight: 1},
+		{ID: 13, From: 1, Label: "b", To: 4, Weight: 10},
+		{ID: 14, From: 1, Label: "a", To: 5, Weight: 1},
+		{ID: 15, From: 5, Label: "b", To: 4, Weight: 1},
+	}
+
+	tests := []struct {
+		name      string // description of this test case
+		args      store.PathArgs
+		want      [][]uint64
+		wantCosts []int
+		wantErr   error
+	}{
+		{
+			name:      "shortest by hops",
+			args:      store.PathArgs{From: 1, To: 4},
+			want:      [][]uint64{{1, 4}},
+			wantCosts: []int{1},
+		},
+		{
+			name:      "cheapest by weight",
+			args:      store.PathArgs{From: 1, To: 4, Mode: store.PathWeighted},
+			want:      [][]uint64{{1, 5, 4}},
+			wantCosts: []int{2},
+		},
+		{
+			name:      "k shortest by weight",
+			args:      store.PathArgs{From: 1, To: 4, Mode: store.PathWeighted, K: 3},
+			want:      [][]uint64{{1, 5, 4}, {1, 2, 3, 4}, {1, 4}},
+			wantCosts: []int{2, 3, 10},
+		},
+		{
+			name:      "k larger than the number of paths",
+			args:      store.PathArgs{From: 1, To: 4, K: 10},
+			want:      [][]uint64{{1, 4}, {1, 5, 4}, {1, 2, 3, 4}},
+			wantCosts: []int{1, 2, 3},
+		},
+		{
+			name:      "edge label filter",
+			args:      store.PathArgs{From: 1, To: 4, EdgeLabels: []string{"a"}},
+			want:      [][]uint64{{1, 2, 3, 4}},
+			wantCosts: []int{3},
+		},
+		{
+			name:    "max depth guard",
+			args:    store.PathArgs{From: 1, To: 4, EdgeLabels: []string{"a"}, MaxDepth: 2},
+			wantErr: store.ErrNotFound,
+		},
+		{
+			name:    "wrong direction",
+			args:    store.PathArgs{From: 4, To: 1},
+			wantErr: store.ErrNotFound,
+		},
+		{
+			name:      "incoming direction",
+			args:      store.PathArgs{From: 4, To: 1, Direction: store.DirectionIn},
+			want:      [][]uint64{{4, 1}},
+			wantCosts: []int{1},
+		},
+		{
+			name:      "both directions",
+			args:      store.PathArgs{From: 2, To: 5, Direction: store.DirectionBoth},
+			want:      [][]uint64{{2, 1, 5}},
+			wantCosts: []int{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adjacent, nodes := adjacency(edges...)
+
+			// the label filter is applied by the backend.
+			if len(tt.args.EdgeLabels) > 0 {
+				filtered := []models.Edge{}
+				for _, e := range edges {
+					if e.Label == tt.args.EdgeLabels[0] {
+						filtered = append(filtered, e)
+					}
+				}
+				adjacent, nodes = adjacency(filtered...)
+			}
+
+			got, gotErr := store.FindPaths(t.Context(), tt.args, adjacent, nodes)
+			if tt.wantErr != nil {
+				if !errors.Is(gotErr, tt.wantErr) {
+					t.Fatalf("FindPaths() error = %v, want %v", gotErr, tt.wantErr)
+				}
+				return
+			}
+
+			if gotErr != nil {
+				t.Fatalf("FindPaths() failed: %v", gotErr)
+			}
+
+			if diff := cmp.Diff(tt.want, walked(got)); diff != "" {
+				t.Errorf("FindPaths() paths mismatch (-want, +got): \n%s", diff)
+			}
+
+			gotCosts := []int{}
+			for _, p := range got {
+				gotCosts = append(gotCosts, p.Cost)
+
+				if len(p.Graph.Nodes) != len(p.Hops)+1 || len(p.Graph.Edges) != len(p.Hops) {
+					t.Errorf("FindPaths() graph has %d nodes and %d edges for %d hops", len(p.Graph.Nodes), len(p.Graph.Edges), len(p.Hops))
+				}
+			}
+
+			if diff := cmp.Diff(tt.wantCosts, gotCosts); diff != "" {
+				t.Errorf("FindPaths() costs mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFindPaths_NegativeWeight(t *testing.T) {
+	adjacent, nodes := adjacency(models.Edge{ID: 10, From: 1, Label: "a", To: 2, Weight: -1})
+
+	_, err := store.FindPaths(t.Context(), store.PathArgs{From: 1, To: 2, Mode: store.PathWeighted}, adjacent, nodes)
+	if err == nil {
+		t.Fatal("FindPaths() expected an error for negative weights")
+	}
+}
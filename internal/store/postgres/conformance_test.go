@@ -0,0 +1,14 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		return newStore(t)
+	})
+}
@@ -0,0 +1,264 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/postgres"
+	"github.com/jenmud/edgedb/models"
+)
+
+func preload(t *testing.T, s *postgres.Store, g models.Graph) {
+	if _, err := s.UpsertNodes(t.Context(), g.Nodes...); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.UpsertEdges(t.Context(), g.Edges...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// people is a small graph used by most tests.
+//
+//	1 (person foo) -knows-> 2 (person bar) -owns-> 3 (dog socks)
+var people = models.Graph{
+	Nodes: []models.Node{
+		{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
+		{ID: 2, Label: "person", Properties: models.Properties{"name": "bar", "age": 21}},
+		{ID: 3, Label: "dog", Properties: models.Properties{"short": true, "name": "socks"}},
+	},
+	Edges: []models.Edge{
+		{ID: 4, From: 1, Label: "knows", To: 2},
+		{ID: 5, From: 2, Label: "owns", To: 3, Properties: models.Properties{"since": 2020}},
+	},
+}
+
+func TestStore_NodesTermSearch(t *testing.T) {
+	tests := []struct {
+		name    string // description of this test case
+		args    store.TermSearchArgs
+		want    []uint64
+		wantErr bool
+	}{
+		{
+			name: "empty term lists all nodes",
+			args: store.TermSearchArgs{},
+			want: []uint64{1, 2, 3},
+		},
+		{
+			name: "bare word matches any column",
+			args: store.TermSearchArgs{Term: "socks"},
+			want: []uint64{3},
+		},
+		{
+			name: "stemmed",
+			args: store.TermSearchArgs{Term: "sock"},
+			want: []uint64{3},
+		},
+		{
+			name: "column filter",
+			args: store.TermSearchArgs{Term: "label:person"},
+			want: []uint64{1, 2},
+		},
+		{
+			name: "type and id columns",
+			args: store.TermSearchArgs{Term: "type:node AND id:2"},
+			want: []uint64{2},
+		},
+		{
+			name: "implicit and",
+			args: store.TermSearchArgs{Term: "label:person foo"},
+			want: []uint64{1},
+		},
+		{
+			name: "or",
+			args: store.TermSearchArgs{Term: "foo OR socks"},
+			want: []uint64{1, 3},
+		},
+		{
+			name: "not",
+			args: store.TermSearchArgs{Term: "prop_keys:name NOT label:dog"},
+			want: []uint64{1, 2},
+		},
+		{
+			name: "prefix",
+			args: store.TermSearchArgs{Term: "so*"},
+			want: []uint64{3},
+		},
+		{
+			name: "phrase",
+			args: store.TermSearchArgs{Term: `prop_keys:"name,short"`},
+			want: []uint64{3},
+		},
+		{
+			name: "parentheses",
+			args: store.TermSearchArgs{Term: "label:person AND (foo OR socks)"},
+			want: []uint64{1},
+		},
+		{
+			name: "unindexed column never matches",
+			args: store.TermSearchArgs{Term: "weight:1"},
+			want: []uint64{},
+		},
+		{
+			name: "limit",
+			args: store.TermSearchArgs{Term: "prop_keys:name", Limit: 2},
+			want: []uint64{1, 2},
+		},
+		{
+			name: "after last ID",
+			args: store.TermSearchArgs{Term: "prop_keys:name", LastID: 1},
+			want: []uint64{2, 3},
+		},
+		{
+			name:    "unknown column",
+			args:    store.TermSearchArgs{Term: "colour:red"},
+			wantErr: true,
+		},
+		{
+			name:    "unbalanced parentheses",
+			args:    store.TermSearchArgs{Term: "(foo OR bar"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore(t)
+			preload(t, s, people)
+
+			got, gotErr := s.NodesTermSearch(t.Context(), tt.args)
+			if gotErr != nil {
+				if !tt.wantErr {
+					t.Errorf("NodesTermSearch() failed: %v", gotErr)
+				}
+				return
+			}
+
+			if tt.wantErr {
+				t.Fatal("NodesTermSearch() succeeded unexpectedly")
+			}
+
+			ids := []uint64{}
+			for _, n := range got {
+				ids = append(ids, n.ID)
+			}
+
+			diff := cmp.Diff(tt.want, ids, cmpopts.SortSlices(func(a, b uint64) bool { return a < b }))
+			if diff != "" {
+				t.Errorf("NodesTermSearch() = mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStore_NodesTermSearch_Snippet(t *testing.T) {
+	s := newStore(t)
+	preload(t, s, people)
+
+	got, err := s.NodesTermSearch(t.Context(), store.TermSearchArgs{Term: "socks", SnippetTokens: 10, SnippetStart: "[", SnippetEnd: "]"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("NodesTermSearch() returned %d nodes, want 1", len(got))
+	}
+
+	if want := "[socks],true"; got[0].Snippet != want {
+		t.Errorf("NodesTermSearch() snippet = %q, want %q", got[0].Snippet, want)
+	}
+}
+
+func TestStore_FTSFollowsUpdates(t *testing.T) {
+	ctx := t.Context()
+
+	s := newStore(t)
+	preload(t, s, people)
+
+	if _, err := s.UpsertNodes(ctx, models.Node{ID: 3, Label: "cat", Properties: models.Properties{"name": "tom"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for term, want := range map[string]int{"socks": 0, "label:dog": 0, "tom": 1, "label:cat": 1} {
+		got, err := s.NodesTermSearch(ctx, store.TermSearchArgs{Term: term})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(got) != want {
+			t.Errorf("NodesTermSearch(%q) returned %d nodes, want %d", term, len(got), want)
+		}
+	}
+
+	count, err := s.Reindex(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := 5; count != want {
+		t.Errorf("Reindex() = %d, want %d", count, want)
+	}
+}
+
+func TestStore_NodesFilter(t *testing.T) {
+	tests := []struct {
+		name   string // description of this test case
+		filter store.Filter
+		want   []uint64
+	}{
+		{
+			name:   "labels",
+			filter: store.Filter{Labels: []string{"dog"}},
+			want:   []uint64{3},
+		},
+		{
+			name:   "numeric comparison",
+			filter: store.Filter{Where: []store.Predicate{{Path: "age", Op: store.OpGte, Value: 18}}},
+			want:   []uint64{2},
+		},
+		{
+			name:   "in",
+			filter: store.Filter{Where: []store.Predicate{{Path: "properties.name", Op: store.OpIn, Value: []any{"foo", "socks"}}}},
+			want:   []uint64{1, 3},
+		},
+		{
+			name:   "not equal skips missing",
+			filter: store.Filter{Where: []store.Predicate{{Path: "age", Op: store.OpNe, Value: 30}}},
+			want:   []uint64{2},
+		},
+		{
+			name:   "not exists",
+			filter: store.Filter{Where: []store.Predicate{{Path: "age", Op: store.OpNotExists}}},
+			want:   []uint64{1, 3},
+		},
+		{
+			name:   "bool",
+			filter: store.Filter{Where: []store.Predicate{{Path: "short", Op: store.OpEq, Value: true}}},
+			want:   []uint64{3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore(t)
+			preload(t, s, people)
+
+			got, err := s.Nodes(t.Context(), store.NodesArgs{Filter: tt.filter})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ids := []uint64{}
+			for _, n := range got {
+				ids = append(ids, n.ID)
+			}
+
+			if diff := cmp.Diff(tt.want, ids); diff != "" {
+				t.Errorf("Nodes() = mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// DeleteNodes deletes the nodes with the given IDs applying the delete policy to the attached edges.
+// IDs which do not exist, or are not nodes, are ignored and will not be reported in the result.
+func (s *Store) DeleteNodes(ctx context.Context, args store.DeleteNodesArgs) (store.DeleteResult, error) {
+	result := store.DeleteResult{
+		Nodes:    make([]models.Node, 0),
+		Edges:    make([]models.Edge, 0),
+		Detached: make([]models.Edge, 0),
+	}
+
+	if args.Policy == "" {
+		args.Policy = store.DeleteRestrict
+	}
+
+	switch args.Policy {
+	case store.DeleteRestrict, store.DeleteCascade, store.DeleteDetach:
+	default:
+		return result, fmt.Errorf("%w: unsupported delete policy: %s", store.ErrInvalid, args.Policy)
+	}
+
+	if len(args.IDs) == 0 {
+		return result, nil
+	}
+
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	defer tx.Rollback()
+
+	// lock the nodes so no edges can be attached while they are being deleted.
+	query := fmt.Sprintf(
+		`
+			SELECT %s
+			FROM items n
+			WHERE n.id = ANY($1::BIGINT[]) AND n.from_id = 0 AND n.to_id = 0
+			ORDER BY n.id
+			FOR UPDATE;
+		`,
+		nodeColumns,
+	)
+
+	rows, err := tx.QueryContext(ctx, query, int64s(args.IDs))
+	if err != nil {
+		return result, err
+	}
+
+	nodes, err := collectNodes(rows)
+	if err != nil {
+		return result, err
+	}
+
+	if len(nodes) == 0 {
+		return result, nil
+	}
+
+	nodeIDs := make([]uint64, len(nodes))
+	for i, n := range nodes {
+		nodeIDs[i] = n.ID
+	}
+
+	attached, err := edgesByNodeID(ctx, tx, nodeIDs...)
+	if err != nil {
+		return result, err
+	}
+
+	if len(attached) > 0 && args.Policy == store.DeleteRestrict {
+		return result, &store.RestrictError{Edges: attached}
+	}
+
+	edgeIDs := make([]uint64, len(attached))
+	for i, e := range attached {
+		edgeIDs[i] = e.ID
+	}
+
+	if err := deleteByID(ctx, tx, edgeIDs...); err != nil {
+		return result, err
+	}
+
+	if err := deleteByID(ctx, tx, nodeIDs...); err != nil {
+		return result, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, err
+	}
+
+	result.Nodes = nodes
+
+	switch args.Policy {
+	case store.DeleteDetach:
+		result.Detached = attached
+	default:
+		result.Edges = attached
+	}
+
+	return result, nil
+}
+
+// DeleteEdges deletes the edges with the given IDs returning the edges deleted.
+// IDs which do not exist, or are not edges, are ignored and will not be reported in the result.
+func (s *Store) DeleteEdges(ctx context.Context, ids ...uint64) ([]models.Edge, error) {
+	if len(ids) == 0 {
+		return []models.Edge{}, nil
+	}
+
+	query := fmt.Sprintf(
+		`
+			DELETE FROM items e
+			WHERE e.id = ANY($1::BIGINT[]) AND e.from_id > 0 AND e.to_id > 0
+			RETURNING %s;
+		`,
+		edgeColumns,
+	)
+
+	rows, err := s.db.QueryContext(ctx, query, int64s(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	edges, err := collectEdges(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(edges, func(a, b models.Edge) int { return cmp.Compare(a.ID, b.ID) })
+	return edges, nil
+}
+
+// edgesByNodeID is a helper used to retrieve all edges attached to the nodes with the given IDs.
+func edgesByNodeID(ctx context.Context, db querier, ids ...uint64) ([]models.Edge, error) {
+	query := fmt.Sprintf(
+		`
+			SELECT %s
+			FROM items e
+			WHERE e.from_id = ANY($1::BIGINT[]) OR e.to_id = ANY($1::BIGINT[])
+			ORDER BY e.id;
+		`,
+		edgeColumns,
+	)
+
+	rows, err := db.QueryContext(ctx, query, int64s(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	return collectEdges(rows)
+}
+
+// deleteByID is a helper used to delete all the items with the given IDs.
+func deleteByID(ctx context.Context, q querier, ids ...uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := q.ExecContext(ctx, `DELETE FROM items WHERE id = ANY($1::BIGINT[]);`, int64s(ids))
+	return err
+}
@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jenmud/edgedb/internal/store"
+)
+
+// jsonPath returns the quoted postgres path literal for the predicate, eg: `'{meta,hair}'`.
+// The path is validated by the predicate so it is safe to inline, inlining also lets postgres match
+// expression indexes on the same path.
+func jsonPath(p store.Predicate) string {
+	return "'{" + strings.Join(p.Keys(), ",") + "}'"
+}
+
+// compileFilter compiles the filter into SQL conditions on the table alias.
+// Each condition is prefixed with `AND` so the result can be appended to an existing `WHERE`.
+// Values are compared with `edgedb_compare` so they sort the same way as the other backends.
+func compileFilter(alias string, f store.Filter, p *params) (string, error) {
+	if err := f.Validate(); err != nil {
+		return "", err
+	}
+
+	var conds []string
+
+	if len(f.Labels) > 0 {
+		conds = append(conds, fmt.Sprintf("%s.label = ANY(%s::TEXT[])", alias, p.add(f.Labels)))
+	}
+
+	for _, pred := range f.Where {
+		extract := fmt.Sprintf("(%s.properties #> %s)", alias, jsonPath(pred))
+
+		switch pred.Op {
+		case store.OpExists:
+			conds = append(conds, extract+" IS NOT NULL")
+			continue
+		case store.OpNotExists:
+			conds = append(conds, extract+" IS NULL")
+			continue
+		}
+
+		value, err := json.Marshal(pred.Value)
+		if err != nil {
+			return "", fmt.Errorf("invalid value for %s: %w", pred.Path, err)
+		}
+
+		if pred.Op == store.OpIn {
+			conds = append(conds, fmt.Sprintf(
+				"EXISTS (SELECT 1 FROM jsonb_array_elements(%s::JSONB) v WHERE edgedb_compare(%s, v) = 0)",
+				p.add(string(value)),
+				extract,
+			))
+			continue
+		}
+
+		compare := fmt.Sprintf("edgedb_compare(%s, %s::JSONB)", extract, p.add(string(value)))
+
+		switch pred.Op {
+		case store.OpEq:
+			conds = append(conds, compare+" = 0")
+		case store.OpNe:
+			conds = append(conds, compare+" != 0")
+		case store.OpGt:
+			conds = append(conds, compare+" > 0")
+		case store.OpGte:
+			conds = append(conds, compare+" >= 0")
+		case store.OpLt:
+			conds = append(conds, compare+" < 0")
+		case store.OpLte:
+			conds = append(conds, compare+" <= 0")
+		}
+	}
+
+	ranges := []struct {
+		column string
+		r      store.TimeRange
+	}{
+		{column: "created_at", r: f.CreatedAt},
+		{column: "updated_at", r: f.UpdatedAt},
+	}
+
+	for _, tr := range ranges {
+		if !tr.r.After.IsZero() {
+			conds = append(conds, fmt.Sprintf("%s.%s >= %s", alias, tr.column, p.add(tr.r.After.Unix())))
+		}
+
+		if !tr.r.Before.IsZero() {
+			conds = append(conds, fmt.Sprintf("%s.%s < %s", alias, tr.column, p.add(tr.r.Before.Unix())))
+		}
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+
+	return "AND " + strings.Join(conds, " AND "), nil
+}
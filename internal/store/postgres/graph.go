@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// UpsertGraph inserts or updates the nodes and edges in a single transaction, either everything is written or nothing is.
+// Edges can reference nodes in the same graph using the node Ref with FromRef and ToRef, returning store.ErrInvalid
+// for duplicate or unknown references.
+func (s *Store) UpsertGraph(ctx context.Context, g models.Graph) (store.UpsertGraphResult, error) {
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		return store.UpsertGraphResult{}, err
+	}
+
+	defer tx.Rollback()
+
+	result, err := upsertGraph(ctx, tx, g)
+	if err != nil {
+		return result, err
+	}
+
+	return result, tx.Commit()
+}
+
+// CheckGraph writes the nodes and edges the same way as UpsertGraph returning the same errors, but always rolls back.
+func (s *Store) CheckGraph(ctx context.Context, g models.Graph) error {
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	_, err = upsertGraph(ctx, tx, g)
+	return err
+}
+
+// upsertGraph writes the nodes and edges using the transaction resolving the edge refs.
+func upsertGraph(ctx context.Context, tx *sql.Tx, g models.Graph) (store.UpsertGraphResult, error) {
+	result := store.UpsertGraphResult{
+		Nodes: []models.Node{},
+		Edges: []models.Edge{},
+		Refs:  map[string]uint64{},
+	}
+
+	if err := store.CheckRefs(g.Nodes); err != nil {
+		return result, err
+	}
+
+	nodes, err := upsertNodes(ctx, tx, g.Nodes...)
+	if err != nil {
+		return result, err
+	}
+
+	for i, n := range g.Nodes {
+		nodes[i].Ref = n.Ref
+		if n.Ref != "" {
+			result.Refs[n.Ref] = nodes[i].ID
+		}
+	}
+
+	edges, err := store.ResolveRefs(result.Refs, g.Edges)
+	if err != nil {
+		return result, err
+	}
+
+	upserted, err := upsertEdges(ctx, tx, edges...)
+	if err != nil {
+		return result, err
+	}
+
+	for i, e := range g.Edges {
+		upserted[i].FromRef = e.FromRef
+		upserted[i].ToRef = e.ToRef
+	}
+
+	result.Nodes = nodes
+	result.Edges = upserted
+
+	return result, nil
+}
@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+	"github.com/jenmud/edgedb/pkg/common"
+)
+
+// querier is implemented by both *sql.DB and *sql.Tx so helpers can be used inside and outside of a transaction.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// uniqueViolation is the postgres error code returned when a write breaks a unique index.
+const uniqueViolation = "23505"
+
+// isUniqueViolation returns true if the error is caused by a unique index, eg: two nodes with the same natural key.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// params collects the arguments of a query, postgres uses numbered `$1` placeholders.
+type params struct {
+	args []any
+}
+
+// add appends the value returning its placeholder.
+func (p *params) add(v any) string {
+	p.args = append(p.args, v)
+	return fmt.Sprintf("$%d", len(p.args))
+}
+
+// int64s converts the IDs into a slice which can be bound to a `BIGINT[]` placeholder.
+func int64s(ids []uint64) []int64 {
+	converted := make([]int64, len(ids))
+	for i, id := range ids {
+		converted[i] = int64(id)
+	}
+	return converted
+}
+
+// nodeColumns are the columns selected by scanNode.
+const nodeColumns = "n.id, n.created_at, n.updated_at, n.version, n.label, n.properties"
+
+// edgeColumns are the columns selected by scanEdge.
+const edgeColumns = "e.id, e.created_at, e.updated_at, e.version, e.from_id, e.label, e.to_id, e.weight, e.properties"
+
+// scanNode scans a row selected as `id, created_at, updated_at, version, label, properties` into a node.
+func scanNode(row scanner, extra ...any) (models.Node, error) {
+	n := models.Node{}
+
+	var createdAt int64
+	var updatedAt int64
+
+	var props []byte
+	if err := row.Scan(append([]any{&n.ID, &createdAt, &updatedAt, &n.Version, &n.Label, &props}, extra...)...); err != nil {
+		return n, err
+	}
+
+	if err := n.Properties.FromBytes(props); err != nil {
+		return n, err
+	}
+
+	n.CreatedAt = time.Unix(createdAt, 0)
+	n.UpdatedAt = time.Unix(updatedAt, 0)
+
+	return n, nil
+}
+
+// scanEdge scans a row selected as `id, created_at, updated_at, version, from_id, label, to_id, weight, properties` into an edge.
+func scanEdge(row scanner, extra ...any) (models.Edge, error) {
+	e := models.Edge{}
+
+	var createdAt int64
+	var updatedAt int64
+
+	var props []byte
+	if err := row.Scan(append([]any{&e.ID, &createdAt, &updatedAt, &e.Version, &e.From, &e.Label, &e.To, &e.Weight, &props}, extra...)...); err != nil {
+		return e, err
+	}
+
+	if err := e.Properties.FromBytes(props); err != nil {
+		return e, err
+	}
+
+	e.CreatedAt = time.Unix(createdAt, 0)
+	e.UpdatedAt = time.Unix(updatedAt, 0)
+
+	return e, nil
+}
+
+// collectNodes scans all the rows into nodes, closing the rows once done.
+func collectNodes(rows *sql.Rows) ([]models.Node, error) {
+	defer rows.Close()
+
+	nodes := []models.Node{}
+
+	for rows.Next() {
+		n, err := scanNode(rows)
+		if err != nil {
+			return nodes, err
+		}
+
+		nodes = append(nodes, n)
+	}
+
+	return nodes, rows.Err()
+}
+
+// collectEdges scans all the rows into edges, closing the rows once done.
+func collectEdges(rows *sql.Rows) ([]models.Edge, error) {
+	defer rows.Close()
+
+	edges := []models.Edge{}
+
+	for rows.Next() {
+		e, err := scanEdge(rows)
+		if err != nil {
+			return edges, err
+		}
+
+		edges = append(edges, e)
+	}
+
+	return edges, rows.Err()
+}
+
+// searchText is the text written to the term search vectors of an item.
+type searchText struct {
+	// keys and values are the flattened property keys and values, they are stored for snippets.
+	keys, values string
+
+	// labelTokens, keyTokens and valueTokens are the space separated tokens indexed by the search vectors.
+	labelTokens, keyTokens, valueTokens string
+}
+
+// newSearchText flattens the properties and splits the text with store.Tokenize, the tokens are joined with spaces
+// so postgres indexes the same words as the other backends.
+func newSearchText(label string, props models.Properties) searchText {
+	keys, values := common.FlattenMAP(props)
+
+	st := searchText{
+		keys:   strings.Join(keys, ","),
+		values: strings.Join(values, ","),
+	}
+
+	st.labelTokens = joinTokens(label)
+	st.keyTokens = joinTokens(st.keys)
+	st.valueTokens = joinTokens(st.values)
+
+	return st
+}
+
+// joinTokens returns the tokens of the text separated by spaces.
+func joinTokens(text string) string {
+	tokens := store.Tokenize(text)
+
+	words := make([]string, len(tokens))
+	for i, t := range tokens {
+		words[i] = t.Text
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,4 @@
+DROP FUNCTION IF EXISTS edgedb_compare(JSONB, JSONB);
+DROP TABLE IF EXISTS items;
+DROP TEXT SEARCH CONFIGURATION IF EXISTS edgedb;
+DROP TEXT SEARCH DICTIONARY IF EXISTS edgedb_stem;
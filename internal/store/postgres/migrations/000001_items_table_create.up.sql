@@ -0,0 +1,73 @@
+-- Migration to create the table used to store graph data, nodes have a from_id and to_id of 0.
+
+
+-- edgedb is the text search configuration, it stems english words like the sqlite porter tokenizer but keeps stop
+-- words so every word can be searched.
+CREATE TEXT SEARCH DICTIONARY edgedb_stem (TEMPLATE = snowball, Language = english);
+CREATE TEXT SEARCH CONFIGURATION edgedb (COPY = english);
+ALTER TEXT SEARCH CONFIGURATION edgedb
+    ALTER MAPPING FOR asciiword, asciihword, hword_asciipart, word, hword, hword_part WITH edgedb_stem;
+
+
+CREATE TABLE IF NOT EXISTS items (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    created_at BIGINT NOT NULL DEFAULT extract(epoch FROM now())::BIGINT,
+    updated_at BIGINT NOT NULL DEFAULT extract(epoch FROM now())::BIGINT,
+    from_id BIGINT NOT NULL DEFAULT 0,
+    label TEXT NOT NULL,
+    to_id BIGINT NOT NULL DEFAULT 0,
+    weight BIGINT NOT NULL DEFAULT 0,
+    properties JSONB NOT NULL DEFAULT '{}',
+
+    -- the flattened property keys and values, they are used for snippets.
+    prop_keys TEXT NOT NULL DEFAULT '',
+    prop_values TEXT NOT NULL DEFAULT '',
+
+    -- the term search vectors are written by the store so the text is split the same way for every backend.
+    search_label TSVECTOR NOT NULL DEFAULT ''::TSVECTOR,
+    search_keys TSVECTOR NOT NULL DEFAULT ''::TSVECTOR,
+    search_values TSVECTOR NOT NULL DEFAULT ''::TSVECTOR
+);
+
+
+CREATE INDEX IF NOT EXISTS idx_label        ON items(label);
+CREATE INDEX IF NOT EXISTS idx_edges_from   ON items(from_id);
+CREATE INDEX IF NOT EXISTS idx_edges_to     ON items(to_id);
+CREATE INDEX IF NOT EXISTS idx_edges_flt    ON items(from_id, label, to_id);
+
+CREATE INDEX IF NOT EXISTS idx_search_label  ON items USING GIN (search_label);
+CREATE INDEX IF NOT EXISTS idx_search_keys   ON items USING GIN (search_keys);
+CREATE INDEX IF NOT EXISTS idx_search_values ON items USING GIN (search_values);
+
+
+-- edgedb_compare compares two JSON values the same way for every backend, numbers and booleans (as 1 and 0) sort
+-- before strings, and everything else is compared as JSON text. Nulls are not comparable and return NULL.
+CREATE OR REPLACE FUNCTION edgedb_compare(a JSONB, b JSONB) RETURNS INTEGER
+LANGUAGE plpgsql IMMUTABLE AS $$
+DECLARE
+    an NUMERIC;
+    bn NUMERIC;
+    at TEXT;
+    bt TEXT;
+BEGIN
+    IF a IS NULL OR b IS NULL OR jsonb_typeof(a) = 'null' OR jsonb_typeof(b) = 'null' THEN
+        RETURN NULL;
+    END IF;
+
+    an := CASE jsonb_typeof(a) WHEN 'number' THEN a::NUMERIC WHEN 'boolean' THEN a::BOOLEAN::INTEGER END;
+    bn := CASE jsonb_typeof(b) WHEN 'number' THEN b::NUMERIC WHEN 'boolean' THEN b::BOOLEAN::INTEGER END;
+
+    IF an IS NOT NULL AND bn IS NOT NULL THEN
+        RETURN sign(an - bn)::INTEGER;
+    ELSIF an IS NOT NULL THEN
+        RETURN -1;
+    ELSIF bn IS NOT NULL THEN
+        RETURN 1;
+    END IF;
+
+    at := CASE jsonb_typeof(a) WHEN 'string' THEN a #>> '{}' ELSE a::TEXT END;
+    bt := CASE jsonb_typeof(b) WHEN 'string' THEN b #>> '{}' ELSE b::TEXT END;
+
+    RETURN CASE WHEN at COLLATE "C" < bt COLLATE "C" THEN -1 WHEN at COLLATE "C" > bt COLLATE "C" THEN 1 ELSE 0 END;
+END;
+$$;
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// Paths returns up to K paths between two nodes, returning store.ErrNotFound if there is no path.
+func (s *Store) Paths(ctx context.Context, args store.PathArgs) ([]models.Path, error) {
+	if args.Direction == "" {
+		args.Direction = store.DirectionOut
+	}
+
+	var where string
+
+	switch args.Direction {
+	case store.DirectionOut:
+		where = "e.from_id = $1"
+	case store.DirectionIn:
+		where = "e.to_id = $1"
+	default:
+		where = "(e.from_id = $1 OR e.to_id = $1)"
+	}
+
+	if len(args.EdgeLabels) > 0 {
+		where += " AND e.label = ANY($2::TEXT[])"
+	}
+
+	query := fmt.Sprintf(
+		`
+		SELECT %s
+		FROM items e
+		WHERE e.from_id > 0 AND e.to_id > 0 AND %s
+		ORDER BY e.id;
+		`,
+		edgeColumns,
+		where,
+	)
+
+	adjacent := func(ctx context.Context, id uint64) ([]models.Edge, error) {
+		queryArgs := []any{int64(id)}
+		if len(args.EdgeLabels) > 0 {
+			queryArgs = append(queryArgs, args.EdgeLabels)
+		}
+
+		rows, err := s.db.QueryContext(ctx, query, queryArgs...)
+		if err != nil {
+			return nil, err
+		}
+
+		return collectEdges(rows)
+	}
+
+	nodes := func(ctx context.Context, ids ...uint64) ([]models.Node, error) {
+		return nodesByID(ctx, s.db, ids...)
+	}
+
+	return store.FindPaths(ctx, args, adjacent, nodes)
+}
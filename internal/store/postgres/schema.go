@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// scanSchema scans a row selected as `definition, created_at, updated_at` into a schema.
+func scanSchema(row scanner) (models.Schema, error) {
+	schema := models.Schema{}
+
+	var definition []byte
+	var createdAt int64
+	var updatedAt int64
+
+	if err := row.Scan(&definition, &createdAt, &updatedAt); err != nil {
+		return schema, err
+	}
+
+	if err := json.Unmarshal(definition, &schema); err != nil {
+		return schema, err
+	}
+
+	schema.CreatedAt = time.Unix(createdAt, 0)
+	schema.UpdatedAt = time.Unix(updatedAt, 0)
+
+	return schema, nil
+}
+
+// schemas is a helper returning all the schemas ordered by kind and label.
+func schemas(ctx context.Context, q querier) ([]models.Schema, error) {
+	rows, err := q.QueryContext(ctx, `SELECT definition, created_at, updated_at FROM schemas ORDER BY kind, label;`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	schemas := []models.Schema{}
+
+	for rows.Next() {
+		schema, err := scanSchema(rows)
+		if err != nil {
+			return schemas, err
+		}
+
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, rows.Err()
+}
+
+// schemaSet is a helper returning the set of schemas used to check writes.
+func schemaSet(ctx context.Context, q querier) (store.SchemaSet, error) {
+	schemas, err := schemas(ctx, q)
+	if err != nil {
+		return store.SchemaSet{}, err
+	}
+
+	return store.NewSchemaSet(schemas...), nil
+}
+
+// nodeLabel returns a store.NodeLabelFunc looking up node labels, nodes written in the same transaction are found.
+func nodeLabel(ctx context.Context, q querier) store.NodeLabelFunc {
+	return func(id uint64) (string, bool, error) {
+		var label string
+
+		err := q.QueryRowContext(ctx, `SELECT label FROM items WHERE id = $1 AND from_id = 0 AND to_id = 0;`, int64(id)).Scan(&label)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", false, nil
+		}
+
+		return label, err == nil, err
+	}
+}
+
+// Schemas returns all the schemas ordered by kind and label.
+func (s *Store) Schemas(ctx context.Context) ([]models.Schema, error) {
+	return schemas(ctx, s.db)
+}
+
+// Schema returns the schema for the kind and label, returning store.ErrNotFound if there is no such schema.
+func (s *Store) Schema(ctx context.Context, kind models.SchemaKind, label string) (models.Schema, error) {
+	row := s.db.QueryRowContext(
+		ctx,
+		`SELECT definition, created_at, updated_at FROM schemas WHERE kind = $1 AND label = $2;`,
+		string(kind),
+		label,
+	)
+
+	schema, err := scanSchema(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Schema{}, fmt.Errorf("%w: %s schema %q", store.ErrNotFound, kind, label)
+		}
+		return models.Schema{}, err
+	}
+
+	return schema, nil
+}
+
+// UpsertSchema inserts or replaces the schema for its kind and label, returning store.ErrInvalid if the schema is not valid.
+func (s *Store) UpsertSchema(ctx context.Context, schema models.Schema) (models.Schema, error) {
+	if err := store.ValidateSchema(&schema); err != nil {
+		return models.Schema{}, err
+	}
+
+	definition, err := json.Marshal(schema)
+	if err != nil {
+		return models.Schema{}, err
+	}
+
+	row := s.db.QueryRowContext(
+		ctx,
+		`
+		INSERT INTO schemas (kind, label, definition)
+		VALUES ($1, $2, $3::JSONB)
+		ON CONFLICT(kind, label) DO UPDATE SET
+			definition = excluded.definition,
+			updated_at = extract(epoch FROM now())::BIGINT
+		RETURNING definition, created_at, updated_at;
+		`,
+		string(schema.Kind),
+		schema.Label,
+		string(definition),
+	)
+
+	return scanSchema(row)
+}
+
+// DeleteSchema deletes the schema for the kind and label returning the deleted schema,
+// returning store.ErrNotFound if there is no such schema.
+func (s *Store) DeleteSchema(ctx context.Context, kind models.SchemaKind, label string) (models.Schema, error) {
+	row := s.db.QueryRowContext(
+		ctx,
+		`DELETE FROM schemas WHERE kind = $1 AND label = $2 RETURNING definition, created_at, updated_at;`,
+		string(kind),
+		label,
+	)
+
+	schema, err := scanSchema(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Schema{}, fmt.Errorf("%w: %s schema %q", store.ErrNotFound, kind, label)
+		}
+		return models.Schema{}, err
+	}
+
+	return schema, nil
+}
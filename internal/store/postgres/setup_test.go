@@ -0,0 +1,176 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jenmud/edgedb/internal/store/postgres"
+)
+
+// A no-op handler that discards all log messages.
+type noopHandler struct{}
+
+func (h *noopHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (h *noopHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h *noopHandler) WithAttrs([]slog.Attr) slog.Handler        { return h }
+func (h *noopHandler) WithGroup(string) slog.Handler             { return h }
+
+// dsn is the DSN of the postgres server used by the tests, tests are skipped if it is empty.
+var dsn string
+
+// skipReason explains why there is no postgres server.
+var skipReason string
+
+// schemas is used to give every store a unique schema.
+var schemas atomic.Int64
+
+// TestMain runs before any tests and applies globally for all tests in the package.
+//
+// The tests use the server in EDGEDB_TEST_POSTGRES_DSN, otherwise a throwaway server is started using the postgres
+// binaries found in EDGEDB_TEST_POSTGRES_BIN or the PATH. The tests are skipped if there is no server, unless
+// EDGEDB_TEST_POSTGRES_REQUIRED is set in which case they fail.
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(&noopHandler{}))
+
+	stop := func() {}
+
+	dsn = os.Getenv("EDGEDB_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		started, stopServer, err := startServer()
+		if err != nil {
+			skipReason = err.Error()
+		} else {
+			dsn, stop = started, stopServer
+		}
+	}
+
+	exitVal := m.Run()
+	stop()
+	os.Exit(exitVal)
+}
+
+// startServer starts a postgres server in a temporary directory returning the DSN and a function stopping the server.
+func startServer() (string, func(), error) {
+	if bin := os.Getenv("EDGEDB_TEST_POSTGRES_BIN"); bin != "" {
+		os.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	}
+
+	initdb, err := exec.LookPath("initdb")
+	if err != nil {
+		return "", nil, fmt.Errorf("no postgres server, set EDGEDB_TEST_POSTGRES_DSN or EDGEDB_TEST_POSTGRES_BIN: %w", err)
+	}
+
+	pgctl, err := exec.LookPath("pg_ctl")
+	if err != nil {
+		return "", nil, fmt.Errorf("no postgres server, set EDGEDB_TEST_POSTGRES_DSN or EDGEDB_TEST_POSTGRES_BIN: %w", err)
+	}
+
+	if os.Geteuid() == 0 {
+		return "", nil, fmt.Errorf("postgres can not be started as root, set EDGEDB_TEST_POSTGRES_DSN")
+	}
+
+	dir, err := os.MkdirTemp("", "edgedb-postgres-")
+	if err != nil {
+		return "", nil, err
+	}
+
+	data := filepath.Join(dir, "data")
+
+	out, err := exec.Command(initdb, "-D", data, "-U", "postgres", "-A", "trust", "--no-locale", "-E", "UTF8").CombinedOutput()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", nil, fmt.Errorf("initdb failed: %w: %s", err, out)
+	}
+
+	port, err := freePort()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", nil, err
+	}
+
+	// only listen on a unix socket in the temporary directory.
+	opts := fmt.Sprintf("-k %s -p %d -c listen_addresses=''", dir, port)
+
+	out, err = exec.Command(pgctl, "-D", data, "-l", filepath.Join(dir, "postgres.log"), "-o", opts, "-w", "start").CombinedOutput()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", nil, fmt.Errorf("pg_ctl start failed: %w: %s", err, out)
+	}
+
+	stop := func() {
+		exec.Command(pgctl, "-D", data, "-m", "immediate", "-w", "stop").Run()
+		os.RemoveAll(dir)
+	}
+
+	return fmt.Sprintf("host=%s port=%d user=postgres dbname=postgres sslmode=disable", dir, port), stop, nil
+}
+
+// freePort returns a port which is not in use, postgres uses it to name the unix socket.
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// withSearchPath returns the DSN using the schema.
+func withSearchPath(dsn, schema string) string {
+	if u, err := url.Parse(dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		q := u.Query()
+		q.Set("search_path", schema)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	return strings.TrimSpace(dsn) + " search_path=" + schema
+}
+
+// newStore returns a new store in its own schema, the schema is dropped when the test is done.
+func newStore(t *testing.T) *postgres.Store {
+	t.Helper()
+
+	if dsn == "" {
+		if os.Getenv("EDGEDB_TEST_POSTGRES_REQUIRED") != "" {
+			t.Fatal(skipReason)
+		}
+		t.Skip(skipReason)
+	}
+
+	ctx := t.Context()
+
+	admin, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("edgedb_test_%d_%d", os.Getpid(), schemas.Add(1))
+
+	if _, err := admin.ExecContext(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		admin.ExecContext(context.Background(), "DROP SCHEMA "+schema+" CASCADE")
+	})
+
+	s, err := postgres.New(ctx, withSearchPath(dsn, schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { s.Close() })
+	return s
+}
@@ -0,0 +1,253 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// neighbourhood walks the graph from the seeds returning the reached node IDs and their depth, closest first.
+//
+// The walk expands a hop at a time and only keeps the nodes it has not reached before, so each node is kept at its
+// shortest depth and the walk stops as soon as a hop reaches no new nodes, however deep it is allowed to go.
+func neighbourhood(ctx context.Context, tx *sql.Tx, seeds []uint64, args store.SubGraphArgs) ([]int64, []int64, error) {
+	p := &params{}
+	frontierParam := p.add(nil)
+
+	labelFilter := ""
+	if len(args.EdgeLabels) > 0 {
+		labelFilter = fmt.Sprintf("AND e.label = ANY(%s::TEXT[])", p.add(args.EdgeLabels))
+	}
+
+	out := fmt.Sprintf(
+		`
+		SELECT e.to_id
+		FROM items e
+		WHERE e.from_id = ANY(%s::BIGINT[]) AND e.to_id > 0 %s
+		`,
+		frontierParam,
+		labelFilter,
+	)
+
+	in := fmt.Sprintf(
+		`
+		SELECT e.from_id
+		FROM items e
+		WHERE e.to_id = ANY(%s::BIGINT[]) AND e.from_id > 0 %s
+		`,
+		frontierParam,
+		labelFilter,
+	)
+
+	var step string
+
+	switch args.Direction {
+	case store.DirectionOut:
+		step = out
+	case store.DirectionIn:
+		step = in
+	case store.DirectionBoth:
+		step = out + "UNION" + in
+	default:
+		return nil, nil, fmt.Errorf("%w: unsupported direction: %s", store.ErrInvalid, args.Direction)
+	}
+
+	seen := map[uint64]struct{}{}
+	ids := []int64{}
+	depths := []int64{}
+	frontier := []uint64{}
+
+	for _, id := range seeds {
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			ids = append(ids, int64(id))
+			depths = append(depths, 0)
+			frontier = append(frontier, id)
+		}
+	}
+
+	for depth := 1; depth <= args.Depth && len(frontier) > 0; depth++ {
+		p.args[0] = int64s(frontier)
+
+		rows, err := tx.QueryContext(ctx, step, p.args...)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		frontier = []uint64{}
+
+		for rows.Next() {
+			var id uint64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, nil, err
+			}
+
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				ids = append(ids, int64(id))
+				depths = append(depths, int64(depth))
+				frontier = append(frontier, id)
+			}
+		}
+
+		if err := rows.Close(); err != nil {
+			return nil, nil, err
+		}
+
+		if err := rows.Err(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return ids, depths, nil
+}
+
+// hood returns the `hood` CTE holding the walked nodes and their depth limited to the node budget, closest first.
+func hood(ids, depths []int64, budget int, p *params) string {
+	return fmt.Sprintf(
+		`
+		WITH
+		hood(id, depth) AS (
+			SELECT n.id, w.depth
+			FROM unnest(%s::BIGINT[], %s::BIGINT[]) AS w(id, depth)
+			JOIN items n ON n.id = w.id AND n.from_id = 0 AND n.to_id = 0
+			ORDER BY w.depth, n.id
+			LIMIT %s
+		)
+		`,
+		p.add(ids),
+		p.add(depths),
+		p.add(limit(budget)),
+	)
+}
+
+// SubGraph returns a new sub-graph expanding N hops from the starting point.
+// The starting points are the FromNodeID, ToNodeID and the nodes of EdgeID.
+func (s *Store) SubGraph(ctx context.Context, args store.SubGraphArgs) (models.Graph, error) {
+	graph := models.Graph{
+		Nodes: make([]models.Node, 0),
+		Edges: make([]models.Edge, 0),
+	}
+
+	if args.Depth <= 0 {
+		args.Depth = 1
+	}
+
+	args.Depth = min(args.Depth, store.MaxSubGraphDepth)
+
+	if args.Direction == "" {
+		args.Direction = store.DirectionBoth
+	}
+
+	if args.Limit == 0 {
+		args.Limit = DefaultLimit
+	}
+
+	seeds := []uint64{}
+
+	if args.FromNodeID > 0 {
+		seeds = append(seeds, args.FromNodeID)
+	}
+
+	if args.ToNodeID > 0 {
+		seeds = append(seeds, args.ToNodeID)
+	}
+
+	// if we have a edge ID, then fetch it from the db and walk from both ends of it.
+	if args.EdgeID > 0 {
+		edge, err := s.Edge(ctx, args.EdgeID)
+		if err != nil {
+			return models.Graph{}, err
+		}
+
+		seeds = append(seeds, edge.From, edge.To)
+	}
+
+	if len(seeds) == 0 {
+		return graph, nil
+	}
+
+	// use a single snapshot so that the nodes and edges are from the same point in time.
+	tx, err := s.snapshot(ctx)
+	if err != nil {
+		return graph, err
+	}
+
+	defer tx.Rollback()
+
+	ids, depths, err := neighbourhood(ctx, tx, seeds, args)
+	if err != nil {
+		return graph, err
+	}
+
+	p := &params{}
+	cte := hood(ids, depths, args.Limit, p)
+
+	rows, err := tx.QueryContext(
+		ctx,
+		cte+fmt.Sprintf(
+			`
+			SELECT %s
+			FROM hood h
+			JOIN items n ON n.id = h.id
+			ORDER BY h.depth, n.id;
+			`,
+			nodeColumns,
+		),
+		p.args...,
+	)
+
+	if err != nil {
+		return graph, err
+	}
+
+	nodes, err := collectNodes(rows)
+	if err != nil {
+		return graph, err
+	}
+
+	graph.AddNodes(nodes...)
+
+	p = &params{}
+	cte = hood(ids, depths, args.Limit, p)
+
+	labelFilter := ""
+	if len(args.EdgeLabels) > 0 {
+		labelFilter = fmt.Sprintf("AND e.label = ANY(%s::TEXT[])", p.add(args.EdgeLabels))
+	}
+
+	// only the edges between the nodes in the neighbourhood are returned.
+	rows, err = tx.QueryContext(
+		ctx,
+		cte+fmt.Sprintf(
+			`
+			SELECT %s
+			FROM items e
+			WHERE
+				e.from_id IN (SELECT id FROM hood)
+				AND e.to_id IN (SELECT id FROM hood)
+				%s
+			ORDER BY e.id;
+			`,
+			edgeColumns,
+			labelFilter,
+		),
+		p.args...,
+	)
+
+	if err != nil {
+		return graph, err
+	}
+
+	edges, err := collectEdges(rows)
+	if err != nil {
+		return graph, err
+	}
+
+	graph.AddEdges(edges...)
+	return graph, tx.Commit()
+}
@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenmud/edgedb/internal/store"
+)
+
+// textSearchConfig is the text search configuration created by the migrations, see the migrations for details.
+const textSearchConfig = "edgedb"
+
+// termVectors returns the tsvector expression for each searchable column of the table alias.
+// The id and type vectors are built when searching, they are short and rarely searched.
+func termVectors(alias string) map[string]string {
+	return map[string]string{
+		"id":          fmt.Sprintf("to_tsvector('%s', %s.id::TEXT)", textSearchConfig, alias),
+		"type":        fmt.Sprintf("to_tsvector('%s', CASE WHEN %[2]s.from_id = 0 AND %[2]s.to_id = 0 THEN 'node' ELSE 'edge' END)", textSearchConfig, alias),
+		"label":       alias + ".search_label",
+		"prop_keys":   alias + ".search_keys",
+		"prop_values": alias + ".search_values",
+	}
+}
+
+// tsquery returns the tsquery text for the phrase, each token is quoted so it is never read as an operator.
+func tsquery(ph *store.TermPhrase) string {
+	quoted := make([]string, len(ph.Tokens))
+	for i, t := range ph.Tokens {
+		quoted[i] = "'" + strings.ReplaceAll(t, "'", "''") + "'"
+	}
+
+	if ph.Prefix {
+		quoted[len(quoted)-1] += ":*"
+	}
+
+	return strings.Join(quoted, " <-> ")
+}
+
+// compiledTerm is a search term compiled into SQL.
+type compiledTerm struct {
+	// where is the condition matching the items.
+	where string
+
+	// query is a tsquery matching any of the phrases, it is used for ranking and snippets.
+	query string
+}
+
+// compileTerm parses and compiles the search term into SQL on the table alias.
+func compileTerm(alias, term string, p *params) (compiledTerm, error) {
+	parsed, err := store.ParseTerm(term)
+	if err != nil {
+		return compiledTerm{}, err
+	}
+
+	vectors := termVectors(alias)
+	queries := map[*store.TermPhrase]string{}
+
+	var compile func(t store.Term) string
+	compile = func(t store.Term) string {
+		switch t := t.(type) {
+		case *store.TermOp:
+			left, right := compile(t.Left), compile(t.Right)
+
+			switch t.Op {
+			case store.TermAnd:
+				return fmt.Sprintf("(%s AND %s)", left, right)
+			case store.TermOr:
+				return fmt.Sprintf("(%s OR %s)", left, right)
+			default:
+				return fmt.Sprintf("(%s AND NOT %s)", left, right)
+			}
+
+		case *store.TermPhrase:
+			if len(t.Tokens) == 0 || len(t.Columns) == 0 {
+				return "FALSE"
+			}
+
+			query := fmt.Sprintf("to_tsquery('%s', %s)", textSearchConfig, p.add(tsquery(t)))
+			queries[t] = query
+
+			conds := make([]string, len(t.Columns))
+			for i, c := range t.Columns {
+				conds[i] = fmt.Sprintf("%s @@ %s", vectors[c], query)
+			}
+
+			return "(" + strings.Join(conds, " OR ") + ")"
+		}
+
+		return "FALSE"
+	}
+
+	compiled := compiledTerm{where: compile(parsed)}
+
+	matches := []string{}
+	for _, ph := range store.Phrases(parsed) {
+		if q, ok := queries[ph]; ok {
+			matches = append(matches, q)
+		}
+	}
+
+	compiled.query = "''::TSQUERY"
+	if len(matches) > 0 {
+		compiled.query = "(" + strings.Join(matches, " || ") + ")"
+	}
+
+	return compiled, nil
+}
+
+// rank returns the SQL ranking the item, higher is better.
+func (c compiledTerm) rank(alias string) string {
+	return fmt.Sprintf("ts_rank(%[1]s.search_label || %[1]s.search_keys || %[1]s.search_values, %[2]s)", alias, c.query)
+}
+
+// snippet markers are replaced with the snippet start and end once read, ts_headline options can not safely hold
+// arbitrary HTML.
+const (
+	snippetStartMarker = "\x01"
+	snippetEndMarker   = "\x02"
+)
+
+// snippet returns the SQL building a snippet of the best matching column with up to n words.
+func (c compiledTerm) snippet(alias string, n int, p *params) string {
+	if n <= 0 {
+		return "''"
+	}
+
+	// ts_headline needs MinWords to be less than MaxWords.
+	n = max(n, 2)
+
+	options := fmt.Sprintf(
+		"StartSel=\"%s\", StopSel=\"%s\", MaxWords=%d, MinWords=%d, MaxFragments=1, FragmentDelimiter=\" ... \"",
+		snippetStartMarker,
+		snippetEndMarker,
+		n,
+		max(1, n/2),
+	)
+
+	return fmt.Sprintf(
+		`ts_headline(
+			'%[1]s',
+			CASE
+				WHEN %[2]s.search_values @@ %[3]s THEN %[2]s.prop_values
+				WHEN %[2]s.search_keys @@ %[3]s THEN %[2]s.prop_keys
+				WHEN %[2]s.search_label @@ %[3]s THEN %[2]s.label
+				ELSE ''
+			END,
+			%[3]s,
+			%[4]s
+		)`,
+		textSearchConfig,
+		alias,
+		c.query,
+		p.add(options),
+	)
+}
+
+// highlight replaces the snippet markers with the start and end tags.
+func highlight(snippet, start, end string) string {
+	return strings.NewReplacer(snippetStartMarker, start, snippetEndMarker, end).Replace(snippet)
+}
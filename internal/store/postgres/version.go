@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jenmud/edgedb/internal/store"
+)
+
+// checkNodeVersion returns a *store.VersionError holding the current node if the expected version is not the current
+// version of the node. The node is locked until the transaction ends so it can not change before it is written.
+func checkNodeVersion(ctx context.Context, tx *sql.Tx, id, expected uint64) error {
+	query := fmt.Sprintf(`SELECT %s FROM items n WHERE n.id = $1 AND n.from_id = 0 AND n.to_id = 0 FOR UPDATE;`, nodeColumns)
+
+	n, err := scanNode(tx.QueryRowContext(ctx, query, int64(id)))
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return &store.VersionError{ID: id, Expected: expected}
+	case err != nil:
+		return err
+	case n.Version != expected:
+		return &store.VersionError{ID: id, Expected: expected, Node: &n}
+	}
+
+	return nil
+}
+
+// checkEdgeVersion returns a *store.VersionError holding the current edge if the expected version is not the current
+// version of the edge. The edge is locked until the transaction ends so it can not change before it is written.
+func checkEdgeVersion(ctx context.Context, tx *sql.Tx, id, expected uint64) error {
+	query := fmt.Sprintf(`SELECT %s FROM items e WHERE e.id = $1 AND e.from_id > 0 AND e.to_id > 0 FOR UPDATE;`, edgeColumns)
+
+	e, err := scanEdge(tx.QueryRowContext(ctx, query, int64(id)))
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return &store.VersionError{ID: id, Expected: expected}
+	case err != nil:
+		return err
+	case e.Version != expected:
+		return &store.VersionError{ID: id, Expected: expected, Edge: &e}
+	}
+
+	return nil
+}
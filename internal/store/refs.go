@@ -0,0 +1,65 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/jenmud/edgedb/models"
+)
+
+// CheckRefs returns ErrInvalid if two or more nodes use the same reference.
+func CheckRefs(nodes []models.Node) error {
+	seen := make(map[string]struct{}, len(nodes))
+
+	for _, n := range nodes {
+		if n.Ref == "" {
+			continue
+		}
+
+		if _, ok := seen[n.Ref]; ok {
+			return fmt.Errorf("%w: duplicate node ref %q", ErrInvalid, n.Ref)
+		}
+		seen[n.Ref] = struct{}{}
+	}
+
+	return nil
+}
+
+// ResolveRefs returns a copy of the edges with FromRef and ToRef resolved to node IDs using the refs,
+// returning ErrInvalid for unknown references or references which do not match the edge node IDs.
+func ResolveRefs(refs map[string]uint64, edges []models.Edge) ([]models.Edge, error) {
+	resolved := make([]models.Edge, len(edges))
+
+	resolve := func(id uint64, ref string) (uint64, error) {
+		if ref == "" {
+			return id, nil
+		}
+
+		refID, ok := refs[ref]
+		if !ok {
+			return 0, fmt.Errorf("%w: unknown node ref %q", ErrInvalid, ref)
+		}
+
+		if id != 0 && id != refID {
+			return 0, fmt.Errorf("%w: node ref %q is node %d but the edge uses node %d", ErrInvalid, ref, refID, id)
+		}
+
+		return refID, nil
+	}
+
+	for i, e := range edges {
+		from, err := resolve(e.From, e.FromRef)
+		if err != nil {
+			return nil, fmt.Errorf("edge %d from: %w", i, err)
+		}
+
+		to, err := resolve(e.To, e.ToRef)
+		if err != nil {
+			return nil, fmt.Errorf("edge %d to: %w", i, err)
+		}
+
+		e.From, e.To = from, to
+		resolved[i] = e
+	}
+
+	return resolved, nil
+}
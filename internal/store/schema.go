@@ -0,0 +1,370 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/jenmud/edgedb/models"
+)
+
+// propertyTypes are the JSON Schema types supported by property schemas.
+var propertyTypes = []string{"string", "number", "integer", "boolean", "object", "array", "null"}
+
+// patterns caches the compiled schema patterns.
+var patterns sync.Map
+
+// compilePattern returns the compiled pattern, patterns are cached as they are used for every write.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns.Store(pattern, re)
+	return re, nil
+}
+
+// SchemaError is returned when an item does not match the strict schema of its label.
+type SchemaError struct {
+	// Kind and Label identify the schema.
+	Kind  models.SchemaKind `json:"kind"`
+	Label string            `json:"label"`
+
+	// ID is the ID of the item, it is 0 for new items.
+	ID uint64 `json:"id,omitempty"`
+
+	// Violations describe every way the item does not match the schema.
+	Violations []string `json:"violations"`
+}
+
+// Error implements the error interface.
+func (e *SchemaError) Error() string {
+	item := string(e.Kind)
+	if e.ID > 0 {
+		item = fmt.Sprintf("%s %d", e.Kind, e.ID)
+	}
+
+	return fmt.Sprintf("%s: %s with label %q does not match its schema: %s", ErrInvalid, item, e.Label, strings.Join(e.Violations, "; "))
+}
+
+// Unwrap allows errors.Is(err, ErrInvalid) to match.
+func (e *SchemaError) Unwrap() error {
+	return ErrInvalid
+}
+
+// ValidateSchema returns ErrInvalid if the schema can not be used, eg: an unknown type or a pattern which does not compile.
+// A missing mode defaults to models.SchemaStrict.
+func ValidateSchema(s *models.Schema) error {
+	switch s.Kind {
+	case models.SchemaNode:
+		if len(s.FromLabels) > 0 || len(s.ToLabels) > 0 {
+			return fmt.Errorf("%w: from and to labels can only be used by edge schemas", ErrInvalid)
+		}
+	case models.SchemaEdge:
+	default:
+		return fmt.Errorf("%w: unsupported schema kind %q, expected node or edge", ErrInvalid, s.Kind)
+	}
+
+	if s.Label == "" {
+		return fmt.Errorf("%w: schema label is required", ErrInvalid)
+	}
+
+	switch s.Mode {
+	case "":
+		s.Mode = models.SchemaStrict
+	case models.SchemaStrict, models.SchemaWarn:
+	default:
+		return fmt.Errorf("%w: unsupported schema mode %q, expected strict or warn", ErrInvalid, s.Mode)
+	}
+
+	object := models.PropertySchema{Type: "object", Properties: s.Properties, Required: s.Required}
+	return validatePropertySchema("properties", object)
+}
+
+// validatePropertySchema returns ErrInvalid if the property schema at the path can not be used.
+func validatePropertySchema(path string, p models.PropertySchema) error {
+	if p.Type != "" && !slices.Contains(propertyTypes, p.Type) {
+		return fmt.Errorf("%w: %s: unsupported type %q, expected one of %s", ErrInvalid, path, p.Type, strings.Join(propertyTypes, ", "))
+	}
+
+	if p.Pattern != "" {
+		if _, err := compilePattern(p.Pattern); err != nil {
+			return fmt.Errorf("%w: %s: invalid pattern: %s", ErrInvalid, path, err)
+		}
+	}
+
+	if p.Minimum != nil && p.Maximum != nil && *p.Minimum > *p.Maximum {
+		return fmt.Errorf("%w: %s: minimum is greater than maximum", ErrInvalid, path)
+	}
+
+	if p.MinLength != nil && p.MaxLength != nil && *p.MinLength > *p.MaxLength {
+		return fmt.Errorf("%w: %s: minLength is greater than maxLength", ErrInvalid, path)
+	}
+
+	if p.Items != nil {
+		if err := validatePropertySchema(path+"[]", *p.Items); err != nil {
+			return err
+		}
+	}
+
+	for name, prop := range p.Properties {
+		if err := validatePropertySchema(path+"."+name, prop); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// jsonValue returns the value as it is stored, eg: integers are float64 and structs are maps.
+func jsonValue(v any) any {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+
+	var decoded any
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return v
+	}
+
+	return decoded
+}
+
+// typeOf returns the JSON Schema type of a decoded JSON value.
+func typeOf(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// checkType returns true if the decoded JSON value has the type, integers are numbers without a fraction.
+func checkType(v any, typ string) bool {
+	got := typeOf(v)
+
+	if typ == "integer" {
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	}
+
+	return got == typ
+}
+
+// checkProperty appends the violations of the decoded JSON value at the path.
+func checkProperty(path string, v any, p models.PropertySchema, violations []string) []string {
+	if p.Type != "" && !checkType(v, p.Type) {
+		return append(violations, fmt.Sprintf("%s must be of type %s, got %s", path, p.Type, typeOf(v)))
+	}
+
+	if len(p.Enum) > 0 {
+		found := slices.ContainsFunc(p.Enum, func(allowed any) bool {
+			return reflect.DeepEqual(jsonValue(allowed), v)
+		})
+
+		if !found {
+			allowed, _ := json.Marshal(p.Enum)
+			violations = append(violations, fmt.Sprintf("%s must be one of %s", path, allowed))
+		}
+	}
+
+	switch v := v.(type) {
+	case string:
+		if p.Pattern != "" {
+			if re, err := compilePattern(p.Pattern); err == nil && !re.MatchString(v) {
+				violations = append(violations, fmt.Sprintf("%s must match the pattern %q", path, p.Pattern))
+			}
+		}
+
+		length := utf8.RuneCountInString(v)
+
+		if p.MinLength != nil && length < *p.MinLength {
+			violations = append(violations, fmt.Sprintf("%s must be at least %d characters", path, *p.MinLength))
+		}
+
+		if p.MaxLength != nil && length > *p.MaxLength {
+			violations = append(violations, fmt.Sprintf("%s must be at most %d characters", path, *p.MaxLength))
+		}
+
+	case float64:
+		if p.Minimum != nil && v < *p.Minimum {
+			violations = append(violations, fmt.Sprintf("%s must be greater than or equal to %v", path, *p.Minimum))
+		}
+
+		if p.Maximum != nil && v > *p.Maximum {
+			violations = append(violations, fmt.Sprintf("%s must be less than or equal to %v", path, *p.Maximum))
+		}
+
+	case []any:
+		if p.Items != nil {
+			for i, item := range v {
+				violations = checkProperty(fmt.Sprintf("%s[%d]", path, i), item, *p.Items, violations)
+			}
+		}
+
+	case map[string]any:
+		violations = checkObject(path+".", v, p.Properties, p.Required, p.AdditionalProperties, violations)
+	}
+
+	return violations
+}
+
+// checkObject appends the violations of the object properties, the prefix is prepended to the property names.
+func checkObject(prefix string, obj map[string]any, props map[string]models.PropertySchema, required []string, additional *bool, violations []string) []string {
+	for _, name := range required {
+		if _, ok := obj[name]; !ok {
+			violations = append(violations, fmt.Sprintf("%s%s is required", prefix, name))
+		}
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+
+	// sorted so the violations are always reported in the same order.
+	slices.Sort(names)
+
+	for _, name := range names {
+		p, ok := props[name]
+		if !ok {
+			if additional != nil && !*additional {
+				violations = append(violations, fmt.Sprintf("%s%s is not allowed", prefix, name))
+			}
+			continue
+		}
+
+		violations = checkProperty(prefix+name, obj[name], p, violations)
+	}
+
+	return violations
+}
+
+// NodeLabelFunc returns the label of the node with the ID, found is false if there is no such node.
+type NodeLabelFunc func(id uint64) (label string, found bool, err error)
+
+// SchemaSet is the set of schemas used to check writes.
+type SchemaSet struct {
+	schemas map[models.SchemaKind]map[string]models.Schema
+}
+
+// NewSchemaSet returns a set holding the schemas.
+func NewSchemaSet(schemas ...models.Schema) SchemaSet {
+	set := SchemaSet{
+		schemas: map[models.SchemaKind]map[string]models.Schema{
+			models.SchemaNode: {},
+			models.SchemaEdge: {},
+		},
+	}
+
+	for _, s := range schemas {
+		if set.schemas[s.Kind] != nil {
+			set.schemas[s.Kind][s.Label] = s
+		}
+	}
+
+	return set
+}
+
+// schema returns the schema for the kind and label.
+func (s SchemaSet) schema(kind models.SchemaKind, label string) (models.Schema, bool) {
+	schema, ok := s.schemas[kind][label]
+	return schema, ok
+}
+
+// enforce applies the schema mode to the violations, warnings are logged and strict violations are returned as a *SchemaError.
+func enforce(schema models.Schema, id uint64, violations []string) error {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	if schema.Mode == models.SchemaWarn {
+		slog.Warn(
+			"schema violation",
+			slog.String("kind", string(schema.Kind)),
+			slog.String("label", schema.Label),
+			slog.Uint64("id", id),
+			slog.Any("violations", violations),
+		)
+		return nil
+	}
+
+	return &SchemaError{Kind: schema.Kind, Label: schema.Label, ID: id, Violations: violations}
+}
+
+// CheckNode checks the node against the schema of its label, nodes without a schema are always valid.
+func (s SchemaSet) CheckNode(n models.Node) error {
+	schema, ok := s.schema(models.SchemaNode, n.Label)
+	if !ok {
+		return nil
+	}
+
+	props, _ := jsonValue(n.Properties).(map[string]any)
+	violations := checkObject("", props, schema.Properties, schema.Required, schema.AdditionalProperties, nil)
+
+	return enforce(schema, n.ID, violations)
+}
+
+// CheckEdge checks the edge against the schema of its label, edges without a schema are always valid.
+// The node labels are only looked up if the schema limits the from or to labels.
+func (s SchemaSet) CheckEdge(e models.Edge, nodeLabel NodeLabelFunc) error {
+	schema, ok := s.schema(models.SchemaEdge, e.Label)
+	if !ok {
+		return nil
+	}
+
+	props, _ := jsonValue(e.Properties).(map[string]any)
+	violations := checkObject("", props, schema.Properties, schema.Required, schema.AdditionalProperties, nil)
+
+	ends := []struct {
+		name   string
+		id     uint64
+		labels []string
+	}{
+		{name: "from", id: e.From, labels: schema.FromLabels},
+		{name: "to", id: e.To, labels: schema.ToLabels},
+	}
+
+	for _, end := range ends {
+		if len(end.labels) == 0 {
+			continue
+		}
+
+		label, found, err := nodeLabel(end.id)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case !found:
+			violations = append(violations, fmt.Sprintf("%s node %d does not exist", end.name, end.id))
+		case !slices.Contains(end.labels, label):
+			violations = append(violations, fmt.Sprintf("%s node %d has label %q, expected one of %s", end.name, end.id, label, strings.Join(end.labels, ", ")))
+		}
+	}
+
+	return enforce(schema, e.ID, violations)
+}
@@ -0,0 +1,163 @@
+package store_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+func TestSchemaSet_CheckNode(t *testing.T) {
+	closed := false
+	one := 1
+	three := 3
+
+	schema := models.Schema{
+		Kind:     models.SchemaNode,
+		Label:    "person",
+		Required: []string{"name"},
+		Properties: map[string]models.PropertySchema{
+			"name":  {Type: "string", MinLength: &one, MaxLength: &three},
+			"age":   {Type: "integer"},
+			"role":  {Enum: []any{"admin", "user", 1}},
+			"email": {Type: "string", Pattern: `^[^@]+@[^@]+$`},
+			"tags":  {Type: "array", Items: &models.PropertySchema{Type: "string"}},
+			"meta": {
+				Type:                 "object",
+				Required:             []string{"hair"},
+				Properties:           map[string]models.PropertySchema{"hair": {Type: "string"}},
+				AdditionalProperties: &closed,
+			},
+		},
+	}
+
+	set := store.NewSchemaSet(schema)
+
+	tests := []struct {
+		name  string // description of this test case
+		node  models.Node
+		wants []string
+	}{
+		{
+			name: "valid",
+			node: models.Node{Label: "person", Properties: models.Properties{"name": "foo", "age": 21, "role": 1, "tags": []string{"a"}}},
+		},
+		{
+			name: "labels without a schema are not checked",
+			node: models.Node{Label: "dog", Properties: models.Properties{"age": "old"}},
+		},
+		{
+			name:  "required",
+			node:  models.Node{Label: "person"},
+			wants: []string{"name is required"},
+		},
+		{
+			name:  "type",
+			node:  models.Node{Label: "person", Properties: models.Properties{"name": "foo", "age": 21.5}},
+			wants: []string{"age must be of type integer, got number"},
+		},
+		{
+			name:  "length",
+			node:  models.Node{Label: "person", Properties: models.Properties{"name": "fooo"}},
+			wants: []string{"name must be at most 3 characters"},
+		},
+		{
+			name:  "enum",
+			node:  models.Node{Label: "person", Properties: models.Properties{"name": "foo", "role": "root"}},
+			wants: []string{`role must be one of ["admin","user",1]`},
+		},
+		{
+			name:  "pattern",
+			node:  models.Node{Label: "person", Properties: models.Properties{"name": "foo", "email": "foo"}},
+			wants: []string{`email must match the pattern "^[^@]+@[^@]+$"`},
+		},
+		{
+			name:  "items",
+			node:  models.Node{Label: "person", Properties: models.Properties{"name": "foo", "tags": []any{"a", 2}}},
+			wants: []string{"tags[1] must be of type string, got number"},
+		},
+		{
+			name:  "nested object",
+			node:  models.Node{Label: "person", Properties: models.Properties{"name": "foo", "meta": map[string]any{"eyes": "blue"}}},
+			wants: []string{"meta.hair is required", "meta.eyes is not allowed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := set.CheckNode(tt.node)
+
+			var got []string
+
+			var schemaErr *store.SchemaError
+			if errors.As(err, &schemaErr) {
+				got = schemaErr.Violations
+			} else if err != nil {
+				t.Fatalf("CheckNode() failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.wants, got); diff != "" {
+				t.Errorf("CheckNode() violations = mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSchemaSet_CheckEdge(t *testing.T) {
+	set := store.NewSchemaSet(
+		models.Schema{Kind: models.SchemaEdge, Label: "owns", FromLabels: []string{"person"}, ToLabels: []string{"dog"}},
+		models.Schema{Kind: models.SchemaEdge, Label: "likes", Mode: models.SchemaWarn, ToLabels: []string{"dog"}},
+	)
+
+	labels := map[uint64]string{1: "person", 2: "dog", 3: "cat"}
+	nodeLabel := func(id uint64) (string, bool, error) {
+		label, ok := labels[id]
+		return label, ok, nil
+	}
+
+	tests := []struct {
+		name  string // description of this test case
+		edge  models.Edge
+		wants []string
+	}{
+		{
+			name: "valid",
+			edge: models.Edge{From: 1, Label: "owns", To: 2},
+		},
+		{
+			name:  "wrong label",
+			edge:  models.Edge{From: 1, Label: "owns", To: 3},
+			wants: []string{`to node 3 has label "cat", expected one of dog`},
+		},
+		{
+			name:  "missing node",
+			edge:  models.Edge{From: 10, Label: "owns", To: 2},
+			wants: []string{"from node 10 does not exist"},
+		},
+		{
+			name: "warn mode",
+			edge: models.Edge{From: 1, Label: "likes", To: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := set.CheckEdge(tt.edge, nodeLabel)
+
+			var got []string
+
+			var schemaErr *store.SchemaError
+			if errors.As(err, &schemaErr) {
+				got = schemaErr.Violations
+			} else if err != nil {
+				t.Fatalf("CheckEdge() failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.wants, got); diff != "" {
+				t.Errorf("CheckEdge() violations = mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// scanAPIKey scans a row selected as `id, name, role, prefix, created_at, last_used_at` into an API key.
+func scanAPIKey(row scanner) (models.APIKey, error) {
+	k := models.APIKey{}
+
+	var createdAt int64
+	var lastUsedAt sql.NullInt64
+
+	if err := row.Scan(&k.ID, &k.Name, &k.Role, &k.Prefix, &createdAt, &lastUsedAt); err != nil {
+		return k, err
+	}
+
+	k.CreatedAt = time.Unix(createdAt, 0)
+
+	if lastUsedAt.Valid {
+		usedAt := time.Unix(lastUsedAt.Int64, 0)
+		k.LastUsedAt = &usedAt
+	}
+
+	return k, nil
+}
+
+// APIKeys returns all the API keys ordered by ID, the keys are not returned.
+func (s *Store) APIKeys(ctx context.Context) ([]models.APIKey, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, name, role, prefix, created_at, last_used_at FROM api_keys ORDER BY id;`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return keys, err
+		}
+
+		keys = append(keys, k)
+	}
+
+	return keys, rows.Err()
+}
+
+// CreateAPIKey creates the API key returning it with its generated key, returning store.ErrInvalid if the API key is
+// not valid. Only the hash of the key is stored.
+func (s *Store) CreateAPIKey(ctx context.Context, k models.APIKey) (models.APIKey, error) {
+	if err := store.ValidateAPIKey(&k); err != nil {
+		return models.APIKey{}, err
+	}
+
+	row := s.db.QueryRowContext(
+		ctx,
+		`INSERT INTO api_keys (name, role, prefix, hash) VALUES (?, ?, ?, ?) RETURNING id, name, role, prefix, created_at, last_used_at;`,
+		k.Name, k.Role, k.Prefix, store.HashAPIKey(k.Key),
+	)
+
+	created, err := scanAPIKey(row)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.APIKey{}, fmt.Errorf("%w: api key already exists", store.ErrInvalid)
+		}
+		return models.APIKey{}, err
+	}
+
+	created.Key = k.Key
+	return created, nil
+}
+
+// DeleteAPIKey deletes the API key returning the deleted API key, returning store.ErrNotFound if there is no such key.
+func (s *Store) DeleteAPIKey(ctx context.Context, id uint64) (models.APIKey, error) {
+	row := s.db.QueryRowContext(
+		ctx,
+		`DELETE FROM api_keys WHERE id = ? RETURNING id, name, role, prefix, created_at, last_used_at;`,
+		id,
+	)
+
+	k, err := scanAPIKey(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.APIKey{}, fmt.Errorf("%w: api key %d", store.ErrNotFound, id)
+		}
+		return models.APIKey{}, err
+	}
+
+	return k, nil
+}
+
+// AuthenticateAPIKey returns the API key of the key recording when it was used, returning store.ErrNotFound if there
+// is no such key. The use is only written if the recorded use is older than store.APIKeyUsedInterval, so most requests
+// only read the key.
+func (s *Store) AuthenticateAPIKey(ctx context.Context, key string) (models.APIKey, error) {
+	row := s.db.QueryRowContext(
+		ctx,
+		`SELECT id, name, role, prefix, created_at, last_used_at FROM api_keys WHERE hash = ?;`,
+		store.HashAPIKey(key),
+	)
+
+	k, err := scanAPIKey(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.APIKey{}, fmt.Errorf("%w: api key", store.ErrNotFound)
+		}
+		return models.APIKey{}, err
+	}
+
+	now := time.Now()
+
+	if k.LastUsedAt != nil && now.Sub(*k.LastUsedAt) < store.APIKeyUsedInterval {
+		return k, nil
+	}
+
+	if _, err := s.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = ? WHERE id = ?;`, now.Unix(), k.ID); err != nil {
+		return models.APIKey{}, err
+	}
+
+	usedAt := time.Unix(now.Unix(), 0)
+	k.LastUsedAt = &usedAt
+
+	return k, nil
+}
@@ -0,0 +1,129 @@
+package sqlite_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/models"
+)
+
+func TestStore_APIKeys(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := sqlite.New(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	defer s.Close()
+
+	created, err := s.CreateAPIKey(ctx, models.APIKey{Name: "importer", Role: models.RoleWriter})
+	if err != nil {
+		t.Fatalf("CreateAPIKey() failed: %v", err)
+	}
+
+	if created.ID != 1 || !strings.HasPrefix(created.Key, store.APIKeyPrefix) || !strings.HasPrefix(created.Key, created.Prefix) {
+		t.Errorf("CreateAPIKey() = %+v, want an API key with a generated key", created)
+	}
+
+	dashboard, err := s.CreateAPIKey(ctx, models.APIKey{Name: "dashboard", Role: models.RoleReader})
+	if err != nil {
+		t.Fatalf("CreateAPIKey() failed: %v", err)
+	}
+
+	if dashboard.Key == created.Key {
+		t.Errorf("CreateAPIKey() = %+v, want a new key for each API key", dashboard)
+	}
+
+	invalid := []models.APIKey{
+		{Role: models.RoleReader},
+		{Name: "root", Role: "root"},
+		{Name: "given", Role: models.RoleReader, Key: "edb_0123456789abcdefghijklmnopqrstuvwxyz"},
+	}
+
+	for _, k := range invalid {
+		if _, err := s.CreateAPIKey(ctx, k); !errors.Is(err, store.ErrInvalid) {
+			t.Errorf("CreateAPIKey(%+v) error = %v, want store.ErrInvalid", k, err)
+		}
+	}
+
+	authenticated, err := s.AuthenticateAPIKey(ctx, created.Key)
+	if err != nil {
+		t.Fatalf("AuthenticateAPIKey() failed: %v", err)
+	}
+
+	if authenticated.Name != "importer" || authenticated.Role != models.RoleWriter || authenticated.LastUsedAt == nil {
+		t.Errorf("AuthenticateAPIKey() = %+v, want the used importer key", authenticated)
+	}
+
+	// the use is only recorded again once the recorded use is older than the interval.
+	for _, tt := range []struct {
+		ago     time.Duration
+		updated bool
+	}{
+		{ago: 30 * time.Second, updated: false},
+		{ago: 2 * store.APIKeyUsedInterval, updated: true},
+	} {
+		usedAt := time.Now().Add(-tt.ago).Unix()
+
+		tx, err := s.Tx(ctx)
+		if err != nil {
+			t.Fatalf("Tx() failed: %v", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE api_keys SET last_used_at = ? WHERE id = ?;`, usedAt, created.ID); err != nil {
+			t.Fatalf("updating the last use failed: %v", err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("Commit() failed: %v", err)
+		}
+
+		authenticated, err := s.AuthenticateAPIKey(ctx, created.Key)
+		if err != nil {
+			t.Fatalf("AuthenticateAPIKey() failed: %v", err)
+		}
+
+		if got := authenticated.LastUsedAt.Unix() != usedAt; got != tt.updated {
+			t.Errorf("AuthenticateAPIKey() used %s ago updated = %v, want %v", tt.ago, got, tt.updated)
+		}
+	}
+
+	if _, err := s.AuthenticateAPIKey(ctx, "edb_unknown"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("AuthenticateAPIKey() of an unknown key error = %v, want store.ErrNotFound", err)
+	}
+
+	keys, err := s.APIKeys(ctx)
+	if err != nil {
+		t.Fatalf("APIKeys() failed: %v", err)
+	}
+
+	want := []models.APIKey{
+		{ID: 1, Name: "importer", Role: models.RoleWriter, Prefix: created.Prefix},
+		{ID: 2, Name: "dashboard", Role: models.RoleReader, Prefix: dashboard.Prefix},
+	}
+
+	// the keys are never returned once created.
+	opts := []cmp.Option{cmpopts.IgnoreFields(models.APIKey{}, "CreatedAt", "LastUsedAt")}
+	if diff := cmp.Diff(want, keys, opts...); diff != "" {
+		t.Errorf("APIKeys() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	if _, err := s.DeleteAPIKey(ctx, 1); err != nil {
+		t.Fatalf("DeleteAPIKey() failed: %v", err)
+	}
+
+	if _, err := s.DeleteAPIKey(ctx, 1); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("DeleteAPIKey() of a deleted key error = %v, want store.ErrNotFound", err)
+	}
+
+	if _, err := s.AuthenticateAPIKey(ctx, created.Key); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("AuthenticateAPIKey() of a deleted key error = %v, want store.ErrNotFound", err)
+	}
+}
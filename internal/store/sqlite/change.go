@@ -0,0 +1,168 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// image is a before or after image of an item written to the changelog by the items triggers.
+type image struct {
+	ID         uint64            `json:"id"`
+	CreatedAt  int64             `json:"created_at"`
+	UpdatedAt  int64             `json:"updated_at"`
+	From       uint64            `json:"from_id"`
+	Label      string            `json:"label"`
+	To         uint64            `json:"to_id"`
+	Weight     int               `json:"weight"`
+	Properties models.Properties `json:"properties"`
+}
+
+// node returns the node in the image.
+func (img image) node() models.Node {
+	return models.Node{
+		ID:         img.ID,
+		CreatedAt:  time.Unix(img.CreatedAt, 0),
+		UpdatedAt:  time.Unix(img.UpdatedAt, 0),
+		Label:      img.Label,
+		Properties: img.Properties,
+	}
+}
+
+// edge returns the edge in the image.
+func (img image) edge() models.Edge {
+	return models.Edge{
+		ID:         img.ID,
+		CreatedAt:  time.Unix(img.CreatedAt, 0),
+		UpdatedAt:  time.Unix(img.UpdatedAt, 0),
+		From:       img.From,
+		Label:      img.Label,
+		To:         img.To,
+		Weight:     img.Weight,
+		Properties: img.Properties,
+	}
+}
+
+// readImage reads the stored image, a missing image is returned as nil.
+func readImage(data []byte) (*image, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	img := image{}
+	if err := json.Unmarshal(data, &img); err != nil {
+		return nil, err
+	}
+
+	return &img, nil
+}
+
+// decodeImage decodes the stored image returning the node or edge JSON, a missing image is returned as nil.
+func decodeImage(kind models.SchemaKind, data []byte) (json.RawMessage, error) {
+	img, err := readImage(data)
+	if err != nil || img == nil {
+		return nil, err
+	}
+
+	if kind == models.SchemaNode {
+		return json.Marshal(img.node())
+	}
+
+	return json.Marshal(img.edge())
+}
+
+// scanChange scans a row selected using changeColumns into a change.
+func scanChange(row scanner) (models.Change, error) {
+	c := models.Change{}
+
+	var createdAt int64
+	var before, after []byte
+
+	if err := row.Scan(&c.Seq, &createdAt, &c.Op, &c.Kind, &c.ID, &c.Label, &before, &after); err != nil {
+		return c, err
+	}
+
+	var err error
+
+	if c.Before, err = decodeImage(c.Kind, before); err != nil {
+		return c, err
+	}
+
+	if c.After, err = decodeImage(c.Kind, after); err != nil {
+		return c, err
+	}
+
+	c.CreatedAt = time.Unix(createdAt, 0)
+	return c, nil
+}
+
+// changeColumns are the columns selected by scanChange.
+const changeColumns = `c.seq, c.created_at, c.op, c.kind, c.item_id, c.label, c.before, c.after`
+
+// change returns the change with the sequence number.
+func change(ctx context.Context, q querier, seq uint64) (models.Change, error) {
+	row := q.QueryRowContext(ctx, `SELECT `+changeColumns+` FROM changes c WHERE c.seq = ?;`, seq)
+
+	c, err := scanChange(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Change{}, fmt.Errorf("%w: change %d", store.ErrNotFound, seq)
+		}
+		return models.Change{}, err
+	}
+
+	return c, nil
+}
+
+// Changes returns the changes after the sequence number ordered by sequence number.
+func (s *Store) Changes(ctx context.Context, args store.ChangesArgs) ([]models.Change, error) {
+	if args.Limit <= 0 {
+		args.Limit = DefaultLimit
+	}
+
+	query := `SELECT ` + changeColumns + ` FROM changes c WHERE c.seq > ?`
+
+	params := []any{args.Since}
+
+	if len(args.Labels) > 0 {
+		conds := make([]string, 0, len(args.Labels))
+
+		// label changes are returned for both the old and new label.
+		for _, label := range args.Labels {
+			conds = append(conds, `c.label = ? OR json_extract(c.before, '$.label') = ?`)
+			params = append(params, label, label)
+		}
+
+		query += ` AND (` + strings.Join(conds, " OR ") + `)`
+	}
+
+	query += ` ORDER BY c.seq LIMIT ?;`
+	params = append(params, args.Limit)
+
+	rows, err := s.db.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	changes := []models.Change{}
+
+	for rows.Next() {
+		c, err := scanChange(rows)
+		if err != nil {
+			return changes, err
+		}
+
+		changes = append(changes, c)
+	}
+
+	return changes, rows.Err()
+}
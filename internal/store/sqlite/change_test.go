@@ -0,0 +1,152 @@
+package sqlite_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/models"
+)
+
+// change is the part of a change compared by the tests, the images are reduced to the label and properties.
+type change struct {
+	Seq    uint64
+	Op     models.ChangeOp
+	Kind   models.SchemaKind
+	ID     uint64
+	Label  string
+	Before models.Properties
+	After  models.Properties
+}
+
+// summarize reduces the changes to the fields compared by the tests.
+func summarize(t *testing.T, changes []models.Change) []change {
+	t.Helper()
+
+	props := func(data json.RawMessage) models.Properties {
+		if data == nil {
+			return nil
+		}
+
+		item := struct {
+			Properties models.Properties `json:"properties"`
+		}{}
+
+		if err := json.Unmarshal(data, &item); err != nil {
+			t.Fatalf("decoding the change image failed: %v", err)
+		}
+
+		return item.Properties
+	}
+
+	summary := make([]change, len(changes))
+	for i, c := range changes {
+		summary[i] = change{Seq: c.Seq, Op: c.Op, Kind: c.Kind, ID: c.ID, Label: c.Label, Before: props(c.Before), After: props(c.After)}
+	}
+
+	return summary
+}
+
+func TestStore_Changes(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := sqlite.New(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	defer s.Close()
+
+	preload(t, s,
+		models.Node{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
+		models.Node{ID: 2, Label: "person", Properties: models.Properties{"name": "bar"}},
+		models.Node{ID: 3, Label: "dog", Properties: models.Properties{"name": "socks"}},
+	)
+
+	preloadEdges(t, s, models.Edge{ID: 4, From: 1, Label: "knows", To: 2, Properties: models.Properties{}})
+
+	// upserting a node without any changes is not recorded.
+	preload(t, s,
+		models.Node{ID: 1, Label: "person", Properties: models.Properties{"name": "foo", "age": float64(30)}},
+		models.Node{ID: 3, Label: "dog", Properties: models.Properties{"name": "socks"}},
+	)
+
+	if _, err := s.DeleteNodes(ctx, store.DeleteNodesArgs{IDs: []uint64{2}, Policy: store.DeleteCascade}); err != nil {
+		t.Fatalf("DeleteNodes() failed: %v", err)
+	}
+
+	all := []change{
+		{Seq: 1, Op: models.ChangeCreate, Kind: models.SchemaNode, ID: 1, Label: "person", After: models.Properties{"name": "foo"}},
+		{Seq: 2, Op: models.ChangeCreate, Kind: models.SchemaNode, ID: 2, Label: "person", After: models.Properties{"name": "bar"}},
+		{Seq: 3, Op: models.ChangeCreate, Kind: models.SchemaNode, ID: 3, Label: "dog", After: models.Properties{"name": "socks"}},
+		{Seq: 4, Op: models.ChangeCreate, Kind: models.SchemaEdge, ID: 4, Label: "knows", After: models.Properties{}},
+		{
+			Seq: 5, Op: models.ChangeUpdate, Kind: models.SchemaNode, ID: 1, Label: "person",
+			Before: models.Properties{"name": "foo"},
+			After:  models.Properties{"name": "foo", "age": float64(30)},
+		},
+		{Seq: 6, Op: models.ChangeDelete, Kind: models.SchemaEdge, ID: 4, Label: "knows", Before: models.Properties{}},
+		{Seq: 7, Op: models.ChangeDelete, Kind: models.SchemaNode, ID: 2, Label: "person", Before: models.Properties{"name": "bar"}},
+	}
+
+	tests := []struct {
+		name string
+		args store.ChangesArgs
+		want []change
+	}{
+		{
+			name: "all changes",
+			want: all,
+		},
+		{
+			name: "since a sequence number",
+			args: store.ChangesArgs{Since: 5},
+			want: all[5:],
+		},
+		{
+			name: "limit",
+			args: store.ChangesArgs{Since: 1, Limit: 2},
+			want: all[1:3],
+		},
+		{
+			name: "labels",
+			args: store.ChangesArgs{Labels: []string{"dog", "knows"}},
+			want: []change{all[2], all[3], all[5]},
+		},
+		{
+			name: "no changes",
+			args: store.ChangesArgs{Since: 7},
+			want: []change{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Changes(ctx, tt.args)
+			if err != nil {
+				t.Fatalf("Changes() failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, summarize(t, got), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Changes() = mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+
+	// a label change is returned for both the old and the new label.
+	preload(t, s, models.Node{ID: 3, Label: "cat", Properties: models.Properties{"name": "socks"}})
+
+	for _, label := range []string{"dog", "cat"} {
+		got, err := s.Changes(ctx, store.ChangesArgs{Since: 7, Labels: []string{label}})
+		if err != nil {
+			t.Fatalf("Changes() failed: %v", err)
+		}
+
+		if len(got) != 1 || got[0].Op != models.ChangeUpdate || got[0].ID != 3 {
+			t.Errorf("Changes(%q) = %+v, want the label update", label, got)
+		}
+	}
+}
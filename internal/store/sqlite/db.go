@@ -27,6 +27,37 @@ import (
 // DefaultLimit is the default limit of return items to return.
 const DefaultLimit int = 1000
 
+// upsertNodeQuery inserts or updates a node, the ID is NULL for new nodes. Updating a trashed node restores it.
+const upsertNodeQuery = `
+	INSERT INTO items (id, label, properties)
+	VALUES (?, ?, ?)
+	ON CONFLICT(id) DO UPDATE SET
+		id = excluded.id,
+		label = excluded.label,
+		properties = excluded.properties,
+		version = items.version + 1,
+		deleted_at = NULL,
+		deleted_with = NULL
+	RETURNING id, created_at, updated_at, version, label, properties;
+`
+
+// upsertEdgeQuery inserts or updates an edge, the ID is NULL for new edges. Updating a trashed edge restores it.
+const upsertEdgeQuery = `
+	INSERT INTO items (id, from_id, label, to_id, weight, properties)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT(id) DO UPDATE SET
+		id = excluded.id,
+		from_id = excluded.from_id,
+		label = excluded.label,
+		to_id = excluded.to_id,
+		weight = excluded.weight,
+		properties = excluded.properties,
+		version = items.version + 1,
+		deleted_at = NULL,
+		deleted_with = NULL
+	RETURNING id, created_at, updated_at, version, from_id, label, to_id, weight, properties;
+`
+
 //go:embed "migrations/*.sql"
 var migrations embed.FS
 var once sync.Once
@@ -45,20 +76,23 @@ func New(ctx context.Context, dns string) (*Store, error) {
 	// call SetMaxOpenConns to 1 for SQLite to avoid "database is locked" errors on the original underlying DB
 	db.SetMaxOpenConns(1)
 
-	slog.SetDefault(
-		slog.With(
-			slog.Group(
-				"store",
-				slog.String("driver", "sqlite"),
-				slog.String("dsn", dns),
-			),
+	// each store has its own logger so opening several stores, eg: the named graphs, does not change the default.
+	s.logger = slog.With(
+		slog.Group(
+			"store",
+			slog.String("driver", "sqlite"),
+			slog.String("dsn", dns),
 		),
 	)
 
-	slog.Debug("attached to store")
+	s.logger.Debug("attached to store")
 	once.Do(registerFuncs)
 
-	return s, ApplyMigrations(ctx, s.db)
+	if err := ApplyMigrations(ctx, s.db); err != nil {
+		return s, err
+	}
+
+	return s, s.resumeIndexes(ctx)
 }
 
 // ApplyMigrations applies database migrations from the embedded filesystem.
@@ -148,11 +182,17 @@ func registerFuncs() {
 
 // Store is the underlying sqlite store.
 type Store struct {
-	db *sql.DB
+	db     *sql.DB
+	logger *slog.Logger
+
+	// builds tracks the property indexes being built in the background.
+	builds sync.WaitGroup
 }
 
 // Close closed the store.
 func (s *Store) Close() error {
+	s.builds.Wait()
+
 	if s.db != nil {
 		return s.db.Close()
 	}
@@ -167,7 +207,8 @@ func (s *Store) Tx(ctx context.Context) (*sql.Tx, error) {
 	return s.db.BeginTx(ctx, nil)
 }
 
-// UpsertNodes inserts or creates one or more nodes.
+// UpsertNodes inserts or creates one or more nodes. Nodes with a version are only updated if it is the current version,
+// returning a *store.VersionError otherwise.
 func (s *Store) UpsertNodes(ctx context.Context, n ...models.Node) ([]models.Node, error) {
 	tx, err := s.Tx(ctx)
 	if err != nil {
@@ -176,28 +217,43 @@ func (s *Store) UpsertNodes(ctx context.Context, n ...models.Node) ([]models.Nod
 
 	defer tx.Rollback()
 
-	query := `
-		INSERT INTO items (id, label, properties)
-		VALUES (?, ?, ?)
-		ON CONFLICT(id) DO UPDATE SET
-			id = excluded.id,
-			label = excluded.label,
-			properties = excluded.properties
-		RETURNING id, created_at, updated_at, label, properties;
-	`
+	nodes, err := upsertNodes(ctx, tx, n...)
+	if err != nil {
+		return nodes, err
+	}
 
+	return nodes, tx.Commit()
+}
+
+// upsertNodes inserts or updates the nodes in the transaction.
+func upsertNodes(ctx context.Context, tx *sql.Tx, n ...models.Node) ([]models.Node, error) {
 	// Prepare the statement once and reuse it for all nodes.
-	stmt, err := tx.PrepareContext(ctx, query)
+	stmt, err := tx.PrepareContext(ctx, upsertNodeQuery)
 	if err != nil {
 		return nil, err
 	}
 
 	defer stmt.Close()
 
+	schemas, err := schemaSet(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
 	nodes := make([]models.Node, len(n))
 
 	for i, n := range n {
 
+		if n.Version > 0 {
+			if err := checkNodeVersion(ctx, tx, n.ID, n.Version); err != nil {
+				return nodes, err
+			}
+		}
+
+		if err := schemas.CheckNode(n); err != nil {
+			return nodes, err
+		}
+
 		node := models.Node{}
 
 		props, err := n.Properties.ToBytes()
@@ -217,12 +273,15 @@ func (s *Store) UpsertNodes(ctx context.Context, n ...models.Node) ([]models.Nod
 			id = &n.ID
 		}
 
-		row := stmt.QueryRowContext(ctx, query, id, n.Label, props)
+		row := stmt.QueryRowContext(ctx, id, n.Label, props)
 
 		var createdAt int64
 		var updatedAt int64
 
-		if err := row.Scan(&node.ID, &createdAt, &updatedAt, &node.Label, &props); err != nil {
+		if err := row.Scan(&node.ID, &createdAt, &updatedAt, &node.Version, &node.Label, &props); err != nil {
+			if isUniqueViolation(err) {
+				return nodes, store.DuplicateKeyError(n.Label)
+			}
 			return nodes, err
 		}
 
@@ -236,7 +295,7 @@ func (s *Store) UpsertNodes(ctx context.Context, n ...models.Node) ([]models.Nod
 		nodes[i] = node
 	}
 
-	return nodes, tx.Commit()
+	return nodes, nil
 }
 
 // NodesTermSearch applies the search term and returns nodes with match. Limit defaults to 1000 if limit is 0
@@ -266,13 +325,14 @@ func (s *Store) NodesTermSearch(ctx context.Context, args store.TermSearchArgs)
 	}
 
 	query := `
-	SELECT n.id, n.created_at, n.updated_at, n.label, n.properties, snippet(fts, -1, ?, ?, ' ... ', ?) as snippet
+	SELECT n.id, n.created_at, n.updated_at, n.version, n.label, n.properties, snippet(fts, -1, ?, ?, ' ... ', ?) as snippet
 	FROM fts
 	JOIN items n ON n.id = fts.id
 	WHERE
 		fts.type = 'node'
 		AND fts MATCH ?
 		AND n.id > ?
+		AND n.deleted_at IS NULL
 	ORDER BY bm25(fts)
 	LIMIT ?;
 	`
@@ -291,7 +351,7 @@ func (s *Store) NodesTermSearch(ctx context.Context, args store.TermSearchArgs)
 		var updatedAt int64
 
 		var props []byte
-		if err := rows.Scan(&n.ID, &createdAt, &updatedAt, &n.Label, &props, &n.Snippet); err != nil {
+		if err := rows.Scan(&n.ID, &createdAt, &updatedAt, &n.Version, &n.Label, &props, &n.Snippet); err != nil {
 			return nodes, err
 		}
 
@@ -308,15 +368,20 @@ func (s *Store) NodesTermSearch(ctx context.Context, args store.TermSearchArgs)
 	return nodes, nil
 }
 
-// Node returns the node with the provided ID.
+// Node returns the node with the provided ID, returning store.ErrNotFound if there is no such node.
 func (s *Store) Node(ctx context.Context, id uint64) (models.Node, error) {
+	return node(ctx, s.db, id)
+}
+
+// node returns the node with the provided ID, returning store.ErrNotFound if there is no such node.
+func node(ctx context.Context, q querier, id uint64) (models.Node, error) {
 	query := `
-		SELECT n.id, n.created_at, n.updated_at, n.label, n.properties
+		SELECT n.id, n.created_at, n.updated_at, n.version, n.label, n.properties
 		FROM items n
-		WHERE n.id = ? AND n.from_id = 0 AND n.to_id = 0
+		WHERE n.id = ? AND n.from_id = 0 AND n.to_id = 0 AND n.deleted_at IS NULL
 	`
 
-	row := s.db.QueryRowContext(ctx, query, id)
+	row := q.QueryRowContext(ctx, query, id)
 
 	if row.Err() != nil {
 		return models.Node{}, row.Err()
@@ -328,7 +393,10 @@ func (s *Store) Node(ctx context.Context, id uint64) (models.Node, error) {
 	var updatedAt int64
 
 	var props []byte
-	if err := row.Scan(&n.ID, &createdAt, &updatedAt, &n.Label, &props); err != nil {
+	if err := row.Scan(&n.ID, &createdAt, &updatedAt, &n.Version, &n.Label, &props); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Node{}, fmt.Errorf("%w: node %d", store.ErrNotFound, id)
+		}
 		return models.Node{}, err
 	}
 
@@ -344,84 +412,107 @@ func (s *Store) Node(ctx context.Context, id uint64) (models.Node, error) {
 
 // Nodes applies the search for all nodes in the store.
 func (s *Store) Nodes(ctx context.Context, args store.NodesArgs) ([]models.Node, error) {
+	return nodes(ctx, s.db, args)
+}
+
+// nodes applies the search for all nodes in the store.
+func nodes(ctx context.Context, q querier, args store.NodesArgs) ([]models.Node, error) {
+	query, queryArgs, err := nodesQuery(args)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectNodes(rows)
+}
+
+// nodesQuery returns the query and its arguments searching the nodes.
+func nodesQuery(args store.NodesArgs) (string, []any, error) {
 	if args.Limit == 0 {
 		args.Limit = DefaultLimit
 	}
 
-	query := `
-	SELECT n.id, n.created_at, n.updated_at, n.label, n.properties
+	filter, filterArgs, err := compileFilter("n", args.Filter)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(args.IDs) > 0 {
+		marks, idArgs := placeholders(args.IDs...)
+		filter += fmt.Sprintf(" AND n.id IN (%s)", marks)
+		filterArgs = append(filterArgs, idArgs...)
+	}
+
+	query := fmt.Sprintf(`
+	SELECT n.id, n.created_at, n.updated_at, n.version, n.label, n.properties
 	FROM items n
 	WHERE
-		n.from_id = 0 AND n.to_id = 0
+		n.from_id = 0 AND n.to_id = 0 AND n.deleted_at IS NULL
 	AND
 		n.id > ?
+	%s
 	ORDER BY n.id
 	LIMIT ?;
-	`
+	`, filter)
 
-	rows, err := s.db.QueryContext(ctx, query, args.LastID, args.Limit)
+	queryArgs := append([]any{args.LastID}, filterArgs...)
+	queryArgs = append(queryArgs, args.Limit)
+
+	return query, queryArgs, nil
+}
+
+// UpsertEdges inserts or creates one or more edges. Edges with a version are only updated if it is the current version,
+// returning a *store.VersionError otherwise.
+func (s *Store) UpsertEdges(ctx context.Context, e ...models.Edge) ([]models.Edge, error) {
+	tx, err := s.Tx(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	nodes := []models.Node{}
-
-	for rows.Next() {
-		n := models.Node{}
-
-		var createdAt int64
-		var updatedAt int64
-
-		var props []byte
-		if err := rows.Scan(&n.ID, &createdAt, &updatedAt, &n.Label, &props); err != nil {
-			return nodes, err
-		}
-
-		if err := n.Properties.FromBytes(props); err != nil {
-			return nodes, err
-		}
-
-		n.CreatedAt = time.Unix(createdAt, 0)
-		n.UpdatedAt = time.Unix(updatedAt, 0)
+	defer tx.Rollback()
 
-		nodes = append(nodes, n)
+	edges, err := upsertEdges(ctx, tx, e...)
+	if err != nil {
+		return edges, err
 	}
 
-	return nodes, nil
+	return edges, tx.Commit()
 }
 
-// UpsertEdges inserts or creates one or more edges.
-func (s *Store) UpsertEdges(ctx context.Context, e ...models.Edge) ([]models.Edge, error) {
-	tx, err := s.Tx(ctx)
+// upsertEdges inserts or updates the edges in the transaction.
+func upsertEdges(ctx context.Context, tx *sql.Tx, e ...models.Edge) ([]models.Edge, error) {
+	// Prepare the statement once and reuse it for all nodes.
+	stmt, err := tx.PrepareContext(ctx, upsertEdgeQuery)
 	if err != nil {
 		return nil, err
 	}
 
-	defer tx.Rollback()
-
-	query := `
-		INSERT INTO items (id, from_id, label, to_id, weight, properties)
-		VALUES (?, ?, ?, ?, ?, ?)
-		ON CONFLICT(id) DO UPDATE SET
-			id = excluded.id,
-			from_id = excluded.from_id,
-			label = excluded.label,
-			to_id = excluded.to_id,
-			weight = excluded.weight,
-			properties = excluded.properties
-		RETURNING id, created_at, updated_at, from_id, label, to_id, weight, properties;
-	`
+	defer stmt.Close()
 
-	// Prepare the statement once and reuse it for all nodes.
-	stmt, err := tx.PrepareContext(ctx, query)
+	schemas, err := schemaSet(ctx, tx)
 	if err != nil {
 		return nil, err
 	}
 
+	labels := nodeLabel(ctx, tx)
 	edges := make([]models.Edge, len(e))
 
 	for i, e := range e {
 
+		if e.Version > 0 {
+			if err := checkEdgeVersion(ctx, tx, e.ID, e.Version); err != nil {
+				return edges, err
+			}
+		}
+
+		if err := schemas.CheckEdge(e, labels); err != nil {
+			return edges, err
+		}
+
 		edge := models.Edge{}
 
 		props, err := e.Properties.ToBytes()
@@ -441,12 +532,12 @@ func (s *Store) UpsertEdges(ctx context.Context, e ...models.Edge) ([]models.Edg
 			id = &e.ID
 		}
 
-		row := stmt.QueryRowContext(ctx, query, id, e.From, e.Label, e.To, e.Weight, props)
+		row := stmt.QueryRowContext(ctx, id, e.From, e.Label, e.To, e.Weight, props)
 
 		var createdAt int64
 		var updatedAt int64
 
-		if err := row.Scan(&edge.ID, &createdAt, &updatedAt, &edge.From, &edge.Label, &edge.To, &edge.Weight, &props); err != nil {
+		if err := row.Scan(&edge.ID, &createdAt, &updatedAt, &edge.Version, &edge.From, &edge.Label, &edge.To, &edge.Weight, &props); err != nil {
 			return edges, err
 		}
 
@@ -460,7 +551,7 @@ func (s *Store) UpsertEdges(ctx context.Context, e ...models.Edge) ([]models.Edg
 		edges[i] = edge
 	}
 
-	return edges, tx.Commit()
+	return edges, nil
 }
 
 // EdgesTermSearch applies the search term and returns edges with match. Limit defaults to 1000 if limit is 0
@@ -490,13 +581,14 @@ func (s *Store) EdgesTermSearch(ctx context.Context, args store.TermSearchArgs)
 	}
 
 	query := `
-	SELECT e.id, e.created_at, e.updated_at, e.from_id, e.label, e.to_id, e.weight, e.properties, snippet(fts, -1, ?, ?, ' ... ', ?) as snippet
+	SELECT e.id, e.created_at, e.updated_at, e.version, e.from_id, e.label, e.to_id, e.weight, e.properties, snippet(fts, -1, ?, ?, ' ... ', ?) as snippet
 	FROM fts
 	JOIN items e ON e.id = fts.id
 	WHERE
 		fts.type = 'edge'
 		AND fts MATCH ?
 		AND e.id > ?
+		AND e.deleted_at IS NULL
 	ORDER BY bm25(fts), e.id
 	LIMIT ?;
 	`
@@ -515,7 +607,7 @@ func (s *Store) EdgesTermSearch(ctx context.Context, args store.TermSearchArgs)
 		var updatedAt int64
 
 		var props []byte
-		if err := rows.Scan(&e.ID, &createdAt, &updatedAt, &e.From, &e.Label, &e.To, &e.Weight, &props, &e.Snippet); err != nil {
+		if err := rows.Scan(&e.ID, &createdAt, &updatedAt, &e.Version, &e.From, &e.Label, &e.To, &e.Weight, &props, &e.Snippet); err != nil {
 			return edges, err
 		}
 
@@ -532,15 +624,20 @@ func (s *Store) EdgesTermSearch(ctx context.Context, args store.TermSearchArgs)
 	return edges, nil
 }
 
-// Edge returns the edge with the provided ID.
+// Edge returns the edge with the provided ID, returning store.ErrNotFound if there is no such edge.
 func (s *Store) Edge(ctx context.Context, id uint64) (models.Edge, error) {
+	return edge(ctx, s.db, id)
+}
+
+// edge returns the edge with the provided ID, returning store.ErrNotFound if there is no such edge.
+func edge(ctx context.Context, q querier, id uint64) (models.Edge, error) {
 	query := `
-		SELECT e.id, e.created_at, e.updated_at, e.from_id, e.label, e.to_id, e.properties
+		SELECT e.id, e.created_at, e.updated_at, e.version, e.from_id, e.label, e.to_id, e.weight, e.properties
 		FROM items e
-		WHERE e.id = ? AND e.from_id > 0 AND e.to_id > 0
+		WHERE e.id = ? AND e.from_id > 0 AND e.to_id > 0 AND e.deleted_at IS NULL
 	`
 
-	row := s.db.QueryRowContext(ctx, query, id)
+	row := q.QueryRowContext(ctx, query, id)
 
 	if row.Err() != nil {
 		return models.Edge{}, row.Err()
@@ -552,7 +649,10 @@ func (s *Store) Edge(ctx context.Context, id uint64) (models.Edge, error) {
 	var updatedAt int64
 
 	var props []byte
-	if err := row.Scan(&e.ID, &createdAt, &updatedAt, &e.From, &e.Label, &e.To, &props); err != nil {
+	if err := row.Scan(&e.ID, &createdAt, &updatedAt, &e.Version, &e.From, &e.Label, &e.To, &e.Weight, &props); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Edge{}, fmt.Errorf("%w: edge %d", store.ErrNotFound, id)
+		}
 		return models.Edge{}, err
 	}
 
@@ -572,50 +672,48 @@ func (s *Store) Edges(ctx context.Context, args store.EdgesArgs) ([]models.Edge,
 		args.Limit = DefaultLimit
 	}
 
-	query := `
-	SELECT e.id, e.created_at, e.updated_at, e.from_id, e.label, e.to_id, e.weight, e.properties
+	filter, filterArgs, err := compileFilter("e", args.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args.FromIDs) > 0 {
+		marks, idArgs := placeholders(args.FromIDs...)
+		filter += fmt.Sprintf(" AND e.from_id IN (%s)", marks)
+		filterArgs = append(filterArgs, idArgs...)
+	}
+
+	if len(args.ToIDs) > 0 {
+		marks, idArgs := placeholders(args.ToIDs...)
+		filter += fmt.Sprintf(" AND e.to_id IN (%s)", marks)
+		filterArgs = append(filterArgs, idArgs...)
+	}
+
+	query := fmt.Sprintf(`
+	SELECT e.id, e.created_at, e.updated_at, e.version, e.from_id, e.label, e.to_id, e.weight, e.properties
 	FROM items e
 	WHERE 
-		e.from_id > 0 AND e.to_id > 0
+		e.from_id > 0 AND e.to_id > 0 AND e.deleted_at IS NULL
 	AND
 		e.id > ?
+	%s
 	ORDER BY e.id
 	LIMIT ?;
-	`
+	`, filter)
 
-	rows, err := s.db.QueryContext(ctx, query, args.LastID, args.Limit)
+	queryArgs := append([]any{args.LastID}, filterArgs...)
+	queryArgs = append(queryArgs, args.Limit)
+
+	rows, err := s.db.QueryContext(ctx, query, queryArgs...)
 	if err != nil {
 		return nil, err
 	}
 
-	edges := []models.Edge{}
-
-	for rows.Next() {
-		e := models.Edge{}
-
-		var createdAt int64
-		var updatedAt int64
-
-		var props []byte
-		if err := rows.Scan(&e.ID, &createdAt, &updatedAt, &e.From, &e.Label, &e.To, &e.Weight, &props); err != nil {
-			return edges, err
-		}
-
-		if err := e.Properties.FromBytes(props); err != nil {
-			return edges, err
-		}
-
-		e.CreatedAt = time.Unix(createdAt, 0)
-		e.UpdatedAt = time.Unix(updatedAt, 0)
-
-		edges = append(edges, e)
-	}
-
-	return edges, nil
+	return collectEdges(rows)
 }
 
 // nodesByID is a helper used to retrieve all nodes with the given IDs.
-func nodesByID(ctx context.Context, db *sql.DB, ids ...uint64) ([]models.Node, error) {
+func nodesByID(ctx context.Context, db querier, ids ...uint64) ([]models.Node, error) {
 	nodes := make([]models.Node, 0, len(ids))
 
 	/*
@@ -635,9 +733,9 @@ func nodesByID(ctx context.Context, db *sql.DB, ids ...uint64) ([]models.Node, e
 	*/
 	query := fmt.Sprintf(
 		`
-			SELECT n.id, n.created_at, n.updated_at, n.label, n.properties
+			SELECT n.id, n.created_at, n.updated_at, n.version, n.label, n.properties
 			FROM items n
-			WHERE n.id IN (%s);
+			WHERE n.id IN (%s) AND n.deleted_at IS NULL;
 		`,
 		strings.Join(placeholders, ","),
 	)
@@ -654,7 +752,7 @@ func nodesByID(ctx context.Context, db *sql.DB, ids ...uint64) ([]models.Node, e
 		var updatedAt int64
 
 		var props []byte
-		if err := rows.Scan(&n.ID, &createdAt, &updatedAt, &n.Label, &props); err != nil {
+		if err := rows.Scan(&n.ID, &createdAt, &updatedAt, &n.Version, &n.Label, &props); err != nil {
 			return nodes, err
 		}
 
@@ -701,6 +799,11 @@ func missingNodes(g models.Graph) []uint64 {
 
 // Graph applies the search term and returns the graph containing matched nodes and edges. Limit defaults to 1000 if limit is 0
 func (s *Store) Graph(ctx context.Context, args store.TermSearchArgs) (models.Graph, error) {
+	return graph(ctx, s.db, args)
+}
+
+// graph applies the search term and returns the graph containing matched nodes and edges.
+func graph(ctx context.Context, q querier, args store.TermSearchArgs) (models.Graph, error) {
 	if args.Limit == 0 {
 		args.Limit = DefaultLimit
 	}
@@ -741,6 +844,7 @@ func (s *Store) Graph(ctx context.Context, args store.TermSearchArgs) (models.Gr
 		) AS type,
 		i.created_at,
 		i.updated_at,
+		i.version,
 		i.from_id,
 		i.label,
 		i.to_id,
@@ -749,12 +853,12 @@ func (s *Store) Graph(ctx context.Context, args store.TermSearchArgs) (models.Gr
 		snippet(fts, -1, ?, ?, ' ... ', ?) as snippet
 	FROM fts
 	JOIN items i ON i.id = fts.id
-	WHERE fts MATCH ?
+	WHERE fts MATCH ? AND i.deleted_at IS NULL
 	ORDER BY bm25(fts)
 	LIMIT ?;
 	`
 
-	rows, err := s.db.QueryContext(ctx, query, args.SnippetStart, args.SnippetEnd, args.SnippetTokens, args.Term, args.Limit)
+	rows, err := q.QueryContext(ctx, query, args.SnippetStart, args.SnippetEnd, args.SnippetTokens, args.Term, args.Limit)
 	if err != nil {
 		return graph, err
 	}
@@ -765,6 +869,7 @@ func (s *Store) Graph(ctx context.Context, args store.TermSearchArgs) (models.Gr
 		var itemType string
 		var createdAt int64
 		var updatedAt int64
+		var version uint64
 		var from_id uint64
 		var label string
 		var to_id uint64
@@ -772,7 +877,7 @@ func (s *Store) Graph(ctx context.Context, args store.TermSearchArgs) (models.Gr
 		var props []byte
 		var snippet string
 
-		if err := rows.Scan(&id, &itemType, &createdAt, &updatedAt, &from_id, &label, &to_id, &weight, &props, &snippet); err != nil {
+		if err := rows.Scan(&id, &itemType, &createdAt, &updatedAt, &version, &from_id, &label, &to_id, &weight, &props, &snippet); err != nil {
 			return graph, err
 		}
 
@@ -790,6 +895,7 @@ func (s *Store) Graph(ctx context.Context, args store.TermSearchArgs) (models.Gr
 					ID:         id,
 					CreatedAt:  time.Unix(createdAt, 0),
 					UpdatedAt:  time.Unix(updatedAt, 0),
+					Version:    version,
 					Label:      label,
 					Properties: properties,
 					Snippet:    snippet,
@@ -802,6 +908,7 @@ func (s *Store) Graph(ctx context.Context, args store.TermSearchArgs) (models.Gr
 					ID:         id,
 					CreatedAt:  time.Unix(createdAt, 0),
 					UpdatedAt:  time.Unix(updatedAt, 0),
+					Version:    version,
 					From:       from_id,
 					Label:      label,
 					To:         to_id,
@@ -819,7 +926,7 @@ func (s *Store) Graph(ctx context.Context, args store.TermSearchArgs) (models.Gr
 
 	// check for missing nodes and if any missing nodes found, fetch the missing.
 	missing := missingNodes(graph)
-	missingNodesFetched, err := nodesByID(ctx, s.db, missing...)
+	missingNodesFetched, err := nodesByID(ctx, q, missing...)
 	if err != nil {
 		return graph, err
 	}
@@ -829,32 +936,25 @@ func (s *Store) Graph(ctx context.Context, args store.TermSearchArgs) (models.Gr
 }
 
 // edgesByNodeID is a helper used to retrieve all edges with the given node IDs.
-func edgesByNodeID(ctx context.Context, db *sql.DB, ids ...uint64) ([]models.Edge, error) {
-	edges := make([]models.Edge, 0, len(ids))
-
+func edgesByNodeID(ctx context.Context, db querier, ids ...uint64) ([]models.Edge, error) {
 	/*
 		You need this ugly syntax because you need to build a query string with all the ID's
 		Which means that you need a `?` for every ID.
 	*/
-	placeholders := make([]string, len(ids))
-	args := make([]any, len(ids))
-
-	for i, id := range ids {
-		placeholders[i] = "?"
-		args[i] = id
-	}
+	marks, args := placeholders(ids...)
 
 	/*
 		Build the query string filling all the placeholder with `?` for every ID.
 	*/
 	query := fmt.Sprintf(
 		`
-			SELECT e.id, e.created_at, e.updated_at, e.from_id, e.label, e.to_id, e.properties
+			SELECT e.id, e.created_at, e.updated_at, e.version, e.from_id, e.label, e.to_id, e.weight, e.properties
 			FROM items e
-			WHERE e.from_id IN (%s) OR e.to_id IN (%s);
+			WHERE (e.from_id IN (%s) OR e.to_id IN (%s)) AND e.deleted_at IS NULL
+			ORDER BY e.id;
 		`,
-		strings.Join(placeholders, ","),
-		strings.Join(placeholders, ","),
+		marks,
+		marks,
 	)
 
 	rows, err := db.QueryContext(ctx, query, append(args, args...)...)
@@ -862,75 +962,345 @@ func edgesByNodeID(ctx context.Context, db *sql.DB, ids ...uint64) ([]models.Edg
 		return nil, err
 	}
 
-	for rows.Next() {
-		e := models.Edge{}
+	return collectEdges(rows)
+}
 
-		var createdAt int64
-		var updatedAt int64
+// edgesByID is a helper used to retrieve all edges with the given IDs.
+func edgesByID(ctx context.Context, db querier, ids ...uint64) ([]models.Edge, error) {
+	marks, args := placeholders(ids...)
 
-		var props []byte
-		if err := rows.Scan(&e.ID, &createdAt, &updatedAt, &e.From, &e.Label, &e.To, &props); err != nil {
-			return edges, err
+	query := fmt.Sprintf(
+		`
+			SELECT e.id, e.created_at, e.updated_at, e.version, e.from_id, e.label, e.to_id, e.weight, e.properties
+			FROM items e
+			WHERE e.id IN (%s) AND e.from_id > 0 AND e.to_id > 0 AND e.deleted_at IS NULL
+			ORDER BY e.id;
+		`,
+		marks,
+	)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectEdges(rows)
+}
+
+// neighbourhood walks the graph from the seeds returning the CTE, and the arguments for it, exposing `hood(id, depth)`
+// which is every node within the depth limited to the node budget, closest first.
+//
+// The walk expands a hop at a time and only keeps the nodes it has not reached before, so each node is kept at its
+// shortest depth and the walk stops as soon as a hop reaches no new nodes, however deep it is allowed to go.
+func neighbourhood(ctx context.Context, q querier, seeds []uint64, args store.SubGraphArgs) (string, []any, error) {
+	labelsJSON, err := json.Marshal(args.EdgeLabels)
+	if err != nil {
+		return "", nil, err
+	}
+
+	labelFilter := ""
+	if len(args.EdgeLabels) > 0 {
+		labelFilter = "AND e.label IN (SELECT value FROM json_each(?))"
+	}
+
+	out := fmt.Sprintf(
+		`
+		SELECT e.to_id
+		FROM items e
+		WHERE e.from_id IN (SELECT value FROM json_each(?)) AND e.to_id > 0 AND e.deleted_at IS NULL %s
+		`,
+		labelFilter,
+	)
+
+	in := fmt.Sprintf(
+		`
+		SELECT e.from_id
+		FROM items e
+		WHERE e.to_id IN (SELECT value FROM json_each(?)) AND e.from_id > 0 AND e.deleted_at IS NULL %s
+		`,
+		labelFilter,
+	)
+
+	var steps []string
+
+	switch args.Direction {
+	case store.DirectionOut:
+		steps = []string{out}
+	case store.DirectionIn:
+		steps = []string{in}
+	case store.DirectionBoth:
+		steps = []string{out, in}
+	default:
+		return "", nil, fmt.Errorf("%w: unsupported direction: %s", store.ErrInvalid, args.Direction)
+	}
+
+	step := strings.Join(steps, "UNION")
+
+	// walked holds the `[id, depth]` of each reached node in the order they were reached.
+	depths := map[uint64]int{}
+	walked := [][2]uint64{}
+	frontier := []uint64{}
+
+	for _, id := range seeds {
+		if _, ok := depths[id]; !ok {
+			depths[id] = 0
+			walked = append(walked, [2]uint64{id, 0})
+			frontier = append(frontier, id)
 		}
+	}
 
-		if err := e.Properties.FromBytes(props); err != nil {
-			return edges, err
+	for depth := 1; depth <= args.Depth && len(frontier) > 0; depth++ {
+		frontierJSON, err := json.Marshal(frontier)
+		if err != nil {
+			return "", nil, err
 		}
 
-		e.CreatedAt = time.Unix(createdAt, 0)
-		e.UpdatedAt = time.Unix(updatedAt, 0)
+		stepArgs := []any{}
+		for range steps {
+			stepArgs = append(stepArgs, string(frontierJSON))
+			if len(args.EdgeLabels) > 0 {
+				stepArgs = append(stepArgs, string(labelsJSON))
+			}
+		}
 
-		edges = append(edges, e)
+		rows, err := q.QueryContext(ctx, step, stepArgs...)
+		if err != nil {
+			return "", nil, err
+		}
+
+		frontier = []uint64{}
+
+		for rows.Next() {
+			var id uint64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return "", nil, err
+			}
+
+			if _, ok := depths[id]; !ok {
+				depths[id] = depth
+				walked = append(walked, [2]uint64{id, uint64(depth)})
+				frontier = append(frontier, id)
+			}
+		}
+
+		if err := rows.Close(); err != nil {
+			return "", nil, err
+		}
+
+		if err := rows.Err(); err != nil {
+			return "", nil, err
+		}
 	}
 
-	return edges, nil
+	walkedJSON, err := json.Marshal(walked)
+	if err != nil {
+		return "", nil, err
+	}
+
+	cte := `
+		WITH
+		hood(id, depth) AS (
+			SELECT n.id, json_extract(w.value, '$[1]')
+			FROM json_each(?) w
+			JOIN items n ON n.id = json_extract(w.value, '$[0]') AND n.from_id = 0 AND n.to_id = 0 AND n.deleted_at IS NULL
+			ORDER BY json_extract(w.value, '$[1]'), n.id
+			LIMIT ?
+		)
+		`
+
+	return cte, []any{string(walkedJSON), args.Limit}, nil
 }
 
-// SubGraph returns a new sub-graph a starting point.
+// SubGraph returns a new sub-graph expanding N hops from the starting point.
+// The starting points are the FromNodeID, ToNodeID and the nodes of EdgeID.
 func (s *Store) SubGraph(ctx context.Context, args store.SubGraphArgs) (models.Graph, error) {
+	// use a single transaction so that the nodes and edges are from the same snapshot.
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		return models.Graph{}, err
+	}
+
+	defer tx.Rollback()
+
+	graph, err := subGraph(ctx, tx, args)
+	if err != nil {
+		return graph, err
+	}
+
+	return graph, tx.Commit()
+}
+
+// subGraph returns a new sub-graph expanding N hops from the starting point.
+func subGraph(ctx context.Context, q querier, args store.SubGraphArgs) (models.Graph, error) {
 	graph := models.Graph{
 		Nodes: make([]models.Node, 0),
 		Edges: make([]models.Edge, 0),
 	}
 
-	// if we do not have node ID's then we skip this step
-	if args.FromNodeID > 0 || args.ToNodeID > 0 {
-		nodes, err := nodesByID(ctx, s.db, args.FromNodeID, args.EdgeID)
-		if err != nil {
-			return models.Graph{}, err
-		}
+	if args.Depth <= 0 {
+		args.Depth = 1
+	}
+
+	args.Depth = min(args.Depth, store.MaxSubGraphDepth)
+
+	if args.Direction == "" {
+		args.Direction = store.DirectionBoth
+	}
+
+	if args.Limit == 0 {
+		args.Limit = DefaultLimit
+	}
 
-		graph.AddNodes(nodes...)
+	seeds := []uint64{}
+
+	if args.FromNodeID > 0 {
+		seeds = append(seeds, args.FromNodeID)
+	}
 
-		edges, err := edgesByNodeID(ctx, s.db, args.FromNodeID, args.ToNodeID)
+	if args.ToNodeID > 0 {
+		seeds = append(seeds, args.ToNodeID)
+	}
+
+	// if we have a edge ID, then fetch it from the db and walk from both ends of it.
+	if args.EdgeID > 0 {
+		e, err := edge(ctx, q, args.EdgeID)
 		if err != nil {
 			return models.Graph{}, err
 		}
 
-		graph.AddEdges(edges...)
+		seeds = append(seeds, e.From, e.To)
 	}
 
-	// if we have a edge ID, then fetch it from the db
-	if args.EdgeID > 0 {
-		edge, err := s.Edge(ctx, args.EdgeID)
+	if len(seeds) == 0 {
+		return graph, nil
+	}
+
+	cte, cteArgs, err := neighbourhood(ctx, q, seeds, args)
+	if err != nil {
+		return graph, err
+	}
+
+	rows, err := q.QueryContext(
+		ctx,
+		cte+`
+		SELECT n.id, n.created_at, n.updated_at, n.version, n.label, n.properties
+		FROM hood h
+		JOIN items n ON n.id = h.id
+		ORDER BY h.depth, n.id;
+		`,
+		cteArgs...,
+	)
+
+	if err != nil {
+		return graph, err
+	}
+
+	nodes, err := collectNodes(rows)
+	if err != nil {
+		return graph, err
+	}
+
+	graph.AddNodes(nodes...)
+
+	labelFilter := ""
+	edgeArgs := cteArgs
+
+	if len(args.EdgeLabels) > 0 {
+		labels, err := json.Marshal(args.EdgeLabels)
 		if err != nil {
-			return models.Graph{}, err
+			return graph, err
 		}
 
-		graph.AddEdges(edge)
+		labelFilter = "AND e.label IN (SELECT value FROM json_each(?))"
+		edgeArgs = append(edgeArgs, string(labels))
 	}
 
-	// check for missing nodes and if any missing nodes found, fetch the missing.
-	missing := missingNodes(graph)
-	missingNodesFetched, err := nodesByID(ctx, s.db, missing...)
+	// only the edges between the nodes in the neighbourhood are returned.
+	rows, err = q.QueryContext(
+		ctx,
+		cte+fmt.Sprintf(
+			`
+			SELECT e.id, e.created_at, e.updated_at, e.version, e.from_id, e.label, e.to_id, e.weight, e.properties
+			FROM items e
+			WHERE
+				e.from_id IN (SELECT id FROM hood)
+				AND e.to_id IN (SELECT id FROM hood)
+				AND e.deleted_at IS NULL
+				%s
+			ORDER BY e.id;
+			`,
+			labelFilter,
+		),
+		edgeArgs...,
+	)
+
 	if err != nil {
 		return graph, err
 	}
 
-	graph.AddNodes(missingNodesFetched...)
+	edges, err := collectEdges(rows)
+	if err != nil {
+		return graph, err
+	}
+
+	graph.AddEdges(edges...)
 	return graph, nil
 }
 
+// Reindex rebuilds the full text search index from the items table returning the number of items indexed.
+// It is used to repair databases where the index has drifted from the stored items. Trashed items are not indexed,
+// they are indexed again when they are restored.
+func (s *Store) Reindex(ctx context.Context) (int, error) {
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM fts;`); err != nil {
+		return 0, err
+	}
+
+	// NOTE: json_extract_keys and json_extract_values is a custom registered function.
+	query := `
+	INSERT INTO fts (rowid, id, type, from_id, label, to_id, weight, prop_keys, prop_values)
+	SELECT
+		i.id,
+		i.id,
+		CASE
+			WHEN i.from_id = 0 AND i.to_id = 0 THEN 'node'
+			WHEN i.from_id != 0 AND i.to_id != 0 THEN 'edge'
+		END,
+		i.from_id,
+		i.label,
+		i.to_id,
+		i.weight,
+		json_extract_keys(i.properties),
+		json_extract_values(i.properties)
+	FROM items i
+	WHERE i.deleted_at IS NULL;
+	`
+
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	indexed, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO fts(fts) VALUES ('optimize');`); err != nil {
+		return 0, err
+	}
+
+	s.logger.Info("full text search reindexed", slog.Int64("items", indexed))
+	return int(indexed), tx.Commit()
+}
+
 // Health will test the DB connection.
 func (s *Store) Health(ctx context.Context) models.Health {
 	err := s.db.PingContext(ctx)
@@ -1,6 +1,7 @@
 package sqlite_test
 
 import (
+	"log/slog"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -17,6 +18,21 @@ func preload(t *testing.T, store *sqlite.Store, nodes ...models.Node) {
 	}
 }
 
+func TestNew_DefaultLogger(t *testing.T) {
+	before := slog.Default()
+
+	s, err := sqlite.New(t.Context(), ":memory:")
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	defer s.Close()
+
+	if slog.Default() != before {
+		t.Errorf("New() changed the default logger")
+	}
+}
+
 func TestStore_UpsertNodes(t *testing.T) {
 	tests := []struct {
 		name    string // description of this test case
@@ -34,8 +50,8 @@ func TestStore_UpsertNodes(t *testing.T) {
 				{Label: "person", Properties: models.Properties{"name": "bar", "age": 21}},
 			},
 			want: []models.Node{
-				{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
-				{ID: 2, Label: "person", Properties: models.Properties{"name": "bar", "age": float64(21)}},
+				{ID: 1, Version: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
+				{ID: 2, Version: 1, Label: "person", Properties: models.Properties{"name": "bar", "age": float64(21)}},
 			},
 			wantErr: false,
 		},
@@ -53,8 +69,8 @@ func TestStore_UpsertNodes(t *testing.T) {
 				{ID: 1, Label: "person", Properties: models.Properties{"name": "bar", "age": 21, "meta": map[string]string{"hair": "brown"}}},
 			},
 			want: []models.Node{
-				{ID: 1, Label: "person", Properties: models.Properties{"name": "bar", "age": float64(21), "meta": map[string]any{"hair": string("brown")}}},
-				{ID: 2, Label: "person", Properties: models.Properties{"name": "foo"}},
+				{ID: 1, Version: 2, Label: "person", Properties: models.Properties{"name": "bar", "age": float64(21), "meta": map[string]any{"hair": string("brown")}}},
+				{ID: 2, Version: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
 			},
 			wantErr: false,
 		},
@@ -87,6 +103,7 @@ func TestStore_UpsertNodes(t *testing.T) {
 				tt.want,
 				got,
 				cmpopts.EquateEmpty(),
+				cmpopts.IgnoreFields(models.Node{}, "CreatedAt", "UpdatedAt", "Snippet"),
 				cmpopts.SortSlices(
 					func(a, b models.Node) bool { return int(a.ID) < int(b.ID) },
 				),
@@ -116,8 +133,8 @@ func TestNodesTermSearch(t *testing.T) {
 				{ID: 2, Label: "person", Properties: models.Properties{"name": "bar", "age": 21}},
 			},
 			want: []models.Node{
-				{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
-				{ID: 2, Label: "person", Properties: models.Properties{"name": "bar", "age": float64(21)}},
+				{ID: 1, Version: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
+				{ID: 2, Version: 1, Label: "person", Properties: models.Properties{"name": "bar", "age": float64(21)}},
 			},
 			args:    store.TermSearchArgs{Term: "name"},
 			wantErr: false,
@@ -130,7 +147,7 @@ func TestNodesTermSearch(t *testing.T) {
 				{ID: 2, Label: "person", Properties: models.Properties{"name": "bar", "age": 21}},
 			},
 			want: []models.Node{
-				{ID: 2, Label: "person", Properties: models.Properties{"name": "bar", "age": float64(21)}},
+				{ID: 2, Version: 1, Label: "person", Properties: models.Properties{"name": "bar", "age": float64(21)}},
 			},
 			args:    store.TermSearchArgs{Term: "bar"},
 			wantErr: false,
@@ -143,7 +160,7 @@ func TestNodesTermSearch(t *testing.T) {
 				{ID: 2, Label: "person", Properties: models.Properties{"name": "bar", "age": 21}},
 			},
 			want: []models.Node{
-				{ID: 2, Label: "person", Properties: models.Properties{"name": "bar", "age": float64(21)}},
+				{ID: 2, Version: 1, Label: "person", Properties: models.Properties{"name": "bar", "age": float64(21)}},
 			},
 			args:    store.TermSearchArgs{Term: "prop_keys:age"},
 			wantErr: false,
@@ -157,8 +174,8 @@ func TestNodesTermSearch(t *testing.T) {
 				{ID: 3, Label: "dog", Properties: models.Properties{"short": true, "name": "socks"}},
 			},
 			want: []models.Node{
-				{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
-				{ID: 2, Label: "person", Properties: models.Properties{"name": "bar", "age": float64(21)}},
+				{ID: 1, Version: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
+				{ID: 2, Version: 1, Label: "person", Properties: models.Properties{"name": "bar", "age": float64(21)}},
 			},
 			args:    store.TermSearchArgs{Term: "prop_values:foo OR prop_values:bar"},
 			wantErr: false,
@@ -172,7 +189,7 @@ func TestNodesTermSearch(t *testing.T) {
 				{ID: 3, Label: "dog", Properties: models.Properties{"short": true, "name": "socks"}},
 			},
 			want: []models.Node{
-				{ID: 3, Label: "dog", Properties: models.Properties{"short": true, "name": "socks"}},
+				{ID: 3, Version: 1, Label: "dog", Properties: models.Properties{"short": true, "name": "socks"}},
 			},
 			args:    store.TermSearchArgs{Term: "label:dog"},
 			wantErr: false,
@@ -186,7 +203,7 @@ func TestNodesTermSearch(t *testing.T) {
 				{ID: 3, Label: "dog", Properties: models.Properties{"short": true, "name": "socks"}},
 			},
 			want: []models.Node{
-				{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
+				{ID: 1, Version: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
 			},
 			args:    store.TermSearchArgs{Term: "label:person AND foo"},
 			wantErr: false,
@@ -200,8 +217,8 @@ func TestNodesTermSearch(t *testing.T) {
 				{ID: 3, Label: "dog", Properties: models.Properties{"short": true, "name": "socks"}},
 			},
 			want: []models.Node{
-				{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
-				{ID: 2, Label: "person", Properties: models.Properties{"name": "bar", "age": float64(21)}},
+				{ID: 1, Version: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
+				{ID: 2, Version: 1, Label: "person", Properties: models.Properties{"name": "bar", "age": float64(21)}},
 			},
 			args:    store.TermSearchArgs{Term: "prop_keys:name", Limit: 2},
 			wantErr: false,
@@ -235,6 +252,7 @@ func TestNodesTermSearch(t *testing.T) {
 				tt.want,
 				got,
 				cmpopts.EquateEmpty(),
+				cmpopts.IgnoreFields(models.Node{}, "CreatedAt", "UpdatedAt", "Snippet"),
 				cmpopts.SortSlices(
 					func(a, b models.Node) bool { return int(a.ID) < int(b.ID) },
 				),
@@ -0,0 +1,186 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// DeleteNodes deletes the nodes with the given IDs applying the delete policy to the attached edges.
+// IDs which do not exist, or are not nodes, are ignored and will not be reported in the result.
+func (s *Store) DeleteNodes(ctx context.Context, args store.DeleteNodesArgs) (store.DeleteResult, error) {
+	return s.deleteNodes(ctx, args, func(tx *sql.Tx, nodeIDs, edgeIDs []uint64) error {
+		// remove the edges explicitly before the nodes so that we know exactly what was removed,
+		// and do not rely on the `items_after_delete` trigger cleaning up after us.
+		if err := deleteByID(ctx, tx, edgeIDs...); err != nil {
+			return err
+		}
+
+		return deleteByID(ctx, tx, nodeIDs...)
+	})
+}
+
+// deleteNodes applies the delete policy to the nodes with the given IDs, remove is called in the transaction with the
+// nodes and the attached edges which are removed.
+func (s *Store) deleteNodes(ctx context.Context, args store.DeleteNodesArgs, remove func(tx *sql.Tx, nodeIDs, edgeIDs []uint64) error) (store.DeleteResult, error) {
+	result := store.DeleteResult{
+		Nodes:    make([]models.Node, 0),
+		Edges:    make([]models.Edge, 0),
+		Detached: make([]models.Edge, 0),
+	}
+
+	if args.Policy == "" {
+		args.Policy = store.DeleteRestrict
+	}
+
+	switch args.Policy {
+	case store.DeleteRestrict, store.DeleteCascade, store.DeleteDetach:
+	default:
+		return result, fmt.Errorf("%w: unsupported delete policy: %s", store.ErrInvalid, args.Policy)
+	}
+
+	if len(args.IDs) == 0 {
+		return result, nil
+	}
+
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	defer tx.Rollback()
+
+	marks, ids := placeholders(args.IDs...)
+
+	query := fmt.Sprintf(
+		`
+			SELECT n.id, n.created_at, n.updated_at, n.version, n.label, n.properties
+			FROM items n
+			WHERE n.id IN (%s) AND n.from_id = 0 AND n.to_id = 0 AND n.deleted_at IS NULL
+			ORDER BY n.id;
+		`,
+		marks,
+	)
+
+	rows, err := tx.QueryContext(ctx, query, ids...)
+	if err != nil {
+		return result, err
+	}
+
+	nodes, err := collectNodes(rows)
+	if err != nil {
+		return result, err
+	}
+
+	if len(nodes) == 0 {
+		return result, nil
+	}
+
+	nodeIDs := make([]uint64, len(nodes))
+	for i, n := range nodes {
+		nodeIDs[i] = n.ID
+	}
+
+	attached, err := edgesByNodeID(ctx, tx, nodeIDs...)
+	if err != nil {
+		return result, err
+	}
+
+	if len(attached) > 0 && args.Policy == store.DeleteRestrict {
+		return result, &store.RestrictError{Edges: attached}
+	}
+
+	edgeIDs := make([]uint64, len(attached))
+	for i, e := range attached {
+		edgeIDs[i] = e.ID
+	}
+
+	if err := remove(tx, nodeIDs, edgeIDs); err != nil {
+		return result, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, err
+	}
+
+	result.Nodes = nodes
+
+	switch args.Policy {
+	case store.DeleteDetach:
+		result.Detached = attached
+	default:
+		result.Edges = attached
+	}
+
+	return result, nil
+}
+
+// DeleteEdges deletes the edges with the given IDs returning the edges deleted.
+// IDs which do not exist, or are not edges, are ignored and will not be reported in the result.
+func (s *Store) DeleteEdges(ctx context.Context, ids ...uint64) ([]models.Edge, error) {
+	return s.deleteEdges(ctx, ids, func(tx *sql.Tx, edgeIDs []uint64) error {
+		return deleteByID(ctx, tx, edgeIDs...)
+	})
+}
+
+// deleteEdges finds the edges with the given IDs, remove is called in the transaction with the edges which are removed.
+func (s *Store) deleteEdges(ctx context.Context, ids []uint64, remove func(tx *sql.Tx, edgeIDs []uint64) error) ([]models.Edge, error) {
+	if len(ids) == 0 {
+		return []models.Edge{}, nil
+	}
+
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	marks, args := placeholders(ids...)
+
+	query := fmt.Sprintf(
+		`
+			SELECT e.id, e.created_at, e.updated_at, e.version, e.from_id, e.label, e.to_id, e.weight, e.properties
+			FROM items e
+			WHERE e.id IN (%s) AND e.from_id > 0 AND e.to_id > 0 AND e.deleted_at IS NULL
+			ORDER BY e.id;
+		`,
+		marks,
+	)
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	edges, err := collectEdges(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	edgeIDs := make([]uint64, len(edges))
+	for i, e := range edges {
+		edgeIDs[i] = e.ID
+	}
+
+	if err := remove(tx, edgeIDs); err != nil {
+		return nil, err
+	}
+
+	return edges, tx.Commit()
+}
+
+// deleteByID is a helper used to delete all the items with the given IDs.
+func deleteByID(ctx context.Context, q querier, ids ...uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	marks, args := placeholders(ids...)
+
+	_, err := q.ExecContext(ctx, fmt.Sprintf(`DELETE FROM items WHERE id IN (%s);`, marks), args...)
+	return err
+}
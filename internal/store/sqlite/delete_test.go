@@ -0,0 +1,190 @@
+package sqlite_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/models"
+)
+
+func preloadEdges(t *testing.T, store *sqlite.Store, edges ...models.Edge) {
+	_, err := store.UpsertEdges(t.Context(), edges...)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStore_DeleteNodes(t *testing.T) {
+	nodes := []models.Node{
+		{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
+		{ID: 2, Label: "person", Properties: models.Properties{"name": "bar"}},
+		{ID: 3, Label: "dog", Properties: models.Properties{"name": "socks"}},
+	}
+
+	edges := []models.Edge{
+		{ID: 4, From: 1, Label: "knows", To: 2},
+		{ID: 5, From: 1, Label: "owns", To: 3},
+	}
+
+	tests := []struct {
+		name         string // description of this test case
+		args         store.DeleteNodesArgs
+		want         store.DeleteResult
+		wantConflict bool
+		wantNodes    []uint64 // node IDs remaining after the delete
+		wantEdges    []uint64 // edge IDs remaining after the delete
+	}{
+		{
+			name:         "restrict with attached edges",
+			args:         store.DeleteNodesArgs{IDs: []uint64{1}, Policy: store.DeleteRestrict},
+			want:         store.DeleteResult{Edges: []models.Edge{edges[0], edges[1]}},
+			wantConflict: true,
+			wantNodes:    []uint64{1, 2, 3},
+			wantEdges:    []uint64{4, 5},
+		},
+		{
+			name:         "default policy is restrict",
+			args:         store.DeleteNodesArgs{IDs: []uint64{3}},
+			want:         store.DeleteResult{Edges: []models.Edge{edges[1]}},
+			wantConflict: true,
+			wantNodes:    []uint64{1, 2, 3},
+			wantEdges:    []uint64{4, 5},
+		},
+		{
+			name:      "cascade",
+			args:      store.DeleteNodesArgs{IDs: []uint64{3}, Policy: store.DeleteCascade},
+			want:      store.DeleteResult{Nodes: []models.Node{nodes[2]}, Edges: []models.Edge{edges[1]}},
+			wantNodes: []uint64{1, 2},
+			wantEdges: []uint64{4},
+		},
+		{
+			name:      "detach",
+			args:      store.DeleteNodesArgs{IDs: []uint64{1}, Policy: store.DeleteDetach},
+			want:      store.DeleteResult{Nodes: []models.Node{nodes[0]}, Detached: []models.Edge{edges[0], edges[1]}},
+			wantNodes: []uint64{2, 3},
+			wantEdges: []uint64{},
+		},
+		{
+			name:      "missing and edge IDs are ignored",
+			args:      store.DeleteNodesArgs{IDs: []uint64{4, 100}, Policy: store.DeleteCascade},
+			want:      store.DeleteResult{},
+			wantNodes: []uint64{1, 2, 3},
+			wantEdges: []uint64{4, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+
+			s, err := sqlite.New(ctx, ":memory:")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			preload(t, s, nodes...)
+			preloadEdges(t, s, edges...)
+
+			got, gotErr := s.DeleteNodes(ctx, tt.args)
+
+			if tt.wantConflict {
+				var restrict *store.RestrictError
+				if !errors.As(gotErr, &restrict) || !errors.Is(gotErr, store.ErrConflict) {
+					t.Fatalf("DeleteNodes() expected a restrict conflict, got: %v", gotErr)
+				}
+
+				got = store.DeleteResult{Edges: restrict.Edges}
+			} else if gotErr != nil {
+				t.Fatalf("DeleteNodes() failed: %v", gotErr)
+			}
+
+			diff := cmp.Diff(
+				tt.want,
+				got,
+				cmpopts.EquateEmpty(),
+				cmpopts.IgnoreFields(models.Node{}, "CreatedAt", "UpdatedAt", "Version"),
+				cmpopts.IgnoreFields(models.Edge{}, "CreatedAt", "UpdatedAt", "Version"),
+			)
+
+			if diff != "" {
+				t.Errorf("DeleteNodes() = mismatch (-want, +got): \n%s", diff)
+			}
+
+			remainingNodes, err := s.Nodes(ctx, store.NodesArgs{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gotNodes := []uint64{}
+			for _, n := range remainingNodes {
+				gotNodes = append(gotNodes, n.ID)
+			}
+
+			if diff := cmp.Diff(tt.wantNodes, gotNodes, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("DeleteNodes() remaining nodes mismatch (-want, +got): \n%s", diff)
+			}
+
+			remainingEdges, err := s.Edges(ctx, store.EdgesArgs{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gotEdges := []uint64{}
+			for _, e := range remainingEdges {
+				gotEdges = append(gotEdges, e.ID)
+			}
+
+			if diff := cmp.Diff(tt.wantEdges, gotEdges, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("DeleteNodes() remaining edges mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStore_DeleteEdges(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := sqlite.New(ctx, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	preload(t, s,
+		models.Node{ID: 1, Label: "person"},
+		models.Node{ID: 2, Label: "person"},
+	)
+
+	preloadEdges(t, s, models.Edge{ID: 3, From: 1, Label: "knows", To: 2, Weight: 2})
+
+	// node IDs are ignored when deleting edges.
+	got, err := s.DeleteEdges(ctx, 1, 3)
+	if err != nil {
+		t.Fatalf("DeleteEdges() failed: %v", err)
+	}
+
+	want := []models.Edge{{ID: 3, Version: 1, From: 1, Label: "knows", To: 2, Weight: 2, Properties: models.Properties{}}}
+
+	diff := cmp.Diff(
+		want,
+		got,
+		cmpopts.EquateEmpty(),
+		cmpopts.IgnoreFields(models.Edge{}, "CreatedAt", "UpdatedAt"),
+	)
+
+	if diff != "" {
+		t.Errorf("DeleteEdges() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	nodes, err := s.Nodes(ctx, store.NodesArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nodes) != 2 {
+		t.Errorf("DeleteEdges() expected the nodes to remain, got %d nodes", len(nodes))
+	}
+}
@@ -0,0 +1,4 @@
+package sqlite
+
+// NodesQuery returns the query and its arguments used by Nodes, eg: to check the query plans.
+var NodesQuery = nodesQuery
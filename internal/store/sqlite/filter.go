@@ -0,0 +1,130 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenmud/edgedb/internal/store"
+)
+
+// jsonPath returns the quoted SQLite JSON path literal for the predicate, eg: `'$."meta"."hair"'`.
+// The path is validated by the predicate so it is safe to inline, inlining also lets sqlite match
+// expression indexes on the same path.
+func jsonPath(p store.Predicate) string {
+	keys := p.Keys()
+
+	var b strings.Builder
+	b.WriteString(`'$`)
+	for _, k := range keys {
+		fmt.Fprintf(&b, `."%s"`, k)
+	}
+	b.WriteString(`'`)
+
+	return b.String()
+}
+
+// bindValue converts a predicate value into a value sqlite compares to `json_extract` results.
+func bindValue(v any) any {
+	switch v := v.(type) {
+	case bool:
+		// json_extract returns JSON true and false as 1 and 0.
+		if v {
+			return 1
+		}
+		return 0
+	default:
+		return v
+	}
+}
+
+// compileFilter compiles the filter into SQL conditions on the table alias.
+// Each condition is prefixed with `AND` so the result can be appended to an existing `WHERE`.
+func compileFilter(alias string, f store.Filter) (string, []any, error) {
+	if err := f.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	var conds []string
+	var args []any
+
+	if len(f.Labels) > 0 {
+		// labels are compared with `=` instead of `IN` so sqlite can use the property indexes scoped to the labels.
+		// Several labels only use the indexes if every label has an index on the path, sqlite then unions the indexes.
+		labels := make([]string, len(f.Labels))
+		for i, label := range f.Labels {
+			labels[i] = fmt.Sprintf("%s.label = ?", alias)
+			args = append(args, label)
+		}
+		conds = append(conds, "("+strings.Join(labels, " OR ")+")")
+	}
+
+	for _, p := range f.Where {
+		extract := fmt.Sprintf("json_extract(%s.properties, %s)", alias, jsonPath(p))
+
+		switch p.Op {
+		case store.OpEq:
+			conds = append(conds, extract+" = ?")
+		case store.OpNe:
+			conds = append(conds, extract+" != ?")
+		case store.OpGt:
+			conds = append(conds, extract+" > ?")
+		case store.OpGte:
+			conds = append(conds, extract+" >= ?")
+		case store.OpLt:
+			conds = append(conds, extract+" < ?")
+		case store.OpLte:
+			conds = append(conds, extract+" <= ?")
+		case store.OpIn:
+			values := p.Value.([]any)
+			if len(values) == 0 {
+				// nothing is in an empty list.
+				conds = append(conds, "0")
+				continue
+			}
+
+			bound := make([]any, len(values))
+			for i, v := range values {
+				bound[i] = bindValue(v)
+			}
+
+			ph, phArgs := placeholders(bound...)
+			conds = append(conds, fmt.Sprintf("%s IN (%s)", extract, ph))
+			args = append(args, phArgs...)
+			continue
+		case store.OpExists:
+			conds = append(conds, fmt.Sprintf("json_type(%s.properties, %s) IS NOT NULL", alias, jsonPath(p)))
+			continue
+		case store.OpNotExists:
+			conds = append(conds, fmt.Sprintf("json_type(%s.properties, %s) IS NULL", alias, jsonPath(p)))
+			continue
+		}
+
+		args = append(args, bindValue(p.Value))
+	}
+
+	ranges := []struct {
+		column string
+		r      store.TimeRange
+	}{
+		{column: "created_at", r: f.CreatedAt},
+		{column: "updated_at", r: f.UpdatedAt},
+	}
+
+	for _, tr := range ranges {
+		if !tr.r.After.IsZero() {
+			conds = append(conds, fmt.Sprintf("%s.%s >= ?", alias, tr.column))
+			args = append(args, tr.r.After.Unix())
+		}
+
+		if !tr.r.Before.IsZero() {
+			conds = append(conds, fmt.Sprintf("%s.%s < ?", alias, tr.column))
+			args = append(args, tr.r.Before.Unix())
+		}
+	}
+
+	if len(conds) == 0 {
+		return "", nil, nil
+	}
+
+	return "AND " + strings.Join(conds, " AND "), args, nil
+}
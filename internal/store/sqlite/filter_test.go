@@ -0,0 +1,197 @@
+package sqlite_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/models"
+)
+
+func nodeIDs(nodes []models.Node) []uint64 {
+	ids := make([]uint64, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.ID
+	}
+	return ids
+}
+
+func TestStore_NodesFilter(t *testing.T) {
+	nodes := []models.Node{
+		{ID: 1, Label: "person", Properties: models.Properties{"name": "foo", "age": 21, "meta": map[string]any{"hair": "brown"}}},
+		{ID: 2, Label: "person", Properties: models.Properties{"name": "bar", "age": 42, "active": true}},
+		{ID: 3, Label: "dog", Properties: models.Properties{"name": "socks", "age": 3, "active": false}},
+		{ID: 4, Label: "cat", Properties: models.Properties{"name": "tom"}},
+	}
+
+	tests := []struct {
+		name    string // description of this test case
+		filter  store.Filter
+		want    []uint64
+		wantErr bool
+	}{
+		{
+			name:   "no filter",
+			filter: store.Filter{},
+			want:   []uint64{1, 2, 3, 4},
+		},
+		{
+			name:   "labels",
+			filter: store.Filter{Labels: []string{"dog", "cat"}},
+			want:   []uint64{3, 4},
+		},
+		{
+			name:   "greater than",
+			filter: store.Filter{Where: []store.Predicate{{Path: "age", Op: store.OpGt, Value: 30}}},
+			want:   []uint64{2},
+		},
+		{
+			name:   "less than or equal with label",
+			filter: store.Filter{Labels: []string{"person"}, Where: []store.Predicate{{Path: "age", Op: store.OpLte, Value: 21}}},
+			want:   []uint64{1},
+		},
+		{
+			name:   "nested path with properties prefix",
+			filter: store.Filter{Where: []store.Predicate{{Path: "properties.meta.hair", Op: store.OpEq, Value: "brown"}}},
+			want:   []uint64{1},
+		},
+		{
+			name:   "not equal excludes missing",
+			filter: store.Filter{Where: []store.Predicate{{Path: "active", Op: store.OpNe, Value: true}}},
+			want:   []uint64{3},
+		},
+		{
+			name:   "boolean",
+			filter: store.Filter{Where: []store.Predicate{{Path: "active", Op: store.OpEq, Value: true}}},
+			want:   []uint64{2},
+		},
+		{
+			name:   "in",
+			filter: store.Filter{Where: []store.Predicate{{Path: "name", Op: store.OpIn, Value: []any{"foo", "tom", "nobody"}}}},
+			want:   []uint64{1, 4},
+		},
+		{
+			name:   "in empty list",
+			filter: store.Filter{Where: []store.Predicate{{Path: "name", Op: store.OpIn, Value: []any{}}}},
+			want:   []uint64{},
+		},
+		{
+			name:   "exists",
+			filter: store.Filter{Where: []store.Predicate{{Path: "active", Op: store.OpExists}}},
+			want:   []uint64{2, 3},
+		},
+		{
+			name:   "not exists",
+			filter: store.Filter{Where: []store.Predicate{{Path: "age", Op: store.OpNotExists}}},
+			want:   []uint64{4},
+		},
+		{
+			name:   "created in range",
+			filter: store.Filter{CreatedAt: store.TimeRange{After: time.Now().Add(-time.Hour), Before: time.Now().Add(time.Hour)}},
+			want:   []uint64{1, 2, 3, 4},
+		},
+		{
+			name:   "updated in the future",
+			filter: store.Filter{UpdatedAt: store.TimeRange{After: time.Now().Add(time.Hour)}},
+			want:   []uint64{},
+		},
+		{
+			name:    "invalid path",
+			filter:  store.Filter{Where: []store.Predicate{{Path: "name') OR 1=1 --", Op: store.OpEq, Value: "x"}}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid operator",
+			filter:  store.Filter{Where: []store.Predicate{{Path: "name", Op: "like", Value: "x"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+
+			s, err := sqlite.New(ctx, ":memory:")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			preload(t, s, nodes...)
+
+			got, gotErr := s.Nodes(ctx, store.NodesArgs{Filter: tt.filter})
+			if gotErr != nil {
+				if !tt.wantErr {
+					t.Errorf("Nodes() failed: %v", gotErr)
+				}
+				return
+			}
+
+			if tt.wantErr {
+				t.Fatal("Nodes() succeeded unexpectedly")
+			}
+
+			if diff := cmp.Diff(tt.want, nodeIDs(got)); diff != "" {
+				t.Errorf("Nodes() = mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStore_EdgesFilter(t *testing.T) {
+	nodes := []models.Node{
+		{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
+		{ID: 2, Label: "person", Properties: models.Properties{"name": "bar"}},
+	}
+
+	edges := []models.Edge{
+		{ID: 3, From: 1, Label: "knows", To: 2, Properties: models.Properties{"since": 2001}},
+		{ID: 4, From: 2, Label: "likes", To: 1, Properties: models.Properties{"since": 2020}},
+	}
+
+	tests := []struct {
+		name   string // description of this test case
+		filter store.Filter
+		want   []uint64
+	}{
+		{
+			name:   "label",
+			filter: store.Filter{Labels: []string{"likes"}},
+			want:   []uint64{4},
+		},
+		{
+			name:   "property",
+			filter: store.Filter{Where: []store.Predicate{{Path: "since", Op: store.OpLt, Value: 2010}}},
+			want:   []uint64{3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+
+			s, err := sqlite.New(ctx, ":memory:")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			preload(t, s, nodes...)
+			preloadEdges(t, s, edges...)
+
+			got, err := s.Edges(ctx, store.EdgesArgs{Filter: tt.filter})
+			if err != nil {
+				t.Fatalf("Edges() failed: %v", err)
+			}
+
+			ids := make([]uint64, len(got))
+			for i, e := range got {
+				ids[i] = e.ID
+			}
+
+			if diff := cmp.Diff(tt.want, ids); diff != "" {
+				t.Errorf("Edges() = mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
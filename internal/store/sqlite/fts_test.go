@@ -0,0 +1,137 @@
+package sqlite_test
+
+import (
+	"testing"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/models"
+)
+
+// termIDs is a helper returning the IDs of the nodes matching the term.
+func termIDs(t *testing.T, s *sqlite.Store, term string) []uint64 {
+	nodes, err := s.NodesTermSearch(t.Context(), store.TermSearchArgs{Term: term})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []uint64{}
+	for _, n := range nodes {
+		ids = append(ids, n.ID)
+	}
+
+	return ids
+}
+
+func TestStore_FTSFollowsUpdates(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := sqlite.New(ctx, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	preload(t, s, models.Node{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}})
+	preload(t, s, models.Node{ID: 1, Label: "dog", Properties: models.Properties{"name": "socks"}})
+
+	if got := termIDs(t, s, "foo"); len(got) != 0 {
+		t.Errorf("expected the old property value to be removed from the index, got %v", got)
+	}
+
+	if got := termIDs(t, s, "label:person"); len(got) != 0 {
+		t.Errorf("expected the old label to be removed from the index, got %v", got)
+	}
+
+	if got := termIDs(t, s, "socks AND label:dog"); len(got) != 1 || got[0] != 1 {
+		t.Errorf("expected the updated node to be matched, got %v", got)
+	}
+}
+
+func TestStore_Reindex(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := sqlite.New(ctx, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	preload(t, s,
+		models.Node{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
+		models.Node{ID: 2, Label: "person", Properties: models.Properties{"name": "bar"}},
+		models.Node{ID: 3, Label: "person", Properties: models.Properties{"name": "baz"}},
+	)
+
+	// trashed items are not indexed.
+	if _, err := s.TrashNodes(ctx, store.DeleteNodesArgs{IDs: []uint64{3}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// simulate a drifted index.
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM fts;`); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := termIDs(t, s, "foo"); len(got) != 0 {
+		t.Fatalf("expected an empty index, got %v", got)
+	}
+
+	indexed, err := s.Reindex(ctx)
+	if err != nil {
+		t.Fatalf("Reindex() failed: %v", err)
+	}
+
+	if indexed != 2 {
+		t.Errorf("Reindex() = %d, want 2", indexed)
+	}
+
+	if got := termIDs(t, s, "foo"); len(got) != 1 || got[0] != 1 {
+		t.Errorf("expected node 1 to be matched after reindexing, got %v", got)
+	}
+}
+
+func TestStore_FTSFollowsTheTrash(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := sqlite.New(ctx, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	preload(t, s, models.Node{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}})
+
+	if _, err := s.TrashNodes(ctx, store.DeleteNodesArgs{IDs: []uint64{1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Reindex(ctx); err != nil {
+		t.Fatalf("Reindex() failed: %v", err)
+	}
+
+	if _, err := s.RestoreNode(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := termIDs(t, s, "foo"); len(got) != 1 || got[0] != 1 {
+		t.Errorf("expected the restored node to be matched, got %v", got)
+	}
+
+	if _, err := s.TrashNodes(ctx, store.DeleteNodesArgs{IDs: []uint64{1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// upserting a trashed node restores it.
+	preload(t, s, models.Node{ID: 1, Label: "person", Properties: models.Properties{"name": "bar"}})
+
+	if got := termIDs(t, s, "bar"); len(got) != 1 || got[0] != 1 {
+		t.Errorf("expected the upserted node to be matched, got %v", got)
+	}
+}
@@ -0,0 +1,140 @@
+package sqlite_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/models"
+)
+
+func TestStore_UpsertGraph(t *testing.T) {
+	tests := []struct {
+		name      string // description of this test case
+		preload   []models.Node
+		graph     models.Graph
+		wantNodes []models.Node
+		wantEdges []models.Edge
+		wantRefs  map[string]uint64
+		wantErr   error
+	}{
+		{
+			name: "edges reference new nodes",
+			graph: models.Graph{
+				Nodes: []models.Node{
+					{Ref: "foo", Label: "person", Properties: models.Properties{"name": "foo"}},
+					{Ref: "bar", Label: "person", Properties: models.Properties{"name": "bar"}},
+				},
+				Edges: []models.Edge{
+					{FromRef: "foo", Label: "knows", ToRef: "bar", Weight: 2},
+				},
+			},
+			wantNodes: []models.Node{
+				{ID: 1, Version: 1, Ref: "foo", Label: "person", Properties: models.Properties{"name": "foo"}},
+				{ID: 2, Version: 1, Ref: "bar", Label: "person", Properties: models.Properties{"name": "bar"}},
+			},
+			wantEdges: []models.Edge{
+				{ID: 3, Version: 1, From: 1, FromRef: "foo", Label: "knows", To: 2, ToRef: "bar", Weight: 2},
+			},
+			wantRefs: map[string]uint64{"foo": 1, "bar": 2},
+		},
+		{
+			name: "mixing references and existing IDs",
+			preload: []models.Node{
+				{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
+			},
+			graph: models.Graph{
+				Nodes: []models.Node{
+					{Ref: "socks", Label: "dog", Properties: models.Properties{"name": "socks"}},
+				},
+				Edges: []models.Edge{
+					{From: 1, Label: "owns", ToRef: "socks"},
+				},
+			},
+			wantNodes: []models.Node{
+				{ID: 2, Version: 1, Ref: "socks", Label: "dog", Properties: models.Properties{"name": "socks"}},
+			},
+			wantEdges: []models.Edge{
+				{ID: 3, Version: 1, From: 1, Label: "owns", To: 2, ToRef: "socks"},
+			},
+			wantRefs: map[string]uint64{"socks": 2},
+		},
+		{
+			name: "unknown reference rolls back the nodes",
+			graph: models.Graph{
+				Nodes: []models.Node{
+					{Ref: "foo", Label: "person"},
+				},
+				Edges: []models.Edge{
+					{FromRef: "foo", Label: "knows", ToRef: "nobody"},
+				},
+			},
+			wantErr: store.ErrInvalid,
+		},
+		{
+			name: "duplicate reference",
+			graph: models.Graph{
+				Nodes: []models.Node{
+					{Ref: "foo", Label: "person"},
+					{Ref: "foo", Label: "person"},
+				},
+			},
+			wantErr: store.ErrInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+
+			s, err := sqlite.New(ctx, ":memory:")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			preload(t, s, tt.preload...)
+
+			got, gotErr := s.UpsertGraph(ctx, tt.graph)
+			if tt.wantErr != nil {
+				if !errors.Is(gotErr, tt.wantErr) {
+					t.Fatalf("UpsertGraph() error = %v, want %v", gotErr, tt.wantErr)
+				}
+
+				nodes, err := s.Nodes(ctx, store.NodesArgs{})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(nodes) != len(tt.preload) {
+					t.Errorf("UpsertGraph() left %d nodes behind after failing", len(nodes)-len(tt.preload))
+				}
+				return
+			}
+
+			if gotErr != nil {
+				t.Fatalf("UpsertGraph() failed: %v", gotErr)
+			}
+
+			opts := cmp.Options{
+				cmpopts.EquateEmpty(),
+				cmpopts.IgnoreFields(models.Node{}, "CreatedAt", "UpdatedAt"),
+				cmpopts.IgnoreFields(models.Edge{}, "CreatedAt", "UpdatedAt"),
+			}
+
+			if diff := cmp.Diff(tt.wantNodes, got.Nodes, opts); diff != "" {
+				t.Errorf("UpsertGraph() nodes mismatch (-want, +got): \n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.wantEdges, got.Edges, opts); diff != "" {
+				t.Errorf("UpsertGraph() edges mismatch (-want, +got): \n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.wantRefs, got.Refs, opts); diff != "" {
+				t.Errorf("UpsertGraph() refs mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jenmud/edgedb/models"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// querier is implemented by both *sql.DB and *sql.Tx so helpers can be used inside and outside of a transaction.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// placeholders returns a `?,?,?` placeholder string and the matching query args for the values.
+func placeholders[T any](values ...T) (string, []any) {
+	marks := make([]string, len(values))
+	args := make([]any, len(values))
+
+	for i, v := range values {
+		marks[i] = "?"
+		args[i] = v
+	}
+
+	return strings.Join(marks, ","), args
+}
+
+// isUniqueViolation returns true if the error is caused by a unique index, eg: two nodes with the same natural key.
+func isUniqueViolation(err error) bool {
+	var se *sqlite.Error
+	return errors.As(err, &se) && se.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
+
+// scanNode scans a row selected as `id, created_at, updated_at, version, label, properties` into a node.
+func scanNode(row scanner) (models.Node, error) {
+	n := models.Node{}
+
+	var createdAt int64
+	var updatedAt int64
+
+	var props []byte
+	if err := row.Scan(&n.ID, &createdAt, &updatedAt, &n.Version, &n.Label, &props); err != nil {
+		return n, err
+	}
+
+	if err := n.Properties.FromBytes(props); err != nil {
+		return n, err
+	}
+
+	n.CreatedAt = time.Unix(createdAt, 0)
+	n.UpdatedAt = time.Unix(updatedAt, 0)
+
+	return n, nil
+}
+
+// scanEdge scans a row selected as `id, created_at, updated_at, version, from_id, label, to_id, weight, properties` into an edge.
+func scanEdge(row scanner) (models.Edge, error) {
+	e := models.Edge{}
+
+	var createdAt int64
+	var updatedAt int64
+
+	var props []byte
+	if err := row.Scan(&e.ID, &createdAt, &updatedAt, &e.Version, &e.From, &e.Label, &e.To, &e.Weight, &props); err != nil {
+		return e, err
+	}
+
+	if err := e.Properties.FromBytes(props); err != nil {
+		return e, err
+	}
+
+	e.CreatedAt = time.Unix(createdAt, 0)
+	e.UpdatedAt = time.Unix(updatedAt, 0)
+
+	return e, nil
+}
+
+// collectNodes scans all the rows into nodes, closing the rows once done.
+func collectNodes(rows *sql.Rows) ([]models.Node, error) {
+	defer rows.Close()
+
+	nodes := []models.Node{}
+
+	for rows.Next() {
+		n, err := scanNode(rows)
+		if err != nil {
+			return nodes, err
+		}
+
+		nodes = append(nodes, n)
+	}
+
+	return nodes, rows.Err()
+}
+
+// collectEdges scans all the rows into edges, closing the rows once done.
+func collectEdges(rows *sql.Rows) ([]models.Edge, error) {
+	defer rows.Close()
+
+	edges := []models.Edge{}
+
+	for rows.Next() {
+		e, err := scanEdge(rows)
+		if err != nil {
+			return edges, err
+		}
+
+		edges = append(edges, e)
+	}
+
+	return edges, rows.Err()
+}
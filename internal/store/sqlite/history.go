@@ -0,0 +1,328 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// imageQuery selects the image of each candidate item `s.id` in the subquery as it was at the time bound to `?1`.
+//
+// An item is as it was after its last change made by the time, or before its first change made after the time.
+// Items without any changes, written before the changelog was added, are used as they are. The changes of each item
+// are looked up using the idx_changes_item index, so only the changes of the candidates are read.
+const imageQuery = `
+	SELECT
+		CASE
+			WHEN EXISTS (SELECT 1 FROM main.changes c WHERE c.item_id = s.id AND c.created_at <= ?1) THEN (
+				SELECT c.after FROM main.changes c
+				WHERE c.item_id = s.id AND c.created_at <= ?1
+				ORDER BY c.seq DESC
+				LIMIT 1
+			)
+			WHEN EXISTS (SELECT 1 FROM main.changes c WHERE c.item_id = s.id) THEN (
+				SELECT c.before FROM main.changes c WHERE c.item_id = s.id ORDER BY c.seq LIMIT 1
+			)
+			ELSE (
+				SELECT json_object(
+					'id', i.id,
+					'created_at', i.created_at,
+					'updated_at', i.updated_at,
+					'from_id', i.from_id,
+					'label', i.label,
+					'to_id', i.to_id,
+					'weight', i.weight,
+					'properties', json(i.properties)
+				)
+				FROM main.items i
+				WHERE i.id = s.id AND i.deleted_at IS NULL
+			)
+		END AS image
+	FROM (%s) s
+`
+
+// candidatesQuery selects the IDs of the items which could have existed at the time bound to `?1`, the items created
+// by the time which are still stored and the items deleted after the time.
+const candidatesQuery = `
+	SELECT i.id FROM main.items i WHERE i.created_at <= ?1
+	UNION
+	SELECT c.item_id FROM main.changes c WHERE c.created_at > ?1 AND c.op = 'delete'
+`
+
+// snapshotQuery creates a temporary items table holding the candidate items in the subquery as they were at the time
+// bound to `?1`. Temporary tables shadow the tables with the same name, so the queries used by Nodes, Graph and
+// SubGraph read the snapshot instead. The changelog does not record the versions, so the items of a snapshot have no
+// version.
+const snapshotQuery = `
+	CREATE TEMP TABLE items AS
+	-- the images are materialized so each image is looked up once rather than once per column.
+	WITH images(image) AS MATERIALIZED (` + imageQuery + `)
+	SELECT
+		json_extract(image, '$.id') AS id,
+		json_extract(image, '$.created_at') AS created_at,
+		json_extract(image, '$.updated_at') AS updated_at,
+		0 AS version,
+		json_extract(image, '$.from_id') AS from_id,
+		json_extract(image, '$.label') AS label,
+		json_extract(image, '$.to_id') AS to_id,
+		json_extract(image, '$.weight') AS weight,
+		json_extract(image, '$.properties') AS properties,
+		NULL AS deleted_at,
+		NULL AS deleted_with
+	FROM images
+	WHERE image IS NOT NULL AND json_extract(image, '$.created_at') <= ?1;
+`
+
+// snapshotSearchQuery creates a temporary full text search table for the snapshot, shadowing the fts table.
+const snapshotSearchQuery = `
+	CREATE VIRTUAL TABLE temp.fts USING fts5(
+		id,
+		type,
+		from_id UNINDEXED,
+		label,
+		to_id UNINDEXED,
+		weight UNINDEXED,
+		prop_keys,
+		prop_values,
+		tokenize = 'porter ascii'
+	);
+
+	INSERT INTO temp.fts (rowid, id, type, from_id, label, to_id, weight, prop_keys, prop_values)
+	SELECT
+		i.id,
+		i.id,
+		CASE
+			WHEN i.from_id == 0 AND i.to_id == 0 THEN 'node'
+			WHEN i.from_id != 0 AND i.to_id != 0 THEN 'edge'
+		END,
+		i.from_id,
+		i.label,
+		i.to_id,
+		i.weight,
+		json_extract_keys(i.properties),
+		json_extract_values(i.properties)
+	FROM temp.items i;
+`
+
+// snapshot returns a transaction reading the items as they were at the time, limited to the candidates selecting the
+// IDs of the items which could be read. The candidates bind their arguments from `?2`. The full text search is rebuilt
+// for the snapshot if search is true. The transaction must be rolled back once done, which drops the snapshot.
+func (s *Store) snapshot(ctx context.Context, at time.Time, candidates string, args []any, search bool) (*sql.Tx, error) {
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(snapshotQuery, candidates)
+
+	if _, err := tx.ExecContext(ctx, query, append([]any{at.Unix()}, args...)...); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if search {
+		if _, err := tx.ExecContext(ctx, snapshotSearchQuery); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	return tx, nil
+}
+
+// NodeAsOf returns the node as it was at the time, returning store.ErrNotFound if the node did not exist at the time.
+func (s *Store) NodeAsOf(ctx context.Context, id uint64, at time.Time) (models.Node, error) {
+	var data []byte
+
+	query := fmt.Sprintf(imageQuery, `SELECT ?2 AS id`)
+	if err := s.db.QueryRowContext(ctx, query, at.Unix(), id).Scan(&data); err != nil {
+		return models.Node{}, err
+	}
+
+	img, err := readImage(data)
+	if err != nil {
+		return models.Node{}, err
+	}
+
+	if img == nil || img.CreatedAt > at.Unix() || img.From != 0 || img.To != 0 {
+		return models.Node{}, fmt.Errorf("%w: node %d", store.ErrNotFound, id)
+	}
+
+	return img.node(), nil
+}
+
+// NodesAsOf applies the search for all the nodes as they were at the time.
+func (s *Store) NodesAsOf(ctx context.Context, args store.NodesArgs, at time.Time) ([]models.Node, error) {
+	// the filter applies to the nodes as they were, so only the pagination and IDs limit the candidates.
+	candidates := `SELECT id FROM (` + candidatesQuery + `) WHERE id > ?2`
+	candidateArgs := []any{args.LastID}
+
+	if len(args.IDs) > 0 {
+		marks := make([]string, len(args.IDs))
+		for i, id := range args.IDs {
+			marks[i] = fmt.Sprintf("?%d", i+3)
+			candidateArgs = append(candidateArgs, id)
+		}
+		candidates += ` AND id IN (` + strings.Join(marks, ",") + `)`
+	}
+
+	tx, err := s.snapshot(ctx, at, candidates, candidateArgs, false)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+	return nodes(ctx, tx, args)
+}
+
+// GraphAsOf applies the search term to the graph as it was at the time.
+func (s *Store) GraphAsOf(ctx context.Context, args store.TermSearchArgs, at time.Time) (models.Graph, error) {
+	tx, err := s.snapshot(ctx, at, candidatesQuery, nil, true)
+	if err != nil {
+		return models.Graph{}, err
+	}
+
+	defer tx.Rollback()
+	return graph(ctx, tx, args)
+}
+
+// SubGraphAsOf returns the sub-graph as it was at the time.
+func (s *Store) SubGraphAsOf(ctx context.Context, args store.SubGraphArgs, at time.Time) (models.Graph, error) {
+	tx, err := s.snapshot(ctx, at, candidatesQuery, nil, false)
+	if err != nil {
+		return models.Graph{}, err
+	}
+
+	defer tx.Rollback()
+	return subGraph(ctx, tx, args)
+}
+
+// history returns the revisions of the item oldest first, revise sets the item and diff of each revision from the
+// images before and after the change.
+func (s *Store) history(ctx context.Context, id uint64, kind models.SchemaKind, revise func(*models.Revision, *image, *image)) ([]models.Revision, error) {
+	query := `
+	SELECT c.seq, c.created_at, c.op, c.before, c.after
+	FROM changes c
+	WHERE c.item_id = ? AND c.kind = ?
+	ORDER BY c.seq;
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, id, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	revisions := []models.Revision{}
+
+	for rows.Next() {
+		rev := models.Revision{Version: len(revisions) + 1}
+
+		var createdAt int64
+		var before, after []byte
+
+		if err := rows.Scan(&rev.Seq, &createdAt, &rev.Op, &before, &after); err != nil {
+			return revisions, err
+		}
+
+		b, err := readImage(before)
+		if err != nil {
+			return revisions, err
+		}
+
+		a, err := readImage(after)
+		if err != nil {
+			return revisions, err
+		}
+
+		rev.At = time.Unix(createdAt, 0)
+		revise(&rev, b, a)
+
+		revisions = append(revisions, rev)
+	}
+
+	return revisions, rows.Err()
+}
+
+// NodeHistory returns the revisions of the node oldest first with the property changes of each revision,
+// returning store.ErrNotFound if the node has no history.
+//
+// Nodes written before the changelog was added and not changed since have a single revision of the current node.
+func (s *Store) NodeHistory(ctx context.Context, id uint64) ([]models.Revision, error) {
+	revisions, err := s.history(ctx, id, models.SchemaNode, func(rev *models.Revision, before, after *image) {
+		var b, a *models.Node
+
+		if before != nil {
+			n := before.node()
+			b = &n
+		}
+
+		if after != nil {
+			n := after.node()
+			a = &n
+		}
+
+		rev.Node = a
+		rev.Diff = store.DiffNodes(b, a)
+	})
+
+	if err != nil || len(revisions) > 0 {
+		return revisions, err
+	}
+
+	n, err := node(ctx, s.db, id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, fmt.Errorf("%w: no history for node %d", store.ErrNotFound, id)
+		}
+		return nil, err
+	}
+
+	rev := models.Revision{Version: 1, Op: models.ChangeCreate, At: n.CreatedAt, Node: &n, Diff: store.DiffNodes(nil, &n)}
+	return []models.Revision{rev}, nil
+}
+
+// EdgeHistory returns the revisions of the edge oldest first with the property changes of each revision,
+// returning store.ErrNotFound if the edge has no history.
+//
+// Edges written before the changelog was added and not changed since have a single revision of the current edge.
+func (s *Store) EdgeHistory(ctx context.Context, id uint64) ([]models.Revision, error) {
+	revisions, err := s.history(ctx, id, models.SchemaEdge, func(rev *models.Revision, before, after *image) {
+		var b, a *models.Edge
+
+		if before != nil {
+			e := before.edge()
+			b = &e
+		}
+
+		if after != nil {
+			e := after.edge()
+			a = &e
+		}
+
+		rev.Edge = a
+		rev.Diff = store.DiffEdges(b, a)
+	})
+
+	if err != nil || len(revisions) > 0 {
+		return revisions, err
+	}
+
+	e, err := edge(ctx, s.db, id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, fmt.Errorf("%w: no history for edge %d", store.ErrNotFound, id)
+		}
+		return nil, err
+	}
+
+	rev := models.Revision{Version: 1, Op: models.ChangeCreate, At: e.CreatedAt, Edge: &e, Diff: store.DiffEdges(nil, &e)}
+	return []models.Revision{rev}, nil
+}
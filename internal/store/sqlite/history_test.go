@@ -0,0 +1,355 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/models"
+)
+
+// backdate moves the changes made so far back in time, the items are created at 1000 with the first three changes,
+// the fourth change is made at 2000 and the rest at 3000.
+func backdate(t *testing.T, s *sqlite.Store) {
+	t.Helper()
+
+	tx, err := s.Tx(t.Context())
+	if err != nil {
+		t.Fatalf("Tx() failed: %v", err)
+	}
+
+	defer tx.Rollback()
+
+	query := `
+	UPDATE changes SET
+		created_at = CASE WHEN seq <= 3 THEN 1000 WHEN seq = 4 THEN 2000 ELSE 3000 END,
+		before = CASE WHEN before IS NULL THEN NULL ELSE json_set(before, '$.created_at', 1000) END,
+		after = CASE WHEN after IS NULL THEN NULL ELSE json_set(after, '$.created_at', 1000) END;
+	`
+
+	if _, err := tx.ExecContext(t.Context(), query); err != nil {
+		t.Fatalf("backdating the changes failed: %v", err)
+	}
+
+	if _, err := tx.ExecContext(t.Context(), `UPDATE items SET created_at = 1000;`); err != nil {
+		t.Fatalf("backdating the items failed: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+}
+
+// graphIDs returns the node and edge IDs of the graph.
+func graphIDs(g models.Graph) ([]uint64, []uint64) {
+	edges := []uint64{}
+	for _, e := range g.Edges {
+		edges = append(edges, e.ID)
+	}
+
+	return nodeIDs(g.Nodes), edges
+}
+
+func TestStore_AsOf(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := sqlite.New(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	defer s.Close()
+
+	preload(t, s,
+		models.Node{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
+		models.Node{ID: 2, Label: "person", Properties: models.Properties{"name": "bar"}},
+	)
+
+	preloadEdges(t, s, models.Edge{ID: 3, From: 1, Label: "knows", To: 2, Properties: models.Properties{}})
+	preload(t, s, models.Node{ID: 1, Label: "person", Properties: models.Properties{"name": "foo", "age": float64(30)}})
+
+	if _, err := s.DeleteNodes(ctx, store.DeleteNodesArgs{IDs: []uint64{2}, Policy: store.DeleteCascade}); err != nil {
+		t.Fatalf("DeleteNodes() failed: %v", err)
+	}
+
+	backdate(t, s)
+
+	tests := []struct {
+		name      string
+		at        int64
+		wantNode  models.Properties
+		wantErr   bool
+		wantNodes []uint64
+		wantEdges []uint64
+		wantTerm  []uint64
+	}{
+		{
+			name:      "before the nodes were created",
+			at:        500,
+			wantErr:   true,
+			wantNodes: []uint64{},
+			wantEdges: []uint64{},
+			wantTerm:  []uint64{},
+		},
+		{
+			name:      "after the nodes were created",
+			at:        1500,
+			wantNode:  models.Properties{"name": "foo"},
+			wantNodes: []uint64{1, 2},
+			wantEdges: []uint64{3},
+			wantTerm:  []uint64{2},
+		},
+		{
+			name:      "after the node was updated",
+			at:        2500,
+			wantNode:  models.Properties{"name": "foo", "age": float64(30)},
+			wantNodes: []uint64{1, 2},
+			wantEdges: []uint64{3},
+			wantTerm:  []uint64{2},
+		},
+		{
+			name:      "after the node was deleted",
+			at:        3500,
+			wantNode:  models.Properties{"name": "foo", "age": float64(30)},
+			wantNodes: []uint64{1},
+			wantEdges: []uint64{},
+			wantTerm:  []uint64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := time.Unix(tt.at, 0)
+
+			n, err := s.NodeAsOf(ctx, 1, at)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NodeAsOf() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr && !errors.Is(err, store.ErrNotFound) {
+				t.Errorf("NodeAsOf() error = %v, want store.ErrNotFound", err)
+			}
+
+			if diff := cmp.Diff(tt.wantNode, n.Properties); diff != "" {
+				t.Errorf("NodeAsOf() = mismatch (-want, +got): \n%s", diff)
+			}
+
+			nodes, err := s.NodesAsOf(ctx, store.NodesArgs{}, at)
+			if err != nil {
+				t.Fatalf("NodesAsOf() failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.wantNodes, nodeIDs(nodes)); diff != "" {
+				t.Errorf("NodesAsOf() = mismatch (-want, +got): \n%s", diff)
+			}
+
+			sub, err := s.SubGraphAsOf(ctx, store.SubGraphArgs{FromNodeID: 1}, at)
+			if err != nil && !errors.Is(err, store.ErrNotFound) {
+				t.Fatalf("SubGraphAsOf() failed: %v", err)
+			}
+
+			_, edges := graphIDs(sub)
+			if diff := cmp.Diff(tt.wantEdges, edges); diff != "" {
+				t.Errorf("SubGraphAsOf() edges = mismatch (-want, +got): \n%s", diff)
+			}
+
+			g, err := s.GraphAsOf(ctx, store.TermSearchArgs{Term: "bar"}, at)
+			if err != nil {
+				t.Fatalf("GraphAsOf() failed: %v", err)
+			}
+
+			term, _ := graphIDs(g)
+			if diff := cmp.Diff(tt.wantTerm, term); diff != "" {
+				t.Errorf("GraphAsOf() nodes = mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+
+	// the IDs and pagination limit the nodes read into the snapshot.
+	for _, args := range []store.NodesArgs{{IDs: []uint64{2}}, {LastID: 1}} {
+		nodes, err := s.NodesAsOf(ctx, args, time.Unix(1500, 0))
+		if err != nil {
+			t.Fatalf("NodesAsOf() failed: %v", err)
+		}
+
+		if diff := cmp.Diff([]uint64{2}, nodeIDs(nodes)); diff != "" {
+			t.Errorf("NodesAsOf(%+v) = mismatch (-want, +got): \n%s", args, diff)
+		}
+	}
+
+	// the snapshots are dropped once read, leaving the current graph as it is.
+	nodes, err := s.Nodes(ctx, store.NodesArgs{})
+	if err != nil {
+		t.Fatalf("Nodes() failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]uint64{1}, nodeIDs(nodes)); diff != "" {
+		t.Errorf("Nodes() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	if ids := termIDs(t, s, "bar"); len(ids) != 0 {
+		t.Errorf("Graph() = %v, want no matches for a deleted node", ids)
+	}
+}
+
+func TestStore_History(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := sqlite.New(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	defer s.Close()
+
+	preload(t, s,
+		models.Node{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
+		models.Node{ID: 2, Label: "person", Properties: models.Properties{"name": "bar"}},
+	)
+
+	preloadEdges(t, s, models.Edge{ID: 3, From: 1, Label: "knows", To: 2, Properties: models.Properties{}})
+	preload(t, s, models.Node{ID: 1, Label: "human", Properties: models.Properties{"name": "foo", "age": float64(30)}})
+
+	if _, err := s.DeleteNodes(ctx, store.DeleteNodesArgs{IDs: []uint64{2}, Policy: store.DeleteCascade}); err != nil {
+		t.Fatalf("DeleteNodes() failed: %v", err)
+	}
+
+	backdate(t, s)
+
+	// revision is the part of a revision compared by the tests.
+	type revision struct {
+		Version int
+		Seq     uint64
+		Op      models.ChangeOp
+		At      int64
+		Label   string
+		Diff    []models.PropertyDiff
+	}
+
+	summary := func(revisions []models.Revision) []revision {
+		got := make([]revision, len(revisions))
+		for i, r := range revisions {
+			got[i] = revision{Version: r.Version, Seq: r.Seq, Op: r.Op, At: r.At.Unix(), Diff: r.Diff}
+
+			switch {
+			case r.Node != nil:
+				got[i].Label = r.Node.Label
+			case r.Edge != nil:
+				got[i].Label = r.Edge.Label
+			}
+		}
+		return got
+	}
+
+	tests := []struct {
+		name    string
+		history func(context.Context, uint64) ([]models.Revision, error)
+		id      uint64
+		want    []revision
+		wantErr bool
+	}{
+		{
+			name:    "updated node",
+			history: s.NodeHistory,
+			id:      1,
+			want: []revision{
+				{
+					Version: 1, Seq: 1, Op: models.ChangeCreate, At: 1000, Label: "person",
+					Diff: []models.PropertyDiff{
+						{Path: "label", Op: models.DiffAdded, After: "person"},
+						{Path: "properties.name", Op: models.DiffAdded, After: "foo"},
+					},
+				},
+				{
+					Version: 2, Seq: 4, Op: models.ChangeUpdate, At: 2000, Label: "human",
+					Diff: []models.PropertyDiff{
+						{Path: "label", Op: models.DiffChanged, Before: "person", After: "human"},
+						{Path: "properties.age", Op: models.DiffAdded, After: float64(30)},
+					},
+				},
+			},
+		},
+		{
+			name:    "deleted node",
+			history: s.NodeHistory,
+			id:      2,
+			want: []revision{
+				{
+					Version: 1, Seq: 2, Op: models.ChangeCreate, At: 1000, Label: "person",
+					Diff: []models.PropertyDiff{
+						{Path: "label", Op: models.DiffAdded, After: "person"},
+						{Path: "properties.name", Op: models.DiffAdded, After: "bar"},
+					},
+				},
+				{
+					Version: 2, Seq: 6, Op: models.ChangeDelete, At: 3000,
+					Diff: []models.PropertyDiff{
+						{Path: "label", Op: models.DiffRemoved, Before: "person"},
+						{Path: "properties.name", Op: models.DiffRemoved, Before: "bar"},
+					},
+				},
+			},
+		},
+		{
+			name:    "deleted edge",
+			history: s.EdgeHistory,
+			id:      3,
+			want: []revision{
+				{
+					Version: 1, Seq: 3, Op: models.ChangeCreate, At: 1000, Label: "knows",
+					Diff: []models.PropertyDiff{
+						{Path: "from_id", Op: models.DiffAdded, After: uint64(1)},
+						{Path: "label", Op: models.DiffAdded, After: "knows"},
+						{Path: "to_id", Op: models.DiffAdded, After: uint64(2)},
+						{Path: "weight", Op: models.DiffAdded, After: 0},
+					},
+				},
+				{
+					Version: 2, Seq: 5, Op: models.ChangeDelete, At: 3000,
+					Diff: []models.PropertyDiff{
+						{Path: "from_id", Op: models.DiffRemoved, Before: uint64(1)},
+						{Path: "label", Op: models.DiffRemoved, Before: "knows"},
+						{Path: "to_id", Op: models.DiffRemoved, Before: uint64(2)},
+						{Path: "weight", Op: models.DiffRemoved, Before: 0},
+					},
+				},
+			},
+		},
+		{
+			name:    "node is not an edge",
+			history: s.EdgeHistory,
+			id:      1,
+			wantErr: true,
+		},
+		{
+			name:    "missing node",
+			history: s.NodeHistory,
+			id:      99,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.history(ctx, tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("history error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				if !errors.Is(err, store.ErrNotFound) {
+					t.Errorf("history error = %v, want store.ErrNotFound", err)
+				}
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, summary(got)); diff != "" {
+				t.Errorf("history = mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
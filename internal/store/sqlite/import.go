@@ -0,0 +1,249 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"iter"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// nullableID returns nil for a zero ID so the database assigns a new ID.
+func nullableID(id uint64) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// Import writes the items in chunked transactions. The upsert statements are prepared once and
+// bound to each chunk transaction. Items which fail are reported to args.OnError and skipped,
+// the rest of the chunk is still committed.
+func (s *Store) Import(ctx context.Context, items iter.Seq[store.ImportItem], args store.ImportArgs) (store.ImportProgress, error) {
+	if args.ChunkSize <= 0 {
+		args.ChunkSize = store.DefaultImportChunkSize
+	}
+
+	if args.OnError == nil {
+		args.OnError = func(int, error) {}
+	}
+
+	if args.OnProgress == nil {
+		args.OnProgress = func(store.ImportProgress) {}
+	}
+
+	progress := store.ImportProgress{}
+
+	nodeStmt, err := s.db.PrepareContext(ctx, upsertNodeQuery)
+	if err != nil {
+		return progress, err
+	}
+
+	defer nodeStmt.Close()
+
+	edgeStmt, err := s.db.PrepareContext(ctx, upsertEdgeQuery)
+	if err != nil {
+		return progress, err
+	}
+
+	defer edgeStmt.Close()
+
+	refs := map[string]uint64{}
+	chunk := make([]store.ImportItem, 0, args.ChunkSize)
+
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+
+		tx, err := s.Tx(ctx)
+		if err != nil {
+			return err
+		}
+
+		defer tx.Rollback()
+
+		nodes := tx.StmtContext(ctx, nodeStmt)
+		edges := tx.StmtContext(ctx, edgeStmt)
+
+		schemas, err := schemaSet(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		labels := nodeLabel(ctx, tx)
+
+		// refs are only kept once the chunk is committed.
+		pending := map[string]uint64{}
+		lookup := func(ref string) (uint64, bool) {
+			if id, ok := pending[ref]; ok {
+				return id, true
+			}
+			id, ok := refs[ref]
+			return id, ok
+		}
+
+		resolve := func(id uint64, ref string) (uint64, error) {
+			if ref == "" {
+				return id, nil
+			}
+
+			refID, ok := lookup(ref)
+			if !ok {
+				return 0, fmt.Errorf("%w: unknown node ref %q", store.ErrInvalid, ref)
+			}
+			return refID, nil
+		}
+
+		imported := store.ImportProgress{}
+		failed := []store.ImportItem{}
+
+		fail := func(item store.ImportItem, err error) {
+			item.Err = err
+			failed = append(failed, item)
+		}
+
+		for _, item := range chunk {
+			switch {
+			case item.Err != nil:
+				fail(item, item.Err)
+
+			case item.Node != nil:
+				n := item.Node
+				if _, ok := lookup(n.Ref); ok && n.Ref != "" {
+					fail(item, fmt.Errorf("%w: duplicate node ref %q", store.ErrInvalid, n.Ref))
+					continue
+				}
+
+				if n.Version > 0 {
+					if err := checkNodeVersion(ctx, tx, n.ID, n.Version); err != nil {
+						fail(item, err)
+						continue
+					}
+				}
+
+				if err := schemas.CheckNode(*n); err != nil {
+					fail(item, err)
+					continue
+				}
+
+				node, err := importNode(ctx, nodes, n)
+				if err != nil {
+					fail(item, err)
+					continue
+				}
+
+				if n.Ref != "" {
+					pending[n.Ref] = node.ID
+				}
+				imported.Nodes++
+
+			case item.Edge != nil:
+				e := *item.Edge
+
+				from, err := resolve(e.From, e.FromRef)
+				if err != nil {
+					fail(item, err)
+					continue
+				}
+
+				to, err := resolve(e.To, e.ToRef)
+				if err != nil {
+					fail(item, err)
+					continue
+				}
+
+				e.From, e.To = from, to
+
+				if e.Version > 0 {
+					if err := checkEdgeVersion(ctx, tx, e.ID, e.Version); err != nil {
+						fail(item, err)
+						continue
+					}
+				}
+
+				if err := schemas.CheckEdge(e, labels); err != nil {
+					fail(item, err)
+					continue
+				}
+
+				if err := importEdge(ctx, edges, &e); err != nil {
+					fail(item, err)
+					continue
+				}
+				imported.Edges++
+
+			default:
+				fail(item, fmt.Errorf("%w: line is neither a node nor an edge", store.ErrInvalid))
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+
+		for ref, id := range pending {
+			refs[ref] = id
+		}
+
+		for _, item := range failed {
+			args.OnError(item.Line, item.Err)
+		}
+
+		progress.Line = chunk[len(chunk)-1].Line
+		progress.Nodes += imported.Nodes
+		progress.Edges += imported.Edges
+		progress.Errors += len(failed)
+		args.OnProgress(progress)
+
+		chunk = chunk[:0]
+		return nil
+	}
+
+	for item := range items {
+		if err := ctx.Err(); err != nil {
+			return progress, err
+		}
+
+		chunk = append(chunk, item)
+		if len(chunk) == args.ChunkSize {
+			if err := flush(); err != nil {
+				return progress, err
+			}
+		}
+	}
+
+	return progress, flush()
+}
+
+// importNode upserts the node using the transaction bound statement.
+func importNode(ctx context.Context, stmt *sql.Stmt, n *models.Node) (models.Node, error) {
+	props, err := n.Properties.ToBytes()
+	if err != nil {
+		return models.Node{}, err
+	}
+
+	node, err := scanNode(stmt.QueryRowContext(ctx, nullableID(n.ID), n.Label, props))
+	if isUniqueViolation(err) {
+		return node, store.DuplicateKeyError(n.Label)
+	}
+
+	return node, err
+}
+
+// importEdge upserts the edge using the transaction bound statement.
+func importEdge(ctx context.Context, stmt *sql.Stmt, e *models.Edge) error {
+	if e.From == 0 || e.To == 0 {
+		return fmt.Errorf("%w: edges need both a from and to node", store.ErrInvalid)
+	}
+
+	props, err := e.Properties.ToBytes()
+	if err != nil {
+		return err
+	}
+
+	_, err = scanEdge(stmt.QueryRowContext(ctx, nullableID(e.ID), e.From, e.Label, e.To, e.Weight, props))
+	return err
+}
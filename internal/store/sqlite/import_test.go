@@ -0,0 +1,85 @@
+package sqlite_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/models"
+)
+
+func TestStore_Import(t *testing.T) {
+	items := []store.ImportItem{
+		{Line: 1, Node: &models.Node{Ref: "foo", Label: "person", Properties: models.Properties{"name": "foo"}}},
+		{Line: 2, Node: &models.Node{Ref: "bar", Label: "person", Properties: models.Properties{"name": "bar"}}},
+		// the refs from the previous chunk are still known.
+		{Line: 3, Edge: &models.Edge{FromRef: "foo", Label: "knows", ToRef: "bar"}},
+		{Line: 4, Err: errors.New("bad json")},
+		{Line: 5, Edge: &models.Edge{FromRef: "foo", Label: "knows", ToRef: "nobody"}},
+		{Line: 6, Node: &models.Node{Ref: "foo", Label: "person"}},
+		{Line: 7, Node: &models.Node{Ref: "baz", Label: "person", Properties: models.Properties{"name": "baz"}}},
+		{Line: 8, Edge: &models.Edge{From: 2, Label: "knows", ToRef: "baz"}},
+		// versioned lines are only applied if they are still the current version.
+		{Line: 9, Node: &models.Node{ID: 1, Version: 1, Label: "person", Properties: models.Properties{"name": "foo"}}},
+		{Line: 10, Node: &models.Node{ID: 2, Version: 5, Label: "person", Properties: models.Properties{"name": "bar"}}},
+		{Line: 11, Edge: &models.Edge{ID: 3, Version: 7, From: 1, Label: "knows", To: 2}},
+		{Line: 12, Edge: &models.Edge{ID: 3, Version: 1, From: 1, Label: "knows", To: 2}},
+	}
+
+	ctx := t.Context()
+
+	s, err := sqlite.New(ctx, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errLines := []int{}
+	progress := []store.ImportProgress{}
+
+	got, err := s.Import(ctx, slices.Values(items), store.ImportArgs{
+		ChunkSize:  2,
+		OnError:    func(line int, err error) { errLines = append(errLines, line) },
+		OnProgress: func(p store.ImportProgress) { progress = append(progress, p) },
+	})
+	if err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+
+	want := store.ImportProgress{Line: 12, Nodes: 4, Edges: 3, Errors: 5}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Import() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	if diff := cmp.Diff([]int{4, 5, 6, 10, 11}, errLines); diff != "" {
+		t.Errorf("Import() error lines mismatch (-want, +got): \n%s", diff)
+	}
+
+	wantProgress := []store.ImportProgress{
+		{Line: 2, Nodes: 2},
+		{Line: 4, Nodes: 2, Edges: 1, Errors: 1},
+		{Line: 6, Nodes: 2, Edges: 1, Errors: 3},
+		{Line: 8, Nodes: 3, Edges: 2, Errors: 3},
+		{Line: 10, Nodes: 4, Edges: 2, Errors: 4},
+		{Line: 12, Nodes: 4, Edges: 3, Errors: 5},
+	}
+	if diff := cmp.Diff(wantProgress, progress); diff != "" {
+		t.Errorf("Import() progress mismatch (-want, +got): \n%s", diff)
+	}
+
+	edges, err := s.Edges(ctx, store.EdgesArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ends := [][2]uint64{}
+	for _, e := range edges {
+		ends = append(ends, [2]uint64{e.From, e.To})
+	}
+
+	if diff := cmp.Diff([][2]uint64{{1, 2}, {2, 4}}, ends); diff != "" {
+		t.Errorf("Import() edges mismatch (-want, +got): \n%s", diff)
+	}
+}
@@ -0,0 +1,226 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// propertyIndexName returns the name of the expression index with the ID.
+func propertyIndexName(id uint64) string {
+	return fmt.Sprintf("items_property_index_%d", id)
+}
+
+// propertyIndexColumns are the columns selected by scanPropertyIndex, the size is read from the dbstat virtual table.
+const propertyIndexColumns = `
+	p.id,
+	p.label,
+	p.path,
+	p.status,
+	p.error,
+	p.created_at,
+	COALESCE((SELECT SUM(pgsize) FROM dbstat WHERE name = 'items_property_index_' || p.id), 0)
+`
+
+// scanPropertyIndex scans a row selected using propertyIndexColumns into a property index.
+func scanPropertyIndex(row scanner) (models.PropertyIndex, error) {
+	idx := models.PropertyIndex{}
+
+	var createdAt int64
+
+	if err := row.Scan(&idx.ID, &idx.Label, &idx.Path, &idx.Status, &idx.Error, &createdAt, &idx.Size); err != nil {
+		return idx, err
+	}
+
+	idx.CreatedAt = time.Unix(createdAt, 0)
+	return idx, nil
+}
+
+// propertyIndex returns the property index with the ID.
+func propertyIndex(ctx context.Context, q querier, id uint64) (models.PropertyIndex, error) {
+	row := q.QueryRowContext(ctx, `SELECT `+propertyIndexColumns+` FROM property_indexes p WHERE p.id = ?;`, id)
+
+	idx, err := scanPropertyIndex(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.PropertyIndex{}, fmt.Errorf("%w: property index %d", store.ErrNotFound, id)
+		}
+		return models.PropertyIndex{}, err
+	}
+
+	return idx, nil
+}
+
+// Indexes returns all the property indexes ordered by ID.
+func (s *Store) Indexes(ctx context.Context) ([]models.PropertyIndex, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+propertyIndexColumns+` FROM property_indexes p ORDER BY p.id;`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	indexes := []models.PropertyIndex{}
+
+	for rows.Next() {
+		idx, err := scanPropertyIndex(rows)
+		if err != nil {
+			return indexes, err
+		}
+
+		indexes = append(indexes, idx)
+	}
+
+	return indexes, rows.Err()
+}
+
+// Index returns the property index with the ID, returning store.ErrNotFound if there is no such index.
+func (s *Store) Index(ctx context.Context, id uint64) (models.PropertyIndex, error) {
+	return propertyIndex(ctx, s.db, id)
+}
+
+// CreateIndex starts building the property index in the background returning the index while it is being built,
+// returning store.ErrInvalid if the index is not valid and store.ErrConflict if the label and path are already indexed.
+func (s *Store) CreateIndex(ctx context.Context, idx models.PropertyIndex) (models.PropertyIndex, error) {
+	if err := store.ValidateIndex(&idx); err != nil {
+		return models.PropertyIndex{}, err
+	}
+
+	var id uint64
+
+	err := s.db.QueryRowContext(ctx, `INSERT INTO property_indexes (label, path) VALUES (?, ?) RETURNING id;`, idx.Label, idx.Path).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.PropertyIndex{}, fmt.Errorf("%w: label %q and path %s are already indexed", store.ErrConflict, idx.Label, idx.Path)
+		}
+		return models.PropertyIndex{}, err
+	}
+
+	created, err := propertyIndex(ctx, s.db, id)
+	if err != nil {
+		return created, err
+	}
+
+	s.buildIndex(created)
+	return created, nil
+}
+
+// buildIndex builds the property index in the background recording if the build succeeded or failed.
+// The build is skipped if the index is dropped before the build starts.
+func (s *Store) buildIndex(idx models.PropertyIndex) {
+	s.builds.Add(1)
+
+	go func() {
+		defer s.builds.Done()
+
+		ctx := context.Background()
+		started := time.Now()
+
+		err := s.createPropertyIndex(ctx, idx)
+
+		status, reason := models.IndexReady, ""
+		if err != nil {
+			status, reason = models.IndexFailed, err.Error()
+			s.logger.Error("error building property index", slog.Uint64("id", idx.ID), slog.String("reason", reason))
+		}
+
+		if _, err := s.db.ExecContext(ctx, `UPDATE property_indexes SET status = ?, error = ? WHERE id = ?;`, status, reason, idx.ID); err != nil {
+			s.logger.Error("error updating property index status", slog.Uint64("id", idx.ID), slog.String("reason", err.Error()))
+			return
+		}
+
+		s.logger.Info(
+			"property index built",
+			slog.Uint64("id", idx.ID),
+			slog.String("label", idx.Label),
+			slog.String("path", idx.Path),
+			slog.String("status", string(status)),
+			slog.Duration("took", time.Since(started)),
+		)
+	}()
+}
+
+// createPropertyIndex creates the expression index, the index compares the same `json_extract` expression used by
+// filters and queries so sqlite uses it for them. Indexes scoped to a label are partial indexes.
+func (s *Store) createPropertyIndex(ctx context.Context, idx models.PropertyIndex) error {
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	// the index was dropped before the build started.
+	if _, err := propertyIndex(ctx, tx, idx.ID); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(
+		`CREATE INDEX IF NOT EXISTS %s ON items(json_extract(properties, %s))`,
+		propertyIndexName(idx.ID),
+		jsonPath(store.Predicate{Path: idx.Path}),
+	)
+
+	if idx.Label != "" {
+		query += " WHERE label = " + quote(idx.Label)
+	}
+
+	if _, err := tx.ExecContext(ctx, query+";"); err != nil {
+		return err
+	}
+
+	// refresh the planner statistics so the index is chosen over scanning the items.
+	if _, err := tx.ExecContext(ctx, `ANALYZE `+propertyIndexName(idx.ID)+`;`); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// resumeIndexes restarts the builds of the property indexes which were still being built when the store was closed.
+func (s *Store) resumeIndexes(ctx context.Context) error {
+	indexes, err := s.Indexes(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, idx := range indexes {
+		if idx.Status == models.IndexBuilding {
+			s.buildIndex(idx)
+		}
+	}
+
+	return nil
+}
+
+// DropIndex drops the property index with the ID returning the dropped index,
+// returning store.ErrNotFound if there is no such index.
+func (s *Store) DropIndex(ctx context.Context, id uint64) (models.PropertyIndex, error) {
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		return models.PropertyIndex{}, err
+	}
+
+	defer tx.Rollback()
+
+	idx, err := propertyIndex(ctx, tx, id)
+	if err != nil {
+		return idx, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM property_indexes WHERE id = ?;`, id); err != nil {
+		return idx, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DROP INDEX IF EXISTS `+propertyIndexName(id)+`;`); err != nil {
+		return idx, err
+	}
+
+	return idx, tx.Commit()
+}
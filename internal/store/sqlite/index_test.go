@@ -0,0 +1,203 @@
+package sqlite_test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/models"
+)
+
+// waitForIndex waits for the property index to finish building.
+func waitForIndex(t *testing.T, s *sqlite.Store, id uint64) models.PropertyIndex {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		idx, err := s.Index(t.Context(), id)
+		if err != nil {
+			t.Fatalf("Index() failed: %v", err)
+		}
+
+		if idx.Status != models.IndexBuilding {
+			return idx
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("index %d is still building", id)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// queryPlan returns the query plan details of the query.
+// A new connection is opened for each plan so the planner uses the statistics of the latest ANALYZE.
+func queryPlan(t *testing.T, dsn string, query string, args ...any) string {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatalf("opening the database failed: %v", err)
+	}
+
+	defer db.Close()
+
+	rows, err := db.QueryContext(t.Context(), "EXPLAIN QUERY PLAN "+query, args...)
+	if err != nil {
+		t.Fatalf("EXPLAIN QUERY PLAN failed: %v", err)
+	}
+
+	defer rows.Close()
+
+	details := []string{}
+
+	for rows.Next() {
+		var id, parent, unused int
+		var detail string
+
+		if err := rows.Scan(&id, &parent, &unused, &detail); err != nil {
+			t.Fatalf("scanning the query plan failed: %v", err)
+		}
+
+		details = append(details, detail)
+	}
+
+	return strings.Join(details, "\n")
+}
+
+func TestStore_PropertyIndexes(t *testing.T) {
+	ctx := t.Context()
+	dsn := filepath.Join(t.TempDir(), "edgedb.db")
+
+	s, err := sqlite.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	defer s.Close()
+
+	nodes := []models.Node{}
+	for i := range 200 {
+		nodes = append(nodes,
+			models.Node{Label: "person", Properties: models.Properties{"name": fmt.Sprintf("person-%d", i)}},
+			models.Node{Label: "dog", Properties: models.Properties{"name": fmt.Sprintf("dog-%d", i)}},
+		)
+	}
+
+	preload(t, s, nodes...)
+
+	created, err := s.CreateIndex(ctx, models.PropertyIndex{Label: "person", Path: "properties.name"})
+	if err != nil {
+		t.Fatalf("CreateIndex() failed: %v", err)
+	}
+
+	want := models.PropertyIndex{ID: 1, Label: "person", Path: "name", Status: models.IndexBuilding}
+	if diff := cmp.Diff(want, created, cmpopts.IgnoreFields(models.PropertyIndex{}, "CreatedAt")); diff != "" {
+		t.Errorf("CreateIndex() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	ready := waitForIndex(t, s, created.ID)
+	if ready.Status != models.IndexReady || ready.Size == 0 {
+		t.Errorf("Index() = %+v, want a ready index with a size", ready)
+	}
+
+	if _, err := s.CreateIndex(ctx, models.PropertyIndex{Label: "person", Path: "name"}); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("CreateIndex() error = %v, want store.ErrConflict", err)
+	}
+
+	if _, err := s.CreateIndex(ctx, models.PropertyIndex{Path: "name'"}); !errors.Is(err, store.ErrInvalid) {
+		t.Errorf("CreateIndex() error = %v, want store.ErrInvalid", err)
+	}
+
+	got, err := s.Nodes(ctx, store.NodesArgs{
+		Filter: store.Filter{
+			Labels: []string{"person"},
+			Where:  []store.Predicate{{Path: "name", Op: store.OpEq, Value: "person-42"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Nodes() failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]uint64{85}, nodeIDs(got)); diff != "" {
+		t.Errorf("Nodes() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	// the query used by Nodes for a label and property filter.
+	query, args, err := sqlite.NodesQuery(store.NodesArgs{
+		Filter: store.Filter{
+			Labels: []string{"person"},
+			Where:  []store.Predicate{{Path: "name", Op: store.OpEq, Value: "person-42"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NodesQuery() failed: %v", err)
+	}
+
+	if plan := queryPlan(t, dsn, query, args...); !strings.Contains(plan, "items_property_index_1") {
+		t.Errorf("query plan = %q, want the property index to be used", plan)
+	}
+
+	// several labels only use the property indexes when each of the labels has an index on the path.
+	query, args, err = sqlite.NodesQuery(store.NodesArgs{
+		Filter: store.Filter{
+			Labels: []string{"person", "dog"},
+			Where:  []store.Predicate{{Path: "name", Op: store.OpEq, Value: "dog-42"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NodesQuery() failed: %v", err)
+	}
+
+	if plan := queryPlan(t, dsn, query, args...); strings.Contains(plan, "items_property_index_1") {
+		t.Errorf("query plan = %q, want no property index without an index for each label", plan)
+	}
+
+	dogs, err := s.CreateIndex(ctx, models.PropertyIndex{Label: "dog", Path: "name"})
+	if err != nil {
+		t.Fatalf("CreateIndex() failed: %v", err)
+	}
+
+	waitForIndex(t, s, dogs.ID)
+
+	plan := queryPlan(t, dsn, query, args...)
+	if !strings.Contains(plan, "items_property_index_1") || !strings.Contains(plan, "items_property_index_2") {
+		t.Errorf("query plan = %q, want the property indexes of both labels to be used", plan)
+	}
+
+	if _, err := s.DropIndex(ctx, dogs.ID); err != nil {
+		t.Fatalf("DropIndex() failed: %v", err)
+	}
+
+	dropped, err := s.DropIndex(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("DropIndex() failed: %v", err)
+	}
+
+	if dropped.ID != created.ID {
+		t.Errorf("DropIndex() = %+v, want index %d", dropped, created.ID)
+	}
+
+	if _, err := s.Index(ctx, created.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Index() error = %v, want store.ErrNotFound", err)
+	}
+
+	indexes, err := s.Indexes(ctx)
+	if err != nil {
+		t.Fatalf("Indexes() failed: %v", err)
+	}
+
+	if len(indexes) != 0 {
+		t.Errorf("Indexes() = %+v, want no indexes", indexes)
+	}
+}
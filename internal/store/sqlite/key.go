@@ -0,0 +1,330 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// keyIndex returns the name of the unique index enforcing the natural key with the ID.
+func keyIndex(id int64) string {
+	return fmt.Sprintf("items_natural_key_%d", id)
+}
+
+// quote returns the text as a SQL string literal.
+func quote(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", "''") + "'"
+}
+
+// keyCondition returns the SQL condition matching the nodes with the key value bound to a `?` placeholder.
+// The label is inlined so sqlite can use the partial unique index enforcing the key.
+func keyCondition(k models.NaturalKey) string {
+	return fmt.Sprintf(
+		"label = %s AND from_id = 0 AND to_id = 0 AND json_extract(properties, %s) = json_extract(?, '$')",
+		quote(k.Label),
+		jsonPath(store.KeyPredicate(k, nil)),
+	)
+}
+
+// scanKey scans a row selected as `id, label, property, created_at` into a natural key.
+func scanKey(row scanner) (int64, models.NaturalKey, error) {
+	k := models.NaturalKey{}
+
+	var id int64
+	var createdAt int64
+
+	if err := row.Scan(&id, &k.Label, &k.Property, &createdAt); err != nil {
+		return id, k, err
+	}
+
+	k.CreatedAt = time.Unix(createdAt, 0)
+	return id, k, nil
+}
+
+// naturalKeys is a helper returning the natural keys keyed by label.
+func naturalKeys(ctx context.Context, q querier) (map[string]models.NaturalKey, error) {
+	rows, err := q.QueryContext(ctx, `SELECT id, label, property, created_at FROM natural_keys;`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	keys := map[string]models.NaturalKey{}
+
+	for rows.Next() {
+		_, k, err := scanKey(rows)
+		if err != nil {
+			return keys, err
+		}
+
+		keys[k.Label] = k
+	}
+
+	return keys, rows.Err()
+}
+
+// keyOwner returns the ID of the node with the natural key value, trashed nodes keep their key so merging restores them.
+func keyOwner(ctx context.Context, q querier, k models.NaturalKey, value any) (uint64, bool, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var id uint64
+
+	err = q.QueryRowContext(ctx, `SELECT id FROM items WHERE `+keyCondition(k)+`;`, string(encoded)).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+
+	return id, err == nil, err
+}
+
+// edgeOwner returns the lowest ID of the edges with the same from ID, label and to ID, trashed edges are only matched
+// if there is no other edge.
+func edgeOwner(ctx context.Context, q querier, e models.Edge) (uint64, bool, error) {
+	if e.From == 0 || e.To == 0 {
+		return 0, false, nil
+	}
+
+	var id uint64
+
+	err := q.QueryRowContext(
+		ctx,
+		`SELECT id FROM items WHERE from_id = ? AND label = ? AND to_id = ? ORDER BY deleted_at IS NOT NULL, id LIMIT 1;`,
+		e.From,
+		e.Label,
+		e.To,
+	).Scan(&id)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+
+	return id, err == nil, err
+}
+
+// mergeNodes inserts or updates the nodes in the transaction matching existing nodes on their natural key.
+func mergeNodes(ctx context.Context, tx *sql.Tx, n ...models.Node) ([]store.MergedNode, error) {
+	keys, err := naturalKeys(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make([]store.MergedNode, len(n))
+
+	for i, n := range n {
+		k, ok := keys[n.Label]
+		if !ok {
+			return merged, store.NoKeyError(n.Label)
+		}
+
+		value, err := store.KeyValue(k, n)
+		if err != nil {
+			return merged, err
+		}
+
+		id, found, err := keyOwner(ctx, tx, k, value)
+		if err != nil {
+			return merged, err
+		}
+
+		n.ID = id
+
+		nodes, err := upsertNodes(ctx, tx, n)
+		if err != nil {
+			return merged, err
+		}
+
+		merged[i] = store.MergedNode{Node: nodes[0], Created: !found}
+	}
+
+	return merged, nil
+}
+
+// mergeEdges inserts or updates the edges in the transaction matching existing edges on their from ID, label and to ID.
+func mergeEdges(ctx context.Context, tx *sql.Tx, e ...models.Edge) ([]store.MergedEdge, error) {
+	merged := make([]store.MergedEdge, len(e))
+
+	for i, e := range e {
+		id, found, err := edgeOwner(ctx, tx, e)
+		if err != nil {
+			return merged, err
+		}
+
+		e.ID = id
+
+		edges, err := upsertEdges(ctx, tx, e)
+		if err != nil {
+			return merged, err
+		}
+
+		merged[i] = store.MergedEdge{Edge: edges[0], Created: !found}
+	}
+
+	return merged, nil
+}
+
+// MergeNodes inserts or updates one or more nodes matching existing nodes on the natural key of their label instead
+// of their ID, either all the nodes are written or none are.
+func (s *Store) MergeNodes(ctx context.Context, n ...models.Node) ([]store.MergedNode, error) {
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	nodes, err := mergeNodes(ctx, tx, n...)
+	if err != nil {
+		return nodes, err
+	}
+
+	return nodes, tx.Commit()
+}
+
+// MergeEdges inserts or updates one or more edges matching existing edges on their from ID, label and to ID instead
+// of their ID, either all the edges are written or none are.
+func (s *Store) MergeEdges(ctx context.Context, e ...models.Edge) ([]store.MergedEdge, error) {
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	edges, err := mergeEdges(ctx, tx, e...)
+	if err != nil {
+		return edges, err
+	}
+
+	return edges, tx.Commit()
+}
+
+// Keys returns all the natural keys ordered by label.
+func (s *Store) Keys(ctx context.Context) ([]models.NaturalKey, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, label, property, created_at FROM natural_keys ORDER BY label;`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	keys := []models.NaturalKey{}
+
+	for rows.Next() {
+		_, k, err := scanKey(rows)
+		if err != nil {
+			return keys, err
+		}
+
+		keys = append(keys, k)
+	}
+
+	return keys, rows.Err()
+}
+
+// Key returns the natural key for the label, returning store.ErrNotFound if there is no such key.
+func (s *Store) Key(ctx context.Context, label string) (models.NaturalKey, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT id, label, property, created_at FROM natural_keys WHERE label = ?;`, label)
+
+	_, k, err := scanKey(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.NaturalKey{}, fmt.Errorf("%w: natural key for label %q", store.ErrNotFound, label)
+		}
+		return models.NaturalKey{}, err
+	}
+
+	return k, nil
+}
+
+// UpsertKey inserts or replaces the natural key for its label, returning store.ErrInvalid if the key is not valid and
+// store.ErrConflict if existing nodes have the same key value.
+// The key is enforced by a unique expression index on items which is rebuilt when the key is replaced.
+func (s *Store) UpsertKey(ctx context.Context, k models.NaturalKey) (models.NaturalKey, error) {
+	if err := store.ValidateKey(&k); err != nil {
+		return models.NaturalKey{}, err
+	}
+
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		return models.NaturalKey{}, err
+	}
+
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(
+		ctx,
+		`
+		INSERT INTO natural_keys (label, property)
+		VALUES (?, ?)
+		ON CONFLICT(label) DO UPDATE SET
+			property = excluded.property,
+			created_at = strftime('%s', 'now')
+		RETURNING id, label, property, created_at;
+		`,
+		k.Label,
+		k.Property,
+	)
+
+	id, k, err := scanKey(row)
+	if err != nil {
+		return models.NaturalKey{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DROP INDEX IF EXISTS `+keyIndex(id)+`;`); err != nil {
+		return models.NaturalKey{}, err
+	}
+
+	index := fmt.Sprintf(
+		`CREATE UNIQUE INDEX %s ON items(json_extract(properties, %s)) WHERE label = %s AND from_id = 0 AND to_id = 0;`,
+		keyIndex(id),
+		jsonPath(store.KeyPredicate(k, nil)),
+		quote(k.Label),
+	)
+
+	if _, err := tx.ExecContext(ctx, index); err != nil {
+		if isUniqueViolation(err) {
+			return models.NaturalKey{}, store.DuplicateKeyError(k.Label)
+		}
+		return models.NaturalKey{}, err
+	}
+
+	return k, tx.Commit()
+}
+
+// DeleteKey deletes the natural key for the label returning the deleted key,
+// returning store.ErrNotFound if there is no such key.
+func (s *Store) DeleteKey(ctx context.Context, label string) (models.NaturalKey, error) {
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		return models.NaturalKey{}, err
+	}
+
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(ctx, `DELETE FROM natural_keys WHERE label = ? RETURNING id, label, property, created_at;`, label)
+
+	id, k, err := scanKey(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.NaturalKey{}, fmt.Errorf("%w: natural key for label %q", store.ErrNotFound, label)
+		}
+		return models.NaturalKey{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DROP INDEX IF EXISTS `+keyIndex(id)+`;`); err != nil {
+		return models.NaturalKey{}, err
+	}
+
+	return k, tx.Commit()
+}
@@ -0,0 +1,41 @@
+DROP TRIGGER IF EXISTS items_fts_update;
+DROP TRIGGER IF EXISTS items_fts_insert;
+DROP TRIGGER IF EXISTS items_after_delete;
+
+
+CREATE TRIGGER IF NOT EXISTS items_fts_insert
+AFTER INSERT ON items
+FOR EACH ROW
+BEGIN
+    INSERT INTO fts (
+        id,
+        type,
+        from_id,
+        label,
+        to_id,
+        weight,
+        prop_keys,
+        prop_values
+    ) VALUES (
+        NEW.id,
+        CASE
+            WHEN NEW.from_id == 0 AND NEW.to_id == 0 THEN 'node'
+            WHEN NEW.from_id != 0 AND NEW.to_id != 0 THEN 'edge'
+        END,
+        NEW.from_id,
+        NEW.label,
+        NEW.to_id,
+        NEW.weight,
+        json_extract_keys(NEW.properties),
+        json_extract_values(NEW.properties)
+    );
+END;
+
+
+CREATE TRIGGER IF NOT EXISTS items_after_delete
+AFTER DELETE ON items
+BEGIN
+    DELETE FROM fts WHERE id = OLD.id;
+    DELETE FROM items WHERE from_id = OLD.id;
+    DELETE FROM items WHERE to_id = OLD.id;
+END;
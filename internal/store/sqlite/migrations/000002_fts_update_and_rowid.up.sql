@@ -0,0 +1,108 @@
+-- Migration to keep the full text search in sync when items are updated.
+--
+-- The fts rowid is now the item ID so that the triggers can find the fts row without scanning the whole fts table.
+
+
+DROP TRIGGER IF EXISTS items_fts_insert;
+DROP TRIGGER IF EXISTS items_after_delete;
+
+
+-- after a INSERT, update the fts table with the new item extracting the property keys and values.
+-- NOTE: json_extract_keys and json_extract_values is a custom registered function.
+CREATE TRIGGER IF NOT EXISTS items_fts_insert
+AFTER INSERT ON items
+FOR EACH ROW
+BEGIN
+    INSERT INTO fts (
+        rowid,
+        id,
+        type,
+        from_id,
+        label,
+        to_id,
+        weight,
+        prop_keys,
+        prop_values
+    ) VALUES (
+        NEW.id,
+        NEW.id,
+        CASE
+            WHEN NEW.from_id == 0 AND NEW.to_id == 0 THEN 'node'
+            WHEN NEW.from_id != 0 AND NEW.to_id != 0 THEN 'edge'
+        END,
+        NEW.from_id,
+        NEW.label,
+        NEW.to_id,
+        NEW.weight,
+        json_extract_keys(NEW.properties),
+        json_extract_values(NEW.properties)
+    );
+END;
+
+
+-- after a UPDATE (including upserts using `ON CONFLICT DO UPDATE`), replace the fts row with the updated item.
+-- NOTE: json_extract_keys and json_extract_values is a custom registered function.
+CREATE TRIGGER IF NOT EXISTS items_fts_update
+AFTER UPDATE OF id, from_id, label, to_id, weight, properties ON items
+FOR EACH ROW
+BEGIN
+    DELETE FROM fts WHERE rowid = OLD.id;
+
+    INSERT INTO fts (
+        rowid,
+        id,
+        type,
+        from_id,
+        label,
+        to_id,
+        weight,
+        prop_keys,
+        prop_values
+    ) VALUES (
+        NEW.id,
+        NEW.id,
+        CASE
+            WHEN NEW.from_id == 0 AND NEW.to_id == 0 THEN 'node'
+            WHEN NEW.from_id != 0 AND NEW.to_id != 0 THEN 'edge'
+        END,
+        NEW.from_id,
+        NEW.label,
+        NEW.to_id,
+        NEW.weight,
+        json_extract_keys(NEW.properties),
+        json_extract_values(NEW.properties)
+    );
+END;
+
+
+-- if using INSERT OR REPLACE, SQLite will do a delete and then an insert. So this delete trigger will be fired.
+CREATE TRIGGER IF NOT EXISTS items_after_delete
+AFTER DELETE ON items
+BEGIN
+    -- clean the full text search
+    DELETE FROM fts WHERE rowid = OLD.id;
+
+    -- make sure that the edges for the node are deleted to
+    DELETE FROM items WHERE from_id = OLD.id;
+    DELETE FROM items WHERE to_id = OLD.id;
+END;
+
+
+-- rebuild the fts table so that existing rows use the item ID as the rowid.
+DELETE FROM fts;
+
+INSERT INTO fts (rowid, id, type, from_id, label, to_id, weight, prop_keys, prop_values)
+SELECT
+    i.id,
+    i.id,
+    CASE
+        WHEN i.from_id == 0 AND i.to_id == 0 THEN 'node'
+        WHEN i.from_id != 0 AND i.to_id != 0 THEN 'edge'
+    END,
+    i.from_id,
+    i.label,
+    i.to_id,
+    i.weight,
+    json_extract_keys(i.properties),
+    json_extract_values(i.properties)
+FROM items i;
@@ -0,0 +1,13 @@
+-- Migration to create the table holding the label schemas enforced when writing nodes and edges.
+--
+-- The definition is the JSON encoded schema, the kind and label are kept as columns so they can be used as the key.
+
+
+CREATE TABLE IF NOT EXISTS schemas (
+    kind TEXT NOT NULL,
+    label TEXT NOT NULL,
+    definition JSON NOT NULL,
+    created_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now')),
+    updated_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now')),
+    PRIMARY KEY (kind, label)
+);
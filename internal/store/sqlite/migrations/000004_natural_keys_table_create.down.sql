@@ -0,0 +1,2 @@
+-- The unique indexes enforcing the keys are created at runtime, delete the keys before rolling back to drop them.
+DROP TABLE IF EXISTS natural_keys;
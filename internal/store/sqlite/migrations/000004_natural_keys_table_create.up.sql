@@ -0,0 +1,12 @@
+-- Migration to create the table holding the natural keys used to merge nodes.
+--
+-- Each key is enforced by a unique expression index on items named `items_natural_key_<id>`, the indexes are created
+-- and dropped with the keys by the store as the key property is only known at runtime.
+
+
+CREATE TABLE IF NOT EXISTS natural_keys (
+    id INTEGER PRIMARY KEY,
+    label TEXT NOT NULL UNIQUE,
+    property TEXT NOT NULL,
+    created_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now'))
+);
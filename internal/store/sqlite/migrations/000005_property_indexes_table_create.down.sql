@@ -0,0 +1,2 @@
+-- The expression indexes are created at runtime, drop the indexes before rolling back to remove them.
+DROP TABLE IF EXISTS property_indexes;
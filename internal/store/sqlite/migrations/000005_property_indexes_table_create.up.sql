@@ -0,0 +1,15 @@
+-- Migration to create the table holding the managed property indexes.
+--
+-- Each index is an expression index on items named `items_property_index_<id>`, the indexes are built in the
+-- background by the store once they are added.
+
+
+CREATE TABLE IF NOT EXISTS property_indexes (
+    id INTEGER PRIMARY KEY,
+    label TEXT NOT NULL DEFAULT '',
+    path TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'building',
+    error TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now')),
+    UNIQUE (label, path)
+);
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS items_changes_insert;
+DROP TRIGGER IF EXISTS items_changes_update;
+DROP TRIGGER IF EXISTS items_changes_delete;
+DROP TABLE IF EXISTS changes;
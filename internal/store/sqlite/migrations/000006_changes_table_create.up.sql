@@ -0,0 +1,103 @@
+-- Migration to create the changelog of the graph mutations used by the change feed.
+--
+-- The changes are written by triggers so they are recorded in the same transaction as the write, including the
+-- edges deleted by the items_after_delete trigger. The before and after images hold the item columns.
+
+
+CREATE TABLE IF NOT EXISTS changes (
+    -- AUTOINCREMENT so sequence numbers are never reused, even if the latest changes are removed.
+    seq INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now')),
+    op TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    item_id INTEGER NOT NULL,
+    label TEXT NOT NULL,
+    before JSON,
+    after JSON
+);
+
+
+CREATE TRIGGER IF NOT EXISTS items_changes_insert
+AFTER INSERT ON items
+FOR EACH ROW
+BEGIN
+    INSERT INTO changes (op, kind, item_id, label, after) VALUES (
+        'create',
+        CASE WHEN NEW.from_id == 0 AND NEW.to_id == 0 THEN 'node' ELSE 'edge' END,
+        NEW.id,
+        NEW.label,
+        json_object(
+            'id', NEW.id,
+            'created_at', NEW.created_at,
+            'updated_at', NEW.updated_at,
+            'from_id', NEW.from_id,
+            'label', NEW.label,
+            'to_id', NEW.to_id,
+            'weight', NEW.weight,
+            'properties', json(NEW.properties)
+        )
+    );
+END;
+
+
+-- only the item columns are watched so the after_items_update trigger setting updated_at is not recorded, upserts
+-- which do not change the item are skipped.
+CREATE TRIGGER IF NOT EXISTS items_changes_update
+AFTER UPDATE OF from_id, label, to_id, weight, properties ON items
+FOR EACH ROW
+WHEN OLD.from_id IS NOT NEW.from_id
+    OR OLD.label IS NOT NEW.label
+    OR OLD.to_id IS NOT NEW.to_id
+    OR OLD.weight IS NOT NEW.weight
+    OR json(OLD.properties) IS NOT json(NEW.properties)
+BEGIN
+    INSERT INTO changes (op, kind, item_id, label, before, after) VALUES (
+        'update',
+        CASE WHEN NEW.from_id == 0 AND NEW.to_id == 0 THEN 'node' ELSE 'edge' END,
+        NEW.id,
+        NEW.label,
+        json_object(
+            'id', OLD.id,
+            'created_at', OLD.created_at,
+            'updated_at', OLD.updated_at,
+            'from_id', OLD.from_id,
+            'label', OLD.label,
+            'to_id', OLD.to_id,
+            'weight', OLD.weight,
+            'properties', json(OLD.properties)
+        ),
+        json_object(
+            'id', NEW.id,
+            'created_at', NEW.created_at,
+            'updated_at', CAST(strftime('%s', 'now') AS INTEGER),
+            'from_id', NEW.from_id,
+            'label', NEW.label,
+            'to_id', NEW.to_id,
+            'weight', NEW.weight,
+            'properties', json(NEW.properties)
+        )
+    );
+END;
+
+
+CREATE TRIGGER IF NOT EXISTS items_changes_delete
+AFTER DELETE ON items
+FOR EACH ROW
+BEGIN
+    INSERT INTO changes (op, kind, item_id, label, before) VALUES (
+        'delete',
+        CASE WHEN OLD.from_id == 0 AND OLD.to_id == 0 THEN 'node' ELSE 'edge' END,
+        OLD.id,
+        OLD.label,
+        json_object(
+            'id', OLD.id,
+            'created_at', OLD.created_at,
+            'updated_at', OLD.updated_at,
+            'from_id', OLD.from_id,
+            'label', OLD.label,
+            'to_id', OLD.to_id,
+            'weight', OLD.weight,
+            'properties', json(OLD.properties)
+        )
+    );
+END;
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS changes_webhook_deliveries;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
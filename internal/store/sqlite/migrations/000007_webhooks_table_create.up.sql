@@ -0,0 +1,54 @@
+-- Migration to create the webhook subscriptions and the outbox of the deliveries.
+--
+-- Deliveries are queued by a trigger on the changelog so they are written in the same transaction as the change.
+-- The delivery attempt times are in milliseconds so retries can back off for less than a second.
+
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id INTEGER PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    labels JSON NOT NULL DEFAULT '[]',
+    ops JSON NOT NULL DEFAULT '[]',
+    created_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now'))
+);
+
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INTEGER PRIMARY KEY,
+    webhook_id INTEGER NOT NULL,
+    seq INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_code INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    next_attempt_at INTEGER NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000 AS INTEGER)),
+    created_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now')),
+    updated_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now'))
+);
+
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due     ON webhook_deliveries(status, next_attempt_at);
+
+
+-- queue a delivery for each webhook matching the label, before or after the change, and the operation.
+CREATE TRIGGER IF NOT EXISTS changes_webhook_deliveries
+AFTER INSERT ON changes
+FOR EACH ROW
+BEGIN
+    INSERT INTO webhook_deliveries (webhook_id, seq)
+    SELECT w.id, NEW.seq
+    FROM webhooks w
+    WHERE (
+        json_array_length(w.labels) = 0
+        OR EXISTS (
+            SELECT 1 FROM json_each(w.labels) l
+            WHERE l.value = NEW.label OR l.value = json_extract(NEW.before, '$.label')
+        )
+    )
+    AND (
+        json_array_length(w.ops) = 0
+        OR EXISTS (SELECT 1 FROM json_each(w.ops) o WHERE o.value = NEW.op)
+    );
+END;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_changes_item;
+DROP INDEX IF EXISTS idx_changes_created_at;
@@ -0,0 +1,8 @@
+-- Migration to index the changelog for the item history and as of queries.
+--
+-- The history of an item is its changes ordered by sequence number, as of queries find the last change of each item
+-- made before a time.
+
+
+CREATE INDEX IF NOT EXISTS idx_changes_item       ON changes(item_id, seq);
+CREATE INDEX IF NOT EXISTS idx_changes_created_at ON changes(created_at, item_id);
@@ -0,0 +1,130 @@
+-- Migration to soft delete items, trashed items are kept as tombstones until they are restored or purged.
+--
+-- deleted_at is when the item was moved to the trash, deleted_with is the node an edge was trashed with so the edge is
+-- restored with the node. Trashing and restoring are recorded in the changelog as a delete and a create, purging the
+-- trash is not recorded again.
+
+
+ALTER TABLE items ADD COLUMN deleted_at INTEGER;
+ALTER TABLE items ADD COLUMN deleted_with INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_items_deleted_at   ON items(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_items_deleted_with ON items(deleted_with) WHERE deleted_with IS NOT NULL;
+
+
+DROP TRIGGER IF EXISTS items_changes_update;
+DROP TRIGGER IF EXISTS items_changes_delete;
+
+
+-- updates restoring a trashed item are recorded as a create by items_changes_restore.
+CREATE TRIGGER IF NOT EXISTS items_changes_update
+AFTER UPDATE OF from_id, label, to_id, weight, properties ON items
+FOR EACH ROW
+WHEN OLD.deleted_at IS NULL AND (
+    OLD.from_id IS NOT NEW.from_id
+    OR OLD.label IS NOT NEW.label
+    OR OLD.to_id IS NOT NEW.to_id
+    OR OLD.weight IS NOT NEW.weight
+    OR json(OLD.properties) IS NOT json(NEW.properties)
+)
+BEGIN
+    INSERT INTO changes (op, kind, item_id, label, before, after) VALUES (
+        'update',
+        CASE WHEN NEW.from_id == 0 AND NEW.to_id == 0 THEN 'node' ELSE 'edge' END,
+        NEW.id,
+        NEW.label,
+        json_object(
+            'id', OLD.id,
+            'created_at', OLD.created_at,
+            'updated_at', OLD.updated_at,
+            'from_id', OLD.from_id,
+            'label', OLD.label,
+            'to_id', OLD.to_id,
+            'weight', OLD.weight,
+            'properties', json(OLD.properties)
+        ),
+        json_object(
+            'id', NEW.id,
+            'created_at', NEW.created_at,
+            'updated_at', CAST(strftime('%s', 'now') AS INTEGER),
+            'from_id', NEW.from_id,
+            'label', NEW.label,
+            'to_id', NEW.to_id,
+            'weight', NEW.weight,
+            'properties', json(NEW.properties)
+        )
+    );
+END;
+
+
+-- trashed items were recorded as deleted when they were trashed.
+CREATE TRIGGER IF NOT EXISTS items_changes_delete
+AFTER DELETE ON items
+FOR EACH ROW
+WHEN OLD.deleted_at IS NULL
+BEGIN
+    INSERT INTO changes (op, kind, item_id, label, before) VALUES (
+        'delete',
+        CASE WHEN OLD.from_id == 0 AND OLD.to_id == 0 THEN 'node' ELSE 'edge' END,
+        OLD.id,
+        OLD.label,
+        json_object(
+            'id', OLD.id,
+            'created_at', OLD.created_at,
+            'updated_at', OLD.updated_at,
+            'from_id', OLD.from_id,
+            'label', OLD.label,
+            'to_id', OLD.to_id,
+            'weight', OLD.weight,
+            'properties', json(OLD.properties)
+        )
+    );
+END;
+
+
+CREATE TRIGGER IF NOT EXISTS items_changes_trash
+AFTER UPDATE OF deleted_at ON items
+FOR EACH ROW
+WHEN OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL
+BEGIN
+    INSERT INTO changes (op, kind, item_id, label, before) VALUES (
+        'delete',
+        CASE WHEN OLD.from_id == 0 AND OLD.to_id == 0 THEN 'node' ELSE 'edge' END,
+        OLD.id,
+        OLD.label,
+        json_object(
+            'id', OLD.id,
+            'created_at', OLD.created_at,
+            'updated_at', OLD.updated_at,
+            'from_id', OLD.from_id,
+            'label', OLD.label,
+            'to_id', OLD.to_id,
+            'weight', OLD.weight,
+            'properties', json(OLD.properties)
+        )
+    );
+END;
+
+
+CREATE TRIGGER IF NOT EXISTS items_changes_restore
+AFTER UPDATE OF deleted_at ON items
+FOR EACH ROW
+WHEN OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL
+BEGIN
+    INSERT INTO changes (op, kind, item_id, label, after) VALUES (
+        'create',
+        CASE WHEN NEW.from_id == 0 AND NEW.to_id == 0 THEN 'node' ELSE 'edge' END,
+        NEW.id,
+        NEW.label,
+        json_object(
+            'id', NEW.id,
+            'created_at', NEW.created_at,
+            'updated_at', CAST(strftime('%s', 'now') AS INTEGER),
+            'from_id', NEW.from_id,
+            'label', NEW.label,
+            'to_id', NEW.to_id,
+            'weight', NEW.weight,
+            'properties', json(NEW.properties)
+        )
+    );
+END;
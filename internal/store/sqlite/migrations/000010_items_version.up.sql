@@ -0,0 +1,4 @@
+-- Migration to version the items for optimistic concurrency control, every write increments the version.
+
+
+ALTER TABLE items ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,14 @@
+-- Migration to create the API keys authenticating the clients of the API.
+--
+-- Only the SHA-256 hash of the keys is stored, the prefix is the start of the key shown to help finding a key.
+
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    role TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    hash TEXT NOT NULL UNIQUE,
+    created_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now')),
+    last_used_at INTEGER
+);
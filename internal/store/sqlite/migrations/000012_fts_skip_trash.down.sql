@@ -0,0 +1,21 @@
+DROP TRIGGER IF EXISTS items_fts_restore;
+DROP TRIGGER IF EXISTS items_fts_trash;
+
+
+-- NOTE: json_extract_keys and json_extract_values is a custom registered function.
+INSERT INTO fts (rowid, id, type, from_id, label, to_id, weight, prop_keys, prop_values)
+SELECT
+    i.id,
+    i.id,
+    CASE
+        WHEN i.from_id = 0 AND i.to_id = 0 THEN 'node'
+        WHEN i.from_id != 0 AND i.to_id != 0 THEN 'edge'
+    END,
+    i.from_id,
+    i.label,
+    i.to_id,
+    i.weight,
+    json_extract_keys(i.properties),
+    json_extract_values(i.properties)
+FROM items i
+WHERE i.deleted_at IS NOT NULL;
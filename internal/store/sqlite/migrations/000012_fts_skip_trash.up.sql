@@ -0,0 +1,51 @@
+-- Migration to keep trashed items out of the full text search, they are indexed again when they are restored.
+--
+-- The searches already skip trashed items, removing them keeps the index the same as a reindex of the items.
+
+
+DELETE FROM fts WHERE rowid IN (SELECT id FROM items WHERE deleted_at IS NOT NULL);
+
+
+CREATE TRIGGER IF NOT EXISTS items_fts_trash
+AFTER UPDATE OF deleted_at ON items
+FOR EACH ROW
+WHEN OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL
+BEGIN
+    DELETE FROM fts WHERE rowid = OLD.id;
+END;
+
+
+-- upserts restoring a trashed item also replace the fts row in items_fts_update, so the row is replaced here too.
+-- NOTE: json_extract_keys and json_extract_values is a custom registered function.
+CREATE TRIGGER IF NOT EXISTS items_fts_restore
+AFTER UPDATE OF deleted_at ON items
+FOR EACH ROW
+WHEN OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL
+BEGIN
+    DELETE FROM fts WHERE rowid = NEW.id;
+
+    INSERT INTO fts (
+        rowid,
+        id,
+        type,
+        from_id,
+        label,
+        to_id,
+        weight,
+        prop_keys,
+        prop_values
+    ) VALUES (
+        NEW.id,
+        NEW.id,
+        CASE
+            WHEN NEW.from_id == 0 AND NEW.to_id == 0 THEN 'node'
+            WHEN NEW.from_id != 0 AND NEW.to_id != 0 THEN 'edge'
+        END,
+        NEW.from_id,
+        NEW.label,
+        NEW.to_id,
+        NEW.weight,
+        json_extract_keys(NEW.properties),
+        json_extract_values(NEW.properties)
+    );
+END;
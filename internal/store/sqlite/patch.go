@@ -0,0 +1,129 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// PatchNode applies the patch to the properties of the node returning the patched node, returning store.ErrNotFound
+// if there is no such node, store.ErrInvalid if the patch is not valid and store.ErrConflict if it can not be applied.
+// A patch with a version returns a *store.VersionError if it is not the current version of the node.
+func (s *Store) PatchNode(ctx context.Context, id uint64, patch store.Patch) (models.Node, error) {
+	if err := patch.Validate(); err != nil {
+		return models.Node{}, err
+	}
+
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		return models.Node{}, err
+	}
+
+	defer tx.Rollback()
+
+	if patch.Version > 0 {
+		if err := checkNodeVersion(ctx, tx, id, patch.Version); err != nil {
+			return models.Node{}, err
+		}
+	}
+
+	n, err := node(ctx, tx, id)
+	if err != nil {
+		return models.Node{}, err
+	}
+
+	if err := patchProperties(ctx, tx, id, n.Properties, patch); err != nil {
+		if isUniqueViolation(err) {
+			return models.Node{}, store.DuplicateKeyError(n.Label)
+		}
+		return models.Node{}, err
+	}
+
+	n, err = node(ctx, tx, id)
+	if err != nil {
+		return models.Node{}, err
+	}
+
+	schemas, err := schemaSet(ctx, tx)
+	if err != nil {
+		return models.Node{}, err
+	}
+
+	if err := schemas.CheckNode(n); err != nil {
+		return models.Node{}, err
+	}
+
+	return n, tx.Commit()
+}
+
+// PatchEdge applies the patch to the properties of the edge returning the patched edge, returning store.ErrNotFound
+// if there is no such edge, store.ErrInvalid if the patch is not valid and store.ErrConflict if it can not be applied.
+// A patch with a version returns a *store.VersionError if it is not the current version of the edge.
+func (s *Store) PatchEdge(ctx context.Context, id uint64, patch store.Patch) (models.Edge, error) {
+	if err := patch.Validate(); err != nil {
+		return models.Edge{}, err
+	}
+
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		return models.Edge{}, err
+	}
+
+	defer tx.Rollback()
+
+	if patch.Version > 0 {
+		if err := checkEdgeVersion(ctx, tx, id, patch.Version); err != nil {
+			return models.Edge{}, err
+		}
+	}
+
+	e, err := edge(ctx, tx, id)
+	if err != nil {
+		return models.Edge{}, err
+	}
+
+	if err := patchProperties(ctx, tx, id, e.Properties, patch); err != nil {
+		return models.Edge{}, err
+	}
+
+	e, err = edge(ctx, tx, id)
+	if err != nil {
+		return models.Edge{}, err
+	}
+
+	schemas, err := schemaSet(ctx, tx)
+	if err != nil {
+		return models.Edge{}, err
+	}
+
+	if err := schemas.CheckEdge(e, nodeLabel(ctx, tx)); err != nil {
+		return models.Edge{}, err
+	}
+
+	return e, tx.Commit()
+}
+
+// patchProperties applies the patch to the current properties of the item in the transaction. Merge patches are
+// applied by sqlite with json_patch, JSON patches are applied to the current properties which are then replaced.
+// The fts row of the item is refreshed by the `items_fts_update` trigger.
+func patchProperties(ctx context.Context, q querier, id uint64, current models.Properties, patch store.Patch) error {
+	query := `UPDATE items SET properties = json_patch(properties, ?), version = version + 1 WHERE id = ?;`
+	doc := []byte(patch.Document)
+
+	if patch.Type == store.JSONPatch {
+		patched, err := patch.Apply(current)
+		if err != nil {
+			return err
+		}
+
+		if doc, err = patched.ToBytes(); err != nil {
+			return err
+		}
+
+		query = `UPDATE items SET properties = json(?), version = version + 1 WHERE id = ?;`
+	}
+
+	_, err := q.ExecContext(ctx, query, string(doc), id)
+	return err
+}
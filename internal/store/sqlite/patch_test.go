@@ -0,0 +1,143 @@
+package sqlite_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/models"
+)
+
+func TestStore_PatchNode(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := sqlite.New(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	defer s.Close()
+
+	preload(t, s,
+		models.Node{ID: 1, Label: "person", Properties: models.Properties{"name": "foo", "age": float64(21), "meta": map[string]any{"hair": "brown"}}},
+		models.Node{ID: 2, Label: "person", Properties: models.Properties{"name": "bar"}},
+	)
+
+	got, err := s.PatchNode(ctx, 1, store.Patch{Type: store.MergePatch, Document: []byte(`{"age": 22, "meta": {"hair": null, "eyes": "blue"}}`)})
+	if err != nil {
+		t.Fatalf("PatchNode() failed: %v", err)
+	}
+
+	want := models.Properties{"name": "foo", "age": float64(22), "meta": map[string]any{"eyes": "blue"}}
+
+	if diff := cmp.Diff(want, got.Properties); diff != "" {
+		t.Errorf("PatchNode() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	if got.Version != 2 {
+		t.Errorf("PatchNode() version = %d, want 2", got.Version)
+	}
+
+	got, err = s.PatchNode(ctx, 1, store.Patch{
+		Type:     store.JSONPatch,
+		Document: []byte(`[{"op": "test", "path": "/age", "value": 22}, {"op": "replace", "path": "/name", "value": "baz"}]`),
+		Version:  2,
+	})
+
+	if err != nil {
+		t.Fatalf("PatchNode() failed: %v", err)
+	}
+
+	if got.Properties["name"] != "baz" || got.Version != 3 {
+		t.Errorf("PatchNode() = %+v, want the name replaced at version 3", got)
+	}
+
+	// the search index is refreshed with the patched properties.
+	if ids := termIDs(t, s, "foo"); len(ids) != 0 {
+		t.Errorf("NodesTermSearch() = %v, want no matches for the old value", ids)
+	}
+
+	if diff := cmp.Diff([]uint64{1}, termIDs(t, s, "baz")); diff != "" {
+		t.Errorf("NodesTermSearch() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	var versionErr *store.VersionError
+	if _, err := s.PatchNode(ctx, 1, store.Patch{Type: store.MergePatch, Document: []byte(`{"age": 30}`), Version: 2}); !errors.As(err, &versionErr) {
+		t.Errorf("PatchNode() with a stale version error = %v, want a *store.VersionError", err)
+	}
+
+	if _, err := s.PatchNode(ctx, 1, store.Patch{Type: store.JSONPatch, Document: []byte(`[{"op": "test", "path": "/age", "value": 30}]`)}); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("PatchNode() with a failed test error = %v, want store.ErrConflict", err)
+	}
+
+	if _, err := s.PatchNode(ctx, 3, store.Patch{Type: store.MergePatch, Document: []byte(`{}`)}); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("PatchNode() of a missing node error = %v, want store.ErrNotFound", err)
+	}
+
+	// patches are checked against the schemas and natural keys like any other write.
+	if _, err := s.UpsertSchema(ctx, models.Schema{Kind: models.SchemaNode, Label: "person", Required: []string{"name"}}); err != nil {
+		t.Fatalf("UpsertSchema() failed: %v", err)
+	}
+
+	var schemaErr *store.SchemaError
+	if _, err := s.PatchNode(ctx, 2, store.Patch{Type: store.JSONPatch, Document: []byte(`[{"op": "remove", "path": "/name"}]`)}); !errors.As(err, &schemaErr) {
+		t.Errorf("PatchNode() removing a required property error = %v, want a *store.SchemaError", err)
+	}
+
+	if _, err := s.UpsertKey(ctx, models.NaturalKey{Label: "person", Property: "name"}); err != nil {
+		t.Fatalf("UpsertKey() failed: %v", err)
+	}
+
+	if _, err := s.PatchNode(ctx, 2, store.Patch{Type: store.MergePatch, Document: []byte(`{"name": "baz"}`)}); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("PatchNode() with a duplicate key error = %v, want store.ErrConflict", err)
+	}
+
+	n, err := s.Node(ctx, 2)
+	if err != nil {
+		t.Fatalf("Node() failed: %v", err)
+	}
+
+	if diff := cmp.Diff(models.Properties{"name": "bar"}, n.Properties); diff != "" {
+		t.Errorf("Node() after failed patches = mismatch (-want, +got): \n%s", diff)
+	}
+}
+
+func TestStore_PatchEdge(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := sqlite.New(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	defer s.Close()
+
+	preload(t, s,
+		models.Node{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
+		models.Node{ID: 2, Label: "person", Properties: models.Properties{"name": "bar"}},
+	)
+
+	preloadEdges(t, s, models.Edge{ID: 3, From: 1, Label: "knows", To: 2, Weight: 1})
+
+	got, err := s.PatchEdge(ctx, 3, store.Patch{Type: store.JSONPatch, Document: []byte(`[{"op": "add", "path": "/since", "value": 2020}]`)})
+	if err != nil {
+		t.Fatalf("PatchEdge() failed: %v", err)
+	}
+
+	want := models.Edge{ID: 3, From: 1, Label: "knows", To: 2, Weight: 1, Version: 2, Properties: models.Properties{"since": float64(2020)}}
+
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(models.Edge{}, "CreatedAt", "UpdatedAt")); diff != "" {
+		t.Errorf("PatchEdge() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	if _, err := s.PatchEdge(ctx, 1, store.Patch{Type: store.MergePatch, Document: []byte(`{}`)}); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("PatchEdge() of a node error = %v, want store.ErrNotFound", err)
+	}
+
+	if _, err := s.PatchEdge(ctx, 3, store.Patch{Type: store.MergePatch, Document: []byte(`null`)}); !errors.Is(err, store.ErrInvalid) {
+		t.Errorf("PatchEdge() with an invalid patch error = %v, want store.ErrInvalid", err)
+	}
+}
@@ -0,0 +1,67 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// Paths returns up to K paths between two nodes, returning store.ErrNotFound if there is no path.
+func (s *Store) Paths(ctx context.Context, args store.PathArgs) ([]models.Path, error) {
+	if args.Direction == "" {
+		args.Direction = store.DirectionOut
+	}
+
+	labels, err := json.Marshal(args.EdgeLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	var where string
+
+	switch args.Direction {
+	case store.DirectionOut:
+		where = "e.from_id = ?1"
+	case store.DirectionIn:
+		where = "e.to_id = ?1"
+	default:
+		where = "(e.from_id = ?1 OR e.to_id = ?1)"
+	}
+
+	if len(args.EdgeLabels) > 0 {
+		where += " AND e.label IN (SELECT value FROM json_each(?2))"
+	}
+
+	query := fmt.Sprintf(
+		`
+		SELECT e.id, e.created_at, e.updated_at, e.version, e.from_id, e.label, e.to_id, e.weight, e.properties
+		FROM items e
+		WHERE e.from_id > 0 AND e.to_id > 0 AND e.deleted_at IS NULL AND %s
+		ORDER BY e.id;
+		`,
+		where,
+	)
+
+	adjacent := func(ctx context.Context, id uint64) ([]models.Edge, error) {
+		queryArgs := []any{id}
+		if len(args.EdgeLabels) > 0 {
+			queryArgs = append(queryArgs, string(labels))
+		}
+
+		rows, err := s.db.QueryContext(ctx, query, queryArgs...)
+		if err != nil {
+			return nil, err
+		}
+
+		return collectEdges(rows)
+	}
+
+	nodes := func(ctx context.Context, ids ...uint64) ([]models.Node, error) {
+		return nodesByID(ctx, s.db, ids...)
+	}
+
+	return store.FindPaths(ctx, args, adjacent, nodes)
+}
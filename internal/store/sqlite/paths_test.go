@@ -0,0 +1,59 @@
+package sqlite_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/models"
+)
+
+func TestStore_Paths(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := sqlite.New(ctx, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	preload(t, s,
+		models.Node{ID: 1, Label: "person"},
+		models.Node{ID: 2, Label: "person"},
+		models.Node{ID: 3, Label: "person"},
+	)
+
+	preloadEdges(t, s,
+		models.Edge{ID: 10, From: 1, Label: "knows", To: 2, Weight: 1},
+		models.Edge{ID: 11, From: 2, Label: "knows", To: 3, Weight: 1},
+		models.Edge{ID: 12, From: 1, Label: "likes", To: 3, Weight: 5},
+	)
+
+	paths, err := s.Paths(ctx, store.PathArgs{From: 1, To: 3, Mode: store.PathWeighted, EdgeLabels: []string{"knows", "likes"}, K: 2})
+	if err != nil {
+		t.Fatalf("Paths() failed: %v", err)
+	}
+
+	got := [][]uint64{}
+	for _, p := range paths {
+		ids := []uint64{}
+		for _, h := range p.Hops {
+			ids = append(ids, h.EdgeID)
+		}
+		got = append(got, ids)
+	}
+
+	if diff := cmp.Diff([][]uint64{{10, 11}, {12}}, got); diff != "" {
+		t.Errorf("Paths() mismatch (-want, +got): \n%s", diff)
+	}
+
+	if paths[0].Graph.Nodes[1].Label != "person" {
+		t.Errorf("Paths() expected the nodes to be filled in, got %+v", paths[0].Graph.Nodes)
+	}
+
+	_, err = s.Paths(ctx, store.PathArgs{From: 1, To: 3, EdgeLabels: []string{"hates"}})
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Paths() error = %v, want %v", err, store.ErrNotFound)
+	}
+}
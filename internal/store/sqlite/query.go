@@ -0,0 +1,213 @@
+package sqlite
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/jenmud/edgedb/internal/query"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// Query runs a read-only graph query returning the rows and the graph of the returned nodes and edges.
+// Parse and compile errors are returned as *query.Error. The result is flagged as truncated if a variable length
+// relationship stopped after query.MaxWalkRows rows.
+func (s *Store) Query(ctx context.Context, args store.QueryArgs) (models.QueryResult, error) {
+	result := models.QueryResult{
+		Table: models.Table{Columns: []string{}, Rows: [][]any{}},
+		Graph: models.Graph{Nodes: []models.Node{}, Edges: []models.Edge{}},
+	}
+
+	q, err := query.Parse(args.Query)
+	if err != nil {
+		return result, err
+	}
+
+	plan, err := query.Compile(q, args.Params)
+	if err != nil {
+		return result, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return result, err
+	}
+
+	defer tx.Rollback()
+
+	if plan.Truncated != "" {
+		if err := tx.QueryRowContext(ctx, plan.Truncated, plan.Args...).Scan(&result.Truncated); err != nil {
+			return result, err
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, plan.SQL, plan.Args...)
+	if err != nil {
+		return result, err
+	}
+
+	/*
+		The plan selects IDs for nodes and edges, scan all the rows first collecting the IDs
+		and then fetch the nodes and edges in bulk.
+	*/
+	raw := [][]any{}
+	nodeIDs := map[uint64]struct{}{}
+	edgeIDs := map[uint64]struct{}{}
+
+	for rows.Next() {
+		cells := make([]any, len(plan.Columns))
+		ptrs := make([]any, len(cells))
+		for i := range cells {
+			ptrs[i] = &cells[i]
+		}
+
+		if err := rows.Scan(ptrs...); err != nil {
+			rows.Close()
+			return result, err
+		}
+
+		for i, col := range plan.Columns {
+			switch col.Kind {
+			case query.ColumnNode:
+				id, err := asID(cells[i])
+				if err != nil {
+					rows.Close()
+					return result, err
+				}
+				cells[i] = id
+				nodeIDs[id] = struct{}{}
+
+			case query.ColumnEdge:
+				id, err := asID(cells[i])
+				if err != nil {
+					rows.Close()
+					return result, err
+				}
+				cells[i] = id
+				edgeIDs[id] = struct{}{}
+
+			case query.ColumnEdges:
+				ids := []uint64{}
+				if err := unmarshalCell(cells[i], &ids); err != nil {
+					rows.Close()
+					return result, err
+				}
+				cells[i] = ids
+				for _, id := range ids {
+					edgeIDs[id] = struct{}{}
+				}
+
+			default:
+				var v any
+				if err := unmarshalCell(cells[i], &v); err != nil {
+					rows.Close()
+					return result, err
+				}
+				cells[i] = v
+			}
+		}
+
+		raw = append(raw, cells)
+	}
+
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+
+	edges, err := edgesByID(ctx, tx, sortedIDs(edgeIDs)...)
+	if err != nil {
+		return result, err
+	}
+
+	edgeByID := make(map[uint64]models.Edge, len(edges))
+	for _, e := range edges {
+		edgeByID[e.ID] = e
+		// include the nodes at either end so the graph can be drawn.
+		nodeIDs[e.From] = struct{}{}
+		nodeIDs[e.To] = struct{}{}
+	}
+
+	nodes, err := nodesByID(ctx, tx, sortedIDs(nodeIDs)...)
+	if err != nil {
+		return result, err
+	}
+
+	nodeByID := make(map[uint64]models.Node, len(nodes))
+	for _, n := range nodes {
+		nodeByID[n.ID] = n
+	}
+
+	for _, col := range plan.Columns {
+		result.Table.Columns = append(result.Table.Columns, col.Name)
+	}
+
+	for _, cells := range raw {
+		for i, col := range plan.Columns {
+			switch col.Kind {
+			case query.ColumnNode:
+				cells[i] = nodeByID[cells[i].(uint64)]
+			case query.ColumnEdge:
+				cells[i] = edgeByID[cells[i].(uint64)]
+			case query.ColumnEdges:
+				ids := cells[i].([]uint64)
+				walked := make([]models.Edge, len(ids))
+				for j, id := range ids {
+					walked[j] = edgeByID[id]
+				}
+				cells[i] = walked
+			}
+		}
+
+		result.Table.Rows = append(result.Table.Rows, cells)
+	}
+
+	slices.SortFunc(nodes, func(a, b models.Node) int { return cmp.Compare(a.ID, b.ID) })
+	result.Graph.AddNodes(nodes...)
+	result.Graph.AddEdges(edges...)
+
+	return result, tx.Commit()
+}
+
+// asID converts a scanned ID cell into an ID.
+func asID(cell any) (uint64, error) {
+	switch v := cell.(type) {
+	case int64:
+		return uint64(v), nil
+	default:
+		return 0, fmt.Errorf("unexpected ID %v (%T)", cell, cell)
+	}
+}
+
+// unmarshalCell decodes a scanned JSON cell, NULL cells are left as is.
+func unmarshalCell(cell any, v any) error {
+	switch c := cell.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(c), v)
+	case []byte:
+		return json.Unmarshal(c, v)
+	default:
+		// numbers selected from expressions are not JSON text.
+		b, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(b, v)
+	}
+}
+
+// sortedIDs returns the IDs in the set in ascending order.
+func sortedIDs(set map[uint64]struct{}) []uint64 {
+	ids := make([]uint64, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
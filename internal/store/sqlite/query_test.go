@@ -0,0 +1,265 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenmud/edgedb/internal/query"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/models"
+)
+
+// cellIDs replaces the nodes and edges in the rows with their IDs to make the rows easy to compare.
+func cellIDs(rows [][]any) [][]any {
+	out := make([][]any, len(rows))
+	for i, row := range rows {
+		out[i] = make([]any, len(row))
+		for j, cell := range row {
+			switch c := cell.(type) {
+			case models.Node:
+				out[i][j] = c.ID
+			case models.Edge:
+				out[i][j] = c.ID
+			case []models.Edge:
+				ids := []uint64{}
+				for _, e := range c {
+					ids = append(ids, e.ID)
+				}
+				out[i][j] = ids
+			default:
+				out[i][j] = c
+			}
+		}
+	}
+	return out
+}
+
+func TestStore_Query(t *testing.T) {
+	nodes := []models.Node{
+		{ID: 1, Label: "person", Properties: models.Properties{"name": "foo", "age": 21}},
+		{ID: 2, Label: "person", Properties: models.Properties{"name": "bar", "age": 42}},
+		{ID: 3, Label: "person", Properties: models.Properties{"name": "baz", "age": 35}},
+		{ID: 4, Label: "dog", Properties: models.Properties{"name": "socks"}},
+	}
+
+	// foo -knows-> bar -knows-> baz -knows-> foo, foo -owns-> socks
+	edges := []models.Edge{
+		{ID: 5, From: 1, Label: "knows", To: 2, Properties: models.Properties{"since": 2001}},
+		{ID: 6, From: 2, Label: "knows", To: 3},
+		{ID: 7, From: 3, Label: "knows", To: 1},
+		{ID: 8, From: 1, Label: "owns", To: 4},
+	}
+
+	tests := []struct {
+		name      string // description of this test case
+		args      store.QueryArgs
+		wantCols  []string
+		wantRows  [][]any
+		wantNodes int
+		wantErr   bool
+	}{
+		{
+			name:     "match label with parameter",
+			args:     store.QueryArgs{Query: "MATCH (a:person) WHERE a.name = $name RETURN a", Params: map[string]any{"name": "bar"}},
+			wantCols: []string{"a"},
+			wantRows: [][]any{{uint64(2)}},
+		},
+		{
+			name:     "properties and order",
+			args:     store.QueryArgs{Query: "MATCH (a:person) WHERE a.age > 30 RETURN a.name AS name, a.age ORDER BY a.age DESC"},
+			wantCols: []string{"name", "a.age"},
+			wantRows: [][]any{{"bar", float64(42)}, {"baz", float64(35)}},
+		},
+		{
+			name:      "single hop",
+			args:      store.QueryArgs{Query: "MATCH (a {name: 'foo'})-[r:knows]->(b) RETURN a, r, b"},
+			wantCols:  []string{"a", "r", "b"},
+			wantRows:  [][]any{{uint64(1), uint64(5), uint64(2)}},
+			wantNodes: 2,
+		},
+		{
+			name:     "incoming",
+			args:     store.QueryArgs{Query: "MATCH (a {name: 'foo'})<-[:knows]-(b) RETURN b.name"},
+			wantCols: []string{"b.name"},
+			wantRows: [][]any{{"baz"}},
+		},
+		{
+			name:     "either direction",
+			args:     store.QueryArgs{Query: "MATCH (a {name: 'foo'})-[:knows]-(b) RETURN b.name ORDER BY b.name"},
+			wantCols: []string{"b.name"},
+			wantRows: [][]any{{"bar"}, {"baz"}},
+		},
+		{
+			name:      "variable length",
+			args:      store.QueryArgs{Query: "MATCH (a:person {name: 'foo'})-[r:knows*1..3]->(b) RETURN b.name, r ORDER BY length(r)"},
+			wantCols:  []string{"b.name", "r"},
+			wantRows:  [][]any{{"bar", []uint64{5}}, {"baz", []uint64{5, 6}}, {"foo", []uint64{5, 6, 7}}},
+			wantNodes: 3,
+		},
+		{
+			name:     "variable length zero hops",
+			args:     store.QueryArgs{Query: "MATCH (a {name: 'foo'})-[*0..1]->(b:person) RETURN b.name ORDER BY b.name"},
+			wantCols: []string{"b.name"},
+			wantRows: [][]any{{"bar"}, {"foo"}},
+		},
+		{
+			name:     "relationships are unique",
+			args:     store.QueryArgs{Query: "MATCH (a)-[r1:knows]->(b)-[r2:knows]->(c) WHERE a.name = 'foo' RETURN id(r1), id(r2)"},
+			wantCols: []string{"id(r1)", "id(r2)"},
+			wantRows: [][]any{{float64(5), float64(6)}},
+		},
+		{
+			name:     "in, starts with and null checks",
+			args:     store.QueryArgs{Query: "MATCH (a) WHERE a.name IN $names AND a.name STARTS WITH 'b' AND a.age IS NOT NULL RETURN a.name ORDER BY a.name", Params: map[string]any{"names": []any{"bar", "baz", "socks"}}},
+			wantCols: []string{"a.name"},
+			wantRows: [][]any{{"bar"}, {"baz"}},
+		},
+		{
+			name:     "distinct",
+			args:     store.QueryArgs{Query: "MATCH (a)-[r:knows]-(b) RETURN DISTINCT type(r) AS t"},
+			wantCols: []string{"t"},
+			wantRows: [][]any{{"knows"}},
+		},
+		{
+			name:     "return star",
+			args:     store.QueryArgs{Query: "MATCH (a:dog) RETURN *"},
+			wantCols: []string{"a"},
+			wantRows: [][]any{{uint64(4)}},
+		},
+		{
+			name:     "limit",
+			args:     store.QueryArgs{Query: "MATCH (a:person) RETURN a.name ORDER BY a.name SKIP 1 LIMIT $n", Params: map[string]any{"n": float64(1)}},
+			wantCols: []string{"a.name"},
+			wantRows: [][]any{{"baz"}},
+		},
+		{
+			name:    "unknown variable",
+			args:    store.QueryArgs{Query: "MATCH (a) RETURN b"},
+			wantErr: true,
+		},
+		{
+			name:    "missing parameter",
+			args:    store.QueryArgs{Query: "MATCH (a) WHERE a.name = $name RETURN a"},
+			wantErr: true,
+		},
+		{
+			name:    "write clauses are rejected",
+			args:    store.QueryArgs{Query: "MATCH (a) DELETE a"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+
+			s, err := sqlite.New(ctx, ":memory:")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			preload(t, s, nodes...)
+			preloadEdges(t, s, edges...)
+
+			got, gotErr := s.Query(ctx, tt.args)
+			if gotErr != nil {
+				if !tt.wantErr {
+					t.Errorf("Query() failed: %v", gotErr)
+				}
+
+				var qerr *query.Error
+				if !errors.As(gotErr, &qerr) {
+					t.Errorf("Query() error = %v, want a *query.Error", gotErr)
+				}
+				return
+			}
+
+			if tt.wantErr {
+				t.Fatal("Query() succeeded unexpectedly")
+			}
+
+			if diff := cmp.Diff(tt.wantCols, got.Table.Columns); diff != "" {
+				t.Errorf("Query() columns mismatch (-want, +got): \n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.wantRows, cellIDs(got.Table.Rows)); diff != "" {
+				t.Errorf("Query() rows mismatch (-want, +got): \n%s", diff)
+			}
+
+			if tt.wantNodes > 0 && len(got.Graph.Nodes) != tt.wantNodes {
+				t.Errorf("Query() graph nodes = %d, want %d", len(got.Graph.Nodes), tt.wantNodes)
+			}
+		})
+	}
+}
+
+func TestStore_QueryDenseGraph(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Second)
+	defer cancel()
+
+	s, err := sqlite.New(ctx, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer s.Close()
+
+	// a complete digraph has more trails than can be walked in time, the walks must stop early.
+	const size = 7
+
+	nodes := []models.Node{}
+	edges := []models.Edge{}
+
+	for i := uint64(1); i <= size; i++ {
+		nodes = append(nodes, models.Node{ID: i, Label: "node", Properties: models.Properties{"name": fmt.Sprintf("n%d", i)}})
+
+		for j := uint64(1); j <= size; j++ {
+			if i != j {
+				edges = append(edges, models.Edge{From: i, Label: "link", To: j})
+			}
+		}
+	}
+
+	preload(t, s, nodes...)
+	preloadEdges(t, s, edges...)
+
+	tests := []struct {
+		name          string
+		query         string
+		params        map[string]any
+		wantRows      int
+		wantTruncated bool
+	}{
+		{name: "limit pushed into the walk", query: "MATCH (a)-[*]->(b) RETURN b LIMIT 1", wantRows: 1},
+		{name: "either direction", query: "MATCH (a)-[*]-(b) RETURN a, b", wantRows: query.DefaultLimit},
+		{name: "max hops", query: "MATCH (a)-[*1..10]->(b) WHERE b.name = 'n1' RETURN a, b LIMIT 10", wantRows: 10, wantTruncated: true},
+		{
+			name:     "walk within the budget",
+			query:    "MATCH (a {name: $start})-[*1..2]->(b) WHERE b.name = $end RETURN a, b",
+			params:   map[string]any{"start": "n1", "end": "n2"},
+			wantRows: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Query(ctx, store.QueryArgs{Query: tt.query, Params: tt.params})
+			if err != nil {
+				t.Fatalf("Query() failed: %v", err)
+			}
+
+			if len(got.Table.Rows) != tt.wantRows {
+				t.Errorf("Query() rows = %d, want %d", len(got.Table.Rows), tt.wantRows)
+			}
+
+			if got.Truncated != tt.wantTruncated {
+				t.Errorf("Query() truncated = %t, want %t", got.Truncated, tt.wantTruncated)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package sqlite_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/models"
+)
+
+func TestStore_SubGraph(t *testing.T) {
+	/*
+		5 -knows-> 1 -knows-> 2 -knows-> 3 -likes-> 4
+		           1 -likes-> 6
+	*/
+	nodes := []models.Node{
+		{ID: 1, Label: "person"},
+		{ID: 2, Label: "person"},
+		{ID: 3, Label: "person"},
+		{ID: 4, Label: "person"},
+		{ID: 5, Label: "person"},
+		{ID: 6, Label: "person"},
+	}
+
+	edges := []models.Edge{
+		{ID: 10, From: 5, Label: "knows", To: 1},
+		{ID: 11, From: 1, Label: "knows", To: 2},
+		{ID: 12, From: 2, Label: "knows", To: 3},
+		{ID: 13, From: 3, Label: "likes", To: 4},
+		{ID: 14, From: 1, Label: "likes", To: 6},
+	}
+
+	tests := []struct {
+		name      string // description of this test case
+		args      store.SubGraphArgs
+		wantNodes []uint64
+		wantEdges []uint64
+		wantErr   bool
+	}{
+		{
+			name:      "defaults to a single hop in both directions",
+			args:      store.SubGraphArgs{FromNodeID: 1},
+			wantNodes: []uint64{1, 2, 5, 6},
+			wantEdges: []uint64{10, 11, 14},
+		},
+		{
+			name:      "two hops out",
+			args:      store.SubGraphArgs{FromNodeID: 1, Depth: 2, Direction: store.DirectionOut},
+			wantNodes: []uint64{1, 2, 6, 3},
+			wantEdges: []uint64{11, 12, 14},
+		},
+		{
+			name:      "three hops in",
+			args:      store.SubGraphArgs{FromNodeID: 4, Depth: 3, Direction: store.DirectionIn},
+			wantNodes: []uint64{4, 3, 2, 1},
+			wantEdges: []uint64{11, 12, 13},
+		},
+		{
+			name:      "allowed edge labels",
+			args:      store.SubGraphArgs{FromNodeID: 1, Depth: 5, EdgeLabels: []string{"knows"}},
+			wantNodes: []uint64{1, 2, 5, 3},
+			wantEdges: []uint64{10, 11, 12},
+		},
+		{
+			name:      "node budget keeps the closest nodes",
+			args:      store.SubGraphArgs{FromNodeID: 1, Depth: 5, Limit: 3},
+			wantNodes: []uint64{1, 2, 5},
+			wantEdges: []uint64{10, 11},
+		},
+		{
+			name:      "starting from an edge",
+			args:      store.SubGraphArgs{EdgeID: 12, Depth: 1, Direction: store.DirectionOut},
+			wantNodes: []uint64{2, 3, 4},
+			wantEdges: []uint64{12, 13},
+		},
+		{
+			name:      "depth is clamped and stops once no new nodes are reached",
+			args:      store.SubGraphArgs{FromNodeID: 1, Depth: 1_000_000},
+			wantNodes: []uint64{1, 2, 3, 4, 5, 6},
+			wantEdges: []uint64{10, 11, 12, 13, 14},
+		},
+		{
+			name:    "unsupported direction",
+			args:    store.SubGraphArgs{FromNodeID: 1, Direction: "sideways"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+
+			s, err := sqlite.New(ctx, ":memory:")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			preload(t, s, nodes...)
+			preloadEdges(t, s, edges...)
+
+			got, gotErr := s.SubGraph(ctx, tt.args)
+			if gotErr != nil {
+				if !tt.wantErr {
+					t.Errorf("SubGraph() failed: %v", gotErr)
+				}
+				return
+			}
+
+			if tt.wantErr {
+				t.Fatal("SubGraph() succeeded unexpectedly")
+			}
+
+			gotNodes := []uint64{}
+			for _, n := range got.Nodes {
+				gotNodes = append(gotNodes, n.ID)
+			}
+
+			gotEdges := []uint64{}
+			for _, e := range got.Edges {
+				gotEdges = append(gotEdges, e.ID)
+			}
+
+			sortIDs := cmpopts.SortSlices(func(a, b uint64) bool { return a < b })
+
+			if diff := cmp.Diff(tt.wantNodes, gotNodes, cmpopts.EquateEmpty(), sortIDs); diff != "" {
+				t.Errorf("SubGraph() nodes mismatch (-want, +got): \n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.wantEdges, gotEdges, cmpopts.EquateEmpty(), sortIDs); diff != "" {
+				t.Errorf("SubGraph() edges mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
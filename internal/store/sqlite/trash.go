@@ -0,0 +1,323 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// TrashNodes moves the nodes with the given IDs to the trash applying the delete policy to the attached edges.
+// The edges trashed by store.DeleteCascade are restored with their node, the edges trashed by store.DeleteDetach are
+// restored on their own. IDs which do not exist, are not nodes, or are already trashed are ignored.
+func (s *Store) TrashNodes(ctx context.Context, args store.DeleteNodesArgs) (store.DeleteResult, error) {
+	return s.deleteNodes(ctx, args, func(tx *sql.Tx, nodeIDs, edgeIDs []uint64) error {
+		var with []uint64
+		if args.Policy == store.DeleteCascade {
+			with = nodeIDs
+		}
+
+		if err := trashByID(ctx, tx, with, edgeIDs...); err != nil {
+			return err
+		}
+
+		return trashByID(ctx, tx, nil, nodeIDs...)
+	})
+}
+
+// TrashEdges moves the edges with the given IDs to the trash returning the trashed edges.
+// IDs which do not exist, are not edges, or are already trashed are ignored.
+func (s *Store) TrashEdges(ctx context.Context, ids ...uint64) ([]models.Edge, error) {
+	return s.deleteEdges(ctx, ids, func(tx *sql.Tx, edgeIDs []uint64) error {
+		return trashByID(ctx, tx, nil, edgeIDs...)
+	})
+}
+
+// trashByID is a helper used to move all the items with the given IDs to the trash, edges attached to one of the
+// nodes in with are restored with the node.
+func trashByID(ctx context.Context, q querier, with []uint64, ids ...uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if with == nil {
+		with = []uint64{}
+	}
+
+	withJSON, err := json.Marshal(with)
+	if err != nil {
+		return err
+	}
+
+	marks, args := placeholders(ids...)
+
+	query := fmt.Sprintf(
+		`
+		UPDATE items SET
+			deleted_at = CAST(strftime('%%s', 'now') AS INTEGER),
+			version = version + 1,
+			deleted_with = CASE
+				WHEN from_id IN (SELECT value FROM json_each(?1)) THEN from_id
+				WHEN to_id IN (SELECT value FROM json_each(?1)) THEN to_id
+			END
+		WHERE id IN (%s);
+		`,
+		marks,
+	)
+
+	_, err = q.ExecContext(ctx, query, append([]any{string(withJSON)}, args...)...)
+	return err
+}
+
+// Trash returns the trashed nodes and edges ordered by ID. Limit defaults to 1000 if limit is 0.
+func (s *Store) Trash(ctx context.Context, args store.TrashArgs) (store.Trash, error) {
+	trash := store.Trash{
+		Nodes: make([]store.TrashedNode, 0),
+		Edges: make([]store.TrashedEdge, 0),
+	}
+
+	if args.Limit == 0 {
+		args.Limit = DefaultLimit
+	}
+
+	query := `
+	SELECT i.id, i.created_at, i.updated_at, i.version, i.from_id, i.label, i.to_id, i.weight, i.properties, i.deleted_at, i.deleted_with
+	FROM items i
+	WHERE i.deleted_at IS NOT NULL AND i.id > ?
+	ORDER BY i.id
+	LIMIT ?;
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, args.LastID, args.Limit)
+	if err != nil {
+		return trash, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var createdAt, updatedAt, deletedAt int64
+		var deletedWith sql.NullInt64
+		var props []byte
+
+		e := models.Edge{}
+
+		if err := rows.Scan(&e.ID, &createdAt, &updatedAt, &e.Version, &e.From, &e.Label, &e.To, &e.Weight, &props, &deletedAt, &deletedWith); err != nil {
+			return trash, err
+		}
+
+		if err := e.Properties.FromBytes(props); err != nil {
+			return trash, err
+		}
+
+		e.CreatedAt = time.Unix(createdAt, 0)
+		e.UpdatedAt = time.Unix(updatedAt, 0)
+
+		if e.From == 0 && e.To == 0 {
+			n := models.Node{ID: e.ID, CreatedAt: e.CreatedAt, UpdatedAt: e.UpdatedAt, Version: e.Version, Label: e.Label, Properties: e.Properties}
+			trash.Nodes = append(trash.Nodes, store.TrashedNode{Node: n, DeletedAt: time.Unix(deletedAt, 0)})
+			continue
+		}
+
+		trash.Edges = append(trash.Edges, store.TrashedEdge{Edge: e, DeletedAt: time.Unix(deletedAt, 0), DeletedWith: uint64(deletedWith.Int64)})
+	}
+
+	return trash, rows.Err()
+}
+
+// RestoreNode restores the node and the edges trashed with it, returning store.ErrNotFound if the node is not in the
+// trash. Edges to nodes which are still in the trash are restored with the other node instead.
+func (s *Store) RestoreNode(ctx context.Context, id uint64) (store.RestoreResult, error) {
+	result := store.RestoreResult{
+		Nodes: make([]models.Node, 0),
+		Edges: make([]models.Edge, 0),
+	}
+
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	defer tx.Rollback()
+
+	restored, err := tx.ExecContext(
+		ctx,
+		`UPDATE items SET deleted_at = NULL, deleted_with = NULL, version = version + 1 WHERE id = ? AND from_id = 0 AND to_id = 0 AND deleted_at IS NOT NULL;`,
+		id,
+	)
+
+	if err != nil {
+		return result, err
+	}
+
+	affected, err := restored.RowsAffected()
+	if err != nil {
+		return result, err
+	}
+
+	if affected == 0 {
+		return result, fmt.Errorf("%w: node %d is not in the trash", store.ErrNotFound, id)
+	}
+
+	rows, err := tx.QueryContext(
+		ctx,
+		`
+		UPDATE items AS e SET deleted_at = NULL, deleted_with = NULL, version = version + 1
+		WHERE
+			e.deleted_with = ?1
+			AND e.deleted_at IS NOT NULL
+			AND NOT EXISTS (SELECT 1 FROM items n WHERE n.id IN (e.from_id, e.to_id) AND n.deleted_at IS NOT NULL)
+		RETURNING id;
+		`,
+		id,
+	)
+
+	if err != nil {
+		return result, err
+	}
+
+	edgeIDs := []uint64{}
+
+	for rows.Next() {
+		var edgeID uint64
+		if err := rows.Scan(&edgeID); err != nil {
+			rows.Close()
+			return result, err
+		}
+
+		edgeIDs = append(edgeIDs, edgeID)
+	}
+
+	if err := rows.Close(); err != nil {
+		return result, err
+	}
+
+	// the edges to nodes which are still in the trash come back with the other node.
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE items SET deleted_with = CASE WHEN from_id = ?1 THEN to_id ELSE from_id END WHERE deleted_with = ?1;`,
+		id,
+	)
+
+	if err != nil {
+		return result, err
+	}
+
+	n, err := node(ctx, tx, id)
+	if err != nil {
+		return result, err
+	}
+
+	if len(edgeIDs) > 0 {
+		edges, err := edgesByID(ctx, tx, edgeIDs...)
+		if err != nil {
+			return result, err
+		}
+
+		result.Edges = edges
+	}
+
+	result.Nodes = append(result.Nodes, n)
+	return result, tx.Commit()
+}
+
+// RestoreEdge restores the edge, returning store.ErrNotFound if the edge is not in the trash and store.ErrConflict if
+// one of its nodes is in the trash.
+func (s *Store) RestoreEdge(ctx context.Context, id uint64) (store.RestoreResult, error) {
+	result := store.RestoreResult{
+		Nodes: make([]models.Node, 0),
+		Edges: make([]models.Edge, 0),
+	}
+
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	defer tx.Rollback()
+
+	var from, to uint64
+
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT from_id, to_id FROM items WHERE id = ? AND from_id > 0 AND to_id > 0 AND deleted_at IS NOT NULL;`,
+		id,
+	).Scan(&from, &to)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return result, fmt.Errorf("%w: edge %d is not in the trash", store.ErrNotFound, id)
+		}
+		return result, err
+	}
+
+	for _, nodeID := range []uint64{from, to} {
+		if _, err := node(ctx, tx, nodeID); err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return result, fmt.Errorf("%w: edge %d is attached to node %d which is in the trash", store.ErrConflict, id, nodeID)
+			}
+			return result, err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE items SET deleted_at = NULL, deleted_with = NULL, version = version + 1 WHERE id = ?;`, id); err != nil {
+		return result, err
+	}
+
+	e, err := edge(ctx, tx, id)
+	if err != nil {
+		return result, err
+	}
+
+	result.Edges = append(result.Edges, e)
+	return result, tx.Commit()
+}
+
+// Purge removes the items trashed before the time from the store.
+func (s *Store) Purge(ctx context.Context, before time.Time) (store.PurgeResult, error) {
+	result := store.PurgeResult{}
+
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	defer tx.Rollback()
+
+	// purge the edges before the nodes so that the `items_after_delete` trigger does not remove them uncounted.
+	edges, err := tx.ExecContext(ctx, `DELETE FROM items WHERE deleted_at < ? AND from_id > 0 AND to_id > 0;`, before.Unix())
+	if err != nil {
+		return result, err
+	}
+
+	purged, err := edges.RowsAffected()
+	if err != nil {
+		return result, err
+	}
+
+	result.Edges = int(purged)
+
+	nodes, err := tx.ExecContext(ctx, `DELETE FROM items WHERE deleted_at < ? AND from_id = 0 AND to_id = 0;`, before.Unix())
+	if err != nil {
+		return result, err
+	}
+
+	purged, err = nodes.RowsAffected()
+	if err != nil {
+		return result, err
+	}
+
+	result.Nodes = int(purged)
+
+	if result.Nodes > 0 || result.Edges > 0 {
+		s.logger.Info("trash purged", slog.Int("nodes", result.Nodes), slog.Int("edges", result.Edges))
+	}
+
+	return result, tx.Commit()
+}
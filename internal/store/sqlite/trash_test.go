@@ -0,0 +1,294 @@
+package sqlite_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/models"
+)
+
+// trashIDs returns the IDs of the trashed nodes and edges, edges are returned as `id:deleted_with`.
+func trashIDs(t *testing.T, s *sqlite.Store) ([]uint64, [][2]uint64) {
+	t.Helper()
+
+	trash, err := s.Trash(t.Context(), store.TrashArgs{})
+	if err != nil {
+		t.Fatalf("Trash() failed: %v", err)
+	}
+
+	nodes := []uint64{}
+	for _, n := range trash.Nodes {
+		nodes = append(nodes, n.ID)
+	}
+
+	edges := [][2]uint64{}
+	for _, e := range trash.Edges {
+		edges = append(edges, [2]uint64{e.ID, e.DeletedWith})
+	}
+
+	return nodes, edges
+}
+
+// edgeIDs returns the IDs of the edges.
+func edgeIDs(edges []models.Edge) []uint64 {
+	ids := []uint64{}
+	for _, e := range edges {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestStore_Trash(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := sqlite.New(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	defer s.Close()
+
+	/*
+		1 -knows-> 2 -knows-> 3
+		1 -likes-> 3
+	*/
+	preload(t, s,
+		models.Node{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
+		models.Node{ID: 2, Label: "person", Properties: models.Properties{"name": "bar"}},
+		models.Node{ID: 3, Label: "person", Properties: models.Properties{"name": "baz"}},
+	)
+
+	preloadEdges(t, s,
+		models.Edge{ID: 10, From: 1, Label: "knows", To: 2, Properties: models.Properties{}},
+		models.Edge{ID: 11, From: 2, Label: "knows", To: 3, Properties: models.Properties{}},
+		models.Edge{ID: 12, From: 1, Label: "likes", To: 3, Properties: models.Properties{}},
+	)
+
+	if _, err := s.TrashNodes(ctx, store.DeleteNodesArgs{IDs: []uint64{2}}); err == nil {
+		t.Fatalf("TrashNodes() with attached edges = nil, want a restrict error")
+	}
+
+	result, err := s.TrashNodes(ctx, store.DeleteNodesArgs{IDs: []uint64{2}, Policy: store.DeleteCascade})
+	if err != nil {
+		t.Fatalf("TrashNodes() failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]uint64{10, 11}, edgeIDs(result.Edges)); diff != "" {
+		t.Errorf("TrashNodes() edges = mismatch (-want, +got): \n%s", diff)
+	}
+
+	// trashed items are left out of the searches.
+	nodes, err := s.Nodes(ctx, store.NodesArgs{})
+	if err != nil {
+		t.Fatalf("Nodes() failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]uint64{1, 3}, nodeIDs(nodes)); diff != "" {
+		t.Errorf("Nodes() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	if ids := termIDs(t, s, "bar"); len(ids) != 0 {
+		t.Errorf("NodesTermSearch() = %v, want no matches for a trashed node", ids)
+	}
+
+	if _, err := s.Node(ctx, 2); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Node() error = %v, want store.ErrNotFound", err)
+	}
+
+	sub, err := s.SubGraph(ctx, store.SubGraphArgs{FromNodeID: 1, Depth: 2})
+	if err != nil {
+		t.Fatalf("SubGraph() failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]uint64{12}, edgeIDs(sub.Edges)); diff != "" {
+		t.Errorf("SubGraph() edges = mismatch (-want, +got): \n%s", diff)
+	}
+
+	query, err := s.Query(ctx, store.QueryArgs{Query: "MATCH (a)-[r]->(b) RETURN r"})
+	if err != nil {
+		t.Fatalf("Query() failed: %v", err)
+	}
+
+	if diff := cmp.Diff([][]any{{uint64(12)}}, cellIDs(query.Table.Rows)); diff != "" {
+		t.Errorf("Query() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	if _, err := s.TrashNodes(ctx, store.DeleteNodesArgs{IDs: []uint64{3}, Policy: store.DeleteDetach}); err != nil {
+		t.Fatalf("TrashNodes() failed: %v", err)
+	}
+
+	gotNodes, gotEdges := trashIDs(t, s)
+
+	if diff := cmp.Diff([]uint64{2, 3}, gotNodes); diff != "" {
+		t.Errorf("Trash() nodes = mismatch (-want, +got): \n%s", diff)
+	}
+
+	// edges trashed by a cascade are restored with their node, detached edges are restored on their own.
+	if diff := cmp.Diff([][2]uint64{{10, 2}, {11, 2}, {12, 0}}, gotEdges); diff != "" {
+		t.Errorf("Trash() edges = mismatch (-want, +got): \n%s", diff)
+	}
+
+	if _, err := s.RestoreEdge(ctx, 12); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("RestoreEdge() to a trashed node error = %v, want store.ErrConflict", err)
+	}
+
+	restored, err := s.RestoreNode(ctx, 2)
+	if err != nil {
+		t.Fatalf("RestoreNode() failed: %v", err)
+	}
+
+	// 11 is attached to 3 which is still in the trash, so it comes back with 3.
+	if diff := cmp.Diff([]uint64{10}, edgeIDs(restored.Edges)); diff != "" {
+		t.Errorf("RestoreNode() edges = mismatch (-want, +got): \n%s", diff)
+	}
+
+	_, gotEdges = trashIDs(t, s)
+	if diff := cmp.Diff([][2]uint64{{11, 3}, {12, 0}}, gotEdges); diff != "" {
+		t.Errorf("Trash() edges = mismatch (-want, +got): \n%s", diff)
+	}
+
+	if _, err := s.RestoreNode(ctx, 2); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("RestoreNode() of a restored node error = %v, want store.ErrNotFound", err)
+	}
+
+	restored, err = s.RestoreNode(ctx, 3)
+	if err != nil {
+		t.Fatalf("RestoreNode() failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]uint64{11}, edgeIDs(restored.Edges)); diff != "" {
+		t.Errorf("RestoreNode() edges = mismatch (-want, +got): \n%s", diff)
+	}
+
+	if _, err := s.RestoreEdge(ctx, 12); err != nil {
+		t.Fatalf("RestoreEdge() failed: %v", err)
+	}
+
+	edges, err := s.Edges(ctx, store.EdgesArgs{})
+	if err != nil {
+		t.Fatalf("Edges() failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]uint64{10, 11, 12}, edgeIDs(edges)); diff != "" {
+		t.Errorf("Edges() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	// trashing and restoring is recorded in the history.
+	history, err := s.NodeHistory(ctx, 2)
+	if err != nil {
+		t.Fatalf("NodeHistory() failed: %v", err)
+	}
+
+	ops := []models.ChangeOp{}
+	for _, rev := range history {
+		ops = append(ops, rev.Op)
+	}
+
+	if diff := cmp.Diff([]models.ChangeOp{models.ChangeCreate, models.ChangeDelete, models.ChangeCreate}, ops); diff != "" {
+		t.Errorf("NodeHistory() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	// upserting a trashed node restores it.
+	if _, err := s.TrashEdges(ctx, 10); err != nil {
+		t.Fatalf("TrashEdges() failed: %v", err)
+	}
+
+	preloadEdges(t, s, models.Edge{ID: 10, From: 1, Label: "knows", To: 2, Properties: models.Properties{"since": float64(2020)}})
+
+	if e, err := s.Edge(ctx, 10); err != nil || e.Properties["since"] != float64(2020) {
+		t.Errorf("Edge() = %+v, %v, want the upserted edge", e, err)
+	}
+
+	gotNodes, gotEdges = trashIDs(t, s)
+	if diff := cmp.Diff([]uint64{}, gotNodes, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("Trash() nodes = mismatch (-want, +got): \n%s", diff)
+	}
+
+	if diff := cmp.Diff([][2]uint64{}, gotEdges, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("Trash() edges = mismatch (-want, +got): \n%s", diff)
+	}
+}
+
+func TestStore_Purge(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := sqlite.New(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	defer s.Close()
+
+	preload(t, s,
+		models.Node{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
+		models.Node{ID: 2, Label: "person", Properties: models.Properties{"name": "bar"}},
+		models.Node{ID: 3, Label: "person", Properties: models.Properties{"name": "baz"}},
+	)
+
+	preloadEdges(t, s,
+		models.Edge{ID: 10, From: 1, Label: "knows", To: 2, Properties: models.Properties{}},
+		models.Edge{ID: 11, From: 2, Label: "knows", To: 3, Properties: models.Properties{}},
+	)
+
+	if _, err := s.TrashNodes(ctx, store.DeleteNodesArgs{IDs: []uint64{1}, Policy: store.DeleteCascade}); err != nil {
+		t.Fatalf("TrashNodes() failed: %v", err)
+	}
+
+	// move the first delete back in time so it is past the retention.
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		t.Fatalf("Tx() failed: %v", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE items SET deleted_at = deleted_at - 7200 WHERE deleted_at IS NOT NULL;`); err != nil {
+		t.Fatalf("backdating the trash failed: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+
+	if _, err := s.TrashEdges(ctx, 11); err != nil {
+		t.Fatalf("TrashEdges() failed: %v", err)
+	}
+
+	got, err := s.Purge(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Purge() failed: %v", err)
+	}
+
+	if diff := cmp.Diff(store.PurgeResult{Nodes: 1, Edges: 1}, got); diff != "" {
+		t.Errorf("Purge() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	if _, err := s.RestoreNode(ctx, 1); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("RestoreNode() of a purged node error = %v, want store.ErrNotFound", err)
+	}
+
+	_, gotEdges := trashIDs(t, s)
+	if diff := cmp.Diff([][2]uint64{{11, 0}}, gotEdges); diff != "" {
+		t.Errorf("Trash() edges = mismatch (-want, +got): \n%s", diff)
+	}
+
+	// the purge is not recorded again, the items were recorded as deleted when they were trashed.
+	changes, err := s.Changes(ctx, store.ChangesArgs{})
+	if err != nil {
+		t.Fatalf("Changes() failed: %v", err)
+	}
+
+	deletes := 0
+	for _, c := range changes {
+		if c.Op == models.ChangeDelete {
+			deletes++
+		}
+	}
+
+	if deletes != 3 {
+		t.Errorf("Changes() deletes = %d, want 3", deletes)
+	}
+}
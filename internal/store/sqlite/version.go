@@ -0,0 +1,42 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jenmud/edgedb/internal/store"
+)
+
+// checkNodeVersion returns a *store.VersionError holding the current node if the expected version is not the current
+// version of the node.
+func checkNodeVersion(ctx context.Context, q querier, id, expected uint64) error {
+	n, err := node(ctx, q, id)
+
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return &store.VersionError{ID: id, Expected: expected}
+	case err != nil:
+		return err
+	case n.Version != expected:
+		return &store.VersionError{ID: id, Expected: expected, Node: &n}
+	}
+
+	return nil
+}
+
+// checkEdgeVersion returns a *store.VersionError holding the current edge if the expected version is not the current
+// version of the edge.
+func checkEdgeVersion(ctx context.Context, q querier, id, expected uint64) error {
+	e, err := edge(ctx, q, id)
+
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return &store.VersionError{ID: id, Expected: expected}
+	case err != nil:
+		return err
+	case e.Version != expected:
+		return &store.VersionError{ID: id, Expected: expected, Edge: &e}
+	}
+
+	return nil
+}
@@ -0,0 +1,311 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// scanWebhook scans a row selected as `id, url, labels, ops, created_at` into a webhook.
+func scanWebhook(row scanner) (models.Webhook, error) {
+	w := models.Webhook{}
+
+	var labels, ops []byte
+	var createdAt int64
+
+	if err := row.Scan(&w.ID, &w.URL, &labels, &ops, &createdAt); err != nil {
+		return w, err
+	}
+
+	if err := json.Unmarshal(labels, &w.Labels); err != nil {
+		return w, err
+	}
+
+	if err := json.Unmarshal(ops, &w.Ops); err != nil {
+		return w, err
+	}
+
+	w.CreatedAt = time.Unix(createdAt, 0)
+	return w, nil
+}
+
+// webhook returns the webhook with the ID without its secret.
+func webhook(ctx context.Context, q querier, id uint64) (models.Webhook, error) {
+	row := q.QueryRowContext(ctx, `SELECT id, url, labels, ops, created_at FROM webhooks WHERE id = ?;`, id)
+
+	w, err := scanWebhook(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Webhook{}, fmt.Errorf("%w: webhook %d", store.ErrNotFound, id)
+		}
+		return models.Webhook{}, err
+	}
+
+	return w, nil
+}
+
+// Webhooks returns all the webhooks ordered by ID, the secrets are not returned.
+func (s *Store) Webhooks(ctx context.Context) ([]models.Webhook, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, url, labels, ops, created_at FROM webhooks ORDER BY id;`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return webhooks, err
+		}
+
+		webhooks = append(webhooks, w)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// Webhook returns the webhook with the ID, returning store.ErrNotFound if there is no such webhook.
+func (s *Store) Webhook(ctx context.Context, id uint64) (models.Webhook, error) {
+	return webhook(ctx, s.db, id)
+}
+
+// CreateWebhook creates the webhook returning it with its secret, returning store.ErrInvalid if the webhook is not valid.
+func (s *Store) CreateWebhook(ctx context.Context, w models.Webhook) (models.Webhook, error) {
+	if err := store.ValidateWebhook(&w); err != nil {
+		return models.Webhook{}, err
+	}
+
+	if w.Labels == nil {
+		w.Labels = []string{}
+	}
+
+	if w.Ops == nil {
+		w.Ops = []models.ChangeOp{}
+	}
+
+	labels, err := json.Marshal(w.Labels)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	ops, err := json.Marshal(w.Ops)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	row := s.db.QueryRowContext(
+		ctx,
+		`INSERT INTO webhooks (url, secret, labels, ops) VALUES (?, ?, ?, ?) RETURNING id, url, labels, ops, created_at;`,
+		w.URL, w.Secret, string(labels), string(ops),
+	)
+
+	created, err := scanWebhook(row)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	created.Secret = w.Secret
+	return created, nil
+}
+
+// DeleteWebhook deletes the webhook and its deliveries returning the deleted webhook,
+// returning store.ErrNotFound if there is no such webhook.
+func (s *Store) DeleteWebhook(ctx context.Context, id uint64) (models.Webhook, error) {
+	tx, err := s.Tx(ctx)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	defer tx.Rollback()
+
+	w, err := webhook(ctx, tx, id)
+	if err != nil {
+		return w, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE webhook_id = ?;`, id); err != nil {
+		return w, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM webhooks WHERE id = ?;`, id); err != nil {
+		return w, err
+	}
+
+	return w, tx.Commit()
+}
+
+// deliveryColumns are the columns selected by scanDelivery.
+const deliveryColumns = `
+	d.id,
+	d.webhook_id,
+	d.seq,
+	d.status,
+	d.attempts,
+	d.response_code,
+	d.error,
+	d.next_attempt_at,
+	d.created_at,
+	d.updated_at
+`
+
+// scanDelivery scans a row selected using deliveryColumns into a delivery.
+func scanDelivery(row scanner, dest ...any) (models.Delivery, error) {
+	d := models.Delivery{}
+
+	var nextAttemptAt, createdAt, updatedAt int64
+
+	dest = append(
+		[]any{&d.ID, &d.WebhookID, &d.Seq, &d.Status, &d.Attempts, &d.ResponseCode, &d.Error, &nextAttemptAt, &createdAt, &updatedAt},
+		dest...,
+	)
+
+	if err := row.Scan(dest...); err != nil {
+		return d, err
+	}
+
+	d.NextAttemptAt = time.UnixMilli(nextAttemptAt)
+	d.CreatedAt = time.Unix(createdAt, 0)
+	d.UpdatedAt = time.Unix(updatedAt, 0)
+
+	return d, nil
+}
+
+// Deliveries returns the deliveries of a webhook ordered by ID, returning store.ErrNotFound if there is no such webhook.
+func (s *Store) Deliveries(ctx context.Context, args store.DeliveriesArgs) ([]models.Delivery, error) {
+	if _, err := webhook(ctx, s.db, args.WebhookID); err != nil {
+		return nil, err
+	}
+
+	if args.Limit <= 0 {
+		args.Limit = DefaultLimit
+	}
+
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries d WHERE d.webhook_id = ? AND d.id > ?`
+	params := []any{args.WebhookID, args.LastID}
+
+	if args.Status != "" {
+		query += ` AND d.status = ?`
+		params = append(params, args.Status)
+	}
+
+	query += ` ORDER BY d.id LIMIT ?;`
+	params = append(params, args.Limit)
+
+	rows, err := s.db.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	deliveries := []models.Delivery{}
+
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return deliveries, err
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// DueDeliveries returns the pending deliveries due to be sent at the time ordered by ID. Deliveries behind a pending
+// delivery of the same webhook which is waiting to be retried are held back, so each webhook receives its changes in order.
+func (s *Store) DueDeliveries(ctx context.Context, at time.Time, limit int) ([]store.DueDelivery, error) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	/*
+		ordering by ID keeps the earlier deliveries of a webhook in the same batch as, or an earlier batch than,
+		its later deliveries.
+	*/
+	query := `
+	SELECT ` + deliveryColumns + `, w.url, w.secret
+	FROM webhook_deliveries d
+	INNER JOIN webhooks w ON w.id = d.webhook_id
+	WHERE
+		d.status = ?1
+		AND d.next_attempt_at <= ?2
+		AND NOT EXISTS (
+			SELECT 1
+			FROM webhook_deliveries p
+			WHERE p.webhook_id = d.webhook_id AND p.id < d.id AND p.status = ?1 AND p.next_attempt_at > ?2
+		)
+	ORDER BY d.id
+	LIMIT ?3;
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, models.DeliveryPending, at.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	due := []store.DueDelivery{}
+
+	for rows.Next() {
+		dd := store.DueDelivery{}
+
+		if dd.Delivery, err = scanDelivery(rows, &dd.URL, &dd.Secret); err != nil {
+			return due, err
+		}
+
+		due = append(due, dd)
+	}
+
+	if err := rows.Err(); err != nil {
+		return due, err
+	}
+
+	// the store only has a single connection, so the changes are read once the deliveries are closed.
+	rows.Close()
+
+	for i, dd := range due {
+		if due[i].Change, err = change(ctx, s.db, dd.Delivery.Seq); err != nil {
+			return due, err
+		}
+	}
+
+	return due, nil
+}
+
+// RecordAttempt records the outcome of sending the delivery with the ID.
+func (s *Store) RecordAttempt(ctx context.Context, id uint64, attempt store.DeliveryAttempt) error {
+	query := `
+	UPDATE webhook_deliveries SET
+		status = ?,
+		attempts = attempts + 1,
+		response_code = ?,
+		error = ?,
+		next_attempt_at = ?,
+		updated_at = strftime('%s', 'now')
+	WHERE id = ?;
+	`
+
+	res, err := s.db.ExecContext(ctx, query, attempt.Status, attempt.ResponseCode, attempt.Error, attempt.NextAttemptAt.UnixMilli(), id)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("%w: webhook delivery %d", store.ErrNotFound, id)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package sqlite_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/models"
+)
+
+func TestStore_Webhooks(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := sqlite.New(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	defer s.Close()
+
+	// changes made before the webhook is created are not delivered.
+	preload(t, s, models.Node{ID: 1, Label: "person"})
+
+	created, err := s.CreateWebhook(ctx, models.Webhook{URL: "http://localhost:9000/hook", Labels: []string{"person"}})
+	if err != nil {
+		t.Fatalf("CreateWebhook() failed: %v", err)
+	}
+
+	if created.ID != 1 || len(created.Secret) != 64 {
+		t.Errorf("CreateWebhook() = %+v, want a webhook with a generated secret", created)
+	}
+
+	got, err := s.Webhook(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Webhook() failed: %v", err)
+	}
+
+	want := models.Webhook{ID: 1, URL: "http://localhost:9000/hook", Labels: []string{"person"}}
+	if diff := cmp.Diff(want, got, cmpopts.EquateEmpty(), cmpopts.IgnoreFields(models.Webhook{}, "CreatedAt")); diff != "" {
+		t.Errorf("Webhook() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	invalid := []models.Webhook{
+		{URL: "localhost:9000"},
+		{URL: "ftp://localhost/hook"},
+		{URL: "http://localhost/hook", Ops: []models.ChangeOp{"merge"}},
+	}
+
+	for _, w := range invalid {
+		if _, err := s.CreateWebhook(ctx, w); !errors.Is(err, store.ErrInvalid) {
+			t.Errorf("CreateWebhook(%+v) error = %v, want store.ErrInvalid", w, err)
+		}
+	}
+
+	preload(t, s, models.Node{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}}, models.Node{ID: 2, Label: "dog"})
+
+	due, err := s.DueDeliveries(ctx, time.Now(), 0)
+	if err != nil {
+		t.Fatalf("DueDeliveries() failed: %v", err)
+	}
+
+	if len(due) != 1 || due[0].URL != created.URL || due[0].Secret != created.Secret || due[0].Change.Op != models.ChangeUpdate {
+		t.Fatalf("DueDeliveries() = %+v, want the update of node 1", due)
+	}
+
+	next := time.Now().Add(time.Hour)
+	attempt := store.DeliveryAttempt{Status: models.DeliveryPending, ResponseCode: 500, Error: "receiver returned 500", NextAttemptAt: next}
+
+	if err := s.RecordAttempt(ctx, due[0].Delivery.ID, attempt); err != nil {
+		t.Fatalf("RecordAttempt() failed: %v", err)
+	}
+
+	if err := s.RecordAttempt(ctx, 42, attempt); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("RecordAttempt() error = %v, want store.ErrNotFound", err)
+	}
+
+	// the delivery is no longer due until the next attempt.
+	if due, err := s.DueDeliveries(ctx, time.Now(), 0); err != nil || len(due) != 0 {
+		t.Errorf("DueDeliveries() = %+v, %v, want no due deliveries", due, err)
+	}
+
+	deliveries, err := s.Deliveries(ctx, store.DeliveriesArgs{WebhookID: created.ID})
+	if err != nil {
+		t.Fatalf("Deliveries() failed: %v", err)
+	}
+
+	wantDeliveries := []models.Delivery{
+		{
+			ID:            1,
+			WebhookID:     1,
+			Seq:           2,
+			Status:        models.DeliveryPending,
+			Attempts:      1,
+			ResponseCode:  500,
+			Error:         "receiver returned 500",
+			NextAttemptAt: time.UnixMilli(next.UnixMilli()),
+		},
+	}
+
+	if diff := cmp.Diff(wantDeliveries, deliveries, cmpopts.IgnoreFields(models.Delivery{}, "CreatedAt", "UpdatedAt")); diff != "" {
+		t.Errorf("Deliveries() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	// later changes wait for the earlier delivery so the webhook receives the changes in order.
+	preload(t, s, models.Node{ID: 1, Label: "person", Properties: models.Properties{"name": "bar"}})
+
+	if due, err := s.DueDeliveries(ctx, time.Now(), 0); err != nil || len(due) != 0 {
+		t.Errorf("DueDeliveries() = %+v, %v, want the later delivery held back", due, err)
+	}
+
+	due, err = s.DueDeliveries(ctx, next.Add(time.Minute), 0)
+	if err != nil {
+		t.Fatalf("DueDeliveries() failed: %v", err)
+	}
+
+	dueIDs := []uint64{}
+	for _, dd := range due {
+		dueIDs = append(dueIDs, dd.Delivery.ID)
+	}
+
+	if diff := cmp.Diff([]uint64{1, 2}, dueIDs); diff != "" {
+		t.Errorf("DueDeliveries() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	if _, err := s.DeleteWebhook(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteWebhook() failed: %v", err)
+	}
+
+	if _, err := s.Deliveries(ctx, store.DeliveriesArgs{WebhookID: created.ID}); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Deliveries() error = %v, want store.ErrNotFound", err)
+	}
+
+	if _, err := s.DeleteWebhook(ctx, created.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("DeleteWebhook() error = %v, want store.ErrNotFound", err)
+	}
+}
@@ -0,0 +1,273 @@
+package storetest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// ignoreKeyTimes ignores the key times which differ between runs and backends.
+var ignoreKeyTimes = cmp.Options{
+	cmpopts.EquateEmpty(),
+	cmpopts.IgnoreFields(models.NaturalKey{}, "CreatedAt"),
+}
+
+func testKeys(t *testing.T, s store.Store) {
+	ctx := t.Context()
+
+	if _, err := s.Key(ctx, "person"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Key() error = %v, want store.ErrNotFound", err)
+	}
+
+	// the properties prefix is optional.
+	for _, k := range []models.NaturalKey{{Label: "person", Property: "properties.name"}, {Label: "dog", Property: "meta.chip"}} {
+		if _, err := s.UpsertKey(ctx, k); err != nil {
+			t.Fatalf("UpsertKey() failed: %v", err)
+		}
+	}
+
+	got, err := s.Key(ctx, "person")
+	if err != nil {
+		t.Fatalf("Key() failed: %v", err)
+	}
+
+	if diff := cmp.Diff(models.NaturalKey{Label: "person", Property: "name"}, got, ignoreKeyTimes); diff != "" {
+		t.Errorf("Key() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	// upserting replaces the key.
+	if _, err := s.UpsertKey(ctx, models.NaturalKey{Label: "dog", Property: "chip"}); err != nil {
+		t.Fatalf("UpsertKey() failed: %v", err)
+	}
+
+	all, err := s.Keys(ctx)
+	if err != nil {
+		t.Fatalf("Keys() failed: %v", err)
+	}
+
+	want := []models.NaturalKey{{Label: "dog", Property: "chip"}, {Label: "person", Property: "name"}}
+	if diff := cmp.Diff(want, all, ignoreKeyTimes); diff != "" {
+		t.Errorf("Keys() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	deleted, err := s.DeleteKey(ctx, "dog")
+	if err != nil {
+		t.Fatalf("DeleteKey() failed: %v", err)
+	}
+
+	if diff := cmp.Diff(want[0], deleted, ignoreKeyTimes); diff != "" {
+		t.Errorf("DeleteKey() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	if _, err := s.DeleteKey(ctx, "dog"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("DeleteKey() error = %v, want store.ErrNotFound", err)
+	}
+
+	invalid := []models.NaturalKey{
+		{Property: "name"},
+		{Label: "person"},
+		{Label: "person", Property: "name'); DROP TABLE items; --"},
+	}
+
+	for _, k := range invalid {
+		if _, err := s.UpsertKey(ctx, k); !errors.Is(err, store.ErrInvalid) {
+			t.Errorf("UpsertKey(%+v) error = %v, want store.ErrInvalid", k, err)
+		}
+	}
+
+	// keys can not be added if existing nodes have the same key value.
+	if _, err := s.UpsertNodes(ctx, models.Node{Label: "cat", Properties: models.Properties{"name": "tom"}}, models.Node{Label: "cat", Properties: models.Properties{"name": "tom"}}); err != nil {
+		t.Fatalf("UpsertNodes() failed: %v", err)
+	}
+
+	if _, err := s.UpsertKey(ctx, models.NaturalKey{Label: "cat", Property: "name"}); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("UpsertKey() error = %v, want store.ErrConflict", err)
+	}
+
+	if _, err := s.Key(ctx, "cat"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Key() error = %v, want store.ErrNotFound", err)
+	}
+}
+
+func testMergeNodes(t *testing.T, s store.Store) {
+	ctx := t.Context()
+	preload(t, s, people)
+
+	if _, err := s.UpsertKey(ctx, models.NaturalKey{Label: "person", Property: "name"}); err != nil {
+		t.Fatalf("UpsertKey() failed: %v", err)
+	}
+
+	// the IDs are ignored, later nodes match the nodes created earlier in the same merge.
+	merged, err := s.MergeNodes(ctx,
+		models.Node{ID: 3, Label: "person", Properties: models.Properties{"name": "foo", "age": float64(30)}},
+		models.Node{Label: "person", Properties: models.Properties{"name": "qux"}},
+		models.Node{Label: "person", Properties: models.Properties{"name": "qux", "age": float64(5)}},
+	)
+	if err != nil {
+		t.Fatalf("MergeNodes() failed: %v", err)
+	}
+
+	want := []store.MergedNode{
+		{Node: models.Node{ID: 1, Label: "person", Properties: models.Properties{"name": "foo", "age": float64(30)}}},
+		{Node: models.Node{ID: 8, Label: "person", Properties: models.Properties{"name": "qux"}}, Created: true},
+		{Node: models.Node{ID: 8, Label: "person", Properties: models.Properties{"name": "qux", "age": float64(5)}}},
+	}
+
+	if diff := cmp.Diff(want, merged, ignoreTimes); diff != "" {
+		t.Errorf("MergeNodes() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	got, err := s.Node(ctx, 1)
+	if err != nil {
+		t.Fatalf("Node() failed: %v", err)
+	}
+
+	if diff := cmp.Diff(want[0].Node, got, ignoreTimes); diff != "" {
+		t.Errorf("Node() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	tests := []struct {
+		name  string
+		nodes []models.Node
+		want  error
+	}{
+		{
+			name:  "label without a key",
+			nodes: []models.Node{{Label: "dog", Properties: models.Properties{"name": "rex"}}},
+			want:  store.ErrInvalid,
+		},
+		{
+			name:  "missing key value",
+			nodes: []models.Node{{Label: "person", Properties: models.Properties{"age": float64(1)}}},
+			want:  store.ErrInvalid,
+		},
+		{
+			name:  "null key value",
+			nodes: []models.Node{{Label: "person", Properties: models.Properties{"name": nil}}},
+			want:  store.ErrInvalid,
+		},
+		{
+			name: "atomic",
+			nodes: []models.Node{
+				{Label: "person", Properties: models.Properties{"name": "quux"}},
+				{Label: "dog", Properties: models.Properties{"name": "rex"}},
+			},
+			want: store.ErrInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.MergeNodes(ctx, tt.nodes...); !errors.Is(err, tt.want) {
+				t.Errorf("MergeNodes() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	quux, err := s.Nodes(ctx, store.NodesArgs{Filter: store.Filter{Where: []store.Predicate{{Path: "name", Op: store.OpEq, Value: "quux"}}}})
+	if err != nil {
+		t.Fatalf("Nodes() failed: %v", err)
+	}
+
+	if len(quux) > 0 {
+		t.Errorf("Nodes() = %v, a failed merge must not write any nodes", quux)
+	}
+}
+
+func testNaturalKeyUniqueness(t *testing.T, s store.Store) {
+	ctx := t.Context()
+	preload(t, s, people)
+
+	if _, err := s.UpsertKey(ctx, models.NaturalKey{Label: "person", Property: "name"}); err != nil {
+		t.Fatalf("UpsertKey() failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		nodes []models.Node
+		want  error
+	}{
+		{
+			name:  "new node with an existing key",
+			nodes: []models.Node{{Label: "person", Properties: models.Properties{"name": "foo"}}},
+			want:  store.ErrConflict,
+		},
+		{
+			name:  "existing node changed to an existing key",
+			nodes: []models.Node{{ID: 4, Label: "person", Properties: models.Properties{"name": "bar"}}},
+			want:  store.ErrConflict,
+		},
+		{
+			name: "duplicate keys in the same write",
+			nodes: []models.Node{
+				{Label: "person", Properties: models.Properties{"name": "qux"}},
+				{Label: "person", Properties: models.Properties{"name": "qux"}},
+			},
+			want: store.ErrConflict,
+		},
+		{
+			name:  "existing node keeping its key",
+			nodes: []models.Node{{ID: 1, Label: "person", Properties: models.Properties{"name": "foo", "age": float64(1)}}},
+		},
+		{
+			name:  "same key value with another label",
+			nodes: []models.Node{{Label: "dog", Properties: models.Properties{"name": "foo"}}},
+		},
+		{
+			name: "nodes without a key value",
+			nodes: []models.Node{
+				{Label: "person", Properties: models.Properties{"age": float64(1)}},
+				{Label: "person", Properties: models.Properties{"age": float64(2)}},
+				{Label: "person", Properties: models.Properties{"name": nil}},
+				{Label: "person", Properties: models.Properties{"name": nil}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.UpsertNodes(ctx, tt.nodes...)
+
+			if tt.want == nil && err != nil {
+				t.Fatalf("UpsertNodes() failed: %v", err)
+			}
+
+			if !errors.Is(err, tt.want) {
+				t.Errorf("UpsertNodes() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func testMergeEdges(t *testing.T, s store.Store) {
+	ctx := t.Context()
+	preload(t, s, people)
+
+	// a parallel edge, the oldest edge is matched.
+	if _, err := s.UpsertEdges(ctx, models.Edge{ID: 8, From: 1, Label: "knows", To: 2}); err != nil {
+		t.Fatalf("UpsertEdges() failed: %v", err)
+	}
+
+	merged, err := s.MergeEdges(ctx,
+		models.Edge{ID: 7, From: 1, Label: "knows", To: 2, Weight: 9, Properties: models.Properties{"since": float64(2011)}},
+		models.Edge{From: 4, Label: "knows", To: 1, Weight: 1},
+		models.Edge{From: 4, Label: "knows", To: 1, Weight: 2},
+	)
+	if err != nil {
+		t.Fatalf("MergeEdges() failed: %v", err)
+	}
+
+	want := []store.MergedEdge{
+		{Edge: models.Edge{ID: 5, From: 1, Label: "knows", To: 2, Weight: 9, Properties: models.Properties{"since": float64(2011)}}},
+		{Edge: models.Edge{ID: 9, From: 4, Label: "knows", To: 1, Weight: 1}, Created: true},
+		{Edge: models.Edge{ID: 9, From: 4, Label: "knows", To: 1, Weight: 2}},
+	}
+
+	if diff := cmp.Diff(want, merged, ignoreTimes); diff != "" {
+		t.Errorf("MergeEdges() = mismatch (-want, +got): \n%s", diff)
+	}
+}
@@ -0,0 +1,205 @@
+package storetest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// ignoreSchemaTimes ignores the schema times which differ between runs and backends.
+var ignoreSchemaTimes = cmp.Options{
+	cmpopts.EquateEmpty(),
+	cmpopts.IgnoreFields(models.Schema{}, "CreatedAt", "UpdatedAt"),
+}
+
+// personSchema requires a name and limits the age of person nodes.
+var personSchema = models.Schema{
+	Kind:     models.SchemaNode,
+	Label:    "person",
+	Required: []string{"name"},
+	Properties: map[string]models.PropertySchema{
+		"name": {Type: "string", MinLength: ptr(1)},
+		"age":  {Type: "integer", Minimum: ptr(0.0)},
+	},
+}
+
+// ownsSchema only allows people to own dogs.
+var ownsSchema = models.Schema{
+	Kind:       models.SchemaEdge,
+	Label:      "owns",
+	FromLabels: []string{"person"},
+	ToLabels:   []string{"dog"},
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func testSchemas(t *testing.T, s store.Store) {
+	ctx := t.Context()
+
+	if _, err := s.Schema(ctx, models.SchemaNode, "person"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Schema() error = %v, want store.ErrNotFound", err)
+	}
+
+	for _, schema := range []models.Schema{ownsSchema, personSchema} {
+		if _, err := s.UpsertSchema(ctx, schema); err != nil {
+			t.Fatalf("UpsertSchema() failed: %v", err)
+		}
+	}
+
+	wantPerson := personSchema
+	wantPerson.Mode = models.SchemaStrict
+
+	wantOwns := ownsSchema
+	wantOwns.Mode = models.SchemaStrict
+
+	got, err := s.Schema(ctx, models.SchemaNode, "person")
+	if err != nil {
+		t.Fatalf("Schema() failed: %v", err)
+	}
+
+	if diff := cmp.Diff(wantPerson, got, ignoreSchemaTimes); diff != "" {
+		t.Errorf("Schema() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	// schemas are ordered by kind and label.
+	all, err := s.Schemas(ctx)
+	if err != nil {
+		t.Fatalf("Schemas() failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]models.Schema{wantOwns, wantPerson}, all, ignoreSchemaTimes); diff != "" {
+		t.Errorf("Schemas() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	// upserting replaces the schema.
+	replaced := models.Schema{Kind: models.SchemaNode, Label: "person", Mode: models.SchemaWarn}
+	if _, err := s.UpsertSchema(ctx, replaced); err != nil {
+		t.Fatalf("UpsertSchema() failed: %v", err)
+	}
+
+	got, err = s.Schema(ctx, models.SchemaNode, "person")
+	if err != nil {
+		t.Fatalf("Schema() failed: %v", err)
+	}
+
+	if diff := cmp.Diff(replaced, got, ignoreSchemaTimes); diff != "" {
+		t.Errorf("Schema() after replace = mismatch (-want, +got): \n%s", diff)
+	}
+
+	deleted, err := s.DeleteSchema(ctx, models.SchemaNode, "person")
+	if err != nil {
+		t.Fatalf("DeleteSchema() failed: %v", err)
+	}
+
+	if diff := cmp.Diff(replaced, deleted, ignoreSchemaTimes); diff != "" {
+		t.Errorf("DeleteSchema() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	if _, err := s.DeleteSchema(ctx, models.SchemaNode, "person"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("DeleteSchema() error = %v, want store.ErrNotFound", err)
+	}
+
+	invalid := []models.Schema{
+		{Kind: "vertex", Label: "person"},
+		{Kind: models.SchemaNode},
+		{Kind: models.SchemaNode, Label: "person", Mode: "loud"},
+		{Kind: models.SchemaNode, Label: "person", FromLabels: []string{"person"}},
+		{Kind: models.SchemaNode, Label: "person", Properties: map[string]models.PropertySchema{"name": {Type: "text"}}},
+		{Kind: models.SchemaNode, Label: "person", Properties: map[string]models.PropertySchema{"name": {Pattern: "("}}},
+	}
+
+	for _, schema := range invalid {
+		if _, err := s.UpsertSchema(ctx, schema); !errors.Is(err, store.ErrInvalid) {
+			t.Errorf("UpsertSchema(%+v) error = %v, want store.ErrInvalid", schema, err)
+		}
+	}
+}
+
+func testSchemaEnforcement(t *testing.T, s store.Store) {
+	ctx := t.Context()
+
+	for _, schema := range []models.Schema{personSchema, ownsSchema} {
+		if _, err := s.UpsertSchema(ctx, schema); err != nil {
+			t.Fatalf("UpsertSchema() failed: %v", err)
+		}
+	}
+
+	// the second node is missing its name and has a negative age, so nothing should be written.
+	_, err := s.UpsertNodes(ctx,
+		models.Node{Label: "person", Properties: models.Properties{"name": "foo"}},
+		models.Node{Label: "person", Properties: models.Properties{"age": -1}},
+	)
+
+	var schemaErr *store.SchemaError
+	if !errors.As(err, &schemaErr) || !errors.Is(err, store.ErrInvalid) {
+		t.Fatalf("UpsertNodes() error = %v, want a *store.SchemaError", err)
+	}
+
+	wantErr := &store.SchemaError{
+		Kind:       models.SchemaNode,
+		Label:      "person",
+		Violations: []string{"name is required", "age must be greater than or equal to 0"},
+	}
+
+	if diff := cmp.Diff(wantErr, schemaErr); diff != "" {
+		t.Errorf("UpsertNodes() error = mismatch (-want, +got): \n%s", diff)
+	}
+
+	if nodes, err := s.Nodes(ctx, store.NodesArgs{}); err != nil || len(nodes) != 0 {
+		t.Fatalf("Nodes() = %d nodes, %v, want no nodes", len(nodes), err)
+	}
+
+	// labels without a schema are not checked.
+	nodes, err := s.UpsertNodes(ctx,
+		models.Node{Label: "person", Properties: models.Properties{"name": "foo", "age": 21}},
+		models.Node{Label: "dog", Properties: models.Properties{"short": true}},
+		models.Node{Label: "cat"},
+	)
+
+	if err != nil {
+		t.Fatalf("UpsertNodes() failed: %v", err)
+	}
+
+	foo, socks, tom := nodes[0].ID, nodes[1].ID, nodes[2].ID
+
+	if _, err := s.UpsertEdges(ctx, models.Edge{From: foo, Label: "owns", To: socks}); err != nil {
+		t.Errorf("UpsertEdges() failed: %v", err)
+	}
+
+	_, err = s.UpsertEdges(ctx, models.Edge{From: foo, Label: "owns", To: tom})
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("UpsertEdges() error = %v, want a *store.SchemaError", err)
+	}
+
+	if len(schemaErr.Violations) != 1 {
+		t.Errorf("UpsertEdges() violations = %v, want the to node label", schemaErr.Violations)
+	}
+
+	// edges can link nodes written in the same graph.
+	_, err = s.UpsertGraph(ctx, models.Graph{
+		Nodes: []models.Node{
+			{Ref: "bar", Label: "person", Properties: models.Properties{"name": "bar"}},
+			{Ref: "rex", Label: "dog"},
+		},
+		Edges: []models.Edge{{FromRef: "bar", Label: "owns", ToRef: "rex"}},
+	})
+
+	if err != nil {
+		t.Errorf("UpsertGraph() failed: %v", err)
+	}
+
+	// warn schemas write items which do not match.
+	if _, err := s.UpsertSchema(ctx, models.Schema{Kind: models.SchemaNode, Label: "cat", Mode: models.SchemaWarn, Required: []string{"name"}}); err != nil {
+		t.Fatalf("UpsertSchema() failed: %v", err)
+	}
+
+	if _, err := s.UpsertNodes(ctx, models.Node{Label: "cat"}); err != nil {
+		t.Errorf("UpsertNodes() with a warn schema failed: %v", err)
+	}
+}
@@ -0,0 +1,657 @@
+// Package storetest is a conformance suite which certifies a store.Store backend against the behaviour every backend
+// must share, eg:
+//
+//	func TestConformance(t *testing.T) {
+//		storetest.Run(t, func(t *testing.T) store.Store {
+//			return memory.New()
+//		})
+//	}
+package storetest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// Factory returns a new empty store, the suite closes it when the test finishes.
+type Factory func(t *testing.T) store.Store
+
+// Run runs the conformance suite against stores returned by the factory, each test uses a new store.
+func Run(t *testing.T, newStore Factory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s store.Store)
+	}{
+		{"UpsertNodes", testUpsertNodes},
+		{"UpsertEdges", testUpsertEdges},
+		{"IDAssignment", testIDAssignment},
+		{"Pagination", testPagination},
+		{"NodesTermSearch", testNodesTermSearch},
+		{"EdgesTermSearch", testEdgesTermSearch},
+		{"Graph", testGraph},
+		{"SubGraph", testSubGraph},
+		{"Health", testHealth},
+		{"ConcurrentWriters", testConcurrentWriters},
+		{"Schemas", testSchemas},
+		{"SchemaEnforcement", testSchemaEnforcement},
+		{"Keys", testKeys},
+		{"NaturalKeyUniqueness", testNaturalKeyUniqueness},
+		{"MergeNodes", testMergeNodes},
+		{"MergeEdges", testMergeEdges},
+		{"Versions", testVersions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore(t)
+			t.Cleanup(func() {
+				if err := s.Close(); err != nil {
+					t.Errorf("Close() failed: %v", err)
+				}
+			})
+
+			tt.test(t, s)
+		})
+	}
+}
+
+// ignoreTimes ignores the times and snippets which differ between runs and backends, and the versions which are
+// checked by testVersions.
+var ignoreTimes = cmp.Options{
+	cmpopts.EquateEmpty(),
+	cmpopts.IgnoreFields(models.Node{}, "CreatedAt", "UpdatedAt", "Snippet", "Version"),
+	cmpopts.IgnoreFields(models.Edge{}, "CreatedAt", "UpdatedAt", "Snippet", "Version"),
+}
+
+// byID ignores the order of nodes and edges.
+var byID = cmp.Options{
+	cmpopts.SortSlices(func(a, b models.Node) bool { return a.ID < b.ID }),
+	cmpopts.SortSlices(func(a, b models.Edge) bool { return a.ID < b.ID }),
+}
+
+// people is the graph used by most tests.
+//
+//	1 (person foo) -knows-> 2 (person bar) -owns-> 3 (dog socks)
+//	                        2 (person bar) -knows-> 4 (person baz)
+var people = models.Graph{
+	Nodes: []models.Node{
+		{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
+		{ID: 2, Label: "person", Properties: models.Properties{"name": "bar", "age": float64(21)}},
+		{ID: 3, Label: "dog", Properties: models.Properties{"name": "socks", "short": true}},
+		{ID: 4, Label: "person", Properties: models.Properties{"name": "baz"}},
+	},
+	Edges: []models.Edge{
+		{ID: 5, From: 1, Label: "knows", To: 2, Weight: 1, Properties: models.Properties{"since": float64(2010)}},
+		{ID: 6, From: 2, Label: "owns", To: 3, Weight: 2, Properties: models.Properties{"since": float64(2020)}},
+		{ID: 7, From: 2, Label: "knows", To: 4, Weight: 3, Properties: models.Properties{}},
+	},
+}
+
+// preload writes the graph to the store.
+func preload(t *testing.T, s store.Store, g models.Graph) {
+	t.Helper()
+
+	if _, err := s.UpsertNodes(t.Context(), g.Nodes...); err != nil {
+		t.Fatalf("UpsertNodes() failed: %v", err)
+	}
+
+	if _, err := s.UpsertEdges(t.Context(), g.Edges...); err != nil {
+		t.Fatalf("UpsertEdges() failed: %v", err)
+	}
+}
+
+// nodeIDs returns the IDs of the nodes in order.
+func nodeIDs(nodes []models.Node) []uint64 {
+	ids := make([]uint64, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.ID
+	}
+	return ids
+}
+
+// edgeIDs returns the IDs of the edges in order.
+func edgeIDs(edges []models.Edge) []uint64 {
+	ids := make([]uint64, len(edges))
+	for i, e := range edges {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+func testUpsertNodes(t *testing.T, s store.Store) {
+	ctx := t.Context()
+
+	created, err := s.UpsertNodes(ctx,
+		models.Node{Label: "person", Properties: models.Properties{"name": "foo"}},
+		models.Node{Label: "person", Properties: models.Properties{"name": "bar", "age": 21, "meta": map[string]string{"hair": "brown"}}},
+	)
+
+	if err != nil {
+		t.Fatalf("UpsertNodes() failed: %v", err)
+	}
+
+	want := []models.Node{
+		{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}},
+		{ID: 2, Label: "person", Properties: models.Properties{"name": "bar", "age": float64(21), "meta": map[string]any{"hair": "brown"}}},
+	}
+
+	if diff := cmp.Diff(want, created, ignoreTimes); diff != "" {
+		t.Errorf("UpsertNodes() created = mismatch (-want, +got): \n%s", diff)
+	}
+
+	for _, n := range created {
+		if n.CreatedAt.IsZero() || n.UpdatedAt.IsZero() {
+			t.Errorf("UpsertNodes() node %d has no created or updated time", n.ID)
+		}
+	}
+
+	// updates replace the label and properties and keep the creation time.
+	updated, err := s.UpsertNodes(ctx, models.Node{ID: 2, Label: "employee", Properties: models.Properties{"name": "bar"}})
+	if err != nil {
+		t.Fatalf("UpsertNodes() failed: %v", err)
+	}
+
+	wantUpdated := models.Node{ID: 2, Label: "employee", Properties: models.Properties{"name": "bar"}}
+
+	if diff := cmp.Diff([]models.Node{wantUpdated}, updated, ignoreTimes); diff != "" {
+		t.Errorf("UpsertNodes() updated = mismatch (-want, +got): \n%s", diff)
+	}
+
+	got, err := s.Node(ctx, 2)
+	if err != nil {
+		t.Fatalf("Node() failed: %v", err)
+	}
+
+	if diff := cmp.Diff(wantUpdated, got, ignoreTimes); diff != "" {
+		t.Errorf("Node() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	if !got.CreatedAt.Equal(created[1].CreatedAt) {
+		t.Errorf("Node() created at = %v, want %v", got.CreatedAt, created[1].CreatedAt)
+	}
+
+	if _, err := s.Node(ctx, 100); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Node() of a missing node error = %v, want %v", err, store.ErrNotFound)
+	}
+}
+
+func testUpsertEdges(t *testing.T, s store.Store) {
+	ctx := t.Context()
+
+	preload(t, s, models.Graph{Nodes: people.Nodes})
+
+	created, err := s.UpsertEdges(ctx, people.Edges[0])
+	if err != nil {
+		t.Fatalf("UpsertEdges() failed: %v", err)
+	}
+
+	if diff := cmp.Diff(people.Edges[:1], created, ignoreTimes); diff != "" {
+		t.Errorf("UpsertEdges() created = mismatch (-want, +got): \n%s", diff)
+	}
+
+	// updates replace every field apart from the ID and creation time.
+	want := models.Edge{ID: 5, From: 1, Label: "likes", To: 4, Weight: 7, Properties: models.Properties{"much": true}}
+
+	if _, err := s.UpsertEdges(ctx, want); err != nil {
+		t.Fatalf("UpsertEdges() failed: %v", err)
+	}
+
+	got, err := s.Edge(ctx, 5)
+	if err != nil {
+		t.Fatalf("Edge() failed: %v", err)
+	}
+
+	if diff := cmp.Diff(want, got, ignoreTimes); diff != "" {
+		t.Errorf("Edge() = mismatch (-want, +got): \n%s", diff)
+	}
+
+	if !got.CreatedAt.Equal(created[0].CreatedAt) {
+		t.Errorf("Edge() created at = %v, want %v", got.CreatedAt, created[0].CreatedAt)
+	}
+
+	if _, err := s.Edge(ctx, 100); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Edge() of a missing edge error = %v, want %v", err, store.ErrNotFound)
+	}
+
+	// nodes and edges are never returned as each other.
+	if _, err := s.Edge(ctx, 1); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Edge() of a node error = %v, want %v", err, store.ErrNotFound)
+	}
+
+	if _, err := s.Node(ctx, 5); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Node() of an edge error = %v, want %v", err, store.ErrNotFound)
+	}
+}
+
+func testIDAssignment(t *testing.T, s store.Store) {
+	ctx := t.Context()
+
+	nodes, err := s.UpsertNodes(ctx, models.Node{Label: "a"}, models.Node{Label: "b"})
+	if err != nil {
+		t.Fatalf("UpsertNodes() failed: %v", err)
+	}
+
+	// nodes and edges share the same IDs.
+	edges, err := s.UpsertEdges(ctx, models.Edge{From: nodes[0].ID, Label: "ab", To: nodes[1].ID})
+	if err != nil {
+		t.Fatalf("UpsertEdges() failed: %v", err)
+	}
+
+	// explicit IDs are kept, and new IDs are assigned after them.
+	explicit, err := s.UpsertNodes(ctx, models.Node{ID: 10, Label: "c"})
+	if err != nil {
+		t.Fatalf("UpsertNodes() failed: %v", err)
+	}
+
+	next, err := s.UpsertNodes(ctx, models.Node{Label: "d"})
+	if err != nil {
+		t.Fatalf("UpsertNodes() failed: %v", err)
+	}
+
+	got := []uint64{nodes[0].ID, nodes[1].ID, edges[0].ID, explicit[0].ID}
+	if diff := cmp.Diff([]uint64{1, 2, 3, 10}, got); diff != "" {
+		t.Errorf("assigned IDs = mismatch (-want, +got): \n%s", diff)
+	}
+
+	if next[0].ID <= 10 {
+		t.Errorf("UpsertNodes() assigned ID %d, want an ID greater than 10", next[0].ID)
+	}
+}
+
+func testPagination(t *testing.T, s store.Store) {
+	ctx := t.Context()
+
+	nodes := make([]models.Node, 10)
+	for i := range nodes {
+		nodes[i] = models.Node{Label: "item", Properties: models.Properties{"n": i}}
+	}
+
+	created, err := s.UpsertNodes(ctx, nodes...)
+	if err != nil {
+		t.Fatalf("UpsertNodes() failed: %v", err)
+	}
+
+	edges := make([]models.Edge, 9)
+	for i := range edges {
+		edges[i] = models.Edge{From: created[i].ID, Label: "next", To: created[i+1].ID}
+	}
+
+	if _, err := s.UpsertEdges(ctx, edges...); err != nil {
+		t.Fatalf("UpsertEdges() failed: %v", err)
+	}
+
+	pages := map[string]func(lastID uint64) ([]uint64, error){
+		"Nodes": func(lastID uint64) ([]uint64, error) {
+			got, err := s.Nodes(ctx, store.NodesArgs{Limit: 3, LastID: lastID})
+			return nodeIDs(got), err
+		},
+		"Edges": func(lastID uint64) ([]uint64, error) {
+			got, err := s.Edges(ctx, store.EdgesArgs{Limit: 3, LastID: lastID})
+			return edgeIDs(got), err
+		},
+		"NodesTermSearch": func(lastID uint64) ([]uint64, error) {
+			got, err := s.NodesTermSearch(ctx, store.TermSearchArgs{Term: "label:item", Limit: 3, LastID: lastID})
+			return nodeIDs(got), err
+		},
+		"EdgesTermSearch": func(lastID uint64) ([]uint64, error) {
+			got, err := s.EdgesTermSearch(ctx, store.TermSearchArgs{Term: "label:next", Limit: 3, LastID: lastID})
+			return edgeIDs(got), err
+		},
+	}
+
+	want := map[string][]uint64{
+		"Nodes":           {1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		"Edges":           {11, 12, 13, 14, 15, 16, 17, 18, 19},
+		"NodesTermSearch": {1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		"EdgesTermSearch": {11, 12, 13, 14, 15, 16, 17, 18, 19},
+	}
+
+	for name, page := range pages {
+		t.Run(name, func(t *testing.T) {
+			all := []uint64{}
+			lastID := uint64(0)
+
+			for range 10 {
+				ids, err := page(lastID)
+				if err != nil {
+					t.Fatalf("%s() failed: %v", name, err)
+				}
+
+				for _, id := range ids {
+					if id <= lastID {
+						t.Fatalf("%s() returned ID %d, want IDs after %d", name, id, lastID)
+					}
+				}
+
+				all = append(all, ids...)
+
+				if len(ids) < 3 {
+					break
+				}
+
+				lastID = ids[len(ids)-1]
+			}
+
+			diff := cmp.Diff(want[name], all, cmpopts.SortSlices(func(a, b uint64) bool { return a < b }))
+			if diff != "" {
+				t.Errorf("%s() pages = mismatch (-want, +got): \n%s", name, diff)
+			}
+		})
+	}
+}
+
+func testNodesTermSearch(t *testing.T, s store.Store) {
+	preload(t, s, people)
+
+	tests := []struct {
+		name    string
+		term    string
+		want    []uint64
+		wantErr bool
+	}{
+		{name: "empty term", term: "", want: []uint64{1, 2, 3, 4}},
+		{name: "word", term: "socks", want: []uint64{3}},
+		{name: "column", term: "label:person", want: []uint64{1, 2, 4}},
+		{name: "and", term: "label:person AND foo", want: []uint64{1}},
+		{name: "or", term: "foo OR socks", want: []uint64{1, 3}},
+		{name: "not", term: "label:person NOT foo", want: []uint64{2, 4}},
+		{name: "prefix", term: "ba*", want: []uint64{2, 4}},
+		{name: "property keys", term: "prop_keys:age", want: []uint64{2}},
+		{name: "no match", term: "missing", want: []uint64{}},
+		{name: "syntax error", term: "(foo", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.NodesTermSearch(t.Context(), store.TermSearchArgs{Term: tt.term})
+			if err != nil {
+				if !tt.wantErr {
+					t.Errorf("NodesTermSearch() failed: %v", err)
+				}
+				return
+			}
+
+			if tt.wantErr {
+				t.Fatal("NodesTermSearch() succeeded unexpectedly")
+			}
+
+			diff := cmp.Diff(tt.want, nodeIDs(got), cmpopts.EquateEmpty(), cmpopts.SortSlices(func(a, b uint64) bool { return a < b }))
+			if diff != "" {
+				t.Errorf("NodesTermSearch() = mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+
+	t.Run("snippet", func(t *testing.T) {
+		got, err := s.NodesTermSearch(t.Context(), store.TermSearchArgs{Term: "socks", SnippetTokens: 10, SnippetStart: "<b>", SnippetEnd: "</b>"})
+		if err != nil {
+			t.Fatalf("NodesTermSearch() failed: %v", err)
+		}
+
+		if len(got) != 1 || !strings.Contains(got[0].Snippet, "<b>socks</b>") {
+			t.Errorf("NodesTermSearch() = %v, want a single node with a highlighted snippet", got)
+		}
+	})
+}
+
+func testEdgesTermSearch(t *testing.T, s store.Store) {
+	preload(t, s, people)
+
+	tests := []struct {
+		name string
+		term string
+		want []models.Edge
+	}{
+		{name: "empty term", term: "", want: people.Edges},
+		{name: "label", term: "label:knows", want: []models.Edge{people.Edges[0], people.Edges[2]}},
+		{name: "property value", term: "2020", want: []models.Edge{people.Edges[1]}},
+		{name: "nodes are not matched", term: "socks", want: []models.Edge{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.EdgesTermSearch(t.Context(), store.TermSearchArgs{Term: tt.term})
+			if err != nil {
+				t.Fatalf("EdgesTermSearch() failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got, ignoreTimes, byID); diff != "" {
+				t.Errorf("EdgesTermSearch() = mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func testGraph(t *testing.T, s store.Store) {
+	preload(t, s, people)
+
+	tests := []struct {
+		name string
+		args store.TermSearchArgs
+		want models.Graph
+	}{
+		{
+			name: "everything",
+			args: store.TermSearchArgs{},
+			want: people,
+		},
+		{
+			name: "nodes only",
+			args: store.TermSearchArgs{Term: "label:person"},
+			want: models.Graph{Nodes: []models.Node{people.Nodes[0], people.Nodes[1], people.Nodes[3]}},
+		},
+		{
+			// the edge nodes do not match the term, so they are back-filled.
+			name: "missing nodes",
+			args: store.TermSearchArgs{Term: "label:owns"},
+			want: models.Graph{
+				Nodes: []models.Node{people.Nodes[1], people.Nodes[2]},
+				Edges: []models.Edge{people.Edges[1]},
+			},
+		},
+		{
+			name: "matched and missing nodes",
+			args: store.TermSearchArgs{Term: "socks OR label:owns"},
+			want: models.Graph{
+				Nodes: []models.Node{people.Nodes[1], people.Nodes[2]},
+				Edges: []models.Edge{people.Edges[1]},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Graph(t.Context(), tt.args)
+			if err != nil {
+				t.Fatalf("Graph() failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got, ignoreTimes, byID); diff != "" {
+				t.Errorf("Graph() = mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func testSubGraph(t *testing.T, s store.Store) {
+	preload(t, s, people)
+
+	tests := []struct {
+		name      string
+		args      store.SubGraphArgs
+		wantNodes []uint64
+		wantEdges []uint64
+		wantErr   error
+	}{
+		{
+			name:      "one hop",
+			args:      store.SubGraphArgs{FromNodeID: 1},
+			wantNodes: []uint64{1, 2},
+			wantEdges: []uint64{5},
+		},
+		{
+			// nodes are ordered by their depth and then ID.
+			name:      "two hops",
+			args:      store.SubGraphArgs{FromNodeID: 3, Depth: 2},
+			wantNodes: []uint64{3, 2, 1, 4},
+			wantEdges: []uint64{5, 6, 7},
+		},
+		{
+			name:      "out",
+			args:      store.SubGraphArgs{FromNodeID: 2, Direction: store.DirectionOut},
+			wantNodes: []uint64{2, 3, 4},
+			wantEdges: []uint64{6, 7},
+		},
+		{
+			name:      "in",
+			args:      store.SubGraphArgs{FromNodeID: 2, Direction: store.DirectionIn},
+			wantNodes: []uint64{2, 1},
+			wantEdges: []uint64{5},
+		},
+		{
+			name:      "edge labels",
+			args:      store.SubGraphArgs{FromNodeID: 2, EdgeLabels: []string{"knows"}},
+			wantNodes: []uint64{2, 1, 4},
+			wantEdges: []uint64{5, 7},
+		},
+		{
+			name:      "limit",
+			args:      store.SubGraphArgs{FromNodeID: 2, Limit: 2},
+			wantNodes: []uint64{2, 1},
+			wantEdges: []uint64{5},
+		},
+		{
+			name:      "from an edge",
+			args:      store.SubGraphArgs{EdgeID: 6, Direction: store.DirectionOut},
+			wantNodes: []uint64{2, 3, 4},
+			wantEdges: []uint64{6, 7},
+		},
+		{
+			name:      "no starting point",
+			args:      store.SubGraphArgs{},
+			wantNodes: []uint64{},
+			wantEdges: []uint64{},
+		},
+		{
+			name:    "missing edge",
+			args:    store.SubGraphArgs{EdgeID: 100},
+			wantErr: store.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.SubGraph(t.Context(), tt.args)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SubGraph() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if diff := cmp.Diff(tt.wantNodes, nodeIDs(got.Nodes), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("SubGraph() nodes = mismatch (-want, +got): \n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.wantEdges, edgeIDs(got.Edges), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("SubGraph() edges = mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func testHealth(t *testing.T, s store.Store) {
+	got := s.Health(t.Context())
+
+	if got.Status != "ok" {
+		t.Errorf("Health() status = %q, want %q: %v", got.Status, "ok", got.Checks)
+	}
+}
+
+func testConcurrentWriters(t *testing.T, s store.Store) {
+	ctx := t.Context()
+
+	const writers = 8
+	const writes = 25
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+
+	for w := range writers {
+		wg.Go(func() {
+			for i := range writes {
+				nodes, err := s.UpsertNodes(ctx,
+					models.Node{Label: "writer", Properties: models.Properties{"writer": w, "write": i}},
+					models.Node{Label: "writer", Properties: models.Properties{"writer": w, "write": i}},
+				)
+
+				if err != nil {
+					errs <- fmt.Errorf("writer %d: %w", w, err)
+					return
+				}
+
+				if _, err := s.UpsertEdges(ctx, models.Edge{From: nodes[0].ID, Label: "pair", To: nodes[1].ID}); err != nil {
+					errs <- fmt.Errorf("writer %d: %w", w, err)
+					return
+				}
+			}
+		})
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	nodes, err := s.Nodes(ctx, store.NodesArgs{Limit: writers * writes * 2})
+	if err != nil {
+		t.Fatalf("Nodes() failed: %v", err)
+	}
+
+	edges, err := s.Edges(ctx, store.EdgesArgs{Limit: writers * writes})
+	if err != nil {
+		t.Fatalf("Edges() failed: %v", err)
+	}
+
+	if len(nodes) != writers*writes*2 || len(edges) != writers*writes {
+		t.Fatalf("got %d nodes and %d edges, want %d and %d", len(nodes), len(edges), writers*writes*2, writers*writes)
+	}
+
+	// every item has a unique ID and every edge joins two stored nodes.
+	seen := map[uint64]struct{}{}
+	for _, id := range append(nodeIDs(nodes), edgeIDs(edges)...) {
+		if _, ok := seen[id]; ok {
+			t.Errorf("ID %d was assigned more than once", id)
+		}
+		seen[id] = struct{}{}
+	}
+
+	for _, e := range edges {
+		for _, id := range []uint64{e.From, e.To} {
+			if _, err := s.Node(ctx, id); err != nil {
+				t.Errorf("edge %d node %d: %v", e.ID, id, err)
+			}
+		}
+	}
+
+	// the term search index follows concurrent writes.
+	found, err := s.NodesTermSearch(ctx, store.TermSearchArgs{Term: "label:writer", Limit: writers * writes * 2})
+	if err != nil {
+		t.Fatalf("NodesTermSearch() failed: %v", err)
+	}
+
+	if len(found) != writers*writes*2 {
+		t.Errorf("NodesTermSearch() found %d nodes, want %d", len(found), writers*writes*2)
+	}
+}
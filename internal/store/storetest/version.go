@@ -0,0 +1,100 @@
+package storetest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+func testVersions(t *testing.T, s store.Store) {
+	ctx := t.Context()
+	preload(t, s, people)
+
+	// every write increments the version.
+	n, err := s.Node(ctx, 1)
+	if err != nil {
+		t.Fatalf("Node() failed: %v", err)
+	}
+
+	if n.Version != 1 {
+		t.Errorf("Node() version = %d, want 1", n.Version)
+	}
+
+	updated, err := s.UpsertNodes(ctx, models.Node{ID: 1, Label: "person", Properties: models.Properties{"name": "foo", "age": float64(30)}})
+	if err != nil {
+		t.Fatalf("UpsertNodes() failed: %v", err)
+	}
+
+	if updated[0].Version != 2 {
+		t.Errorf("UpsertNodes() version = %d, want 2", updated[0].Version)
+	}
+
+	// writes with the current version succeed.
+	updated, err = s.UpsertNodes(ctx, models.Node{ID: 1, Version: 2, Label: "person", Properties: models.Properties{"name": "foo"}})
+	if err != nil {
+		t.Fatalf("UpsertNodes() with the current version failed: %v", err)
+	}
+
+	if updated[0].Version != 3 {
+		t.Errorf("UpsertNodes() version = %d, want 3", updated[0].Version)
+	}
+
+	// writes with a stale version fail with the current node and leave it untouched.
+	_, err = s.UpsertNodes(ctx, models.Node{ID: 1, Version: 2, Label: "person", Properties: models.Properties{"name": "stale"}})
+
+	var conflict *store.VersionError
+	if !errors.As(err, &conflict) || !errors.Is(err, store.ErrConflict) {
+		t.Fatalf("UpsertNodes() with a stale version error = %v, want a *store.VersionError", err)
+	}
+
+	if conflict.Node == nil || conflict.Node.Version != 3 || conflict.Expected != 2 {
+		t.Errorf("UpsertNodes() conflict = %+v, want the node at version 3", conflict)
+	}
+
+	got, err := s.Node(ctx, 1)
+	if err != nil {
+		t.Fatalf("Node() failed: %v", err)
+	}
+
+	if diff := cmp.Diff(*conflict.Node, got, ignoreTimes); diff != "" || got.Version != 3 {
+		t.Errorf("Node() after a stale write = mismatch (-want, +got): \n%s", diff)
+	}
+
+	// a failed write leaves the other items of the write untouched.
+	_, err = s.UpsertNodes(ctx,
+		models.Node{ID: 4, Label: "person", Properties: models.Properties{"name": "changed"}},
+		models.Node{ID: 2, Version: 5, Label: "person"},
+	)
+
+	if !errors.As(err, &conflict) {
+		t.Fatalf("UpsertNodes() with a stale version error = %v, want a *store.VersionError", err)
+	}
+
+	if got, err := s.Node(ctx, 4); err != nil || got.Properties["name"] != "baz" || got.Version != 1 {
+		t.Errorf("Node() = %+v, %v, want the node left untouched", got, err)
+	}
+
+	// expecting a version of a missing item fails without a current item.
+	_, err = s.UpsertNodes(ctx, models.Node{ID: 100, Version: 1, Label: "person"})
+	if !errors.As(err, &conflict) || conflict.Node != nil || conflict.Edge != nil {
+		t.Errorf("UpsertNodes() of a missing node error = %v, want a *store.VersionError without a node", err)
+	}
+
+	// edges are versioned the same way.
+	edges, err := s.UpsertEdges(ctx, models.Edge{ID: 5, Version: 1, From: 1, Label: "knows", To: 2, Weight: 5})
+	if err != nil {
+		t.Fatalf("UpsertEdges() with the current version failed: %v", err)
+	}
+
+	if edges[0].Version != 2 {
+		t.Errorf("UpsertEdges() version = %d, want 2", edges[0].Version)
+	}
+
+	_, err = s.UpsertEdges(ctx, models.Edge{ID: 5, Version: 1, From: 1, Label: "knows", To: 2})
+	if !errors.As(err, &conflict) || conflict.Edge == nil || conflict.Edge.Version != 2 || conflict.Edge.Weight != 5 {
+		t.Errorf("UpsertEdges() with a stale version error = %v, want a *store.VersionError with the edge at version 2", err)
+	}
+}
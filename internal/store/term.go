@@ -0,0 +1,369 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// TermColumns are the searchable columns, they can be used in column filters, eg: `label:person`.
+var TermColumns = []string{"id", "type", "label", "prop_keys", "prop_values"}
+
+// unindexedTermColumns can be used in column filters but never match, they are stored but not indexed by sqlite.
+var unindexedTermColumns = []string{"from_id", "to_id", "weight"}
+
+// Token is a lower cased word and its byte offsets in the text.
+type Token struct {
+	Text       string
+	Start, End int
+}
+
+// isTokenChar returns true for ASCII letters and digits and all non-ASCII bytes, the same as the sqlite ascii tokenizer.
+func isTokenChar(c byte) bool {
+	return c >= 0x80 || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// Tokenize splits the text into lower cased tokens the same way the sqlite ascii tokenizer does, every other
+// character separates tokens.
+func Tokenize(text string) []Token {
+	tokens := []Token{}
+
+	for i := 0; i < len(text); {
+		if !isTokenChar(text[i]) {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(text) && isTokenChar(text[i]) {
+			i++
+		}
+
+		tokens = append(tokens, Token{Text: strings.ToLower(text[start:i]), Start: start, End: i})
+	}
+
+	return tokens
+}
+
+// Term is a parsed search term, it is either a *TermPhrase or a *TermOp.
+type Term interface {
+	isTerm()
+}
+
+// TermPhrase matches items with the tokens next to each other in one of the columns.
+type TermPhrase struct {
+	// Columns are the columns the phrase is matched against, a phrase without columns never matches.
+	Columns []string
+
+	// Tokens are the lower cased tokens of the phrase.
+	Tokens []string
+
+	// Prefix is set when the last token is matched as a prefix, eg: `foo*`.
+	Prefix bool
+}
+
+// TermOperator combines two terms.
+type TermOperator string
+
+const (
+	// TermAnd matches items matching both terms.
+	TermAnd TermOperator = "AND"
+
+	// TermOr matches items matching either term.
+	TermOr TermOperator = "OR"
+
+	// TermNot matches items matching the left term but not the right term.
+	TermNot TermOperator = "NOT"
+)
+
+// TermOp is two terms combined by an operator.
+type TermOp struct {
+	Op          TermOperator
+	Left, Right Term
+}
+
+func (*TermPhrase) isTerm() {}
+func (*TermOp) isTerm()     {}
+
+// Phrases returns the phrases of the term in the order they appear.
+func Phrases(t Term) []*TermPhrase {
+	switch t := t.(type) {
+	case *TermPhrase:
+		return []*TermPhrase{t}
+	case *TermOp:
+		return append(Phrases(t.Left), Phrases(t.Right)...)
+	}
+	return nil
+}
+
+// ParseTerm parses a search term using the sqlite full text search syntax so every backend accepts the same terms, eg:
+// `foo`, `"foo bar"`, `foo*`, `label:person AND (prop_values:foo OR prop_values:bar) NOT socks`.
+// NEAR groups and the `+`, `^` and `-` operators are not supported.
+func ParseTerm(src string) (Term, error) {
+	p := &termParser{src: src}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	t, err := p.parseOr(TermColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind != tokEOF {
+		return nil, p.syntaxError()
+	}
+
+	return t, nil
+}
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokWord
+	tokString
+	tokLParen
+	tokRParen
+	tokColon
+	tokStar
+)
+
+type termToken struct {
+	kind tokKind
+	text string
+}
+
+// termParser is a recursive descent parser where NOT binds tighter than AND (explicit or implicit), which binds tighter than OR.
+type termParser struct {
+	src string
+	pos int
+	tok termToken
+}
+
+func (p *termParser) syntaxError() error {
+	if p.tok.kind == tokEOF {
+		return fmt.Errorf("%w: syntax error in search term %q: unexpected end of term", ErrInvalid, p.src)
+	}
+	return fmt.Errorf("%w: syntax error in search term %q near %q", ErrInvalid, p.src, p.tok.text)
+}
+
+// isWordChar returns true for the characters allowed in a bareword.
+func isWordChar(c byte) bool {
+	return isTokenChar(c) || c == '_' || c == 0x1A
+}
+
+func (p *termParser) next() error {
+	for p.pos < len(p.src) && strings.ContainsRune(" \t\r\n", rune(p.src[p.pos])) {
+		p.pos++
+	}
+
+	start := p.pos
+
+	if p.pos >= len(p.src) {
+		p.tok = termToken{kind: tokEOF}
+		return nil
+	}
+
+	c := p.src[p.pos]
+
+	switch {
+	case c == '(':
+		p.pos++
+		p.tok = termToken{kind: tokLParen, text: "("}
+	case c == ')':
+		p.pos++
+		p.tok = termToken{kind: tokRParen, text: ")"}
+	case c == ':':
+		p.pos++
+		p.tok = termToken{kind: tokColon, text: ":"}
+	case c == '*':
+		p.pos++
+		p.tok = termToken{kind: tokStar, text: "*"}
+	case c == '"':
+		var b strings.Builder
+		p.pos++
+		for {
+			if p.pos >= len(p.src) {
+				return fmt.Errorf("%w: syntax error in search term %q: unterminated string", ErrInvalid, p.src)
+			}
+
+			if p.src[p.pos] == '"' {
+				// a double quote is escaped by another double quote.
+				if p.pos+1 < len(p.src) && p.src[p.pos+1] == '"' {
+					b.WriteByte('"')
+					p.pos += 2
+					continue
+				}
+				p.pos++
+				break
+			}
+
+			b.WriteByte(p.src[p.pos])
+			p.pos++
+		}
+		p.tok = termToken{kind: tokString, text: b.String()}
+	case isWordChar(c):
+		for p.pos < len(p.src) && isWordChar(p.src[p.pos]) {
+			p.pos++
+		}
+		p.tok = termToken{kind: tokWord, text: p.src[start:p.pos]}
+	default:
+		return fmt.Errorf("%w: syntax error in search term %q near %q", ErrInvalid, p.src, string(c))
+	}
+
+	return nil
+}
+
+// isKeyword returns true if the current token is the keyword, keywords are case sensitive.
+func (p *termParser) isKeyword(kw TermOperator) bool {
+	return p.tok.kind == tokWord && p.tok.text == string(kw)
+}
+
+func (p *termParser) parseOr(cols []string) (Term, error) {
+	left, err := p.parseAnd(cols)
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword(TermOr) {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+
+		right, err := p.parseAnd(cols)
+		if err != nil {
+			return nil, err
+		}
+
+		left = &TermOp{Op: TermOr, Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *termParser) parseAnd(cols []string) (Term, error) {
+	left, err := p.parseNot(cols)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.isKeyword(TermAnd):
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+		case p.isKeyword(TermOr), p.isKeyword(TermNot):
+			return left, nil
+		case p.tok.kind == tokWord, p.tok.kind == tokString, p.tok.kind == tokLParen:
+			// implicit AND
+		default:
+			return left, nil
+		}
+
+		right, err := p.parseNot(cols)
+		if err != nil {
+			return nil, err
+		}
+
+		left = &TermOp{Op: TermAnd, Left: left, Right: right}
+	}
+}
+
+func (p *termParser) parseNot(cols []string) (Term, error) {
+	left, err := p.parsePrimary(cols)
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword(TermNot) {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+
+		right, err := p.parsePrimary(cols)
+		if err != nil {
+			return nil, err
+		}
+
+		left = &TermOp{Op: TermNot, Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *termParser) parsePrimary(cols []string) (Term, error) {
+	switch p.tok.kind {
+	case tokLParen:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+
+		t, err := p.parseOr(cols)
+		if err != nil {
+			return nil, err
+		}
+
+		if p.tok.kind != tokRParen {
+			return nil, p.syntaxError()
+		}
+
+		return t, p.next()
+
+	case tokWord, tokString:
+		if p.tok.kind == tokWord {
+			switch TermOperator(p.tok.text) {
+			case TermAnd, TermOr, TermNot:
+				return nil, p.syntaxError()
+			case "NEAR":
+				return nil, fmt.Errorf("%w: unsupported search term %q: NEAR groups are not supported", ErrInvalid, p.src)
+			}
+		}
+
+		text := p.tok.text
+		isWord := p.tok.kind == tokWord
+
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+
+		// a bareword followed by a colon is a column filter, it narrows the columns already being matched.
+		if isWord && p.tok.kind == tokColon {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+
+			name := strings.ToLower(text)
+
+			filtered := []string{}
+			switch {
+			case slices.Contains(TermColumns, name):
+				if slices.Contains(cols, name) {
+					filtered = append(filtered, name)
+				}
+			case slices.Contains(unindexedTermColumns, name):
+			default:
+				return nil, fmt.Errorf("%w: no such column %q in search term %q", ErrInvalid, text, p.src)
+			}
+
+			return p.parsePrimary(filtered)
+		}
+
+		ph := &TermPhrase{Columns: cols}
+		for _, t := range Tokenize(text) {
+			ph.Tokens = append(ph.Tokens, t.Text)
+		}
+
+		if p.tok.kind == tokStar {
+			ph.Prefix = true
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+		}
+
+		return ph, nil
+	}
+
+	return nil, p.syntaxError()
+}
@@ -0,0 +1,106 @@
+package store_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenmud/edgedb/internal/store"
+)
+
+func TestParseTerm(t *testing.T) {
+	all := store.TermColumns
+
+	tests := []struct {
+		name    string // description of this test case
+		term    string
+		want    store.Term
+		wantErr bool
+	}{
+		{
+			name: "bare word",
+			term: "Foo",
+			want: &store.TermPhrase{Columns: all, Tokens: []string{"foo"}},
+		},
+		{
+			name: "phrase and prefix",
+			term: `"foo bar"*`,
+			want: &store.TermPhrase{Columns: all, Tokens: []string{"foo", "bar"}, Prefix: true},
+		},
+		{
+			name: "column filter",
+			term: "label:person",
+			want: &store.TermPhrase{Columns: []string{"label"}, Tokens: []string{"person"}},
+		},
+		{
+			name: "unindexed column",
+			term: "weight:1",
+			want: &store.TermPhrase{Columns: []string{}, Tokens: []string{"1"}},
+		},
+		{
+			name: "precedence",
+			term: "a OR b c NOT d",
+			want: &store.TermOp{
+				Op:   store.TermOr,
+				Left: &store.TermPhrase{Columns: all, Tokens: []string{"a"}},
+				Right: &store.TermOp{
+					Op:   store.TermAnd,
+					Left: &store.TermPhrase{Columns: all, Tokens: []string{"b"}},
+					Right: &store.TermOp{
+						Op:    store.TermNot,
+						Left:  &store.TermPhrase{Columns: all, Tokens: []string{"c"}},
+						Right: &store.TermPhrase{Columns: all, Tokens: []string{"d"}},
+					},
+				},
+			},
+		},
+		{
+			name: "column filter applies to a group",
+			term: "label:(a OR b)",
+			want: &store.TermOp{
+				Op:    store.TermOr,
+				Left:  &store.TermPhrase{Columns: []string{"label"}, Tokens: []string{"a"}},
+				Right: &store.TermPhrase{Columns: []string{"label"}, Tokens: []string{"b"}},
+			},
+		},
+		{
+			name:    "unknown column",
+			term:    "colour:red",
+			wantErr: true,
+		},
+		{
+			name:    "dangling operator",
+			term:    "foo AND",
+			wantErr: true,
+		},
+		{
+			name:    "near",
+			term:    "NEAR(a b)",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotErr := store.ParseTerm(tt.term)
+			if gotErr != nil {
+				if !tt.wantErr {
+					t.Errorf("ParseTerm() failed: %v", gotErr)
+				}
+
+				if !errors.Is(gotErr, store.ErrInvalid) {
+					t.Errorf("ParseTerm() error = %v, want store.ErrInvalid", gotErr)
+				}
+				return
+			}
+
+			if tt.wantErr {
+				t.Fatal("ParseTerm() succeeded unexpectedly")
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ParseTerm() = mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"time"
+
+	"github.com/jenmud/edgedb/models"
+)
+
+// TrashArgs are the arguments used for listing the trash.
+type TrashArgs struct {
+	// Limit is the max number of nodes and edges to return.
+	Limit int
+
+	// LastID is the last know primary key/ID which will be used for fast pagination.
+	LastID uint64
+}
+
+// TrashedNode is a node in the trash.
+type TrashedNode struct {
+	models.Node
+
+	// DeletedAt is when the node was moved to the trash.
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// TrashedEdge is an edge in the trash.
+type TrashedEdge struct {
+	models.Edge
+
+	// DeletedAt is when the edge was moved to the trash.
+	DeletedAt time.Time `json:"deleted_at"`
+
+	// DeletedWith is the node the edge was trashed with, the edge is restored with the node.
+	// It is 0 if the edge was trashed on its own.
+	DeletedWith uint64 `json:"deleted_with,omitempty"`
+}
+
+// Trash are the trashed nodes and edges ordered by ID.
+type Trash struct {
+	Nodes []TrashedNode `json:"nodes"`
+	Edges []TrashedEdge `json:"edges"`
+}
+
+// RestoreResult reports exactly what was restored from the trash.
+type RestoreResult struct {
+	// Nodes are the restored nodes.
+	Nodes []models.Node `json:"nodes"`
+
+	// Edges are the restored edges.
+	Edges []models.Edge `json:"edges"`
+}
+
+// PurgeResult reports how many items were removed from the trash.
+type PurgeResult struct {
+	// Nodes is the number of purged nodes.
+	Nodes int `json:"nodes"`
+
+	// Edges is the number of purged edges.
+	Edges int `json:"edges"`
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/jenmud/edgedb/models"
+)
+
+// ValidateWebhook returns ErrInvalid if the webhook can not be delivered to, a secret is generated if it has none.
+func ValidateWebhook(w *models.Webhook) error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: webhook url %q must be an absolute http or https url", ErrInvalid, w.URL)
+	}
+
+	ops := []models.ChangeOp{models.ChangeCreate, models.ChangeUpdate, models.ChangeDelete}
+	for _, op := range w.Ops {
+		if !slices.Contains(ops, op) {
+			return fmt.Errorf("%w: unknown webhook op %q, expected one of %v", ErrInvalid, op, ops)
+		}
+	}
+
+	if w.Secret == "" {
+		secret := make([]byte, 32)
+		rand.Read(secret)
+		w.Secret = hex.EncodeToString(secret)
+	}
+
+	return nil
+}
+
+// DeliveriesArgs are the arguments for listing the deliveries of a webhook.
+type DeliveriesArgs struct {
+	WebhookID uint64
+
+	// Status only returns the deliveries with the status, all the deliveries are returned if empty.
+	Status models.DeliveryStatus
+
+	// LastID only returns the deliveries after the ID.
+	LastID uint64
+
+	// Limit is the maximum number of deliveries to return.
+	Limit int
+}
+
+// DueDelivery is a pending delivery which is due to be sent, with the webhook and change needed to send it.
+type DueDelivery struct {
+	Delivery models.Delivery
+	URL      string
+	Secret   string
+	Change   models.Change
+}
+
+// DeliveryAttempt is the outcome of sending a delivery.
+type DeliveryAttempt struct {
+	// Status is DeliveryDelivered, DeliveryPending to retry the delivery at NextAttemptAt or DeliveryFailed to give up.
+	Status        models.DeliveryStatus
+	ResponseCode  int
+	Error         string
+	NextAttemptAt time.Time
+}
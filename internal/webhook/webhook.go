@@ -0,0 +1,270 @@
+// Package webhook delivers the changes queued in a store.WebhookStore outbox to the webhook URLs.
+//
+// Each delivery is posted as the JSON encoded models.Change and signed with the webhook secret, receivers verify the
+// SignatureHeader against Sign of the request body. Failed deliveries are retried with an exponential backoff.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+const (
+	// SignatureHeader is the header holding the signature of the delivery body, eg: `sha256=<hex hmac>`.
+	SignatureHeader = "X-EdgeDB-Signature"
+
+	// DeliveryHeader is the header holding the delivery ID, it is the same for each attempt of a delivery.
+	DeliveryHeader = "X-EdgeDB-Delivery"
+
+	// EventHeader is the header holding the operation of the delivered change.
+	EventHeader = "X-EdgeDB-Event"
+)
+
+const (
+	// DefaultInterval is the default interval the outbox is checked for due deliveries.
+	DefaultInterval = time.Second
+
+	// DefaultBackoff is the default delay before the first retry, the delay doubles on each retry.
+	DefaultBackoff = 5 * time.Second
+
+	// DefaultMaxBackoff is the default maximum delay between retries.
+	DefaultMaxBackoff = time.Hour
+
+	// DefaultMaxAttempts is the default number of attempts before a delivery fails.
+	DefaultMaxAttempts = 10
+
+	// DefaultTimeout is the default timeout of a delivery request.
+	DefaultTimeout = 10 * time.Second
+)
+
+// batch is the maximum number of due deliveries sent at a time.
+const batch = 100
+
+// maxResponse is the maximum number of bytes read from the response of a receiver.
+const maxResponse = 64 << 10
+
+// Sign returns the signature of the body using the secret, eg: `sha256=<hex hmac>`.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher sends the due deliveries from the outbox of the store.
+type Dispatcher struct {
+	Store  store.WebhookStore
+	Client *http.Client
+
+	// Interval is how often the outbox is checked for due deliveries.
+	Interval time.Duration
+
+	// Backoff is the delay before the first retry, the delay doubles on each retry up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// MaxAttempts is the number of attempts before a delivery fails.
+	MaxAttempts int
+}
+
+// NewDispatcher returns a dispatcher for the store using the default settings.
+func NewDispatcher(s store.WebhookStore) *Dispatcher {
+	return &Dispatcher{
+		Store:       s,
+		Client:      &http.Client{Timeout: DefaultTimeout},
+		Interval:    DefaultInterval,
+		Backoff:     DefaultBackoff,
+		MaxBackoff:  DefaultMaxBackoff,
+		MaxAttempts: DefaultMaxAttempts,
+	}
+}
+
+// Run sends the due deliveries every interval until the context is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.Dispatch(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("error dispatching webhook deliveries", slog.String("reason", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch sends the deliveries which are due now returning the number of deliveries attempted.
+//
+// The deliveries of each webhook are sent concurrently with the other webhooks, so a slow receiver only delays its
+// own deliveries. Once a delivery to a webhook fails its remaining deliveries are skipped until the next dispatch.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	attempted := 0
+
+	for {
+		due, err := d.Store.DueDeliveries(ctx, time.Now(), batch)
+		if err != nil {
+			return attempted, err
+		}
+
+		webhooks := []uint64{}
+		deliveries := map[uint64][]store.DueDelivery{}
+
+		for _, dd := range due {
+			id := dd.Delivery.WebhookID
+			if _, ok := deliveries[id]; !ok {
+				webhooks = append(webhooks, id)
+			}
+			deliveries[id] = append(deliveries[id], dd)
+		}
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var errs []error
+		skipped := false
+
+		for _, id := range webhooks {
+			wg.Go(func() {
+				n, skip, err := d.dispatch(ctx, deliveries[id])
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				attempted += n
+				skipped = skipped || skip
+				if err != nil {
+					errs = append(errs, err)
+				}
+			})
+		}
+
+		wg.Wait()
+
+		if len(errs) > 0 {
+			return attempted, errors.Join(errs...)
+		}
+
+		// the skipped deliveries are still due, they are left for the next dispatch instead of being fetched again.
+		if skipped || len(due) < batch {
+			return attempted, nil
+		}
+	}
+}
+
+// dispatch sends the deliveries of a webhook in order returning the number of deliveries attempted, stopping at the
+// first failed delivery and returning true if deliveries were skipped.
+func (d *Dispatcher) dispatch(ctx context.Context, due []store.DueDelivery) (int, bool, error) {
+	for i, dd := range due {
+		attempt := d.send(ctx, dd)
+
+		if err := d.Store.RecordAttempt(ctx, dd.Delivery.ID, attempt); err != nil {
+			return i, false, err
+		}
+
+		if attempt.Status != models.DeliveryDelivered {
+			return i + 1, i+1 < len(due), nil
+		}
+	}
+
+	return len(due), false, nil
+}
+
+// retryAfter returns the delay before retrying a delivery which has been attempted the number of times.
+func (d *Dispatcher) retryAfter(attempts int) time.Duration {
+	delay := d.Backoff
+
+	for range attempts - 1 {
+		delay *= 2
+
+		if delay >= d.MaxBackoff {
+			return d.MaxBackoff
+		}
+	}
+
+	return min(delay, d.MaxBackoff)
+}
+
+// send posts the delivery returning the outcome of the attempt.
+func (d *Dispatcher) send(ctx context.Context, dd store.DueDelivery) store.DeliveryAttempt {
+	attempt := store.DeliveryAttempt{Status: models.DeliveryDelivered, NextAttemptAt: time.Now()}
+
+	code, err := d.post(ctx, dd)
+	attempt.ResponseCode = code
+
+	if err == nil {
+		return attempt
+	}
+
+	attempts := dd.Delivery.Attempts + 1
+	attempt.Error = err.Error()
+
+	if attempts >= d.MaxAttempts {
+		attempt.Status = models.DeliveryFailed
+	} else {
+		attempt.Status = models.DeliveryPending
+		attempt.NextAttemptAt = time.Now().Add(d.retryAfter(attempts))
+	}
+
+	slog.Warn(
+		"webhook delivery failed",
+		slog.Uint64("delivery", dd.Delivery.ID),
+		slog.Uint64("webhook", dd.Delivery.WebhookID),
+		slog.Int("attempts", attempts),
+		slog.String("status", string(attempt.Status)),
+		slog.String("reason", attempt.Error),
+	)
+
+	return attempt
+}
+
+// post posts the signed change to the webhook URL returning the response status code,
+// returning an error if the request failed or the receiver did not return a 2xx status code.
+func (d *Dispatcher) post(ctx context.Context, dd store.DueDelivery) (int, error) {
+	body, err := json.Marshal(dd.Change)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dd.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set(SignatureHeader, Sign(dd.Secret, body))
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(dd.Delivery.ID, 10))
+	req.Header.Set(EventHeader, string(dd.Change.Op))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	// the response is drained so the connection can be reused, a receiver can not keep the dispatcher reading forever.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponse))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver returned %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
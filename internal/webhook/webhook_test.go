@@ -0,0 +1,302 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/internal/webhook"
+	"github.com/jenmud/edgedb/models"
+)
+
+// receiver records the changes posted to it, failing the first requests.
+type receiver struct {
+	t      *testing.T
+	secret string
+	fail   int
+
+	mu      sync.Mutex
+	changes []models.Change
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		rc.t.Errorf("reading the delivery failed: %v", err)
+		return
+	}
+
+	if got, want := r.Header.Get(webhook.SignatureHeader), webhook.Sign(rc.secret, body); got != want {
+		rc.t.Errorf("signature = %q, want %q", got, want)
+	}
+
+	if r.Header.Get(webhook.DeliveryHeader) == "" {
+		rc.t.Errorf("missing the %s header", webhook.DeliveryHeader)
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.fail > 0 {
+		rc.fail--
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	change := models.Change{}
+	if err := json.Unmarshal(body, &change); err != nil {
+		rc.t.Errorf("decoding the delivery failed: %v", err)
+	}
+
+	if got := r.Header.Get(webhook.EventHeader); got != string(change.Op) {
+		rc.t.Errorf("event = %q, want %q", got, change.Op)
+	}
+
+	rc.changes = append(rc.changes, change)
+}
+
+// received returns the ops and labels of the received changes.
+func (rc *receiver) received() []string {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	got := []string{}
+	for _, c := range rc.changes {
+		got = append(got, string(c.Op)+" "+c.Label)
+	}
+
+	return got
+}
+
+// setup returns a store, a dispatcher retrying quickly and a webhook posting to the receiver.
+func setup(t *testing.T, rc *receiver, w models.Webhook) (*sqlite.Store, *webhook.Dispatcher, models.Webhook) {
+	t.Helper()
+
+	s, err := sqlite.New(t.Context(), ":memory:")
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	t.Cleanup(func() { s.Close() })
+
+	server := httptest.NewServer(rc)
+	t.Cleanup(server.Close)
+
+	w.URL = server.URL
+
+	created, err := s.CreateWebhook(t.Context(), w)
+	if err != nil {
+		t.Fatalf("CreateWebhook() failed: %v", err)
+	}
+
+	rc.secret = created.Secret
+
+	d := webhook.NewDispatcher(s)
+	d.Backoff = time.Millisecond
+	d.MaxBackoff = 4 * time.Millisecond
+	d.MaxAttempts = 3
+
+	return s, d, created
+}
+
+// dispatch dispatches the due deliveries until all the deliveries are delivered or failed.
+func dispatch(t *testing.T, s *sqlite.Store, d *webhook.Dispatcher, id uint64) []models.Delivery {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		if _, err := d.Dispatch(t.Context()); err != nil {
+			t.Fatalf("Dispatch() failed: %v", err)
+		}
+
+		pending, err := s.Deliveries(t.Context(), store.DeliveriesArgs{WebhookID: id, Status: models.DeliveryPending})
+		if err != nil {
+			t.Fatalf("Deliveries() failed: %v", err)
+		}
+
+		if len(pending) == 0 {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("deliveries are still pending: %+v", pending)
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	deliveries, err := s.Deliveries(t.Context(), store.DeliveriesArgs{WebhookID: id})
+	if err != nil {
+		t.Fatalf("Deliveries() failed: %v", err)
+	}
+
+	return deliveries
+}
+
+func TestDispatcher_Filters(t *testing.T) {
+	rc := &receiver{t: t}
+	s, d, w := setup(t, rc, models.Webhook{Labels: []string{"person"}, Ops: []models.ChangeOp{models.ChangeCreate, models.ChangeDelete}})
+	ctx := t.Context()
+
+	if _, err := s.UpsertNodes(ctx, models.Node{ID: 1, Label: "person"}, models.Node{ID: 2, Label: "dog"}); err != nil {
+		t.Fatalf("UpsertNodes() failed: %v", err)
+	}
+
+	if _, err := s.UpsertNodes(ctx, models.Node{ID: 1, Label: "person", Properties: models.Properties{"name": "foo"}}); err != nil {
+		t.Fatalf("UpsertNodes() failed: %v", err)
+	}
+
+	if _, err := s.DeleteNodes(ctx, store.DeleteNodesArgs{IDs: []uint64{1, 2}}); err != nil {
+		t.Fatalf("DeleteNodes() failed: %v", err)
+	}
+
+	deliveries := dispatch(t, s, d, w.ID)
+
+	if diff := cmp.Diff([]string{"create person", "delete person"}, rc.received()); diff != "" {
+		t.Errorf("received = mismatch (-want, +got): \n%s", diff)
+	}
+
+	for _, delivery := range deliveries {
+		if delivery.Status != models.DeliveryDelivered || delivery.Attempts != 1 || delivery.ResponseCode != http.StatusOK {
+			t.Errorf("delivery = %+v, want delivered on the first attempt", delivery)
+		}
+	}
+}
+
+func TestDispatcher_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		fail         int
+		wantStatus   models.DeliveryStatus
+		wantAttempts int
+		wantReceived []string
+	}{
+		{
+			name:         "delivered after retrying",
+			fail:         2,
+			wantStatus:   models.DeliveryDelivered,
+			wantAttempts: 3,
+			wantReceived: []string{"create person"},
+		},
+		{
+			name:         "failed after the max attempts",
+			fail:         3,
+			wantStatus:   models.DeliveryFailed,
+			wantAttempts: 3,
+			wantReceived: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := &receiver{t: t, fail: tt.fail}
+			s, d, w := setup(t, rc, models.Webhook{})
+
+			if _, err := s.UpsertNodes(t.Context(), models.Node{Label: "person"}); err != nil {
+				t.Fatalf("UpsertNodes() failed: %v", err)
+			}
+
+			deliveries := dispatch(t, s, d, w.ID)
+			if len(deliveries) != 1 {
+				t.Fatalf("Deliveries() = %+v, want a single delivery", deliveries)
+			}
+
+			got := deliveries[0]
+			if got.Status != tt.wantStatus || got.Attempts != tt.wantAttempts {
+				t.Errorf("delivery = %+v, want status %s after %d attempts", got, tt.wantStatus, tt.wantAttempts)
+			}
+
+			if tt.wantStatus == models.DeliveryFailed && (got.ResponseCode != http.StatusServiceUnavailable || got.Error == "") {
+				t.Errorf("delivery = %+v, want the last response code and error", got)
+			}
+
+			if diff := cmp.Diff(tt.wantReceived, rc.received()); diff != "" {
+				t.Errorf("received = mismatch (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDispatcher_SlowWebhook(t *testing.T) {
+	rc := &receiver{t: t}
+	s, d, w := setup(t, rc, models.Webhook{})
+	ctx := t.Context()
+
+	// the slow webhook blocks until the other webhook received its deliveries and then fails.
+	release := make(chan struct{})
+	slow := 0
+	var mu sync.Mutex
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		slow++
+		mu.Unlock()
+
+		<-release
+		http.Error(rw, "unavailable", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	sw, err := s.CreateWebhook(ctx, models.Webhook{URL: server.URL})
+	if err != nil {
+		t.Fatalf("CreateWebhook() failed: %v", err)
+	}
+
+	if _, err := s.UpsertNodes(ctx, models.Node{Label: "person"}, models.Node{Label: "dog"}, models.Node{Label: "cat"}); err != nil {
+		t.Fatalf("UpsertNodes() failed: %v", err)
+	}
+
+	go func() {
+		deadline := time.Now().Add(5 * time.Second)
+		for len(rc.received()) < 3 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		close(release)
+	}()
+
+	attempted, err := d.Dispatch(ctx)
+	if err != nil {
+		t.Fatalf("Dispatch() failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"create person", "create dog", "create cat"}, rc.received()); diff != "" {
+		t.Errorf("received = mismatch (-want, +got): \n%s", diff)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// the deliveries after the failed delivery of the slow webhook are skipped.
+	if attempted != 4 || slow != 1 {
+		t.Errorf("Dispatch() = %d attempted with %d slow requests, want 4 attempted with 1 slow request", attempted, slow)
+	}
+
+	pending, err := s.Deliveries(ctx, store.DeliveriesArgs{WebhookID: sw.ID, Status: models.DeliveryPending})
+	if err != nil {
+		t.Fatalf("Deliveries() failed: %v", err)
+	}
+
+	if len(pending) != 3 {
+		t.Errorf("Deliveries() = %+v, want the 3 deliveries of the slow webhook pending", pending)
+	}
+
+	if deliveries := dispatch(t, s, d, w.ID); len(deliveries) != 3 {
+		t.Errorf("Deliveries() = %+v, want 3 deliveries", deliveries)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '{"seq":1}' | openssl dgst -sha256 -hmac secret
+	want := "sha256=21b7373c374f3e6011e9326e253361375dbf5379e3a5d800fbedce47bb99b361"
+	if got := webhook.Sign("secret", []byte(`{"seq":1}`)); got != want {
+		t.Errorf("Sign() = %q, want %q", got, want)
+	}
+}
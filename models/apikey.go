@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// Role is the role granted to an API key or token, each role is allowed everything the roles before it are.
+type Role string
+
+const (
+	// RoleReader can read the graphs and run queries.
+	RoleReader Role = "reader"
+
+	// RoleWriter can also write, import and delete nodes and edges.
+	RoleWriter Role = "writer"
+
+	// RoleAdmin can also manage the schemas, keys, indexes, webhooks, named graphs and API keys.
+	RoleAdmin Role = "admin"
+)
+
+// roles are the roles ordered from the least to the most allowed.
+var roles = []Role{RoleReader, RoleWriter, RoleAdmin}
+
+// Valid returns true if the role is a known role.
+func (r Role) Valid() bool {
+	return r.rank() >= 0
+}
+
+// Allows returns true if the role is allowed everything the other role is.
+func (r Role) Allows(other Role) bool {
+	return r.Valid() && r.rank() >= other.rank()
+}
+
+// rank is the position of the role in roles, -1 for unknown roles.
+func (r Role) rank() int {
+	for i, role := range roles {
+		if role == r {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// APIKey is a static key authenticating the clients of the API with a role.
+type APIKey struct {
+	ID uint64 `json:"id"`
+
+	// Name describes who or what uses the key.
+	Name string `json:"name"`
+
+	Role Role `json:"role"`
+
+	// Key is the secret key generated by the server, only a hash of it is stored so it is only returned when the key is
+	// created.
+	Key string `json:"key,omitempty"`
+
+	// Prefix is the start of the key which helps finding the key without revealing it.
+	Prefix string `json:"prefix"`
+
+	CreatedAt time.Time `json:"created_at"`
+
+	// LastUsedAt is when the key last authenticated a request to within a minute, it is nil if the key was never used.
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ChangeOp is the kind of mutation recorded by a change.
+type ChangeOp string
+
+const (
+	// ChangeCreate is a new node or edge, the change only has an after image.
+	ChangeCreate ChangeOp = "create"
+
+	// ChangeUpdate is an updated node or edge, the change has a before and after image.
+	ChangeUpdate ChangeOp = "update"
+
+	// ChangeDelete is a deleted node or edge, the change only has a before image.
+	ChangeDelete ChangeOp = "delete"
+)
+
+// Change is a mutation of a node or edge recorded in the changelog.
+type Change struct {
+	// Seq is the sequence number of the change, it is increasing and is used to resume the change feed.
+	Seq uint64 `json:"seq"`
+
+	Op ChangeOp `json:"op"`
+
+	// Kind is either node or edge.
+	Kind SchemaKind `json:"kind"`
+
+	ID uint64 `json:"id"`
+
+	// Label is the label of the item after the change, or before the change for deletes.
+	Label string `json:"label"`
+
+	// Before is the node or edge before the change.
+	Before json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+
+	// After is the node or edge after the change.
+	After json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
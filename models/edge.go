@@ -9,12 +9,15 @@ type Edge struct {
 	ID         uint64     `db:"id" json:"id"`
 	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
 	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at"`
+	Version    uint64     `db:"version" json:"version,omitempty"` // incremented by every write, a write with a version fails if it is not current
 	Label      string     `db:"label" json:"label"`
 	Properties Properties `db:"properties" json:"properties"`
 	From       uint64     `db:"from_id" json:"from_id"`
 	To         uint64     `db:"to_id" json:"to_id"`
 	Weight     int        `db:"weight" json:"weight"`
-	Snippet    string     `db:"-" json:"snippet,omitempty"` // this is a special field show a small snippet of the match terms
+	Snippet    string     `db:"-" json:"snippet,omitempty"`  // this is a special field show a small snippet of the match terms
+	FromRef    string     `db:"-" json:"from_ref,omitempty"` // reference of a node in the same graph upsert, used instead of From
+	ToRef      string     `db:"-" json:"to_ref,omitempty"`   // reference of a node in the same graph upsert, used instead of To
 }
 
 // NewEdge returns a new edge linking two nodes together.
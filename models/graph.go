@@ -22,3 +22,8 @@ func (g *Graph) AddEdges(edges ...Edge) {
 		g.Edges = append(g.Edges, e)
 	}
 }
+
+// NamedGraph is a graph isolated from the default graph and the other named graphs, it has its own IDs and search index.
+type NamedGraph struct {
+	Name string `json:"name"`
+}
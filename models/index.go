@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// IndexStatus is the build status of a property index.
+type IndexStatus string
+
+const (
+	// IndexBuilding is an index which is still being built.
+	IndexBuilding IndexStatus = "building"
+
+	// IndexReady is an index used by filters and queries.
+	IndexReady IndexStatus = "ready"
+
+	// IndexFailed is an index which could not be built, the reason is in the index error.
+	IndexFailed IndexStatus = "failed"
+)
+
+// PropertyIndex is a secondary index on a property, filters and queries comparing the property use the index.
+type PropertyIndex struct {
+	ID uint64 `json:"id"`
+
+	// Label limits the index to the items with the label, every item is indexed if empty.
+	Label string `json:"label,omitempty"`
+
+	// Path is the dotted path of the indexed property, eg: `name` or `meta.hair`.
+	Path string `json:"path"`
+
+	Status IndexStatus `json:"status"`
+
+	// Error is the reason the index could not be built.
+	Error string `json:"error,omitempty"`
+
+	// Size is the size of the index in bytes.
+	Size int64 `json:"size"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
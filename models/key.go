@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// NaturalKey declares the property identifying the nodes with a label, eg: people are keyed by their email.
+// No two nodes with the label can have the same key value, nodes without the property are not keyed.
+type NaturalKey struct {
+	Label string `json:"label"`
+
+	// Property is the dotted path of the key property, eg: `email` or `contact.email`.
+	Property string `json:"property"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
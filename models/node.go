@@ -10,9 +10,11 @@ type Node struct {
 	ID         uint64     `db:"id" json:"id"`
 	CreatedAt  time.Time  `db:"created_at,omitempty" json:"created_at"`
 	UpdatedAt  time.Time  `db:"updated_at,omitempty" json:"updated_at"`
+	Version    uint64     `db:"version" json:"version,omitempty"` // incremented by every write, a write with a version fails if it is not current
 	Label      string     `db:"label" json:"label"`
 	Properties Properties `db:"properties,omitempty" json:"properties,omitempty"`
 	Snippet    string     `db:"-" json:"snippet,omitempty"` // this is a special field show a small snippet of the match terms
+	Ref        string     `db:"-" json:"ref,omitempty"`     // temporary reference used by edges in the same graph upsert
 }
 
 // NewNode creates a new node with the given label and properties.
@@ -0,0 +1,23 @@
+package models
+
+// Hop is a single step along a path, From and To are in the order the edge was walked
+// which may be the reverse of the edge's direction when walking incoming edges.
+type Hop struct {
+	From   uint64 `json:"from_id"`
+	EdgeID uint64 `json:"edge_id"`
+	Label  string `json:"label"`
+	To     uint64 `json:"to_id"`
+	Weight int    `json:"weight"`
+}
+
+// Path is a path between two nodes.
+type Path struct {
+	// Graph contains all the nodes and edges along the path.
+	Graph Graph `json:"graph"`
+
+	// Hops are the ordered steps from the starting node to the ending node.
+	Hops []Hop `json:"hops"`
+
+	// Cost is the number of hops, or the sum of the edge weights for weighted paths.
+	Cost int `json:"cost"`
+}
@@ -0,0 +1,20 @@
+package models
+
+// Table is a tabular query result with a column for every returned item.
+// Cells hold a Node, an Edge, a list of Edges for variable length relationships or a plain value.
+type Table struct {
+	Columns []string `json:"columns"`
+	Rows    [][]any  `json:"rows"`
+}
+
+// QueryResult is the result of a graph query.
+type QueryResult struct {
+	// Table is the returned rows.
+	Table Table `json:"table"`
+
+	// Graph contains the returned nodes and edges, including the nodes at either end of the edges.
+	Graph Graph `json:"graph"`
+
+	// Truncated is true if a variable length relationship stopped walking early, so not every match was returned.
+	Truncated bool `json:"truncated"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// DiffOp is how a field changed between two revisions.
+type DiffOp string
+
+const (
+	// DiffAdded is a field which was added, the diff only has an after value.
+	DiffAdded DiffOp = "added"
+
+	// DiffRemoved is a field which was removed, the diff only has a before value.
+	DiffRemoved DiffOp = "removed"
+
+	// DiffChanged is a field with a different value, the diff has a before and after value.
+	DiffChanged DiffOp = "changed"
+)
+
+// PropertyDiff is a change to a field between two revisions.
+type PropertyDiff struct {
+	// Path is the dotted path of the field, eg: `label`, `weight` or `properties.meta.hair`.
+	Path string `json:"path"`
+
+	Op     DiffOp `json:"op"`
+	Before any    `json:"before,omitempty"`
+	After  any    `json:"after,omitempty"`
+}
+
+// Revision is a version of a node or edge in its history.
+type Revision struct {
+	// Version is the version of the item, starting at 1 for the oldest revision in the history.
+	Version int `json:"version"`
+
+	// Seq is the sequence number of the change which created the revision.
+	Seq uint64 `json:"seq"`
+
+	Op ChangeOp `json:"op"`
+
+	// At is when the revision was made.
+	At time.Time `json:"at"`
+
+	// Node is the node after the change, it is nil if the node was deleted or the revision is for an edge.
+	Node *Node `json:"node,omitempty"`
+
+	// Edge is the edge after the change, it is nil if the edge was deleted or the revision is for a node.
+	Edge *Edge `json:"edge,omitempty"`
+
+	// Diff are the changes from the previous revision ordered by path.
+	Diff []PropertyDiff `json:"diff"`
+}
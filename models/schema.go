@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// SchemaKind is the kind of item a schema applies to.
+type SchemaKind string
+
+const (
+	// SchemaNode applies the schema to nodes with the label.
+	SchemaNode SchemaKind = "node"
+
+	// SchemaEdge applies the schema to edges with the label.
+	SchemaEdge SchemaKind = "edge"
+)
+
+// SchemaMode is how schema violations are handled.
+type SchemaMode string
+
+const (
+	// SchemaStrict rejects writes which do not match the schema.
+	SchemaStrict SchemaMode = "strict"
+
+	// SchemaWarn logs a warning for writes which do not match the schema and writes them anyway.
+	SchemaWarn SchemaMode = "warn"
+)
+
+// PropertySchema describes a property value using a subset of JSON Schema.
+type PropertySchema struct {
+	// Type is one of string, number, integer, boolean, object, array or null, any type is allowed if empty.
+	Type string `json:"type,omitempty"`
+
+	// Enum lists the allowed values.
+	Enum []any `json:"enum,omitempty"`
+
+	// Pattern is a regular expression strings must match, it is not anchored.
+	Pattern string `json:"pattern,omitempty"`
+
+	// Minimum and Maximum are the inclusive bounds of numbers.
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+
+	// MinLength and MaxLength are the bounds of the number of characters in strings.
+	MinLength *int `json:"minLength,omitempty"`
+	MaxLength *int `json:"maxLength,omitempty"`
+
+	// Items is the schema of every array item.
+	Items *PropertySchema `json:"items,omitempty"`
+
+	// Properties, Required and AdditionalProperties describe objects.
+	Properties           map[string]PropertySchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *bool                     `json:"additionalProperties,omitempty"`
+}
+
+// Schema describes the properties of the nodes or edges with a label.
+type Schema struct {
+	Kind  SchemaKind `json:"kind"`
+	Label string     `json:"label"`
+
+	// Mode is how violations are handled, defaults to SchemaStrict.
+	Mode SchemaMode `json:"mode"`
+
+	// Properties, Required and AdditionalProperties describe the item properties the same way as an object PropertySchema.
+	Properties           map[string]PropertySchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *bool                     `json:"additionalProperties,omitempty"`
+
+	// FromLabels and ToLabels are the allowed labels of the nodes an edge links, any label is allowed if empty.
+	FromLabels []string `json:"from_labels,omitempty"`
+	ToLabels   []string `json:"to_labels,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
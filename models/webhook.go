@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Webhook is a subscription delivering the changes to nodes and edges to a URL.
+type Webhook struct {
+	ID uint64 `json:"id"`
+
+	// URL is the http or https URL the changes are posted to.
+	URL string `json:"url"`
+
+	// Secret is the key used to sign the deliveries, it is only returned when the webhook is created.
+	Secret string `json:"secret,omitempty"`
+
+	// Labels limits the deliveries to the changes to items with any of the labels, every change is delivered if empty.
+	Labels []string `json:"labels,omitempty"`
+
+	// Ops limits the deliveries to the changes with any of the operations, every change is delivered if empty.
+	Ops []ChangeOp `json:"ops,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DeliveryStatus is the status of a webhook delivery.
+type DeliveryStatus string
+
+const (
+	// DeliveryPending is a delivery waiting to be sent or retried.
+	DeliveryPending DeliveryStatus = "pending"
+
+	// DeliveryDelivered is a delivery accepted by the receiver.
+	DeliveryDelivered DeliveryStatus = "delivered"
+
+	// DeliveryFailed is a delivery which was not accepted after all the attempts, the reason is in the delivery error.
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// Delivery is a change queued for delivery to a webhook and the outcome of its last attempt.
+type Delivery struct {
+	ID        uint64 `json:"id"`
+	WebhookID uint64 `json:"webhook_id"`
+
+	// Seq is the sequence number of the delivered change.
+	Seq uint64 `json:"seq"`
+
+	Status   DeliveryStatus `json:"status"`
+	Attempts int            `json:"attempts"`
+
+	// ResponseCode is the HTTP status code returned by the receiver on the last attempt.
+	ResponseCode int `json:"response_code,omitempty"`
+
+	// Error is the reason the last attempt failed.
+	Error string `json:"error,omitempty"`
+
+	// NextAttemptAt is when a pending delivery is sent next.
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 )
 
 // FlattenMAP takes a map and tries to flatten all the keys and values into a single string
@@ -115,3 +116,90 @@ func Values(m any) []string {
 	walker(m)
 	return values
 }
+
+// Flatten takes a map and returns the leaf values keyed by the same dotted keys returned by Keys,
+// eg: `{"meta": {"hair": "brown"}}` becomes `{"meta.hair": "brown"}`. Nil values are skipped.
+func Flatten(m any) map[string]any {
+	flat := map[string]any{}
+
+	if m == nil || reflect.TypeOf(m).Kind() != reflect.Map {
+		return flat
+	}
+
+	var walker func(current reflect.Value, prefix string)
+
+	walker = func(current reflect.Value, prefix string) {
+		for _, k := range current.MapKeys() {
+			fullKey := fmt.Sprintf("%v", k.Interface())
+			if prefix != "" {
+				fullKey = prefix + "." + fullKey
+			}
+
+			val := current.MapIndex(k)
+			if val.Kind() == reflect.Interface {
+				if val.IsNil() {
+					continue
+				}
+				val = val.Elem()
+			}
+
+			if val.Kind() == reflect.Map {
+				walker(val, fullKey)
+				continue
+			}
+
+			flat[fullKey] = val.Interface()
+		}
+	}
+
+	walker(reflect.ValueOf(m), "")
+	return flat
+}
+
+// Unflatten is the reverse of Flatten, dotted keys are expanded into nested maps,
+// eg: `{"meta.hair": "brown"}` becomes `{"meta": {"hair": "brown"}}`.
+// A key which clashes with a value already at a shorter path, eg: `a` and `a.b`, is kept as the dotted key.
+func Unflatten(flat map[string]any) map[string]any {
+	m := map[string]any{}
+
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+
+	// shorter keys are set first so clashes are resolved the same way every time.
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		parts := strings.Split(key, ".")
+		current := m
+		placed := true
+
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := current[part]
+			if !ok {
+				child := map[string]any{}
+				current[part] = child
+				current = child
+				continue
+			}
+
+			child, ok := next.(map[string]any)
+			if !ok {
+				placed = false
+				break
+			}
+			current = child
+		}
+
+		last := parts[len(parts)-1]
+		if _, exists := current[last]; !placed || exists {
+			m[key] = flat[key]
+			continue
+		}
+
+		current[last] = flat[key]
+	}
+
+	return m
+}
@@ -279,3 +279,94 @@ func TestValues(t *testing.T) {
 		})
 	}
 }
+
+func TestFlatten(t *testing.T) {
+	tests := []struct {
+		name string // description of this test case
+		m    any
+		want map[string]any
+	}{
+		{
+			name: "single-level",
+			m: map[string]any{
+				"name": "foo",
+				"age":  21,
+			},
+			want: map[string]any{"name": "foo", "age": 21},
+		},
+		{
+			name: "nested-3-levels",
+			m: map[string]any{
+				"name": "foo",
+				"meta": map[string]any{
+					"age":  21,
+					"tags": []any{"a", "b"},
+					"hair": map[string]string{
+						"colour": "brown",
+					},
+				},
+			},
+			want: map[string]any{"name": "foo", "meta.age": 21, "meta.tags": []any{"a", "b"}, "meta.hair.colour": "brown"},
+		},
+		{
+			name: "nil-values-skipped",
+			m:    models.Properties{"name": "foo", "gone": nil},
+			want: map[string]any{"name": "foo"},
+		},
+		{
+			name: "unknown-supported-type",
+			m:    "not-a-map",
+			want: map[string]any{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := common.Flatten(tt.m)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Flatten() = %s", diff)
+			}
+		})
+	}
+}
+
+func TestUnflatten(t *testing.T) {
+	tests := []struct {
+		name string // description of this test case
+		flat map[string]any
+		want map[string]any
+	}{
+		{
+			name: "single-level",
+			flat: map[string]any{"name": "foo", "age": 21},
+			want: map[string]any{"name": "foo", "age": 21},
+		},
+		{
+			name: "nested-3-levels",
+			flat: map[string]any{"name": "foo", "meta.age": 21, "meta.hair.colour": "brown"},
+			want: map[string]any{
+				"name": "foo",
+				"meta": map[string]any{
+					"age":  21,
+					"hair": map[string]any{"colour": "brown"},
+				},
+			},
+		},
+		{
+			name: "clashing-keys",
+			flat: map[string]any{"meta": "foo", "meta.age": 21},
+			want: map[string]any{"meta": "foo", "meta.age": 21},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := common.Unflatten(tt.flat)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Unflatten() = %s", diff)
+			}
+		})
+	}
+}
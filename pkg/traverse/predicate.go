@@ -0,0 +1,41 @@
+package traverse
+
+import "github.com/jenmud/edgedb/internal/store"
+
+// P is a predicate used with Has, eg: `Has("year", Gt(2000))`.
+type P struct {
+	op    store.Operator
+	value any
+}
+
+// Eq matches values equal to v.
+func Eq(v any) P { return P{op: store.OpEq, value: v} }
+
+// Neq matches values not equal to v, missing properties do not match.
+func Neq(v any) P { return P{op: store.OpNe, value: v} }
+
+// Gt matches values greater than v.
+func Gt(v any) P { return P{op: store.OpGt, value: v} }
+
+// Gte matches values greater than or equal to v.
+func Gte(v any) P { return P{op: store.OpGte, value: v} }
+
+// Lt matches values less than v.
+func Lt(v any) P { return P{op: store.OpLt, value: v} }
+
+// Lte matches values less than or equal to v.
+func Lte(v any) P { return P{op: store.OpLte, value: v} }
+
+// Within matches values equal to any of the values.
+func Within(values ...any) P { return P{op: store.OpIn, value: values} }
+
+// Exists matches nodes which have the property.
+func Exists() P { return P{op: store.OpExists} }
+
+// NotExists matches nodes which do not have the property.
+func NotExists() P { return P{op: store.OpNotExists} }
+
+// predicate converts the predicate into a store predicate on the property path.
+func (p P) predicate(path string) store.Predicate {
+	return store.Predicate{Path: path, Op: p.op, Value: p.value}
+}
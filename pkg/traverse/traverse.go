@@ -0,0 +1,581 @@
+// Package traverse is a Gremlin-style traversal builder over a store, eg:
+//
+//	g := traverse.New(s)
+//	graph, err := g.V(id).Out("acted_in").Has("year", traverse.Gt(2000)).In("directed").Dedup().Limit(20).ToGraph(ctx)
+//
+// Traversals are lazy, nothing is read from the store until one of Iter, Nodes, Count or ToGraph is called.
+// Steps work on batches of nodes so each step is a handful of store queries no matter how many nodes are traversed,
+// and Has and HasLabel steps are pushed into the query fetching the nodes of the previous step.
+package traverse
+
+import (
+	"cmp"
+	"context"
+	"iter"
+	"slices"
+
+	"github.com/jenmud/edgedb/internal/store"
+	"github.com/jenmud/edgedb/models"
+)
+
+// DefaultBatchSize is the number of nodes each step reads from the store at a time.
+const DefaultBatchSize = 256
+
+// edgePageSize is the number of edges read from the store at a time when hopping.
+const edgePageSize = 1000
+
+// Source starts traversals over a store.
+type Source struct {
+	store store.Store
+	batch int
+}
+
+// New returns a new traversal source over the store.
+func New(s store.Store) *Source {
+	return &Source{store: s, batch: DefaultBatchSize}
+}
+
+// WithBatchSize returns a copy of the source reading n nodes from the store at a time.
+func (g *Source) WithBatchSize(n int) *Source {
+	if n <= 0 {
+		n = DefaultBatchSize
+	}
+	return &Source{store: g.store, batch: n}
+}
+
+// V starts a traversal from the nodes with the IDs, or from all the nodes if no IDs are given.
+func (g *Source) V(ids ...uint64) *Traversal {
+	return &Traversal{source: g, steps: []step{{kind: stepStart, ids: ids}}}
+}
+
+// stepKind is the kind of traversal step.
+type stepKind int
+
+const (
+	stepStart stepKind = iota
+	stepHop
+	stepFilter
+	stepDedup
+	stepLimit
+)
+
+// step is a single traversal step.
+type step struct {
+	kind stepKind
+
+	// ids are the starting node IDs for the start step.
+	ids []uint64
+
+	// direction and labels are the edges followed by hop steps.
+	direction store.Direction
+	labels    []string
+
+	// filter is applied when fetching the nodes for start, hop and filter steps.
+	filter store.Filter
+
+	// n is the limit for limit steps.
+	n int
+}
+
+// fetches returns true if the step fetches nodes from the store and can have a filter pushed into it.
+func (s step) fetches() bool {
+	return s.kind == stepStart || s.kind == stepHop || s.kind == stepFilter
+}
+
+// Traversal is a chain of steps, each step returns a new traversal so traversals can be reused and branched.
+type Traversal struct {
+	source *Source
+	steps  []step
+}
+
+// then returns a new traversal with the step appended.
+func (t *Traversal) then(s step) *Traversal {
+	return &Traversal{source: t.source, steps: append(slices.Clip(t.steps), s)}
+}
+
+// Out follows the outgoing edges with any of the labels, or all outgoing edges if no labels are given.
+func (t *Traversal) Out(labels ...string) *Traversal {
+	return t.then(step{kind: stepHop, direction: store.DirectionOut, labels: labels})
+}
+
+// In follows the incoming edges with any of the labels, or all incoming edges if no labels are given.
+func (t *Traversal) In(labels ...string) *Traversal {
+	return t.then(step{kind: stepHop, direction: store.DirectionIn, labels: labels})
+}
+
+// Both follows the outgoing and incoming edges with any of the labels, or all edges if no labels are given.
+func (t *Traversal) Both(labels ...string) *Traversal {
+	return t.then(step{kind: stepHop, direction: store.DirectionBoth, labels: labels})
+}
+
+// refine pushes the filter into the last step fetching nodes. Dedup steps are skipped over as filtering
+// before or after removing duplicates gives the same result, but a limit has to be applied first.
+func (t *Traversal) refine(apply func(f *store.Filter) bool) *Traversal {
+	steps := slices.Clone(t.steps)
+
+	for i := len(steps) - 1; i >= 0; i-- {
+		if steps[i].kind == stepDedup {
+			continue
+		}
+
+		if steps[i].fetches() {
+			f := steps[i].filter
+			f.Labels = slices.Clone(f.Labels)
+			f.Where = slices.Clone(f.Where)
+
+			if apply(&f) {
+				steps[i].filter = f
+				return &Traversal{source: t.source, steps: steps}
+			}
+		}
+		break
+	}
+
+	f := store.Filter{}
+	apply(&f)
+	return t.then(step{kind: stepFilter, filter: f})
+}
+
+// Has keeps the nodes where the property matches the value, the value is either a predicate such as
+// Gt(2000) or a plain value which must be equal. Nested properties use a dotted path, eg: `meta.hair`.
+func (t *Traversal) Has(key string, value any) *Traversal {
+	p, ok := value.(P)
+	if !ok {
+		p = Eq(value)
+	}
+
+	return t.refine(func(f *store.Filter) bool {
+		f.Where = append(f.Where, p.predicate(key))
+		return true
+	})
+}
+
+// HasLabel keeps the nodes with any of the labels.
+func (t *Traversal) HasLabel(labels ...string) *Traversal {
+	return t.refine(func(f *store.Filter) bool {
+		// labels are matched with any, a second set of labels has to be a separate step.
+		if len(f.Labels) > 0 {
+			return false
+		}
+		f.Labels = append(f.Labels, labels...)
+		return true
+	})
+}
+
+// Dedup removes duplicate nodes keeping the first one seen.
+func (t *Traversal) Dedup() *Traversal {
+	return t.then(step{kind: stepDedup})
+}
+
+// Limit stops the traversal after n nodes.
+func (t *Traversal) Limit(n int) *Traversal {
+	return t.then(step{kind: stepLimit, n: n})
+}
+
+// traverser is a node reached by the traversal along with the path taken to reach it.
+type traverser struct {
+	node  models.Node
+	nodes []models.Node
+	edges []models.Edge
+}
+
+// extend returns a new traverser reached by walking the edge to the node.
+func (tr traverser) extend(e models.Edge, n models.Node) traverser {
+	return traverser{
+		node:  n,
+		nodes: append(slices.Clip(tr.nodes), n),
+		edges: append(slices.Clip(tr.edges), e),
+	}
+}
+
+// run returns the traversers produced by the steps.
+func (t *Traversal) run(ctx context.Context) iter.Seq2[traverser, error] {
+	var seq iter.Seq2[traverser, error]
+
+	for _, s := range t.steps {
+		switch s.kind {
+		case stepStart:
+			seq = t.start(ctx, s)
+		case stepHop:
+			seq = t.hop(ctx, s, seq)
+		case stepFilter:
+			seq = t.filter(ctx, s, seq)
+		case stepDedup:
+			seq = dedup(seq)
+		case stepLimit:
+			seq = limit(s.n, seq)
+		}
+	}
+
+	return seq
+}
+
+// Iter streams the nodes reached by the traversal, stopping the traversal early stops reading from the store.
+func (t *Traversal) Iter(ctx context.Context) iter.Seq2[models.Node, error] {
+	return func(yield func(models.Node, error) bool) {
+		for tr, err := range t.run(ctx) {
+			if !yield(tr.node, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Nodes returns the nodes reached by the traversal.
+func (t *Traversal) Nodes(ctx context.Context) ([]models.Node, error) {
+	nodes := []models.Node{}
+
+	for n, err := range t.Iter(ctx) {
+		if err != nil {
+			return nodes, err
+		}
+		nodes = append(nodes, n)
+	}
+
+	return nodes, nil
+}
+
+// Count returns the number of nodes reached by the traversal.
+func (t *Traversal) Count(ctx context.Context) (int, error) {
+	count := 0
+
+	for _, err := range t.run(ctx) {
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// ToGraph returns the graph of the nodes reached by the traversal and the nodes and edges walked to reach them.
+func (t *Traversal) ToGraph(ctx context.Context) (models.Graph, error) {
+	graph := models.Graph{Nodes: []models.Node{}, Edges: []models.Edge{}}
+
+	seenNodes := map[uint64]struct{}{}
+	seenEdges := map[uint64]struct{}{}
+
+	for tr, err := range t.run(ctx) {
+		if err != nil {
+			return graph, err
+		}
+
+		for _, n := range tr.nodes {
+			if _, ok := seenNodes[n.ID]; !ok {
+				seenNodes[n.ID] = struct{}{}
+				graph.AddNodes(n)
+			}
+		}
+
+		for _, e := range tr.edges {
+			if _, ok := seenEdges[e.ID]; !ok {
+				seenEdges[e.ID] = struct{}{}
+				graph.AddEdges(e)
+			}
+		}
+	}
+
+	return graph, nil
+}
+
+// batches groups the traversers into batches of up to n traversers.
+func batches(n int, seq iter.Seq2[traverser, error]) iter.Seq2[[]traverser, error] {
+	return func(yield func([]traverser, error) bool) {
+		batch := make([]traverser, 0, n)
+
+		for tr, err := range seq {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			batch = append(batch, tr)
+			if len(batch) == n {
+				if !yield(batch, nil) {
+					return
+				}
+				batch = make([]traverser, 0, n)
+			}
+		}
+
+		if len(batch) > 0 {
+			yield(batch, nil)
+		}
+	}
+}
+
+// nodeIDs returns the unique node IDs of the traversers in order.
+func nodeIDs(batch []traverser) []uint64 {
+	ids := make([]uint64, 0, len(batch))
+	seen := make(map[uint64]struct{}, len(batch))
+
+	for _, tr := range batch {
+		if _, ok := seen[tr.node.ID]; !ok {
+			seen[tr.node.ID] = struct{}{}
+			ids = append(ids, tr.node.ID)
+		}
+	}
+
+	return ids
+}
+
+// fetch returns the nodes with the IDs matching the filter keyed by ID.
+func (t *Traversal) fetch(ctx context.Context, ids []uint64, filter store.Filter) (map[uint64]models.Node, error) {
+	nodes, err := t.source.store.Nodes(ctx, store.NodesArgs{IDs: ids, Filter: filter, Limit: len(ids)})
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[uint64]models.Node, len(nodes))
+	for _, n := range nodes {
+		found[n.ID] = n
+	}
+
+	return found, nil
+}
+
+// start produces the starting traversers.
+func (t *Traversal) start(ctx context.Context, s step) iter.Seq2[traverser, error] {
+	return func(yield func(traverser, error) bool) {
+		if len(s.ids) == 0 {
+			// page through all the nodes.
+			var lastID uint64
+
+			for {
+				nodes, err := t.source.store.Nodes(ctx, store.NodesArgs{Filter: s.filter, LastID: lastID, Limit: t.source.batch})
+				if err != nil {
+					yield(traverser{}, err)
+					return
+				}
+
+				for _, n := range nodes {
+					if !yield(traverser{node: n, nodes: []models.Node{n}}, nil) {
+						return
+					}
+				}
+
+				if len(nodes) < t.source.batch {
+					return
+				}
+				lastID = nodes[len(nodes)-1].ID
+			}
+		}
+
+		for chunk := range slices.Chunk(s.ids, t.source.batch) {
+			found, err := t.fetch(ctx, chunk, s.filter)
+			if err != nil {
+				yield(traverser{}, err)
+				return
+			}
+
+			// keep the order the IDs were given in.
+			for _, id := range chunk {
+				n, ok := found[id]
+				if !ok {
+					continue
+				}
+
+				if !yield(traverser{node: n, nodes: []models.Node{n}}, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// edges returns all the edges in the direction of the nodes keyed by the node ID they were reached from.
+func (t *Traversal) edges(ctx context.Context, ids []uint64, s step) (map[uint64][]models.Edge, error) {
+	adjacent := map[uint64][]models.Edge{}
+
+	walk := func(out bool) error {
+		args := store.EdgesArgs{Filter: store.Filter{Labels: s.labels}, Limit: edgePageSize}
+		if out {
+			args.FromIDs = ids
+		} else {
+			args.ToIDs = ids
+		}
+
+		for {
+			edges, err := t.source.store.Edges(ctx, args)
+			if err != nil {
+				return err
+			}
+
+			for _, e := range edges {
+				switch {
+				case out:
+					adjacent[e.From] = append(adjacent[e.From], e)
+				case s.direction == store.DirectionBoth && e.From == e.To:
+					// self loops were already walked as outgoing edges.
+				default:
+					adjacent[e.To] = append(adjacent[e.To], e)
+				}
+			}
+
+			if len(edges) < edgePageSize {
+				return nil
+			}
+			args.LastID = edges[len(edges)-1].ID
+		}
+	}
+
+	if s.direction != store.DirectionIn {
+		if err := walk(true); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.direction != store.DirectionOut {
+		if err := walk(false); err != nil {
+			return nil, err
+		}
+	}
+
+	// outgoing and incoming edges are fetched separately, keep them in edge order.
+	for id := range adjacent {
+		slices.SortFunc(adjacent[id], func(a, b models.Edge) int { return cmp.Compare(a.ID, b.ID) })
+	}
+
+	return adjacent, nil
+}
+
+// hop walks the edges from every traverser producing a traverser for every node reached.
+func (t *Traversal) hop(ctx context.Context, s step, in iter.Seq2[traverser, error]) iter.Seq2[traverser, error] {
+	return func(yield func(traverser, error) bool) {
+		for batch, err := range batches(t.source.batch, in) {
+			if err != nil {
+				yield(traverser{}, err)
+				return
+			}
+
+			adjacent, err := t.edges(ctx, nodeIDs(batch), s)
+			if err != nil {
+				yield(traverser{}, err)
+				return
+			}
+
+			// the other end of every edge.
+			ids := []uint64{}
+			seen := map[uint64]struct{}{}
+
+			other := func(from uint64, e models.Edge) uint64 {
+				if e.From == from {
+					return e.To
+				}
+				return e.From
+			}
+
+			for _, tr := range batch {
+				for _, e := range adjacent[tr.node.ID] {
+					id := other(tr.node.ID, e)
+					if _, ok := seen[id]; !ok {
+						seen[id] = struct{}{}
+						ids = append(ids, id)
+					}
+				}
+			}
+
+			if len(ids) == 0 {
+				continue
+			}
+
+			found := map[uint64]models.Node{}
+			for chunk := range slices.Chunk(ids, t.source.batch) {
+				nodes, err := t.fetch(ctx, chunk, s.filter)
+				if err != nil {
+					yield(traverser{}, err)
+					return
+				}
+				for id, n := range nodes {
+					found[id] = n
+				}
+			}
+
+			for _, tr := range batch {
+				for _, e := range adjacent[tr.node.ID] {
+					n, ok := found[other(tr.node.ID, e)]
+					if !ok {
+						continue
+					}
+
+					if !yield(tr.extend(e, n), nil) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// filter keeps the traversers with nodes matching the step filter.
+func (t *Traversal) filter(ctx context.Context, s step, in iter.Seq2[traverser, error]) iter.Seq2[traverser, error] {
+	return func(yield func(traverser, error) bool) {
+		for batch, err := range batches(t.source.batch, in) {
+			if err != nil {
+				yield(traverser{}, err)
+				return
+			}
+
+			found, err := t.fetch(ctx, nodeIDs(batch), s.filter)
+			if err != nil {
+				yield(traverser{}, err)
+				return
+			}
+
+			for _, tr := range batch {
+				if _, ok := found[tr.node.ID]; !ok {
+					continue
+				}
+
+				if !yield(tr, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// dedup drops the traversers for nodes which have already been seen.
+func dedup(in iter.Seq2[traverser, error]) iter.Seq2[traverser, error] {
+	return func(yield func(traverser, error) bool) {
+		seen := map[uint64]struct{}{}
+
+		for tr, err := range in {
+			if err != nil {
+				yield(traverser{}, err)
+				return
+			}
+
+			if _, ok := seen[tr.node.ID]; ok {
+				continue
+			}
+			seen[tr.node.ID] = struct{}{}
+
+			if !yield(tr, nil) {
+				return
+			}
+		}
+	}
+}
+
+// limit stops after n traversers.
+func limit(n int, in iter.Seq2[traverser, error]) iter.Seq2[traverser, error] {
+	return func(yield func(traverser, error) bool) {
+		if n <= 0 {
+			return
+		}
+
+		count := 0
+		for tr, err := range in {
+			if !yield(tr, err) || err != nil {
+				return
+			}
+
+			count++
+			if count >= n {
+				return
+			}
+		}
+	}
+}
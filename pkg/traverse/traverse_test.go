@@ -0,0 +1,213 @@
+package traverse_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenmud/edgedb/internal/store/sqlite"
+	"github.com/jenmud/edgedb/models"
+	"github.com/jenmud/edgedb/pkg/traverse"
+)
+
+// movies returns a store preloaded with a small movie graph.
+func movies(t *testing.T) *sqlite.Store {
+	t.Helper()
+
+	s, err := sqlite.New(t.Context(), ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodes := []models.Node{
+		{ID: 1, Label: "person", Properties: models.Properties{"name": "keanu"}},
+		{ID: 2, Label: "person", Properties: models.Properties{"name": "carrie"}},
+		{ID: 3, Label: "person", Properties: models.Properties{"name": "lana"}},
+		{ID: 4, Label: "person", Properties: models.Properties{"name": "chad"}},
+		{ID: 5, Label: "movie", Properties: models.Properties{"title": "the matrix", "year": 1999}},
+		{ID: 6, Label: "movie", Properties: models.Properties{"title": "the matrix reloaded", "year": 2003}},
+		{ID: 7, Label: "movie", Properties: models.Properties{"title": "john wick", "year": 2014}},
+	}
+
+	edges := []models.Edge{
+		{ID: 8, From: 1, Label: "acted_in", To: 5},
+		{ID: 9, From: 1, Label: "acted_in", To: 6},
+		{ID: 10, From: 1, Label: "acted_in", To: 7},
+		{ID: 11, From: 2, Label: "acted_in", To: 5},
+		{ID: 12, From: 2, Label: "acted_in", To: 6},
+		{ID: 13, From: 3, Label: "directed", To: 5},
+		{ID: 14, From: 3, Label: "directed", To: 6},
+		{ID: 15, From: 4, Label: "directed", To: 7},
+	}
+
+	if _, err := s.UpsertNodes(t.Context(), nodes...); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.UpsertEdges(t.Context(), edges...); err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func ids(nodes []models.Node) []uint64 {
+	out := []uint64{}
+	for _, n := range nodes {
+		out = append(out, n.ID)
+	}
+	return out
+}
+
+func TestTraversal_Nodes(t *testing.T) {
+	s := movies(t)
+
+	// a batch size of 1 makes sure the steps work across batches.
+	for _, g := range []*traverse.Source{traverse.New(s), traverse.New(s).WithBatchSize(1)} {
+		tests := []struct {
+			name      string // description of this test case
+			traversal *traverse.Traversal
+			want      []uint64
+		}{
+			{
+				name:      "start nodes in order",
+				traversal: g.V(7, 1, 99),
+				want:      []uint64{7, 1},
+			},
+			{
+				name:      "all nodes with label",
+				traversal: g.V().HasLabel("movie"),
+				want:      []uint64{5, 6, 7},
+			},
+			{
+				name:      "out",
+				traversal: g.V(1).Out("acted_in"),
+				want:      []uint64{5, 6, 7},
+			},
+			{
+				name:      "out has in",
+				traversal: g.V(1).Out("acted_in").Has("year", traverse.Gt(2000)).In("directed"),
+				want:      []uint64{3, 4},
+			},
+			{
+				name:      "duplicates are kept until dedup",
+				traversal: g.V(1, 2).Out("acted_in").In("directed"),
+				want:      []uint64{3, 3, 4, 3, 3},
+			},
+			{
+				name:      "dedup",
+				traversal: g.V(1, 2).Out("acted_in").In("directed").Dedup(),
+				want:      []uint64{3, 4},
+			},
+			{
+				name:      "both",
+				traversal: g.V(5).Both().Dedup(),
+				want:      []uint64{1, 2, 3},
+			},
+			{
+				name:      "has plain value",
+				traversal: g.V().Has("name", "carrie"),
+				want:      []uint64{2},
+			},
+			{
+				name:      "within",
+				traversal: g.V().Has("title", traverse.Within("john wick", "the matrix")),
+				want:      []uint64{5, 7},
+			},
+			{
+				name:      "limit then has filters the limited nodes",
+				traversal: g.V(1).Out().Limit(2).Has("year", traverse.Lt(2000)),
+				want:      []uint64{5},
+			},
+			{
+				name:      "limit",
+				traversal: g.V(1, 2).Out("acted_in").Limit(4),
+				want:      []uint64{5, 6, 7, 5},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := tt.traversal.Nodes(t.Context())
+				if err != nil {
+					t.Fatalf("Nodes() failed: %v", err)
+				}
+
+				if diff := cmp.Diff(tt.want, ids(got)); diff != "" {
+					t.Errorf("Nodes() = mismatch (-want, +got): \n%s", diff)
+				}
+			})
+		}
+	}
+}
+
+func TestTraversal_BothSelfLoop(t *testing.T) {
+	s := movies(t)
+	g := traverse.New(s)
+
+	if _, err := s.UpsertEdges(t.Context(), models.Edge{ID: 16, From: 7, Label: "sequel_of", To: 7}); err != nil {
+		t.Fatal(err)
+	}
+
+	nodes, err := g.V(7).Both().Nodes(t.Context())
+	if err != nil {
+		t.Fatalf("Nodes() failed: %v", err)
+	}
+
+	// the self loop is walked once even though it is both an outgoing and an incoming edge.
+	if diff := cmp.Diff([]uint64{1, 4, 7}, ids(nodes)); diff != "" {
+		t.Errorf("Nodes() mismatch (-want, +got): \n%s", diff)
+	}
+}
+
+func TestTraversal_ToGraph(t *testing.T) {
+	s := movies(t)
+	g := traverse.New(s)
+
+	graph, err := g.V(1).Out("acted_in").Has("year", traverse.Gt(2000)).In("directed").Dedup().Limit(20).ToGraph(t.Context())
+	if err != nil {
+		t.Fatalf("ToGraph() failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]uint64{1, 6, 3, 7, 4}, ids(graph.Nodes)); diff != "" {
+		t.Errorf("ToGraph() nodes mismatch (-want, +got): \n%s", diff)
+	}
+
+	edges := []uint64{}
+	for _, e := range graph.Edges {
+		edges = append(edges, e.ID)
+	}
+
+	if diff := cmp.Diff([]uint64{9, 14, 10, 15}, edges); diff != "" {
+		t.Errorf("ToGraph() edges mismatch (-want, +got): \n%s", diff)
+	}
+}
+
+func TestTraversal_Iter(t *testing.T) {
+	s := movies(t)
+	g := traverse.New(s)
+
+	count := 0
+	for _, err := range g.V().Iter(t.Context()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		count++
+		if count == 2 {
+			break
+		}
+	}
+
+	if count != 2 {
+		t.Errorf("Iter() stopped after %d nodes, want 2", count)
+	}
+
+	total, err := g.V().HasLabel("person").Out().Count(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total != 8 {
+		t.Errorf("Count() = %d, want 8", total)
+	}
+}